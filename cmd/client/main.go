@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/fngoc/gault/internal/client"
 	"github.com/fngoc/gault/internal/config"
@@ -11,6 +18,8 @@ import (
 	"github.com/fngoc/gault/pkg/logger"
 
 	"github.com/rivo/tview"
+	"go.uber.org/zap"
+	"golang.org/x/term"
 	"google.golang.org/grpc"
 )
 
@@ -25,10 +34,17 @@ func main() {
 	}
 }
 
-// run запуск клиента
+// run запуск клиента, подкоманда config print выводит действующую конфигурацию
 func run() error {
-	versionFlag := flag.Bool("version", false, "Print version and exit")
-	flag.Parse()
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	versionFlag := fs.Bool("version", false, "Print version and exit")
+	loader := config.NewLoader("client_config", fs)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
 
 	if *versionFlag {
 		fmt.Printf("Version: %s\nBuild date: %s\n", Version, BuildDate)
@@ -41,19 +57,36 @@ func run() error {
 	}
 
 	logger.LogInfo("Starting client")
-	logger.LogInfo(fmt.Sprintf("Version: %s, Build date: %s", Version, BuildDate))
+	logger.LogInfo("build info", zap.String("version", Version), zap.String("build_date", BuildDate))
 
-	conf, err := config.ParseConfig("client_config")
+	conf, err := loader.Load()
 	if err != nil {
 		return err
 	}
+	if fs.Arg(0) == "config" {
+		return runConfig(conf, fs.Args()[1:])
+	}
+	if err = conf.ValidateClient(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err = logger.Configure(conf.Log.Options()); err != nil {
+		return err
+	}
+	defer logger.Sync()
+
+	switch fs.Arg(0) {
+	case "totp":
+		return runTOTP(conf, fs.Args()[1:])
+	case "ssh-agent":
+		return runSSHAgent(conf, fs.Args()[1:])
+	}
 
 	var conn *grpc.ClientConn
 	go func() {
 		var err error
-		conn, err = client.GrpcClient(conf.Port)
+		conn, err = client.GrpcClient(conf)
 		if err != nil {
-			logger.LogFatal(err.Error())
+			logger.LogFatal("failed to connect", zap.Error(err))
 		}
 	}()
 
@@ -62,8 +95,108 @@ func run() error {
 	}
 
 	app := tview.NewApplication()
-	if err = client.TUIClientWithApp(app, conf.Aes); err != nil {
+	if err = client.TUIClientWithApp(app, conf); err != nil {
+		return err
+	}
+	return nil
+}
+
+// runConfig подкоманда config print: действующая конфигурация после всех переопределений
+// со скрытыми секретами. Ошибки проверки выводятся после конфигурации
+func runConfig(conf config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: client config print")
+	}
+	if err := config.Print(os.Stdout, conf); err != nil {
 		return err
 	}
+	if err := conf.ValidateClient(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
 	return nil
 }
+
+// runTOTP подкоманда totp: вывод текущего кода TOTP элемента хранилища без запуска TUI.
+// Пароль берётся из GAULT_PASSWORD или запрашивается в терминале
+func runTOTP(conf config.Config, args []string) error {
+	fs := flag.NewFlagSet("totp", flag.ContinueOnError)
+	login := fs.String("login", "", "Account login")
+	name := fs.String("name", "", "Name of the TOTP item")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" || *name == "" {
+		return errors.New("usage: client totp -login <login> -name <item name>")
+	}
+
+	password, err := accountPassword()
+	if err != nil {
+		return err
+	}
+
+	conn, err := client.GrpcClient(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return client.PrintTOTP(os.Stdout, conf.Aes, *login, password, *name, func() (string, error) {
+		return readSecret("Two-factor code: ")
+	})
+}
+
+// runSSHAgent подкоманда ssh-agent: ключи ssh_key хранилища обслуживаются через Unix-сокет
+// до SIGINT или SIGTERM. Пароль берётся из GAULT_PASSWORD или запрашивается в терминале
+func runSSHAgent(conf config.Config, args []string) error {
+	fs := flag.NewFlagSet("ssh-agent", flag.ContinueOnError)
+	login := fs.String("login", "", "Account login")
+	socket := fs.String("socket", "", "Agent socket path, a temporary directory is used by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *login == "" {
+		return errors.New("usage: client ssh-agent -login <login> [-socket <path>]")
+	}
+
+	password, err := accountPassword()
+	if err != nil {
+		return err
+	}
+
+	conn, err := client.GrpcClient(conf)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return client.RunSSHAgent(ctx, os.Stdout, conf.Aes, *login, password, *socket, func() (string, error) {
+		return readSecret("Two-factor code: ")
+	})
+}
+
+// accountPassword пароль учётной записи из GAULT_PASSWORD или из терминала
+func accountPassword() (string, error) {
+	if password, ok := os.LookupEnv("GAULT_PASSWORD"); ok {
+		return password, nil
+	}
+	return readSecret("Password: ")
+}
+
+// readSecret запрос строки в терминале без эха, приглашение выводится в stderr
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		return string(secret), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -1,21 +1,50 @@
 package main
 
 import (
-	"flag"
 	"os"
 	"testing"
 
+	"github.com/fngoc/gault/internal/config"
+
 	"github.com/stretchr/testify/assert"
 )
 
-func resetFlags() {
-	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+// withArgs подменяет аргументы командной строки на время теста
+func withArgs(t *testing.T, args ...string) {
+	t.Helper()
+	saved := os.Args
+	t.Cleanup(func() { os.Args = saved })
+	os.Args = append([]string{"cmd"}, args...)
 }
 
 func TestRun_VersionFlag(t *testing.T) {
-	resetFlags()
-	os.Args = []string{"cmd", "--version"}
+	withArgs(t, "--version")
+
+	err := run()
+	assert.NoError(t, err)
+}
+
+func TestRun_WeakKey(t *testing.T) {
+	withArgs(t, "-aes", "00000000000000000000000000000000")
+
+	err := run()
+	assert.ErrorContains(t, err, "aes: key is too weak")
+}
+
+func TestRun_ConfigPrint(t *testing.T) {
+	t.Setenv("GAULT_AES", "k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2")
+	withArgs(t, "config", "print")
 
 	err := run()
 	assert.NoError(t, err)
 }
+
+func TestRunTOTP_Usage(t *testing.T) {
+	err := runTOTP(config.Config{}, []string{"-login", "alice"})
+	assert.ErrorContains(t, err, "usage")
+}
+
+func TestRunSSHAgent_Usage(t *testing.T) {
+	err := runSSHAgent(config.Config{}, nil)
+	assert.ErrorContains(t, err, "usage")
+}
@@ -1,12 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"sort"
 
 	"github.com/fngoc/gault/internal/config"
 	"github.com/fngoc/gault/internal/db"
 	wire "github.com/fngoc/gault/internal/injector"
 	"github.com/fngoc/gault/internal/server"
+	"github.com/fngoc/gault/pkg/logger"
+	"github.com/fngoc/gault/pkg/secrets"
 )
 
 func main() {
@@ -15,25 +25,175 @@ func main() {
 	}
 }
 
-// run запуск сервера
+// run запуск сервера, подкоманда healthcheck проверяет готовность запущенного сервера,
+// config print выводит действующую конфигурацию, secret encrypt шифрует секрет через KMS,
+// keys rotate и keys status управляют мастер-ключами шифрования данных
 func run() error {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	loader := config.NewLoader("server_config", fs)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
 	err := wire.InitializeLogger()
 	if err != nil {
 		return err
 	}
 
-	conf, err := config.ParseConfig("server_config")
+	conf, err := loader.Load()
 	if err != nil {
 		return err
 	}
 
-	store, err := db.InitializePostgresDB(conf.DB)
+	switch fs.Arg(0) {
+	case "config":
+		return runConfig(conf, fs.Args()[1:])
+	case "secret":
+		return runSecret(os.Stdin, os.Stdout, conf, fs.Args()[1:])
+	}
+	if err = conf.ValidateServer(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err = logger.Configure(conf.Log.Options()); err != nil {
+		return err
+	}
+	defer logger.Sync()
+
+	if fs.Arg(0) == "healthcheck" {
+		return server.CheckHealth(conf)
+	}
+
+	keys, err := newKeyring(conf.Encryption)
 	if err != nil {
 		return err
 	}
+	store, err := db.InitializePostgresDB(conf.DB, keys)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if fs.Arg(0) == "keys" {
+		return runKeys(context.Background(), os.Stdout, store, conf.Encryption.KeyID, fs.Args()[1:])
+	}
+
+	if err = server.Run(conf, store); err != nil {
+		return err
+	}
+	return nil
+}
+
+// runConfig подкоманда config print: действующая конфигурация после всех переопределений
+// со скрытыми секретами. Ошибки проверки выводятся после конфигурации
+func runConfig(conf config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New("usage: server config print")
+	}
+	if err := config.Print(os.Stdout, conf); err != nil {
+		return err
+	}
+	if err := conf.ValidateServer(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
 
-	if err = server.Run(conf.Port, conf.AllowEndpoints, store); err != nil {
+// runSecret подкоманда secret encrypt -key <id>: шифрует секрет из stdin через KMS из конфигурации
+// и выводит ссылку kms:, которую можно подставить в db или aes
+func runSecret(in io.Reader, out io.Writer, conf config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "encrypt" {
+		return errors.New("usage: server secret encrypt -key <key id> < secret")
+	}
+	fs := flag.NewFlagSet("secret encrypt", flag.ContinueOnError)
+	keyID := fs.String("key", "", "KMS key id")
+	if err := fs.Parse(args[1:]); err != nil {
 		return err
 	}
+	if *keyID == "" {
+		return errors.New("usage: server secret encrypt -key <key id> < secret")
+	}
+	if conf.KMS == "" {
+		return errors.New("kms is not configured, set kms or GAULT_KMS")
+	}
+
+	kms, err := secrets.OpenKMS(conf.KMS)
+	if err != nil {
+		return err
+	}
+	plaintext, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	ciphertext, err := kms.Encrypt(context.Background(), *keyID, bytes.TrimRight(plaintext, "\r\n"))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, secrets.Reference(*keyID, ciphertext))
+	return err
+}
+
+// newKeyring мастер-ключи из конфигурации, nil — шифрование данных на сервере выключено
+func newKeyring(conf config.Encryption) (*db.Keyring, error) {
+	if conf.KeyID == "" {
+		return nil, nil
+	}
+	keys, err := conf.MasterKeys()
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	return db.NewKeyring(conf.KeyID, keys)
+}
+
+// runKeys подкоманда keys rotate|status. rotate перешифровывает ключи данных мастер-ключом
+// encryption.keyId без остановки сервера, status выводит число записей по мастер-ключам.
+// Прежний ключ можно убрать из encryption.keys, когда для него не осталось записей
+func runKeys(ctx context.Context, out io.Writer, store db.Repository, currentKeyID string, args []string) error {
+	if len(args) != 1 || (args[0] != "rotate" && args[0] != "status") {
+		return errors.New("usage: server keys rotate|status")
+	}
+	if args[0] == "rotate" {
+		if currentKeyID == "" {
+			return errors.New("encryption is not configured, set encryption.keyId and encryption.keys")
+		}
+		n, err := store.RewrapDataKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("rotation stopped after %d data keys: %w", n, err)
+		}
+		if _, err := fmt.Fprintf(out, "rewrapped %d data keys with %s\n", n, currentKeyID); err != nil {
+			return err
+		}
+	}
+
+	counts, err := store.CountDataKeys(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		name := id
+		switch id {
+		case "":
+			name = "(not encrypted)"
+		case currentKeyID:
+			name += " (current)"
+		}
+		if _, err := fmt.Fprintf(out, "%s\t%d\n", name, counts[id]); err != nil {
+			return err
+		}
+	}
+	// Ротация меняет только обёртку ключей данных, записи без ключа остаются открытыми
+	if plain := counts[""]; plain > 0 && currentKeyID != "" {
+		if _, err := fmt.Fprintf(out, "%d items were saved before server-side encryption and stay unencrypted "+
+			"until they are updated, keys rotate does not encrypt them\n", plain); err != nil {
+			return err
+		}
+	}
 	return nil
 }
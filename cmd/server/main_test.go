@@ -1,12 +1,129 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	mockDB "github.com/fngoc/gault/gen/go/db"
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/secrets"
+
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// withArgs подменяет аргументы командной строки на время теста
+func withArgs(t *testing.T, args ...string) {
+	t.Helper()
+	saved := os.Args
+	t.Cleanup(func() { os.Args = saved })
+	os.Args = append([]string{"cmd"}, args...)
+}
+
 func TestRun_Success(t *testing.T) {
+	withArgs(t, "-db", "host=127.0.0.1 port=1 user=gault dbname=gault connect_timeout=1")
+
+	err := run()
+	assert.Error(t, err)
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	withArgs(t)
+
+	err := run()
+	assert.ErrorContains(t, err, "db: connection string is required")
+}
+
+func TestRun_HealthcheckWithoutServer(t *testing.T) {
+	t.Setenv("GAULT_DB", "host=localhost")
+	withArgs(t, "-port", "1", "healthcheck")
+
 	err := run()
 	assert.Error(t, err)
 }
+
+func TestRun_ConfigPrint(t *testing.T) {
+	t.Setenv("GAULT_DB", "postgres://gault:secret@db/gault")
+	withArgs(t, "config", "print")
+	assert.NoError(t, run())
+
+	withArgs(t, "config", "show")
+	assert.ErrorContains(t, run(), "usage")
+}
+
+func TestRunSecret(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db"), []byte(strings.Repeat("0f", 32)), 0o600))
+	conf := config.Config{KMS: "file://" + dir}
+
+	var out bytes.Buffer
+	err := runSecret(strings.NewReader("postgres://gault:secret@db/gault\n"), &out, conf, []string{"encrypt", "-key", "db"})
+	require.NoError(t, err)
+
+	ref := strings.TrimSpace(out.String())
+	assert.True(t, strings.HasPrefix(ref, "kms:db:"))
+	kms, err := secrets.OpenKMS(conf.KMS)
+	require.NoError(t, err)
+	plain, err := secrets.Resolver{KMS: kms}.Resolve(context.Background(), ref)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://gault:secret@db/gault", plain)
+
+	assert.ErrorContains(t, runSecret(nil, &out, conf, []string{"encrypt"}), "usage")
+	assert.ErrorContains(t, runSecret(nil, &out, config.Config{}, []string{"encrypt", "-key", "db"}), "kms is not configured")
+}
+
+func TestNewKeyring(t *testing.T) {
+	keys, err := newKeyring(config.Encryption{})
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+
+	keys, err = newKeyring(config.Encryption{KeyID: "k1", Keys: map[string]string{"k1": strings.Repeat("ab", 32)}})
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keys.CurrentKeyID())
+
+	_, err = newKeyring(config.Encryption{KeyID: "k1", Keys: map[string]string{"k1": "zz"}})
+	assert.ErrorContains(t, err, "encryption:")
+}
+
+func TestRunKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockDB.NewMockRepository(ctrl)
+	ctx := context.Background()
+
+	t.Run("rotate", func(t *testing.T) {
+		repo.EXPECT().RewrapDataKeys(ctx).Return(int64(3), nil)
+		repo.EXPECT().CountDataKeys(ctx).Return(map[string]int64{"k2": 5, "": 1}, nil)
+
+		var out bytes.Buffer
+		require.NoError(t, runKeys(ctx, &out, repo, "k2", []string{"rotate"}))
+		assert.Equal(t, "rewrapped 3 data keys with k2\n(not encrypted)\t1\nk2 (current)\t5\n"+
+			"1 items were saved before server-side encryption and stay unencrypted until they are updated, "+
+			"keys rotate does not encrypt them\n", out.String())
+	})
+	t.Run("status", func(t *testing.T) {
+		repo.EXPECT().CountDataKeys(ctx).Return(map[string]int64{"k1": 2, "k2": 5}, nil)
+
+		var out bytes.Buffer
+		require.NoError(t, runKeys(ctx, &out, repo, "k2", []string{"status"}))
+		assert.Equal(t, "k1\t2\nk2 (current)\t5\n", out.String())
+	})
+	t.Run("rotate error", func(t *testing.T) {
+		repo.EXPECT().RewrapDataKeys(ctx).Return(int64(100), errors.New("unknown master key"))
+
+		err := runKeys(ctx, io.Discard, repo, "k2", []string{"rotate"})
+		assert.ErrorContains(t, err, "rotation stopped after 100 data keys")
+	})
+	t.Run("not configured", func(t *testing.T) {
+		assert.ErrorContains(t, runKeys(ctx, io.Discard, repo, "", []string{"rotate"}), "encryption is not configured")
+	})
+	t.Run("usage", func(t *testing.T) {
+		assert.ErrorContains(t, runKeys(ctx, io.Discard, repo, "k2", nil), "usage")
+	})
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+
+CREATE TABLE folders
+(
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  UUID REFERENCES folders (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ      DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN folder_id UUID REFERENCES folders (id) ON DELETE SET NULL;
+
+CREATE TABLE data_tags
+(
+    data_id UUID        NOT NULL REFERENCES user_data (id) ON DELETE CASCADE,
+    tag     VARCHAR(50) NOT NULL,
+    PRIMARY KEY (data_id, tag)
+);
+
+CREATE INDEX folders_user_id_idx ON folders (user_id);
+CREATE INDEX user_data_folder_id_idx ON user_data (folder_id);
+CREATE INDEX data_tags_tag_idx ON data_tags (tag);
+
+-- +goose Down
+DROP INDEX IF EXISTS data_tags_tag_idx;
+DROP INDEX IF EXISTS user_data_folder_id_idx;
+DROP INDEX IF EXISTS folders_user_id_idx;
+DROP TABLE IF EXISTS data_tags;
+ALTER TABLE user_data DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,37 @@
+-- +goose Up
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+UPDATE user_data
+SET created_at = NOW()
+WHERE created_at IS NULL;
+
+ALTER TABLE user_data
+    ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE user_data
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE user_data
+    ADD COLUMN size_bytes BIGINT NOT NULL DEFAULT 0;
+
+UPDATE user_data
+SET updated_at = created_at,
+    size_bytes = COALESCE((SELECT SUM(LENGTH(lo.data))
+                           FROM pg_largeobject lo
+                           WHERE lo.loid = user_data.largeobject_oid), 0);
+
+CREATE INDEX user_data_user_name_idx ON user_data (user_id, data_name, id);
+CREATE INDEX user_data_user_created_idx ON user_data (user_id, created_at DESC, id DESC);
+CREATE INDEX user_data_user_updated_idx ON user_data (user_id, updated_at DESC, id DESC);
+CREATE INDEX user_data_user_size_idx ON user_data (user_id, size_bytes DESC, id DESC);
+CREATE INDEX user_data_name_trgm_idx ON user_data USING gin (data_name gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_data_name_trgm_idx;
+DROP INDEX IF EXISTS user_data_user_size_idx;
+DROP INDEX IF EXISTS user_data_user_updated_idx;
+DROP INDEX IF EXISTS user_data_user_created_idx;
+DROP INDEX IF EXISTS user_data_user_name_idx;
+ALTER TABLE user_data DROP COLUMN IF EXISTS size_bytes;
+ALTER TABLE user_data DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE user_data
+    ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,30 @@
+-- +goose Up
+
+CREATE TABLE user_keys
+(
+    user_id               UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    public_key            BYTEA       NOT NULL,
+    encrypted_private_key TEXT        NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN data_key TEXT;
+
+CREATE TABLE data_shares
+(
+    data_id      UUID        NOT NULL REFERENCES user_data (id) ON DELETE CASCADE,
+    recipient_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission   VARCHAR(16) NOT NULL CHECK (permission IN ('read', 'read_write')),
+    wrapped_key  BYTEA       NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (data_id, recipient_id)
+);
+
+CREATE INDEX data_shares_recipient_id_idx ON data_shares (recipient_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS data_shares_recipient_id_idx;
+DROP TABLE IF EXISTS data_shares;
+ALTER TABLE user_data DROP COLUMN IF EXISTS data_key;
+DROP TABLE IF EXISTS user_keys;
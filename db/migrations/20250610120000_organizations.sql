@@ -0,0 +1,57 @@
+-- +goose Up
+
+CREATE TABLE organizations
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE org_members
+(
+    org_id      UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read_only')),
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE TABLE org_invitations
+(
+    id          UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    org_id      UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    invitee_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    inviter_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read_only')),
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, invitee_id)
+);
+
+CREATE TABLE collections
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    org_id     UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN collection_id UUID REFERENCES collections (id) ON DELETE CASCADE;
+
+CREATE INDEX org_members_user_id_idx ON org_members (user_id);
+CREATE INDEX org_invitations_invitee_id_idx ON org_invitations (invitee_id);
+CREATE INDEX collections_org_id_idx ON collections (org_id);
+CREATE INDEX user_data_collection_id_idx ON user_data (collection_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_data_collection_id_idx;
+DROP INDEX IF EXISTS collections_org_id_idx;
+DROP INDEX IF EXISTS org_invitations_invitee_id_idx;
+DROP INDEX IF EXISTS org_members_user_id_idx;
+ALTER TABLE user_data DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS org_invitations;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TABLE audit_events
+(
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     UUID,
+    login       VARCHAR(64) NOT NULL DEFAULT '',
+    action      VARCHAR(32) NOT NULL,
+    data_id     UUID,
+    client_addr TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, id DESC);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_events
+    FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP INDEX IF EXISTS audit_events_user_id_idx;
+DROP TABLE IF EXISTS audit_events;
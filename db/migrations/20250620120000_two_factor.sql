@@ -0,0 +1,35 @@
+-- +goose Up
+
+CREATE TABLE user_totp
+(
+    user_id        UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT        NOT NULL,
+    enabled        BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT      NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE recovery_codes
+(
+    id        UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    user_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT        NOT NULL,
+    used_at   TIMESTAMPTZ
+);
+
+CREATE TABLE login_challenges
+(
+    token      TEXT PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    attempts   INT         NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+CREATE INDEX login_challenges_user_id_idx ON login_challenges (user_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS login_challenges;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,16 @@
+-- +goose Up
+
+CREATE TABLE data_rotation
+(
+    data_id       UUID PRIMARY KEY REFERENCES user_data (id) ON DELETE CASCADE,
+    expires_at    TIMESTAMPTZ,
+    rotation_days INT         NOT NULL DEFAULT 0 CHECK (rotation_days >= 0),
+    rotation_due  BOOLEAN     NOT NULL DEFAULT FALSE,
+    flagged_at    TIMESTAMPTZ
+);
+
+CREATE INDEX data_rotation_due_idx ON data_rotation (rotation_due);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS data_rotation;
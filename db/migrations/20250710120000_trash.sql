@@ -0,0 +1,12 @@
+-- +goose Up
+
+ALTER TABLE user_data
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX user_data_deleted_at_idx ON user_data (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS user_data_deleted_at_idx;
+ALTER TABLE user_data
+    DROP COLUMN IF EXISTS deleted_at;
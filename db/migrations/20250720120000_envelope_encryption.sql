@@ -0,0 +1,14 @@
+-- +goose Up
+
+ALTER TABLE user_data
+    ADD COLUMN key_id      TEXT,
+    ADD COLUMN wrapped_key BYTEA;
+
+CREATE INDEX user_data_key_id_idx ON user_data (key_id) WHERE key_id IS NOT NULL;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS user_data_key_id_idx;
+ALTER TABLE user_data
+    DROP COLUMN IF EXISTS wrapped_key,
+    DROP COLUMN IF EXISTS key_id;
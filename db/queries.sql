@@ -1,7 +1,78 @@
--- name: ListUserData :many
-SELECT id, data_type, data_name
-FROM user_data
-WHERE user_id = $1;
+-- name: ListUserDataByName :many
+SELECT d.*
+FROM user_data d
+WHERE ((d.user_id = sqlc.arg('user_id') AND d.collection_id IS NULL AND sqlc.narg('org_id')::uuid IS NULL)
+    OR d.collection_id IN (SELECT c.id FROM collections c WHERE c.org_id = sqlc.narg('org_id')))
+  AND d.deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR d.collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('folder_id')::uuid IS NULL OR d.folder_id = sqlc.narg('folder_id'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (SELECT 1
+                                                 FROM data_tags t
+                                                 WHERE t.data_id = d.id
+                                                   AND t.tag = sqlc.narg('tag')))
+  AND (sqlc.narg('data_type')::text IS NULL OR d.data_type = sqlc.narg('data_type'))
+  AND (sqlc.narg('name_pattern')::text IS NULL OR d.data_name ILIKE sqlc.narg('name_pattern'))
+  AND (sqlc.narg('cursor_id')::uuid IS NULL OR
+       (d.data_name, d.id) > (sqlc.narg('cursor_key')::text, sqlc.narg('cursor_id')::uuid))
+ORDER BY d.data_name, d.id
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListUserDataByCreated :many
+SELECT d.*
+FROM user_data d
+WHERE ((d.user_id = sqlc.arg('user_id') AND d.collection_id IS NULL AND sqlc.narg('org_id')::uuid IS NULL)
+    OR d.collection_id IN (SELECT c.id FROM collections c WHERE c.org_id = sqlc.narg('org_id')))
+  AND d.deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR d.collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('folder_id')::uuid IS NULL OR d.folder_id = sqlc.narg('folder_id'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (SELECT 1
+                                                 FROM data_tags t
+                                                 WHERE t.data_id = d.id
+                                                   AND t.tag = sqlc.narg('tag')))
+  AND (sqlc.narg('data_type')::text IS NULL OR d.data_type = sqlc.narg('data_type'))
+  AND (sqlc.narg('name_pattern')::text IS NULL OR d.data_name ILIKE sqlc.narg('name_pattern'))
+  AND (sqlc.narg('cursor_id')::uuid IS NULL OR
+       (d.created_at, d.id) < (sqlc.narg('cursor_key')::text::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListUserDataByUpdated :many
+SELECT d.*
+FROM user_data d
+WHERE ((d.user_id = sqlc.arg('user_id') AND d.collection_id IS NULL AND sqlc.narg('org_id')::uuid IS NULL)
+    OR d.collection_id IN (SELECT c.id FROM collections c WHERE c.org_id = sqlc.narg('org_id')))
+  AND d.deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR d.collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('folder_id')::uuid IS NULL OR d.folder_id = sqlc.narg('folder_id'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (SELECT 1
+                                                 FROM data_tags t
+                                                 WHERE t.data_id = d.id
+                                                   AND t.tag = sqlc.narg('tag')))
+  AND (sqlc.narg('data_type')::text IS NULL OR d.data_type = sqlc.narg('data_type'))
+  AND (sqlc.narg('name_pattern')::text IS NULL OR d.data_name ILIKE sqlc.narg('name_pattern'))
+  AND (sqlc.narg('cursor_id')::uuid IS NULL OR
+       (d.updated_at, d.id) < (sqlc.narg('cursor_key')::text::timestamptz, sqlc.narg('cursor_id')::uuid))
+ORDER BY d.updated_at DESC, d.id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListUserDataBySize :many
+SELECT d.*
+FROM user_data d
+WHERE ((d.user_id = sqlc.arg('user_id') AND d.collection_id IS NULL AND sqlc.narg('org_id')::uuid IS NULL)
+    OR d.collection_id IN (SELECT c.id FROM collections c WHERE c.org_id = sqlc.narg('org_id')))
+  AND d.deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR d.collection_id = sqlc.narg('collection_id'))
+  AND (sqlc.narg('folder_id')::uuid IS NULL OR d.folder_id = sqlc.narg('folder_id'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (SELECT 1
+                                                 FROM data_tags t
+                                                 WHERE t.data_id = d.id
+                                                   AND t.tag = sqlc.narg('tag')))
+  AND (sqlc.narg('data_type')::text IS NULL OR d.data_type = sqlc.narg('data_type'))
+  AND (sqlc.narg('name_pattern')::text IS NULL OR d.data_name ILIKE sqlc.narg('name_pattern'))
+  AND (sqlc.narg('cursor_id')::uuid IS NULL OR
+       (d.size_bytes, d.id) < (sqlc.narg('cursor_key')::text::bigint, sqlc.narg('cursor_id')::uuid))
+ORDER BY d.size_bytes DESC, d.id DESC
+LIMIT sqlc.arg('page_limit');
 
 -- name: CreateUser :one
 INSERT INTO users (username, password_hash)
@@ -19,9 +90,10 @@ SELECT EXISTS (SELECT 1
                  AND session_token = $2);
 
 -- name: GetUserCredentialsByUsername :one
-SELECT id, password_hash
-FROM users
-WHERE username = $1;
+SELECT u.id, u.password_hash, COALESCE(t.enabled, FALSE)::boolean AS totp_enabled
+FROM users u
+         LEFT JOIN user_totp t ON t.user_id = u.id
+WHERE u.username = $1;
 
 -- name: DeleteUserData :exec
 DELETE
@@ -33,7 +105,7 @@ INSERT INTO user_sessions (user_id, session_token, expires_at)
 VALUES ($1, $2, NOW() + INTERVAL '20 minutes');
 
 -- name: GetDataInfoByID :one
-SELECT data_type, data_name, largeobject_oid
+SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key
 FROM user_data
 WHERE id = $1;
 
@@ -41,7 +113,563 @@ WHERE id = $1;
 INSERT INTO user_data (id, user_id, data_type, data_name, largeobject_oid)
 VALUES ($1, $2, $3, $4, $5);
 
+-- name: UpdateUserDataSize :exec
+UPDATE user_data
+SET size_bytes = $1,
+    updated_at = NOW()
+WHERE id = $2;
+
 -- name: GetOidByID :one
 SELECT largeobject_oid
 FROM user_data
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListDataTagsByIDs :many
+SELECT data_id, tag
+FROM data_tags
+WHERE data_id = ANY (sqlc.arg('ids')::uuid[])
+ORDER BY tag;
+
+-- name: CreateFolder :one
+INSERT INTO folders (user_id, parent_id, name)
+VALUES ($1, $2, $3) RETURNING id;
+
+-- name: RenameFolder :execrows
+UPDATE folders
+SET name = $1
+WHERE id = $2
+  AND user_id = $3;
+
+-- name: MoveFolder :execrows
+UPDATE folders
+SET parent_id = $1
+WHERE id = $2
+  AND user_id = $3;
+
+-- name: DeleteFolder :execrows
+DELETE
+FROM folders
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: ListFolders :many
+SELECT id, parent_id, name
+FROM folders
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: IsFolderOwner :one
+SELECT EXISTS (SELECT 1
+               FROM folders
+               WHERE id = $1
+                 AND user_id = $2);
+
+-- name: LockUserFolders :exec
+SELECT id
+FROM folders
+WHERE user_id = $1
+ORDER BY id
+    FOR UPDATE;
+
+-- name: IsFolderInSubtree :one
+WITH RECURSIVE subtree AS (SELECT f.id
+                           FROM folders f
+                           WHERE f.id = sqlc.arg('root_id')
+                           UNION ALL
+                           SELECT c.id
+                           FROM folders c
+                                    JOIN subtree s ON c.parent_id = s.id)
+SELECT EXISTS (SELECT 1 FROM subtree WHERE subtree.id = sqlc.arg('folder_id'));
+
+-- name: MoveUserData :execrows
+UPDATE user_data
+SET folder_id = $1
+WHERE id = $2
+  AND user_id = $3
+  AND deleted_at IS NULL;
+
+-- name: IsDataOwner :one
+SELECT EXISTS (SELECT 1
+               FROM user_data
+               WHERE id = $1
+                 AND user_id = $2
+                 AND collection_id IS NULL
+                 AND deleted_at IS NULL);
+
+-- name: DeleteDataTags :exec
+DELETE
+FROM data_tags
+WHERE data_id = $1;
+
+-- name: InsertDataTag :exec
+INSERT INTO data_tags (data_id, tag)
+VALUES ($1, $2) ON CONFLICT DO NOTHING;
+-- name: UpsertUserKeys :exec
+INSERT INTO user_keys (user_id, public_key, encrypted_private_key)
+VALUES ($1, $2, $3) ON CONFLICT (user_id) DO
+UPDATE
+SET public_key            = EXCLUDED.public_key,
+    encrypted_private_key = EXCLUDED.encrypted_private_key,
+    updated_at            = NOW();
+
+-- name: GetUserKeys :one
+SELECT public_key, encrypted_private_key
+FROM user_keys
+WHERE user_id = $1;
+
+-- name: GetPublicKeyByUsername :one
+SELECT u.id, k.public_key
+FROM users u
+         JOIN user_keys k ON k.user_id = u.id
+WHERE u.username = $1;
+
+-- name: UpsertDataShare :exec
+INSERT INTO data_shares (data_id, recipient_id, permission, wrapped_key)
+VALUES ($1, $2, $3, $4) ON CONFLICT (data_id, recipient_id) DO
+UPDATE
+SET permission  = EXCLUDED.permission,
+    wrapped_key = EXCLUDED.wrapped_key;
+
+-- name: DeleteDataShare :execrows
+DELETE
+FROM data_shares s USING user_data d
+WHERE s.data_id = d.id
+  AND s.data_id = $1
+  AND s.recipient_id = $2
+  AND d.user_id = $3;
+
+-- name: ListDataShares :many
+SELECT s.recipient_id, u.username, s.permission
+FROM data_shares s
+         JOIN users u ON u.id = s.recipient_id
+WHERE s.data_id = $1
+ORDER BY u.username;
+
+-- name: ListSharedWithUser :many
+SELECT d.id, d.data_type, d.data_name, u.username AS owner_name, s.permission
+FROM data_shares s
+         JOIN user_data d ON d.id = s.data_id
+         JOIN users u ON u.id = d.user_id
+WHERE s.recipient_id = $1
+  AND d.deleted_at IS NULL
+ORDER BY d.data_name, d.id;
+
+-- name: GetDataAccess :one
+SELECT (d.user_id = sqlc.arg('user_id')::uuid AND d.collection_id IS NULL) AS is_owner,
+       d.data_key,
+       s.permission,
+       s.wrapped_key,
+       m.role AS org_role
+FROM user_data d
+         LEFT JOIN data_shares s ON s.data_id = d.id AND s.recipient_id = sqlc.arg('user_id')::uuid
+         LEFT JOIN collections c ON c.id = d.collection_id
+         LEFT JOIN org_members m ON m.org_id = c.org_id AND m.user_id = sqlc.arg('user_id')::uuid
+WHERE d.id = sqlc.arg('id')
+  AND d.deleted_at IS NULL
+  AND ((d.user_id = sqlc.arg('user_id')::uuid AND d.collection_id IS NULL)
+    OR s.recipient_id IS NOT NULL
+    OR m.user_id IS NOT NULL);
+
+-- name: SetUserDataKey :exec
+UPDATE user_data
+SET data_key = $1
+WHERE id = $2;
+
+-- name: CreateOrganization :one
+INSERT INTO organizations (name)
+VALUES ($1) RETURNING id;
+
+-- name: UpsertOrgMember :exec
+INSERT INTO org_members (org_id, user_id, role, wrapped_key)
+VALUES ($1, $2, $3, $4) ON CONFLICT (org_id, user_id) DO
+UPDATE
+SET role        = EXCLUDED.role,
+    wrapped_key = EXCLUDED.wrapped_key;
+
+-- name: ListUserOrganizations :many
+SELECT o.id, o.name, m.role, m.wrapped_key
+FROM org_members m
+         JOIN organizations o ON o.id = m.org_id
+WHERE m.user_id = $1
+ORDER BY o.name, o.id;
+
+-- name: GetOrgMemberRole :one
+SELECT role
+FROM org_members
+WHERE org_id = $1
+  AND user_id = $2;
+
+-- name: GetCollectionMemberRole :one
+SELECT m.role
+FROM collections c
+         JOIN org_members m ON m.org_id = c.org_id
+WHERE c.id = $1
+  AND m.user_id = $2;
+
+-- name: ListOrgMembers :many
+SELECT m.user_id, u.username, m.role
+FROM org_members m
+         JOIN users u ON u.id = m.user_id
+WHERE m.org_id = $1
+ORDER BY u.username;
+
+-- name: UpdateOrgMemberRole :execrows
+UPDATE org_members
+SET role = $1
+WHERE org_id = $2
+  AND user_id = $3;
+
+-- name: DeleteOrgMember :execrows
+DELETE
+FROM org_members
+WHERE org_id = $1
+  AND user_id = $2;
+
+-- name: CountOrgOwners :one
+SELECT COUNT(*)
+FROM org_members
+WHERE org_id = $1
+  AND role = 'owner';
+
+-- name: UpsertOrgInvitation :one
+INSERT INTO org_invitations (org_id, invitee_id, inviter_id, role, wrapped_key)
+VALUES ($1, $2, $3, $4, $5) ON CONFLICT (org_id, invitee_id) DO
+UPDATE
+SET inviter_id  = EXCLUDED.inviter_id,
+    role        = EXCLUDED.role,
+    wrapped_key = EXCLUDED.wrapped_key,
+    created_at  = NOW()
+RETURNING id;
+
+-- name: ListUserInvitations :many
+SELECT i.id, i.org_id, o.name AS org_name, u.username AS inviter_name, i.role
+FROM org_invitations i
+         JOIN organizations o ON o.id = i.org_id
+         JOIN users u ON u.id = i.inviter_id
+WHERE i.invitee_id = $1
+ORDER BY i.created_at DESC;
+
+-- name: DeleteUserInvitation :one
+DELETE
+FROM org_invitations
+WHERE id = $1
+  AND invitee_id = $2 RETURNING org_id, role, wrapped_key;
+
+-- name: CreateCollection :one
+INSERT INTO collections (org_id, name)
+VALUES ($1, $2) RETURNING id;
+
+-- name: ListOrgCollections :many
+SELECT id, name
+FROM collections
+WHERE org_id = $1
+ORDER BY name, id;
+
+-- name: ListCollectionData :many
+SELECT d.id, d.largeobject_oid
+FROM user_data d
+         JOIN collections c ON c.id = d.collection_id
+WHERE c.id = $1
+  AND c.org_id = $2
+    FOR UPDATE OF d;
+
+-- name: DeleteCollection :execrows
+DELETE
+FROM collections
+WHERE id = $1
+  AND org_id = $2;
+
+-- name: SetUserDataCollection :exec
+UPDATE user_data
+SET collection_id = $1
+WHERE id = $2;
+
+-- name: DeleteUserSession :execrows
+DELETE
+FROM user_sessions
+WHERE user_id = $1
+  AND session_token = $2;
+
+-- name: InsertAuditEvent :exec
+INSERT INTO audit_events (user_id, login, action, data_id, client_addr)
+VALUES (COALESCE(sqlc.narg('user_id')::uuid, (SELECT u.id FROM users u WHERE u.username = sqlc.arg('login'))),
+        sqlc.arg('login'), sqlc.arg('action'), sqlc.narg('data_id'), sqlc.arg('client_addr'));
+
+-- name: ListAuditEvents :many
+SELECT id, action, data_id, client_addr, created_at
+FROM audit_events
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetUsernameByID :one
+SELECT username
+FROM users
+WHERE id = $1;
+
+-- name: GetUserIDByUsername :one
+SELECT id
+FROM users
+WHERE username = $1;
+
+-- name: UpsertUserTotp :exec
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET secret         = EXCLUDED.secret,
+                                    enabled        = FALSE,
+                                    last_used_step = 0,
+                                    created_at     = NOW();
+
+-- name: GetUserTotp :one
+SELECT user_id, secret, enabled, last_used_step, created_at
+FROM user_totp
+WHERE user_id = $1;
+
+-- name: EnableUserTotp :execrows
+UPDATE user_totp
+SET enabled = TRUE
+WHERE user_id = $1;
+
+-- name: SetTotpLastUsedStep :execrows
+UPDATE user_totp
+SET last_used_step = sqlc.arg('step')
+WHERE user_id = sqlc.arg('user_id')
+  AND last_used_step < sqlc.arg('step');
+
+-- name: DeleteUserTotp :exec
+DELETE
+FROM user_totp
+WHERE user_id = $1;
+
+-- name: InsertRecoveryCode :exec
+INSERT INTO recovery_codes (user_id, code_hash)
+VALUES ($1, $2);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*)
+FROM recovery_codes
+WHERE user_id = $1
+  AND used_at IS NULL;
+
+-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenges (token, user_id, expires_at)
+VALUES ($1, $2, NOW() + INTERVAL '5 minutes');
+
+-- name: IncrementLoginChallengeAttempts :one
+UPDATE login_challenges
+SET attempts = attempts + 1
+WHERE token = $1
+  AND expires_at > NOW() RETURNING user_id, attempts;
+
+-- name: DeleteLoginChallenge :exec
+DELETE
+FROM login_challenges
+WHERE token = $1;
+
+-- name: GetUserPasswordHash :one
+SELECT password_hash
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    updated_at    = NOW()
+WHERE id = $1;
+
+-- name: DeleteOtherUserSessions :exec
+DELETE
+FROM user_sessions
+WHERE user_id = $1
+  AND session_token <> $2;
+
+-- name: UpdateEncryptedPrivateKey :execrows
+UPDATE user_keys
+SET encrypted_private_key = $2,
+    updated_at            = NOW()
+WHERE user_id = $1;
+
+-- name: CountAbandonedOrgs :one
+SELECT COUNT(*)
+FROM org_members m
+WHERE m.user_id = sqlc.arg('user_id')::uuid
+  AND m.role = 'owner'
+  AND NOT EXISTS (SELECT 1
+                  FROM org_members o
+                  WHERE o.org_id = m.org_id
+                    AND o.user_id <> sqlc.arg('user_id')::uuid
+                    AND o.role = 'owner')
+  AND EXISTS (SELECT 1
+              FROM org_members o
+              WHERE o.org_id = m.org_id
+                AND o.user_id <> sqlc.arg('user_id')::uuid);
+
+-- name: ListAccountLargeObjects :many
+SELECT d.largeobject_oid
+FROM user_data d
+WHERE (d.user_id = sqlc.arg('user_id')::uuid AND d.collection_id IS NULL)
+   OR d.collection_id IN (SELECT c.id
+                          FROM collections c
+                                   JOIN org_members m ON m.org_id = c.org_id
+                          WHERE m.user_id = sqlc.arg('user_id')::uuid
+                            AND NOT EXISTS (SELECT 1
+                                            FROM org_members o
+                                            WHERE o.org_id = m.org_id
+                                              AND o.user_id <> sqlc.arg('user_id')::uuid));
+
+-- name: DeleteSoleMemberOrgs :exec
+DELETE
+FROM organizations
+WHERE id IN (SELECT m.org_id
+             FROM org_members m
+             WHERE m.user_id = sqlc.arg('user_id')::uuid
+               AND NOT EXISTS (SELECT 1
+                               FROM org_members o
+                               WHERE o.org_id = m.org_id
+                                 AND o.user_id <> sqlc.arg('user_id')::uuid));
+
+-- name: DetachTeamData :exec
+UPDATE user_data
+SET user_id = NULL
+WHERE user_id = $1
+  AND collection_id IS NOT NULL;
+
+-- name: DeleteUser :exec
+DELETE
+FROM users
+WHERE id = $1;
+
+-- name: UpsertDataRotation :exec
+INSERT INTO data_rotation (data_id, expires_at, rotation_days)
+VALUES ($1, $2, $3) ON CONFLICT (data_id) DO
+UPDATE
+SET expires_at    = EXCLUDED.expires_at,
+    rotation_days = EXCLUDED.rotation_days;
+
+-- name: DeleteDataRotation :exec
+DELETE
+FROM data_rotation
+WHERE data_id = $1;
+
+-- name: RefreshRotationDue :execrows
+UPDATE data_rotation r
+SET rotation_due = NOT r.rotation_due,
+    flagged_at   = CASE WHEN r.rotation_due THEN NULL ELSE NOW() END
+FROM user_data d
+WHERE d.id = r.data_id
+  AND d.deleted_at IS NULL
+  AND (sqlc.narg('data_id')::uuid IS NULL OR r.data_id = sqlc.narg('data_id'))
+  AND r.rotation_due <> ((r.expires_at IS NOT NULL AND r.expires_at <= NOW())
+    OR (r.rotation_days > 0 AND d.updated_at + make_interval(days => r.rotation_days) <= NOW()));
+
+-- name: ListVaultHealth :many
+SELECT d.id,
+       d.data_name,
+       d.data_type,
+       d.updated_at,
+       r.expires_at,
+       COALESCE(r.rotation_days, 0)::int        AS rotation_days,
+       COALESCE(r.rotation_due, FALSE)::boolean AS rotation_due
+FROM user_data d
+         LEFT JOIN data_rotation r ON r.data_id = d.id
+WHERE d.user_id = $1
+  AND d.collection_id IS NULL
+  AND d.deleted_at IS NULL
+  AND (d.data_type = 'password' OR r.data_id IS NOT NULL)
+ORDER BY d.data_name, d.id;
+
+-- name: TrashUserData :execrows
+UPDATE user_data
+SET deleted_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NULL;
+
+-- name: ListTrash :many
+SELECT d.id, d.data_name, d.data_type, d.size_bytes, d.deleted_at
+FROM user_data d
+WHERE d.deleted_at IS NOT NULL
+  AND ((d.user_id = sqlc.arg('user_id') AND d.collection_id IS NULL AND sqlc.narg('org_id')::uuid IS NULL)
+    OR d.collection_id IN (SELECT c.id FROM collections c WHERE c.org_id = sqlc.narg('org_id')))
+ORDER BY d.deleted_at DESC, d.id;
+
+-- name: GetTrashAccess :one
+SELECT (d.user_id = sqlc.arg('user_id')::uuid AND d.collection_id IS NULL) AS is_owner,
+       m.role AS org_role
+FROM user_data d
+         LEFT JOIN collections c ON c.id = d.collection_id
+         LEFT JOIN org_members m ON m.org_id = c.org_id AND m.user_id = sqlc.arg('user_id')::uuid
+WHERE d.id = sqlc.arg('id')
+  AND d.deleted_at IS NOT NULL
+  AND ((d.user_id = sqlc.arg('user_id')::uuid AND d.collection_id IS NULL)
+    OR m.user_id IS NOT NULL);
+
+-- name: RestoreUserData :execrows
+UPDATE user_data
+SET deleted_at = NULL
+WHERE id = $1
+  AND deleted_at IS NOT NULL;
+
+-- name: GetTrashedOid :one
+SELECT largeobject_oid
+FROM user_data
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+    FOR UPDATE;
+
+-- name: ListExpiredTrash :many
+SELECT id, largeobject_oid
+FROM user_data
+WHERE deleted_at < $1
+ORDER BY deleted_at
+LIMIT $2 FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteTrashedUserData :execrows
+DELETE
+FROM user_data
+WHERE id = $1
+  AND deleted_at IS NOT NULL;
+
+-- name: SetWrappedKeyByOid :execrows
+UPDATE user_data
+SET key_id      = $1,
+    wrapped_key = $2
+WHERE largeobject_oid = $3;
+
+-- name: ListWrappedKeysForRewrap :many
+SELECT id, key_id, wrapped_key
+FROM user_data
+WHERE key_id IS NOT NULL
+  AND key_id <> sqlc.arg('current_key_id')::text
+ORDER BY id
+LIMIT sqlc.arg('batch_size') FOR UPDATE SKIP LOCKED;
+
+-- name: SetWrappedKeyByID :exec
+UPDATE user_data
+SET key_id      = $1,
+    wrapped_key = $2
+WHERE id = $3;
+
+-- name: CountDataKeysForRewrap :one
+SELECT COUNT(*)
+FROM user_data
+WHERE key_id IS NOT NULL
+  AND key_id <> sqlc.arg('current_key_id')::text;
+
+-- name: CountDataKeysByKeyID :many
+SELECT COALESCE(key_id, '')::text AS key_id, COUNT(*) AS items
+FROM user_data
+GROUP BY key_id
+ORDER BY key_id;
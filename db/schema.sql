@@ -30,3 +30,185 @@ CREATE TABLE user_sessions
     expires_at    TIMESTAMPTZ NOT NULL,
     created_at    TIMESTAMPTZ      DEFAULT NOW()
 );
+
+CREATE TABLE folders
+(
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  UUID REFERENCES folders (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ      DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN folder_id UUID REFERENCES folders (id) ON DELETE SET NULL;
+
+CREATE TABLE data_tags
+(
+    data_id UUID        NOT NULL REFERENCES user_data (id) ON DELETE CASCADE,
+    tag     VARCHAR(50) NOT NULL,
+    PRIMARY KEY (data_id, tag)
+);
+
+CREATE INDEX folders_user_id_idx ON folders (user_id);
+CREATE INDEX user_data_folder_id_idx ON user_data (folder_id);
+CREATE INDEX data_tags_tag_idx ON data_tags (tag);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE user_data
+    ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE user_data
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE user_data
+    ADD COLUMN size_bytes BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX user_data_user_name_idx ON user_data (user_id, data_name, id);
+CREATE INDEX user_data_user_created_idx ON user_data (user_id, created_at DESC, id DESC);
+CREATE INDEX user_data_user_updated_idx ON user_data (user_id, updated_at DESC, id DESC);
+CREATE INDEX user_data_user_size_idx ON user_data (user_id, size_bytes DESC, id DESC);
+CREATE INDEX user_data_name_trgm_idx ON user_data USING gin (data_name gin_trgm_ops);
+
+CREATE TABLE user_keys
+(
+    user_id               UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    public_key            BYTEA       NOT NULL,
+    encrypted_private_key TEXT        NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN data_key TEXT;
+
+CREATE TABLE data_shares
+(
+    data_id      UUID        NOT NULL REFERENCES user_data (id) ON DELETE CASCADE,
+    recipient_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission   VARCHAR(16) NOT NULL CHECK (permission IN ('read', 'read_write')),
+    wrapped_key  BYTEA       NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (data_id, recipient_id)
+);
+
+CREATE INDEX data_shares_recipient_id_idx ON data_shares (recipient_id);
+
+CREATE TABLE organizations
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE org_members
+(
+    org_id      UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read_only')),
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE TABLE org_invitations
+(
+    id          UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    org_id      UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    invitee_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    inviter_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read_only')),
+    wrapped_key BYTEA       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, invitee_id)
+);
+
+CREATE TABLE collections
+(
+    id         UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    org_id     UUID        NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name       VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_data
+    ADD COLUMN collection_id UUID REFERENCES collections (id) ON DELETE CASCADE;
+
+CREATE INDEX org_members_user_id_idx ON org_members (user_id);
+CREATE INDEX org_invitations_invitee_id_idx ON org_invitations (invitee_id);
+CREATE INDEX collections_org_id_idx ON collections (org_id);
+CREATE INDEX user_data_collection_id_idx ON user_data (collection_id);
+
+CREATE TABLE audit_events
+(
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     UUID,
+    login       VARCHAR(64) NOT NULL DEFAULT '',
+    action      VARCHAR(32) NOT NULL,
+    data_id     UUID,
+    client_addr TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, id DESC);
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_events
+    FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TABLE user_totp
+(
+    user_id        UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT        NOT NULL,
+    enabled        BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT      NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE recovery_codes
+(
+    id        UUID PRIMARY KEY     DEFAULT gen_random_uuid(),
+    user_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT        NOT NULL,
+    used_at   TIMESTAMPTZ
+);
+
+CREATE TABLE login_challenges
+(
+    token      TEXT PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    attempts   INT         NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+CREATE INDEX login_challenges_user_id_idx ON login_challenges (user_id);
+
+CREATE TABLE data_rotation
+(
+    data_id       UUID PRIMARY KEY REFERENCES user_data (id) ON DELETE CASCADE,
+    expires_at    TIMESTAMPTZ,
+    rotation_days INT         NOT NULL DEFAULT 0 CHECK (rotation_days >= 0),
+    rotation_due  BOOLEAN     NOT NULL DEFAULT FALSE,
+    flagged_at    TIMESTAMPTZ
+);
+
+CREATE INDEX data_rotation_due_idx ON data_rotation (rotation_due);
+
+ALTER TABLE user_data
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX user_data_deleted_at_idx ON user_data (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE user_data
+    ADD COLUMN key_id      TEXT,
+    ADD COLUMN wrapped_key BYTEA;
+
+CREATE INDEX user_data_key_id_idx ON user_data (key_id) WHERE key_id IS NOT NULL;
@@ -0,0 +1,321 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// errPasswordMismatch новый пароль и его повтор не совпадают
+var errPasswordMismatch = errors.New("new passwords do not match")
+
+// exportFile файл выгрузки всех данных
+type exportFile struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Items      []exportedItem `json:"items"`
+}
+
+// exportedItem элемент файла выгрузки. Пароли и карты сохраняются расшифрованными
+type exportedItem struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	FolderID  string   `json:"folder_id,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+	Text      string   `json:"text,omitempty"`
+	File      []byte   `json:"file,omitempty"`
+}
+
+// showAccountScreen меню учётной записи
+func showAccountScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeAccount := func() {
+		pages.RemovePage("account_screen")
+		pages.SwitchToPage("data_screen")
+	}
+
+	form := tview.NewForm().
+		AddButton("Change password", func() {
+			showChangePasswordDialog(app, userUID, token, message)
+		}).
+		AddButton("Security", func() {
+			pages.RemovePage("account_screen")
+			showSecurityScreen(app, userUID, token, message)
+		}).
+		AddButton("Export all", func() {
+			showExportAllDialog(app, userUID, token, message)
+		}).
+		AddButton("Backup", func() {
+			showBackupDialog(app, userUID, token, message)
+		}).
+		AddButton("Restore", func() {
+			showRestoreDialog(app, userUID, token, message)
+		}).
+		AddButton("Delete account", func() {
+			showDeleteAccountDialog(app, userUID, token, message)
+		}).
+		AddButton("Back", closeAccount)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 3, 1, true).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Account ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("account_screen", flex, true, true)
+	pages.SwitchToPage("account_screen")
+	app.SetFocus(form)
+}
+
+// showChangePasswordDialog модальное окно смены пароля
+func showChangePasswordDialog(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeChange := func() {
+		pages.RemovePage("dialog_change_password")
+		pages.SwitchToPage("account_screen")
+	}
+
+	oldField := tview.NewInputField().
+		SetLabel("Current password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+	newField := tview.NewInputField().
+		SetLabel("New password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+	repeatField := tview.NewInputField().
+		SetLabel("Repeat new password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(oldField).
+		AddFormItem(newField).
+		AddFormItem(passwordStrengthView(newField)).
+		AddFormItem(repeatField).
+		AddButton("Save", func() {
+			err := changePassword(userUID, token, oldField.GetText(), newField.GetText(), repeatField.GetText())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Change password error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText("Password changed, other sessions are signed out")
+			}
+			closeChange()
+		}).
+		AddButton("Cancel", closeChange)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Change password ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_change_password", dialogForm, true, true)
+	pages.SwitchToPage("dialog_change_password")
+	app.SetFocus(dialogForm)
+}
+
+// changePassword запрос смены пароля. Приватный ключ зашифрован ключом хранилища,
+// а не паролем, поэтому заново его не шифруем
+func changePassword(userUID, token, oldPassword, newPassword, repeatPassword string) error {
+	if newPassword != repeatPassword {
+		return errPasswordMismatch
+	}
+	_, err := autClient.ChangePassword(authContext(userUID, token), &pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	return err
+}
+
+// showExportAllDialog модальное окно выгрузки всех данных в файл
+func showExportAllDialog(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeExport := func() {
+		pages.RemovePage("dialog_export_all")
+		pages.SwitchToPage("account_screen")
+	}
+
+	pathField := tview.NewInputField().
+		SetLabel("File: ").
+		SetText("gault-export.json").
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(pathField).
+		AddTextView("", "Passwords and cards are saved unencrypted", 0, 1, true, false).
+		AddButton("Export", func() {
+			count, err := exportAll(userUID, token, pathField.GetText())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Export error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText(fmt.Sprintf("Exported %d items to %s", count, pathField.GetText()))
+			}
+			closeExport()
+		}).
+		AddButton("Cancel", closeExport)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Export all ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_export_all", dialogForm, true, true)
+	pages.SwitchToPage("dialog_export_all")
+	app.SetFocus(dialogForm)
+}
+
+// exportAll получение всех данных личного хранилища и запись их в файл, доступный только владельцу
+func exportAll(userUID, token, path string) (int, error) {
+	stream, err := dataClient.ExportAll(authContext(userUID, token), &pb.ExportAllRequest{})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		items   []exportedItem
+		content []byte
+		dataKey string
+	)
+	// finish завершение текущего элемента после получения всех его частей
+	finish := func() error {
+		if len(items) == 0 {
+			return nil
+		}
+		return fillExportedContent(&items[len(items)-1], dataKey, content)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if item := resp.GetItem(); item != nil {
+			if err := finish(); err != nil {
+				return 0, err
+			}
+			items = append(items, exportedItem{
+				ID:        item.GetId(),
+				Name:      item.GetName(),
+				Type:      item.GetType(),
+				FolderID:  item.GetFolderId(),
+				Tags:      item.GetTags(),
+				CreatedAt: item.GetCreatedAt(),
+				UpdatedAt: item.GetUpdatedAt(),
+			})
+			content, dataKey = nil, resp.GetDataKey()
+		}
+		content = append(content, resp.GetData()...)
+	}
+	if err := finish(); err != nil {
+		return 0, err
+	}
+
+	data, err := json.MarshalIndent(exportFile{ExportedAt: time.Now().UTC(), Items: items}, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	return len(items), nil
+}
+
+// fillExportedContent заполнение содержимого элемента выгрузки с расшифровкой паролей и карт
+func fillExportedContent(item *exportedItem, dataKey string, content []byte) error {
+	if item.Type == "file" {
+		item.File = content
+		return nil
+	}
+	if !isEncryptedType(item.Type) {
+		item.Text = string(content)
+		return nil
+	}
+
+	plain, err := decryptContent(item.Name, dataKey, string(content))
+	if err != nil {
+		return err
+	}
+	item.Text = plain
+	return nil
+}
+
+// decryptContent расшифровка пароля или карты личного хранилища ключом данных или ключом хранилища
+func decryptContent(name, dataKey, content string) (string, error) {
+	key := aes
+	if dataKey != "" {
+		var err error
+		if key, err = utils.Decrypt(dataKey, aes); err != nil {
+			return "", fmt.Errorf("failed to decrypt key of %q: %w", name, err)
+		}
+	}
+	plain, err := utils.Decrypt(content, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %q: %w", name, err)
+	}
+	return plain, nil
+}
+
+// showDeleteAccountDialog модальное окно удаления учётной записи
+func showDeleteAccountDialog(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeDelete := func() {
+		pages.RemovePage("dialog_delete_account")
+		pages.SwitchToPage("account_screen")
+	}
+
+	passField := tview.NewInputField().
+		SetLabel("Password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+	codeField := tview.NewInputField().
+		SetLabel("2FA code (if enabled): ").
+		SetFieldWidth(20)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(passField).
+		AddFormItem(codeField).
+		AddTextView("", "All personal data will be deleted permanently", 0, 1, true, false).
+		AddButton("Delete", func() {
+			pages.RemovePage("dialog_delete_account")
+			deleteAccount(userUID, token, passField.GetText(), codeField.GetText(), message)
+		}).
+		AddButton("Cancel", closeDelete)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Delete account ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_delete_account", dialogForm, true, true)
+	pages.SwitchToPage("dialog_delete_account")
+	app.SetFocus(dialogForm)
+}
+
+// deleteAccount запрос удаления учётной записи и возврат к экрану входа
+func deleteAccount(userUID, token, password, code string, message *tview.TextView) {
+	_, err := autClient.DeleteAccount(authContext(userUID, token), &pb.DeleteAccountRequest{
+		Password: password,
+		Code:     code,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Delete account error: %v", err))
+		pages.SwitchToPage("account_screen")
+		return
+	}
+
+	resetSession()
+	message.SetTextColor(tcell.ColorGreen).SetText("Account deleted")
+	pages.RemovePage("account_screen")
+	pages.RemovePage("data_screen")
+	pages.SwitchToPage("login")
+}
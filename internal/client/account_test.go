@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangePassword(t *testing.T) {
+	client := &fakeAuthClient{}
+	autClient = client
+
+	assert.ErrorIs(t, changePassword("user1", "token1", "old", "new-pass", "other"), errPasswordMismatch)
+	assert.Nil(t, client.lastChangePassword)
+
+	assert.NoError(t, changePassword("user1", "token1", "old", "new-pass", "new-pass"))
+	assert.Equal(t, "old", client.lastChangePassword.OldPassword)
+	assert.Equal(t, "new-pass", client.lastChangePassword.NewPassword)
+}
+
+func TestExportAll_WritesDecryptedItems(t *testing.T) {
+	aes = "1234567891234567"
+	dataKey, err := utils.GenerateDataKey()
+	assert.NoError(t, err)
+	wrappedKey, err := utils.Encrypt(dataKey, aes)
+	assert.NoError(t, err)
+	password, err := utils.Encrypt("login:secret", dataKey)
+	assert.NoError(t, err)
+	card, err := utils.Encrypt("4111", aes)
+	assert.NoError(t, err)
+
+	dataClient = &fakeDataClient{exportResps: []*pb.ExportAllResponse{
+		{Item: &pb.UserDataItem{Id: "1", Name: "mail", Type: "password", Tags: []string{"work"}}, DataKey: wrappedKey, Data: []byte(password)},
+		{Item: &pb.UserDataItem{Id: "2", Name: "visa", Type: "card"}, Data: []byte(card)},
+		{Item: &pb.UserDataItem{Id: "3", Name: "photo", Type: "file"}, Data: []byte("part1-")},
+		{Data: []byte("part2")},
+		{Item: &pb.UserDataItem{Id: "4", Name: "note", Type: "text"}, Data: []byte("hello")},
+	}}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	count, err := exportAll("user1", "token1", path)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var file exportFile
+	assert.NoError(t, json.Unmarshal(raw, &file))
+	assert.Len(t, file.Items, 4)
+	assert.Equal(t, "login:secret", file.Items[0].Text)
+	assert.Equal(t, []string{"work"}, file.Items[0].Tags)
+	assert.Equal(t, "4111", file.Items[1].Text)
+	assert.Equal(t, []byte("part1-part2"), file.Items[2].File)
+	assert.Equal(t, "hello", file.Items[3].Text)
+}
+
+func TestExportAll_Error(t *testing.T) {
+	dataClient = &fakeDataClient{returnErr: errors.New("unavailable")}
+
+	_, err := exportAll("user1", "token1", filepath.Join(t.TempDir(), "export.json"))
+	assert.Error(t, err)
+}
+
+func TestDeleteAccount_ReturnsToLogin(t *testing.T) {
+	pages = tview.NewPages()
+	pages.AddPage("login", tview.NewBox(), true, false)
+	pages.AddPage("data_screen", tview.NewBox(), true, true)
+	message := tview.NewTextView()
+	userPrivateKey = []byte("private")
+	client := &fakeAuthClient{}
+	autClient = client
+
+	deleteAccount("user1", "token1", "password", "123456", message)
+
+	assert.Equal(t, "password", client.lastDeleteAccount.Password)
+	assert.Equal(t, "123456", client.lastDeleteAccount.Code)
+	assert.Nil(t, userPrivateKey)
+	assert.False(t, pages.HasPage("data_screen"))
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "login", name)
+}
+
+func TestDeleteAccount_Error(t *testing.T) {
+	pages = tview.NewPages()
+	message := tview.NewTextView()
+	autClient = &fakeAuthClient{returnErr: errors.New("invalid password")}
+
+	deleteAccount("user1", "token1", "wrong", "", message)
+	assert.Contains(t, message.GetText(true), "Delete account error: invalid password")
+}
+
+func TestShowAccountScreen(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+
+	showAccountScreen(app, "user1", "token1", tview.NewTextView())
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "account_screen", name)
+}
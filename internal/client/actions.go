@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -25,6 +27,7 @@ func registration(app *tview.Application, login string, pass string, message *tv
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Registration error: %v", err))
 		return
 	}
+	sessionLogin = login
 	message.SetTextColor(tcell.ColorGreen).SetText("Registration successful!")
 	showDataScreen(app, response.UserUid, response.Token, message)
 }
@@ -42,10 +45,36 @@ func login(app *tview.Application, login, pass string, message *tview.TextView)
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Login error: %v", err))
 		return
 	}
+	sessionLogin = login
+	if response.GetTwoFactorRequired() {
+		showTwoFactorDialog(app, response.GetChallengeToken(), message)
+		return
+	}
 	message.SetTextColor(tcell.ColorGreen).SetText("Login successful!")
 	showDataScreen(app, response.UserUid, response.Token, message)
 }
 
+// logout запрос на отзыв сессии и возврат к экрану входа
+func logout(userUID, token string, message *tview.TextView) {
+	if _, err := autClient.Logout(authContext(userUID, token), &pb.LogoutRequest{}); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Logout error: %v", err))
+		return
+	}
+
+	resetSession()
+	message.SetTextColor(tcell.ColorGreen).SetText("Logged out")
+	pages.RemovePage("data_screen")
+	pages.SwitchToPage("login")
+}
+
+// resetSession сброс ключей, выбранного хранилища и таймера бездействия при выходе из учётной записи
+func resetSession() {
+	stopIdleTimer()
+	userPublicKey, userPrivateKey = nil, nil
+	itemKeys = make(map[string]string)
+	currentOrg, currentOrgKey, currentCollection = nil, "", ""
+}
+
 // saveText запрос на сохранение текста
 func saveText(text, name, userUID, token string, table *tview.Table, message *tview.TextView) {
 	err := saveData(userUID, token, "text", name, "", []byte(text))
@@ -71,12 +100,12 @@ func updateText(newText, userUID, token, itemID string, table *tview.Table, mess
 	closeDialog("dialog_view_text")
 }
 
-// deleteText запрос на удаление текста
+// deleteText запрос на перемещение текста в корзину
 func deleteText(userUID, token, itemID string, table *tview.Table, message *tview.TextView) {
 	if err := deleteData(userUID, token, itemID); err != nil {
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Delete error: %v", err))
 	} else {
-		message.SetTextColor(tcell.ColorGreen).SetText("Delete success!")
+		message.SetTextColor(tcell.ColorGreen).SetText("Moved to trash!")
 	}
 	_ = loadUserData(table, userUID, token)
 	closeDialog("dialog_view_text")
@@ -119,6 +148,39 @@ func saveCard(text, name, userUID, token string, table *tview.Table, message *tv
 	closeDialog("dialog_add_text")
 }
 
+// saveTOTP запрос на сохранение секрета TOTP, секрет проверяется до отправки
+func saveTOTP(secret, name, userUID, token string, table *tview.Table, message *tview.TextView) {
+	if _, err := utils.ParseOTPAuth(secret); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Save error: %v", err))
+		return
+	}
+	err := saveData(userUID, token, "totp", name, "", []byte(strings.TrimSpace(secret)))
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Save error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("TOTP saved!")
+		_ = loadUserData(table, userUID, token)
+	}
+	closeDialog("dialog_add_text")
+}
+
+// updateTOTP запрос на замену секрета TOTP
+func updateTOTP(secret, userUID, token, itemID string, table *tview.Table, message *tview.TextView) {
+	if _, err := utils.ParseOTPAuth(secret); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Update error: %v", err))
+		return
+	}
+	err := updateData(userUID, token, itemID, "totp", "", []byte(strings.TrimSpace(secret)))
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Update error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Update success!")
+		_ = loadUserData(table, userUID, token)
+	}
+	closeDialog("dialog_edit_text")
+	closeDialog("dialog_view_text")
+}
+
 // updateCard запрос на обновление карты
 func updateCard(newText, userUID, token, itemID string, table *tview.Table, message *tview.TextView) {
 	err := updateData(userUID, token, itemID, "card", "", []byte(newText))
@@ -168,18 +230,18 @@ func downloadFile(path string, fileData []byte, message *tview.TextView) {
 	closeDialog("dialog_view_file")
 }
 
-// deleteFile запрос на удаление файла
+// deleteFile запрос на перемещение файла в корзину
 func deleteFile(userUID string, token string, itemID string, table *tview.Table, message *tview.TextView) {
 	if err := deleteData(userUID, token, itemID); err != nil {
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Delete error: %v", err))
 	} else {
-		message.SetTextColor(tcell.ColorGreen).SetText("Delete success!")
+		message.SetTextColor(tcell.ColorGreen).SetText("Moved to trash!")
 	}
 	_ = loadUserData(table, userUID, token)
 	closeDialog("dialog_view_text")
 }
 
-// deleteData делает запрос на удаление данных
+// deleteData делает запрос на перемещение данных в корзину
 func deleteData(userUID, token, itemID string) error {
 	md := metadata.Pairs(
 		"userUID", userUID,
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// activityPageToken курсор следующей страницы журнала действий
+var activityPageToken string
+
+// actionLabel подпись типа события журнала действий, например "item read"
+func actionLabel(action pb.AuditAction) string {
+	label := strings.TrimPrefix(action.String(), "AUDIT_ACTION_")
+	return strings.ReplaceAll(strings.ToLower(label), "_", " ")
+}
+
+// showActivityScreen экран журнала действий пользователя: входы, сессии и доступ к данным
+func showActivityScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	table := tview.NewTable()
+	table.SetBorders(true)
+
+	table.SetSelectable(true, false).
+		SetSelectionChangedFunc(func(row, _ int) {
+			// Подгрузка следующей страницы при достижении последней строки
+			if row == table.GetRowCount()-1 && activityPageToken != "" {
+				if err := loadActivity(table, userUID, token, activityPageToken); err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading activity: %v", err))
+				}
+			}
+		})
+
+	form := tview.NewForm().
+		AddButton("Back", func() {
+			pages.RemovePage("activity_screen")
+			pages.SwitchToPage("data_screen")
+		})
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyBacktab {
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	if err := loadActivity(table, userUID, token, ""); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading activity: %v", err))
+	}
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(form, 3, 1, false).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Activity ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("activity_screen", flex, true, true)
+	pages.SwitchToPage("activity_screen")
+	app.SetFocus(table)
+}
+
+// loadActivity загрузка страницы журнала действий, пустой pageToken перезагружает таблицу с первой страницы
+func loadActivity(table *tview.Table, userUID, token, pageToken string) error {
+	resp, err := auditClient.ListAuditEvents(authContext(userUID, token), &pb.ListAuditEventsRequest{PageToken: pageToken})
+	if err != nil {
+		return err
+	}
+
+	if pageToken == "" {
+		table.Clear()
+		table.SetCell(0, 0, tview.NewTableCell("TIME").SetSelectable(false)).
+			SetCell(0, 1, tview.NewTableCell("ACTION").SetSelectable(false)).
+			SetCell(0, 2, tview.NewTableCell("ITEM").SetSelectable(false)).
+			SetCell(0, 3, tview.NewTableCell("ADDRESS").SetSelectable(false))
+	}
+	activityPageToken = resp.GetNextPageToken()
+
+	row := table.GetRowCount()
+	for _, event := range resp.GetEvents() {
+		table.SetCell(row, 0, tview.NewTableCell(time.Unix(event.GetCreatedAt(), 0).Format(time.DateTime))).
+			SetCell(row, 1, tview.NewTableCell(actionLabel(event.GetAction()))).
+			SetCell(row, 2, tview.NewTableCell(event.GetDataId())).
+			SetCell(row, 3, tview.NewTableCell(event.GetClientAddr()))
+		row++
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type fakeAuditClient struct {
+	pages         map[string]*pb.ListAuditEventsResponse
+	lastPageToken string
+	returnErr     error
+}
+
+func (f *fakeAuditClient) ListAuditEvents(ctx context.Context, in *pb.ListAuditEventsRequest, opts ...grpc.CallOption) (*pb.ListAuditEventsResponse, error) {
+	f.lastPageToken = in.GetPageToken()
+	return f.pages[in.GetPageToken()], f.returnErr
+}
+
+func TestActionLabel(t *testing.T) {
+	assert.Equal(t, "login failure", actionLabel(pb.AuditAction_AUDIT_ACTION_LOGIN_FAILURE))
+	assert.Equal(t, "item read", actionLabel(pb.AuditAction_AUDIT_ACTION_ITEM_READ))
+}
+
+func TestLoadActivity(t *testing.T) {
+	table := tview.NewTable()
+	client := &fakeAuditClient{pages: map[string]*pb.ListAuditEventsResponse{
+		"": {
+			Events: []*pb.AuditEvent{
+				{Id: 3, Action: pb.AuditAction_AUDIT_ACTION_ITEM_READ, DataId: "data-id", ClientAddr: "10.0.0.1:5000"},
+				{Id: 2, Action: pb.AuditAction_AUDIT_ACTION_LOGIN_SUCCESS},
+			},
+			NextPageToken: "2",
+		},
+		"2": {
+			Events: []*pb.AuditEvent{{Id: 1, Action: pb.AuditAction_AUDIT_ACTION_REGISTRATION}},
+		},
+	}}
+	auditClient = client
+
+	assert.NoError(t, loadActivity(table, "user1", "token1", ""))
+	assert.Equal(t, 3, table.GetRowCount())
+	assert.Equal(t, "item read", table.GetCell(1, 1).Text)
+	assert.Equal(t, "data-id", table.GetCell(1, 2).Text)
+	assert.Equal(t, "10.0.0.1:5000", table.GetCell(1, 3).Text)
+	assert.Equal(t, "2", activityPageToken)
+
+	assert.NoError(t, loadActivity(table, "user1", "token1", activityPageToken))
+	assert.Equal(t, "2", client.lastPageToken)
+	assert.Equal(t, 4, table.GetRowCount())
+	assert.Equal(t, "registration", table.GetCell(3, 1).Text)
+	assert.Equal(t, "", activityPageToken)
+
+	client.returnErr = errors.New("error")
+	assert.Error(t, loadActivity(table, "user1", "token1", ""))
+}
+
+func TestLogout(t *testing.T) {
+	pages = tview.NewPages()
+	pages.AddPage("login", tview.NewBox(), true, false)
+	pages.AddPage("data_screen", tview.NewBox(), true, true)
+	message := tview.NewTextView()
+
+	client := &fakeAuthClient{returnErr: errors.New("error")}
+	autClient = client
+	logout("user1", "token1", message)
+	assert.True(t, client.logoutCalled)
+	assert.True(t, pages.HasPage("data_screen"))
+
+	client.returnErr = nil
+	userPrivateKey = []byte("private")
+	currentOrg = &pb.Organization{Id: "org-id"}
+	logout("user1", "token1", message)
+	assert.False(t, pages.HasPage("data_screen"))
+	assert.Nil(t, userPrivateKey)
+	assert.Nil(t, currentOrg)
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "login", name)
+}
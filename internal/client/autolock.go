@@ -0,0 +1,144 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// defaultAutoLock время бездействия до блокировки по умолчанию
+const defaultAutoLock = 5 * time.Minute
+
+var (
+	// autoLockTimeout время без нажатий клавиш до блокировки, 0 — блокировка отключена
+	autoLockTimeout time.Duration
+	// sessionLogin логин текущей сессии для экрана блокировки
+	sessionLogin string
+	// idleMu защищает idleTimer
+	idleMu sync.Mutex
+	// idleTimer таймер бездействия открытой сессии
+	idleTimer *time.Timer
+)
+
+// setAutoLock сохранение настроек блокировки
+func setAutoLock(conf config.AutoLock) {
+	autoLockTimeout = conf.Timeout
+	if autoLockTimeout <= 0 {
+		autoLockTimeout = defaultAutoLock
+	}
+}
+
+// watchIdle перезапуск таймера бездействия при каждом нажатии клавиши
+func watchIdle(app *tview.Application) {
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		idleMu.Lock()
+		if idleTimer != nil {
+			idleTimer.Reset(autoLockTimeout)
+		}
+		idleMu.Unlock()
+		return event
+	})
+}
+
+// startIdleTimer запуск таймера бездействия после входа. По истечении сессия блокируется в цикле событий приложения
+func startIdleTimer(app *tview.Application, userUID, token string) {
+	if autoLockTimeout <= 0 {
+		return
+	}
+
+	idleMu.Lock()
+	defer idleMu.Unlock()
+	if idleTimer != nil {
+		idleTimer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(autoLockTimeout, func() {
+		app.QueueUpdateDraw(func() {
+			idleMu.Lock()
+			current := idleTimer == timer
+			idleMu.Unlock()
+			// Сессия могла завершиться или смениться, пока блокировка ждала в очереди
+			if current {
+				lockSession(app, userUID, token)
+			}
+		})
+	})
+	idleTimer = timer
+}
+
+// stopIdleTimer остановка таймера бездействия при выходе из сессии
+func stopIdleTimer() {
+	idleMu.Lock()
+	defer idleMu.Unlock()
+	if idleTimer != nil {
+		idleTimer.Stop()
+		idleTimer = nil
+	}
+}
+
+// lockSession блокировка сессии: отзыв токена, удаление расшифрованных данных и экранов с ними,
+// стирание буфера обмена и переход на экран блокировки
+func lockSession(app *tview.Application, userUID, token string) {
+	// Токен отзывается на сервере, если он доступен, локально он удаляется вместе с экранами
+	_, _ = autClient.Logout(authContext(userUID, token), &pb.LogoutRequest{})
+
+	resetSession()
+	wipeClipboard()
+	listFilter = &pb.GetUserDataListRequest{}
+	nextPageToken = ""
+	for _, name := range pages.GetPageNames(false) {
+		if name != "login" {
+			pages.RemovePage(name)
+		}
+	}
+
+	showLockScreen(app)
+}
+
+// showLockScreen экран блокировки, для продолжения работы нужен пароль учётной записи
+func showLockScreen(app *tview.Application) {
+	message := tview.NewTextView().
+		SetText(fmt.Sprintf("Locked after %s of inactivity", autoLockTimeout)).
+		SetTextAlign(tview.AlignCenter)
+
+	passField := tview.NewInputField().
+		SetLabel("Password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	form := tview.NewForm().
+		AddTextView("Login: ", sessionLogin, 40, 1, true, false).
+		AddFormItem(passField).
+		AddButton("Unlock", func() {
+			password := passField.GetText()
+			passField.SetText("")
+			login(app, sessionLogin, password, message)
+		}).
+		AddButton("Logout", func() {
+			sessionLogin = ""
+			pages.RemovePage("lock_screen")
+			pages.SwitchToPage("login")
+		}).
+		AddButton("Exit", func() {
+			app.Stop()
+		})
+
+	form.SetBorder(true).
+		SetTitle(" Locked ").
+		SetTitleAlign(tview.AlignCenter)
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(message, 1, 1, false)
+
+	pages.AddPage("lock_screen", flex, true, true)
+	pages.SwitchToPage("lock_screen")
+	app.SetFocus(form)
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAutoLock_Default(t *testing.T) {
+	defer func() { autoLockTimeout = 0 }()
+
+	setAutoLock(config.AutoLock{})
+	assert.Equal(t, defaultAutoLock, autoLockTimeout)
+
+	setAutoLock(config.AutoLock{Timeout: time.Minute})
+	assert.Equal(t, time.Minute, autoLockTimeout)
+}
+
+func TestLockSession_WipesSession(t *testing.T) {
+	terminal, _ := stubClipboard(t, config.Clipboard{ClearAfter: time.Hour})
+	auth := &fakeAuthClient{}
+	autClient = auth
+	pages = tview.NewPages()
+	pages.AddPage("login", tview.NewBox(), true, false).
+		AddPage("data_screen", tview.NewBox(), true, false).
+		AddPage("dialog_view_text", tview.NewBox(), true, true)
+	itemKeys = map[string]string{"item": "key"}
+	userPrivateKey = []byte("private")
+	sessionLogin = "alice"
+	app := tview.NewApplication()
+
+	require.NoError(t, copyToClipboard(app, "secret"))
+	assert.Equal(t, "secret", <-terminal)
+
+	lockSession(app, "u", "t")
+
+	assert.True(t, auth.logoutCalled)
+	assert.Empty(t, itemKeys)
+	assert.Nil(t, userPrivateKey)
+	assert.Empty(t, <-terminal)
+	assert.ElementsMatch(t, []string{"login", "lock_screen"}, pages.GetPageNames(false))
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "lock_screen", name)
+}
+
+func TestLockScreen_Unlock(t *testing.T) {
+	auth := &fakeAuthClient{returnErr: errors.New("invalid password")}
+	autClient = auth
+	pages = tview.NewPages()
+	pages.AddPage("login", tview.NewBox(), true, true)
+	sessionLogin = "alice"
+
+	showLockScreen(tview.NewApplication())
+	_, page := pages.GetFrontPage()
+	flex := page.(*tview.Flex)
+	form := flex.GetItem(0).(*tview.Form)
+	message := flex.GetItem(1).(*tview.TextView)
+	press := func(label string) {
+		form.GetButton(form.GetButtonIndex(label)).
+			InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	passField := form.GetFormItem(1).(*tview.InputField)
+	passField.SetText("wrong")
+	press("Unlock")
+	require.NotNil(t, auth.lastLoginRequest)
+	assert.Equal(t, "alice", auth.lastLoginRequest.Login)
+	assert.Equal(t, "wrong", auth.lastLoginRequest.Password)
+	assert.Empty(t, passField.GetText())
+	assert.Contains(t, message.GetText(true), "invalid password")
+
+	press("Logout")
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "login", name)
+	assert.False(t, pages.HasPage("lock_screen"))
+	assert.Empty(t, sessionLogin)
+}
+
+func TestStartIdleTimer_LocksAfterTimeout(t *testing.T) {
+	autClient = &fakeAuthClient{}
+	pages = tview.NewPages()
+	pages.AddPage("login", tview.NewBox(), true, false).
+		AddPage("data_screen", tview.NewBox(), true, true)
+	autoLockTimeout = 20 * time.Millisecond
+	defer func() {
+		autoLockTimeout = 0
+		stopIdleTimer()
+	}()
+	app := runSimulatedApp(t)
+
+	startIdleTimer(app, "u", "t")
+
+	assert.Eventually(t, func() bool {
+		var locked bool
+		app.QueueUpdate(func() {
+			name, _ := pages.GetFrontPage()
+			locked = name == "lock_screen"
+		})
+		return locked
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStopIdleTimer_PreventsLock(t *testing.T) {
+	autoLockTimeout = time.Hour
+	defer func() { autoLockTimeout = 0 }()
+
+	startIdleTimer(tview.NewApplication(), "u", "t")
+	require.NotNil(t, idleTimer)
+	resetSession()
+	assert.Nil(t, idleTimer)
+}
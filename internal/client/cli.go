@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+)
+
+// errItemNotFound элемент с таким именем не найден
+var errItemNotFound = errors.New("item not found")
+
+// PrintTOTP вход в учётную запись, поиск элемента TOTP личного хранилища по имени и вывод
+// текущего кода с числом секунд до его смены. readCode запрашивает код второго фактора, если он включён
+func PrintTOTP(w io.Writer, aesKey, login, password, name string, readCode func() (string, error)) error {
+	ctx, logout, err := cliSession(aesKey, login, password, readCode)
+	if err != nil {
+		return err
+	}
+	defer logout()
+
+	item, err := findItem(ctx, "totp", name)
+	if err != nil {
+		return err
+	}
+	secret, err := readItemText(ctx, item)
+	if err != nil {
+		return err
+	}
+	auth, err := utils.ParseOTPAuth(secret)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	code, err := auth.Code(now)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s (%ds)\n", code, auth.Remaining(now))
+	return err
+}
+
+// cliSession вход в учётную запись без TUI. logout отзывает сессию и удаляет ключи данных из памяти
+func cliSession(aesKey, login, password string, readCode func() (string, error)) (context.Context, func(), error) {
+	aes = aesKey
+
+	userUID, token, err := cliLogin(login, password, readCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := authContext(userUID, token)
+	logout := func() {
+		_, _ = autClient.Logout(ctx, &pb.LogoutRequest{})
+		itemKeys = make(map[string]string)
+	}
+	return ctx, logout, nil
+}
+
+// readItemText получение и расшифровка текстового элемента личного хранилища
+func readItemText(ctx context.Context, item *pb.UserDataItem) (string, error) {
+	resp, err := dataClient.GetData(ctx, &pb.GetDataRequest{Id: item.GetId()})
+	if err != nil {
+		return "", fmt.Errorf("failed to get %q: %w", item.GetName(), err)
+	}
+	if err := rememberItemKey(item.GetId(), resp); err != nil {
+		return "", fmt.Errorf("failed to decrypt key of %q: %w", item.GetName(), err)
+	}
+	text := resp.GetTextData()
+	if !isEncryptedType(resp.GetType()) {
+		return text, nil
+	}
+	plain, err := utils.Decrypt(text, itemKey(item.GetId()))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %q: %w", item.GetName(), err)
+	}
+	return plain, nil
+}
+
+// cliLogin вход в учётную запись без TUI, при включённой 2FA код запрашивается через readCode
+func cliLogin(login, password string, readCode func() (string, error)) (string, string, error) {
+	resp, err := autClient.Login(context.Background(), &pb.LoginRequest{Login: login, Password: password})
+	if err != nil {
+		return "", "", fmt.Errorf("login error: %w", err)
+	}
+	if !resp.GetTwoFactorRequired() {
+		return resp.GetUserUid(), resp.GetToken(), nil
+	}
+
+	code, err := readCode()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read two-factor code: %w", err)
+	}
+	verified, err := autClient.VerifyTwoFactor(context.Background(), &pb.VerifyTwoFactorRequest{
+		ChallengeToken: resp.GetChallengeToken(),
+		Code:           strings.TrimSpace(code),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("login error: %w", err)
+	}
+	return verified.GetUserUid(), verified.GetToken(), nil
+}
+
+// findItem элемент личного хранилища с точным совпадением типа и имени
+func findItem(ctx context.Context, dataType, name string) (*pb.UserDataItem, error) {
+	items, err := listAllItems(ctx, &pb.GetUserDataListRequest{Type: dataType, NameQuery: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data: %w", err)
+	}
+	var found []*pb.UserDataItem
+	for _, item := range items {
+		if item.GetName() == name {
+			found = append(found, item)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s %q", errItemNotFound, dataType, name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%d %s items are named %q, rename one of them", len(found), dataType, name)
+	}
+}
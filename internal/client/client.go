@@ -1,17 +1,24 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/logger"
 
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/rivo/tview"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // maxSizeBytes максимальный размер файла
@@ -24,27 +31,34 @@ var (
 	autClient pb.AuthV1ServiceClient
 	// dataClient клиент работы с данными
 	dataClient pb.ContentManagerV1ServiceClient
+	// folderClient клиент работы с папками и тегами
+	folderClient pb.FolderV1ServiceClient
+	// shareClient клиент совместного доступа к данным
+	shareClient pb.ShareV1ServiceClient
+	// orgClient клиент организаций и командных хранилищ
+	orgClient pb.OrgV1ServiceClient
+	// auditClient клиент журнала действий
+	auditClient pb.AuditV1ServiceClient
 )
 
-// GrpcClient устанавливает gRPC-соединение
-func GrpcClient(port int) (*grpc.ClientConn, error) {
-	// Подгружаем CA
-	certPool := x509.NewCertPool()
-	ca, err := os.ReadFile("certs/ca.crt")
+// GrpcClient устанавливает gRPC-соединение. Если в tls заданы certFile и keyFile,
+// клиент предъявляет сертификат для mTLS
+func GrpcClient(conf config.Config) (*grpc.ClientConn, error) {
+	creds, err := clientCredentials(conf.TLS)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CA cert: %w", err)
+		return nil, err
 	}
-	certPool.AppendCertsFromPEM(ca)
 
-	// TLS-конфигурация клиента
-	creds := credentials.NewTLS(&tls.Config{
-		RootCAs:            certPool,
-		InsecureSkipVerify: false, // true — если самоподписанный, но лучше false
-	})
+	host := conf.Host
+	if host == "" {
+		host = "localhost"
+	}
 
 	conn, err := grpc.NewClient(
-		fmt.Sprintf(":%d", port),
+		net.JoinHostPort(host, strconv.Itoa(conf.Port)),
 		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(requestIDUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(requestIDStreamInterceptor),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(maxSizeBytes),
 			grpc.MaxCallSendMsgSize(maxSizeBytes),
@@ -55,16 +69,80 @@ func GrpcClient(port int) (*grpc.ClientConn, error) {
 	}
 	autClient = pb.NewAuthV1ServiceClient(conn)
 	dataClient = pb.NewContentManagerV1ServiceClient(conn)
+	folderClient = pb.NewFolderV1ServiceClient(conn)
+	shareClient = pb.NewShareV1ServiceClient(conn)
+	orgClient = pb.NewOrgV1ServiceClient(conn)
+	auditClient = pb.NewAuditV1ServiceClient(conn)
 	return conn, nil
 }
 
+// clientCredentials учётные данные транспорта клиента по настройкам TLS
+func clientCredentials(conf config.TLS) (credentials.TransportCredentials, error) {
+	if conf.Insecure {
+		logger.LogError("INSECURE MODE: TLS is disabled, traffic is sent in plaintext. Use only for local development")
+		return insecure.NewCredentials(), nil
+	}
+
+	minVersion, err := conf.Version()
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := conf.Ciphers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Подгружаем CA
+	caFile := conf.CAFile
+	if caFile == "" {
+		caFile = "certs/ca.crt"
+	}
+	certPool := x509.NewCertPool()
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA cert: %w", err)
+	}
+	certPool.AppendCertsFromPEM(ca)
+
+	// TLS-конфигурация клиента
+	tlsConfig := &tls.Config{
+		RootCAs:      certPool,
+		ServerName:   conf.ServerName,
+		MinVersion:   minVersion,
+		CipherSuites: ciphers,
+	}
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// authContext контекст запроса с метаданными авторизации пользователя
+func authContext(userUID, token string) context.Context {
+	md := metadata.Pairs(
+		"userUID", userUID,
+		"authorization", token,
+	)
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
 // TUIClientWithApp запуск TUI
-func TUIClientWithApp(app *tview.Application, aes string) error {
+func TUIClientWithApp(app *tview.Application, conf config.Config) error {
 	pages = tview.NewPages()
+	setClipboard(conf.Clipboard)
+	setAutoLock(conf.AutoLock)
+	defer clearClipboardOnExit()
+	defer stopIdleTimer()
 
-	loginFlex := showLoginMenu(app, aes)
+	loginFlex := showLoginMenu(app, conf.Aes)
 	pages.AddPage("login", loginFlex, true, true)
 
+	watchClipboardScreen(app)
+	watchIdle(app)
 	app.SetRoot(pages, true).SetFocus(loginFlex)
 	return app.Run()
 }
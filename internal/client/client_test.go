@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/internal/config"
 
 	"google.golang.org/grpc/credentials"
 
@@ -35,7 +36,36 @@ func TestGrpcClient_Success(t *testing.T) {
 	_, err = fmt.Sscanf(portStr, "%d", &port)
 	assert.NoError(t, err)
 
-	_, err = GrpcClient(port)
+	_, err = GrpcClient(config.Config{Port: port})
+	assert.Error(t, err)
+}
+
+func TestGrpcClient_ClientCertNotFound(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir("../.."))
+	defer func() { _ = os.Chdir(wd) }()
+
+	_, err = GrpcClient(config.Config{
+		Port: 8080,
+		TLS:  config.TLS{CertFile: "certs/missing.crt", KeyFile: "certs/missing.key"},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load client cert/key")
+}
+
+func TestGrpcClient_Insecure(t *testing.T) {
+	conn, err := GrpcClient(config.Config{Host: "127.0.0.1", Port: 8080, TLS: config.TLS{Insecure: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8080", conn.Target())
+	_ = conn.Close()
+}
+
+func TestClientCredentials_InvalidPolicy(t *testing.T) {
+	_, err := clientCredentials(config.TLS{MinVersion: "1.0"})
+	assert.Error(t, err)
+
+	_, err = clientCredentials(config.TLS{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}})
 	assert.Error(t, err)
 }
 
@@ -79,6 +109,6 @@ func TestTUIClientWithApp(t *testing.T) {
 			fmt.Printf("%+v\n", err)
 		}
 	}()
-	err := TUIClientWithApp(nil, "RhBRyjuJvwmkvXFEohPIXGxKunGqohRM")
+	err := TUIClientWithApp(nil, config.Config{Aes: "RhBRyjuJvwmkvXFEohPIXGxKunGqohRM"})
 	assert.Error(t, err)
 }
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// defaultClipboardClear время, через которое по умолчанию стирается скопированный секрет
+const defaultClipboardClear = 30 * time.Second
+
+// errNoClipboardTool не найдена утилита для работы с буфером обмена
+var errNoClipboardTool = errors.New("no clipboard tool found: install wl-copy, xclip or xsel")
+
+var (
+	// clipboardConf настройки буфера обмена
+	clipboardConf config.Clipboard
+	// clipboardScreen экран терминала для записи в буфер через OSC 52
+	clipboardScreen tcell.Screen
+	// clipboardMu защищает clipboardTimer
+	clipboardMu sync.Mutex
+	// clipboardTimer таймер стирания последнего скопированного секрета
+	clipboardTimer *time.Timer
+)
+
+// переменные для замены в тестах
+var (
+	setTerminalClipboard = func(data []byte) {
+		if clipboardScreen != nil {
+			clipboardScreen.SetClipboard(data)
+		}
+	}
+	runClipboardTool = func(data []byte) error {
+		name, args, err := clipboardTool(len(data) == 0)
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(data)
+		return cmd.Run()
+	}
+)
+
+// setClipboard сохранение настроек буфера обмена
+func setClipboard(conf config.Clipboard) {
+	if conf.ClearAfter <= 0 {
+		conf.ClearAfter = defaultClipboardClear
+	}
+	clipboardConf = conf
+}
+
+// watchClipboardScreen запоминает экран приложения при отрисовке, чтобы писать в буфер через OSC 52
+func watchClipboardScreen(app *tview.Application) {
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		clipboardScreen = screen
+		return false
+	})
+}
+
+// copyToClipboard копирование секрета в буфер обмена терминала через OSC 52 и, если включено,
+// через системную утилиту. Через ClearAfter буфер стирается, повторное копирование переносит срок
+func copyToClipboard(app *tview.Application, data string) error {
+	err := writeClipboard([]byte(data))
+
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+	if clipboardTimer != nil {
+		clipboardTimer.Stop()
+	}
+	clipboardTimer = time.AfterFunc(clipboardConf.ClearAfter, func() {
+		clipboardMu.Lock()
+		clipboardTimer = nil
+		clipboardMu.Unlock()
+		// Экран tcell не потокобезопасен для tview, поэтому запись идёт в цикле событий приложения
+		app.QueueUpdateDraw(func() {
+			_ = writeClipboard(nil)
+		})
+	})
+	return err
+}
+
+// clearClipboardOnExit стирание буфера при выходе, если срок скопированного секрета ещё не истёк
+func clearClipboardOnExit() {
+	clipboardMu.Lock()
+	defer clipboardMu.Unlock()
+	if clipboardTimer == nil {
+		return
+	}
+	clipboardTimer.Stop()
+	clipboardTimer = nil
+	if clipboardConf.Fallback {
+		_ = runClipboardTool(nil)
+	}
+}
+
+// wipeClipboard немедленное стирание буфера, если срок скопированного секрета ещё не истёк
+func wipeClipboard() {
+	clipboardMu.Lock()
+	pending := clipboardTimer != nil
+	if pending {
+		clipboardTimer.Stop()
+		clipboardTimer = nil
+	}
+	clipboardMu.Unlock()
+	if pending {
+		_ = writeClipboard(nil)
+	}
+}
+
+// writeClipboard запись в буфер обмена, пустые данные стирают буфер
+func writeClipboard(data []byte) error {
+	setTerminalClipboard(data)
+	if !clipboardConf.Fallback {
+		return nil
+	}
+	return runClipboardTool(data)
+}
+
+// clipboardTool утилита буфера обмена: wl-copy под Wayland, иначе xclip или xsel
+func clipboardTool(clear bool) (string, []string, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if _, err := exec.LookPath("wl-copy"); err == nil {
+			if clear {
+				return "wl-copy", []string{"--clear"}, nil
+			}
+			return "wl-copy", nil, nil
+		}
+	}
+	if _, err := exec.LookPath("xclip"); err == nil {
+		return "xclip", []string{"-selection", "clipboard"}, nil
+	}
+	if _, err := exec.LookPath("xsel"); err == nil {
+		return "xsel", []string{"--clipboard", "--input"}, nil
+	}
+	return "", nil, errNoClipboardTool
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/importer"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubClipboard подмена записи в буфер обмена, записи терминала попадают в канал
+func stubClipboard(t *testing.T, conf config.Clipboard) (terminal chan string, tool *[]string) {
+	terminal = make(chan string, 10)
+	tool = &[]string{}
+	oldTerminal, oldTool := setTerminalClipboard, runClipboardTool
+	setTerminalClipboard = func(data []byte) { terminal <- string(data) }
+	runClipboardTool = func(data []byte) error {
+		*tool = append(*tool, string(data))
+		return nil
+	}
+	setClipboard(conf)
+	t.Cleanup(func() {
+		setTerminalClipboard, runClipboardTool = oldTerminal, oldTool
+		clipboardMu.Lock()
+		if clipboardTimer != nil {
+			clipboardTimer.Stop()
+			clipboardTimer = nil
+		}
+		clipboardMu.Unlock()
+	})
+	return terminal, tool
+}
+
+// runSimulatedApp запуск приложения на эмуляции экрана, чтобы работал QueueUpdate
+func runSimulatedApp(t *testing.T) *tview.Application {
+	screen := tcell.NewSimulationScreen("")
+	require.NoError(t, screen.Init())
+	app := tview.NewApplication().SetScreen(screen).SetRoot(tview.NewBox(), true)
+	go func() { _ = app.Run() }()
+	t.Cleanup(app.Stop)
+	return app
+}
+
+func TestSetClipboard_Default(t *testing.T) {
+	setClipboard(config.Clipboard{})
+	assert.Equal(t, defaultClipboardClear, clipboardConf.ClearAfter)
+
+	setClipboard(config.Clipboard{ClearAfter: time.Minute})
+	assert.Equal(t, time.Minute, clipboardConf.ClearAfter)
+}
+
+func TestCopyToClipboard_ClearsAfterTimeout(t *testing.T) {
+	terminal, tool := stubClipboard(t, config.Clipboard{ClearAfter: 20 * time.Millisecond})
+	app := runSimulatedApp(t)
+
+	require.NoError(t, copyToClipboard(app, "secret"))
+	assert.Equal(t, "secret", <-terminal)
+
+	select {
+	case data := <-terminal:
+		assert.Empty(t, data)
+	case <-time.After(2 * time.Second):
+		t.Fatal("clipboard was not cleared")
+	}
+	assert.Empty(t, *tool)
+}
+
+func TestCopyToClipboard_Fallback(t *testing.T) {
+	_, tool := stubClipboard(t, config.Clipboard{ClearAfter: time.Hour, Fallback: true})
+
+	require.NoError(t, copyToClipboard(tview.NewApplication(), "first"))
+	require.NoError(t, copyToClipboard(tview.NewApplication(), "second"))
+	clearClipboardOnExit()
+	assert.Equal(t, []string{"first", "second", ""}, *tool)
+
+	// Повторный выход ничего не стирает
+	clearClipboardOnExit()
+	assert.Len(t, *tool, 3)
+}
+
+func TestCopyToClipboard_FallbackError(t *testing.T) {
+	stubClipboard(t, config.Clipboard{ClearAfter: time.Hour, Fallback: true})
+	runClipboardTool = func([]byte) error { return errNoClipboardTool }
+
+	err := copyToClipboard(tview.NewApplication(), "secret")
+	assert.True(t, errors.Is(err, errNoClipboardTool))
+}
+
+func TestMaskCardNumber(t *testing.T) {
+	assert.Equal(t, "**** 1111", maskCardNumber("4111 1111 1111 1111"))
+	assert.Equal(t, "****", maskCardNumber("123"))
+}
+
+func TestTableItemName(t *testing.T) {
+	table := tview.NewTable()
+	table.SetCell(1, 0, tview.NewTableCell("id1")).SetCell(1, 2, tview.NewTableCell("alice"))
+	assert.Equal(t, "alice", tableItemName(table, "id1"))
+	assert.Empty(t, tableItemName(table, "missing"))
+}
+
+func TestShowPasswordContentModal_RevealAndCopy(t *testing.T) {
+	terminal, _ := stubClipboard(t, config.Clipboard{ClearAfter: time.Hour})
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	enc, err := utils.Encrypt("s3cret", aes)
+	require.NoError(t, err)
+
+	table := tview.NewTable()
+	table.SetCell(1, 0, tview.NewTableCell("item")).SetCell(1, 2, tview.NewTableCell("alice"))
+	message := tview.NewTextView()
+	app := tview.NewApplication()
+
+	showPasswordContentModal(app, "u", "t", "item", enc, table, message)
+	_, page := pages.GetFrontPage()
+	flex := page.(*tview.Flex)
+	textView := flex.GetItem(0).(*tview.TextView)
+	form := flex.GetItem(1).(*tview.Form)
+	press := func(label string) {
+		form.GetButton(form.GetButtonIndex(label)).
+			InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	assert.Equal(t, "Login: alice\nPassword: ********", textView.GetText(true))
+	press("Reveal")
+	assert.Equal(t, "Login: alice\nPassword: s3cret", textView.GetText(true))
+	press("Hide")
+	assert.Equal(t, "Login: alice\nPassword: ********", textView.GetText(true))
+
+	press("Copy password")
+	assert.Equal(t, "s3cret", <-terminal)
+	assert.Contains(t, message.GetText(true), "Password copied")
+	press("Copy login")
+	assert.Equal(t, "alice", <-terminal)
+}
+
+func TestShowCardContentModal_MaskedNumber(t *testing.T) {
+	terminal, _ := stubClipboard(t, config.Clipboard{ClearAfter: time.Hour})
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	enc, err := utils.Encrypt(importer.FormatCard("4111111111111111", "07/27", "123"), aes)
+	require.NoError(t, err)
+	message := tview.NewTextView()
+
+	showCardContentModal(tview.NewApplication(), "u", "t", "card", enc, tview.NewTable(), message)
+	_, page := pages.GetFrontPage()
+	flex := page.(*tview.Flex)
+	textView := flex.GetItem(0).(*tview.TextView)
+	form := flex.GetItem(1).(*tview.Form)
+
+	assert.Equal(t, importer.FormatCard("**** 1111", "07/27", "********"), textView.GetText(true))
+	form.GetButton(form.GetButtonIndex("Copy number")).
+		InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	assert.Equal(t, "4111111111111111", <-terminal)
+}
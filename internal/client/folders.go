@@ -0,0 +1,327 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// rootFolderName название корня дерева папок
+const rootFolderName = "All items"
+
+// folderList последний загруженный список папок пользователя
+var folderList []*pb.Folder
+
+// loadFolderTree загрузка папок пользователя в дерево
+func loadFolderTree(tree *tview.TreeView, userUID, token string) error {
+	resp, err := folderClient.ListFolders(authContext(userUID, token), &pb.ListFoldersRequest{})
+	if err != nil {
+		return err
+	}
+	folderList = resp.GetFolders()
+
+	root := tview.NewTreeNode(rootFolderName).
+		SetReference("").
+		SetColor(tcell.ColorYellow)
+
+	children := make(map[string][]*pb.Folder)
+	for _, folder := range folderList {
+		children[folder.GetParentId()] = append(children[folder.GetParentId()], folder)
+	}
+
+	var addChildren func(node *tview.TreeNode, parentID string)
+	addChildren = func(node *tview.TreeNode, parentID string) {
+		for _, folder := range children[parentID] {
+			child := tview.NewTreeNode(folder.GetName()).
+				SetReference(folder.GetId()).
+				SetSelectable(true)
+			node.AddChild(child)
+			addChildren(child, folder.GetId())
+		}
+	}
+	addChildren(root, "")
+
+	tree.SetRoot(root).SetCurrentNode(root)
+	return nil
+}
+
+// folderOptions пути папок для выпадающего списка, первым идёт корень
+func folderOptions(exclude string) ([]string, []string) {
+	byID := make(map[string]*pb.Folder, len(folderList))
+	for _, folder := range folderList {
+		byID[folder.GetId()] = folder
+	}
+
+	path := func(folder *pb.Folder) (string, bool) {
+		parts := []string{folder.GetName()}
+		for parent := byID[folder.GetParentId()]; parent != nil; parent = byID[parent.GetParentId()] {
+			if parent.GetId() == exclude {
+				return "", false
+			}
+			parts = append([]string{parent.GetName()}, parts...)
+		}
+		return "/" + strings.Join(parts, "/"), true
+	}
+
+	type option struct{ id, path string }
+	options := make([]option, 0, len(folderList))
+	for _, folder := range folderList {
+		if folder.GetId() == exclude {
+			continue
+		}
+		if p, ok := path(folder); ok {
+			options = append(options, option{id: folder.GetId(), path: p})
+		}
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].path < options[j].path })
+
+	ids := []string{""}
+	labels := []string{"/"}
+	for _, o := range options {
+		ids = append(ids, o.id)
+		labels = append(labels, o.path)
+	}
+	return ids, labels
+}
+
+// showAddFolderDialog модальное окно для создания папки
+func showAddFolderDialog(app *tview.Application, userUID, token, parentID string, tree *tview.TreeView, message *tview.TextView) {
+	nameField := tview.NewInputField().
+		SetLabel("Folder name: ").
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(nameField).
+		AddButton("Save", func() {
+			createFolder(userUID, token, parentID, nameField.GetText(), tree, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_folder")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" New folder ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_folder", dialogForm, true, true)
+	pages.SwitchToPage("dialog_folder")
+	app.SetFocus(dialogForm)
+}
+
+// showRenameFolderDialog модальное окно для переименования папки
+func showRenameFolderDialog(app *tview.Application, userUID, token, folderID string, tree *tview.TreeView, message *tview.TextView) {
+	nameField := tview.NewInputField().
+		SetLabel("New name: ").
+		SetFieldWidth(40)
+	if node := tree.GetCurrentNode(); node != nil {
+		nameField.SetText(node.GetText())
+	}
+
+	dialogForm := tview.NewForm().
+		AddFormItem(nameField).
+		AddButton("Save", func() {
+			renameFolder(userUID, token, folderID, nameField.GetText(), tree, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_folder")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Rename folder ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_folder", dialogForm, true, true)
+	pages.SwitchToPage("dialog_folder")
+	app.SetFocus(dialogForm)
+}
+
+// showMoveFolderDialog модальное окно для перемещения папки
+func showMoveFolderDialog(app *tview.Application, userUID, token, folderID string, tree *tview.TreeView, message *tview.TextView) {
+	ids, labels := folderOptions(folderID)
+	parentField := tview.NewDropDown().
+		SetLabel("Move to: ").
+		SetOptions(labels, nil).
+		SetCurrentOption(0)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(parentField).
+		AddButton("Move", func() {
+			index, _ := parentField.GetCurrentOption()
+			if index < 0 {
+				index = 0
+			}
+			moveFolder(userUID, token, folderID, ids[index], tree, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_folder")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Move folder ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_folder", dialogForm, true, true)
+	pages.SwitchToPage("dialog_folder")
+	app.SetFocus(dialogForm)
+}
+
+// showMoveDataDialog модальное окно для перемещения данных в папку
+func showMoveDataDialog(app *tview.Application, userUID, token, itemID string, table *tview.Table, message *tview.TextView) {
+	ids, labels := folderOptions("")
+	folderField := tview.NewDropDown().
+		SetLabel("Move to: ").
+		SetOptions(labels, nil).
+		SetCurrentOption(0)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(folderField).
+		AddButton("Move", func() {
+			index, _ := folderField.GetCurrentOption()
+			if index < 0 {
+				index = 0
+			}
+			moveItem(userUID, token, itemID, ids[index], table, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_move_data")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Move item ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_move_data", dialogForm, true, true)
+	pages.SwitchToPage("dialog_move_data")
+	app.SetFocus(dialogForm)
+}
+
+// showTagsDialog модальное окно для редактирования тегов данных
+func showTagsDialog(app *tview.Application, userUID, token, itemID, oldTags string, table *tview.Table, message *tview.TextView) {
+	tagsField := tview.NewInputField().
+		SetLabel("Tags (comma separated): ").
+		SetText(oldTags).
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(tagsField).
+		AddButton("Save", func() {
+			setTags(userUID, token, itemID, tagsField.GetText(), table, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_tags")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Edit tags ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_tags", dialogForm, true, true)
+	pages.SwitchToPage("dialog_tags")
+	app.SetFocus(dialogForm)
+}
+
+// createFolder запрос на создание папки
+func createFolder(userUID, token, parentID, name string, tree *tview.TreeView, message *tview.TextView) {
+	_, err := folderClient.CreateFolder(authContext(userUID, token), &pb.CreateFolderRequest{
+		ParentId: parentID,
+		Name:     name,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Create folder error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Folder created!")
+		_ = loadFolderTree(tree, userUID, token)
+	}
+	closeDialog("dialog_folder")
+}
+
+// renameFolder запрос на переименование папки
+func renameFolder(userUID, token, folderID, name string, tree *tview.TreeView, message *tview.TextView) {
+	_, err := folderClient.RenameFolder(authContext(userUID, token), &pb.RenameFolderRequest{
+		Id:   folderID,
+		Name: name,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Rename folder error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Folder renamed!")
+		_ = loadFolderTree(tree, userUID, token)
+	}
+	closeDialog("dialog_folder")
+}
+
+// moveFolder запрос на перемещение папки
+func moveFolder(userUID, token, folderID, parentID string, tree *tview.TreeView, message *tview.TextView) {
+	_, err := folderClient.MoveFolder(authContext(userUID, token), &pb.MoveFolderRequest{
+		Id:       folderID,
+		ParentId: parentID,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Move folder error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Folder moved!")
+		_ = loadFolderTree(tree, userUID, token)
+	}
+	closeDialog("dialog_folder")
+}
+
+// deleteFolder запрос на удаление папки
+func deleteFolder(userUID, token, folderID string, tree *tview.TreeView, table *tview.Table, message *tview.TextView) {
+	_, err := folderClient.DeleteFolder(authContext(userUID, token), &pb.DeleteFolderRequest{Id: folderID})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Delete folder error: %v", err))
+		return
+	}
+	message.SetTextColor(tcell.ColorGreen).SetText("Folder deleted!")
+	if listFilter.FolderId == folderID {
+		listFilter.FolderId = ""
+	}
+	_ = loadFolderTree(tree, userUID, token)
+	_ = loadUserData(table, userUID, token)
+}
+
+// moveItem запрос на перемещение данных в папку
+func moveItem(userUID, token, itemID, folderID string, table *tview.Table, message *tview.TextView) {
+	_, err := folderClient.MoveData(authContext(userUID, token), &pb.MoveDataRequest{
+		Id:       itemID,
+		FolderId: folderID,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Move error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Item moved!")
+		_ = loadUserData(table, userUID, token)
+	}
+	closeDialog("dialog_move_data")
+}
+
+// setTags запрос на установку тегов данных
+func setTags(userUID, token, itemID, tags string, table *tview.Table, message *tview.TextView) {
+	_, err := folderClient.SetDataTags(authContext(userUID, token), &pb.SetDataTagsRequest{
+		Id:   itemID,
+		Tags: splitTags(tags),
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Tags error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Tags saved!")
+		_ = loadUserData(table, userUID, token)
+	}
+	closeDialog("dialog_tags")
+}
+
+// splitTags разбор строки тегов, разделённых запятыми
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
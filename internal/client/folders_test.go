@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type fakeFolderClient struct {
+	listResp        *pb.ListFoldersResponse
+	lastCreate      *pb.CreateFolderRequest
+	lastMoveData    *pb.MoveDataRequest
+	lastSetDataTags *pb.SetDataTagsRequest
+	returnErr       error
+}
+
+func (f *fakeFolderClient) CreateFolder(ctx context.Context, in *pb.CreateFolderRequest, opts ...grpc.CallOption) (*pb.CreateFolderResponse, error) {
+	f.lastCreate = in
+	return &pb.CreateFolderResponse{Id: "new-folder"}, f.returnErr
+}
+
+func (f *fakeFolderClient) RenameFolder(ctx context.Context, in *pb.RenameFolderRequest, opts ...grpc.CallOption) (*pb.RenameFolderResponse, error) {
+	return &pb.RenameFolderResponse{}, f.returnErr
+}
+
+func (f *fakeFolderClient) MoveFolder(ctx context.Context, in *pb.MoveFolderRequest, opts ...grpc.CallOption) (*pb.MoveFolderResponse, error) {
+	return &pb.MoveFolderResponse{}, f.returnErr
+}
+
+func (f *fakeFolderClient) DeleteFolder(ctx context.Context, in *pb.DeleteFolderRequest, opts ...grpc.CallOption) (*pb.DeleteFolderResponse, error) {
+	return &pb.DeleteFolderResponse{}, f.returnErr
+}
+
+func (f *fakeFolderClient) ListFolders(ctx context.Context, in *pb.ListFoldersRequest, opts ...grpc.CallOption) (*pb.ListFoldersResponse, error) {
+	return f.listResp, f.returnErr
+}
+
+func (f *fakeFolderClient) MoveData(ctx context.Context, in *pb.MoveDataRequest, opts ...grpc.CallOption) (*pb.MoveDataResponse, error) {
+	f.lastMoveData = in
+	return &pb.MoveDataResponse{}, f.returnErr
+}
+
+func (f *fakeFolderClient) SetDataTags(ctx context.Context, in *pb.SetDataTagsRequest, opts ...grpc.CallOption) (*pb.SetDataTagsResponse, error) {
+	f.lastSetDataTags = in
+	return &pb.SetDataTagsResponse{}, f.returnErr
+}
+
+func TestLoadFolderTree_Success(t *testing.T) {
+	folderClient = &fakeFolderClient{
+		listResp: &pb.ListFoldersResponse{
+			Folders: []*pb.Folder{
+				{Id: "f1", Name: "work"},
+				{Id: "f2", ParentId: "f1", Name: "bank"},
+				{Id: "f3", Name: "home"},
+			},
+		},
+	}
+	tree := tview.NewTreeView()
+
+	err := loadFolderTree(tree, "user", "token")
+	assert.NoError(t, err)
+
+	root := tree.GetRoot()
+	assert.Equal(t, rootFolderName, root.GetText())
+	assert.Len(t, root.GetChildren(), 2)
+	assert.Equal(t, "work", root.GetChildren()[0].GetText())
+	assert.Equal(t, "f2", root.GetChildren()[0].GetChildren()[0].GetReference())
+}
+
+func TestLoadFolderTree_Error(t *testing.T) {
+	folderClient = &fakeFolderClient{returnErr: errors.New("list failed")}
+
+	err := loadFolderTree(tview.NewTreeView(), "user", "token")
+	assert.EqualError(t, err, "list failed")
+}
+
+func TestFolderOptions_ExcludesSubtree(t *testing.T) {
+	folderList = []*pb.Folder{
+		{Id: "f1", Name: "work"},
+		{Id: "f2", ParentId: "f1", Name: "bank"},
+		{Id: "f3", Name: "home"},
+	}
+
+	ids, labels := folderOptions("f1")
+	assert.Equal(t, []string{"", "f3"}, ids)
+	assert.Equal(t, []string{"/", "/home"}, labels)
+
+	ids, labels = folderOptions("")
+	assert.Equal(t, []string{"", "f3", "f1", "f2"}, ids)
+	assert.Equal(t, []string{"/", "/home", "/work", "/work/bank"}, labels)
+}
+
+func TestMoveItem_Error(t *testing.T) {
+	client := &fakeFolderClient{returnErr: errors.New("boom")}
+	folderClient = client
+	message := tview.NewTextView()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log("Recovered from panic for TUI")
+		}
+	}()
+	moveItem("user", "token", "item", "f1", tview.NewTable(), message)
+
+	assert.Equal(t, "f1", client.lastMoveData.FolderId)
+	assert.Contains(t, message.GetText(true), "Move error: boom")
+}
+
+func TestSetTags(t *testing.T) {
+	client := &fakeFolderClient{}
+	folderClient = client
+	dataClient = &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log("Recovered from panic for TUI")
+		}
+	}()
+	setTags("user", "token", "item", " work, ,bank ", tview.NewTable(), tview.NewTextView())
+
+	assert.Equal(t, []string{"work", "bank"}, client.lastSetDataTags.Tags)
+}
+
+func TestSplitTags(t *testing.T) {
+	assert.Nil(t, splitTags(" , "))
+	assert.Equal(t, []string{"a", "b"}, splitTags("a, b"))
+}
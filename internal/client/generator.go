@@ -0,0 +1,48 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/fngoc/gault/pkg/passgen"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// strengthColors цвета оценок надёжности от очень слабой до очень надёжной
+var strengthColors = []tcell.Color{tcell.ColorRed, tcell.ColorOrangeRed, tcell.ColorYellow, tcell.ColorGreen, tcell.ColorLimeGreen}
+
+// passwordStrengthView поле формы с оценкой надёжности пароля, обновляется при вводе
+func passwordStrengthView(field *tview.InputField) *tview.TextView {
+	view := tview.NewTextView().
+		SetLabel("Strength: ").
+		SetSize(1, 40)
+
+	update := func(text string) {
+		strength := passgen.Estimate(text)
+		view.SetTextColor(strengthColors[strength.Score]).
+			SetText(fmt.Sprintf("%s (%.0f bits)", strength.Label, strength.Entropy))
+	}
+	field.SetChangedFunc(update)
+	update(field.GetText())
+	return view
+}
+
+// addGeneratorButtons кнопки генерации пароля и парольной фразы в поле формы.
+// Маска снимается, чтобы сгенерированный пароль был виден
+func addGeneratorButtons(form *tview.Form, field *tview.InputField, message *tview.TextView) *tview.Form {
+	fill := func(password string, err error) {
+		if err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Generate error: %v", err))
+			return
+		}
+		field.SetMaskCharacter(0).SetText(password)
+	}
+	return form.
+		AddButton("Generate", func() {
+			fill(passgen.Generate(passgen.DefaultOptions))
+		}).
+		AddButton("Passphrase", func() {
+			fill(passgen.Passphrase(passgen.DefaultWords, "-"))
+		})
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fngoc/gault/pkg/passgen"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordStrengthView(t *testing.T) {
+	field := tview.NewInputField()
+	view := passwordStrengthView(field)
+	assert.Equal(t, "very weak (0 bits)", view.GetText(true))
+
+	field.SetText("correct-horse-battery-staple")
+	assert.True(t, strings.HasPrefix(view.GetText(true), "very strong"))
+}
+
+func TestAddGeneratorButtons(t *testing.T) {
+	field := tview.NewInputField().SetMaskCharacter('*')
+	view := passwordStrengthView(field)
+	form := addGeneratorButtons(tview.NewForm(), field, tview.NewTextView())
+	press := func(label string) {
+		form.GetButton(form.GetButtonIndex(label)).
+			InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	}
+
+	press("Generate")
+	assert.Len(t, field.GetText(), passgen.DefaultOptions.Length)
+	assert.True(t, strings.HasPrefix(view.GetText(true), "strong") || strings.HasPrefix(view.GetText(true), "very strong"))
+
+	press("Passphrase")
+	assert.Len(t, strings.Split(field.GetText(), "-"), passgen.DefaultWords)
+}
@@ -0,0 +1,286 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/passgen"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// passwordMaxAge возраст пароля, после которого он считается старым
+const passwordMaxAge = 365 * 24 * time.Hour
+
+// errInvalidRotationDays период смены не число дней
+var errInvalidRotationDays = errors.New("rotation period must be a number of days")
+
+// healthReport отчёт о состоянии хранилища
+type healthReport struct {
+	// expired данные с истёкшим сроком действия
+	expired []*pb.HealthItem
+	// due данные, которые пора сменить по периоду смены
+	due []*pb.HealthItem
+	// old пароли, не менявшиеся дольше passwordMaxAge
+	old []*pb.HealthItem
+	// weak пароли с оценкой надёжности ниже fair
+	weak []*pb.HealthItem
+	// reused группы элементов с одинаковым паролем
+	reused [][]*pb.HealthItem
+}
+
+// buildHealthReport построение отчёта по сведениям сервера и расшифрованным паролям, passwords — пароль по id элемента
+func buildHealthReport(items []*pb.HealthItem, passwords map[string]string, now time.Time) healthReport {
+	var report healthReport
+	groups := make(map[string][]*pb.HealthItem)
+	var order []string
+
+	for _, item := range items {
+		switch {
+		case item.GetExpiresAt() != 0 && item.GetExpiresAt() <= now.Unix():
+			report.expired = append(report.expired, item)
+		case item.GetRotationDue() || rotationDueAt(item, now):
+			// Сервер отмечает данные по расписанию, между проверками срок считается на месте
+			report.due = append(report.due, item)
+		}
+
+		password, ok := passwords[item.GetId()]
+		if !ok {
+			continue
+		}
+		if now.Sub(time.Unix(item.GetUpdatedAt(), 0)) > passwordMaxAge {
+			report.old = append(report.old, item)
+		}
+		if passgen.Estimate(password).Score < passgen.ScoreFair {
+			report.weak = append(report.weak, item)
+		}
+		if _, seen := groups[password]; !seen {
+			order = append(order, password)
+		}
+		groups[password] = append(groups[password], item)
+	}
+
+	for _, password := range order {
+		if len(groups[password]) > 1 {
+			report.reused = append(report.reused, groups[password])
+		}
+	}
+	return report
+}
+
+// rotationDueAt наступил ли срок смены данных по периоду смены
+func rotationDueAt(item *pb.HealthItem, now time.Time) bool {
+	if item.GetRotationDays() == 0 {
+		return false
+	}
+	dueAt := time.Unix(item.GetUpdatedAt(), 0).AddDate(0, 0, int(item.GetRotationDays()))
+	return !now.Before(dueAt)
+}
+
+// loadVaultHealth получение сведений о хранилище и расшифровка паролей для отчёта
+func loadVaultHealth(userUID, token string) (healthReport, error) {
+	ctx := authContext(userUID, token)
+	resp, err := dataClient.GetVaultHealth(ctx, &pb.GetVaultHealthRequest{})
+	if err != nil {
+		return healthReport{}, err
+	}
+
+	passwords := make(map[string]string)
+	for _, item := range resp.GetItems() {
+		if item.GetType() != "password" {
+			continue
+		}
+		password, err := readItemText(ctx, &pb.UserDataItem{Id: item.GetId(), Name: item.GetName()})
+		if err != nil {
+			return healthReport{}, err
+		}
+		passwords[item.GetId()] = password
+	}
+	return buildHealthReport(resp.GetItems(), passwords, timeNow()), nil
+}
+
+// showHealthScreen экран отчёта о состоянии хранилища
+func showHealthScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	table := tview.NewTable()
+	table.SetBorders(true).
+		SetSelectable(true, false)
+
+	form := tview.NewForm().
+		AddButton("Back", func() {
+			pages.RemovePage("health_screen")
+			pages.SwitchToPage("data_screen")
+		})
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyBacktab {
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	report, err := loadVaultHealth(userUID, token)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading vault health: %v", err))
+	}
+	fillHealthTable(table, report, timeNow())
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(form, 3, 1, false).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Vault health ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("health_screen", flex, true, true)
+	pages.SwitchToPage("health_screen")
+	app.SetFocus(table)
+}
+
+// fillHealthTable вывод отчёта в таблицу, по строке на проблему
+func fillHealthTable(table *tview.Table, report healthReport, now time.Time) {
+	table.Clear()
+	table.SetCell(0, 0, tview.NewTableCell("ISSUE").SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("ITEM").SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("DETAILS").SetSelectable(false))
+
+	row := 1
+	add := func(issue string, color tcell.Color, item *pb.HealthItem, details string) {
+		table.SetCell(row, 0, tview.NewTableCell(issue).SetTextColor(color)).
+			SetCell(row, 1, tview.NewTableCell(item.GetName())).
+			SetCell(row, 2, tview.NewTableCell(details))
+		row++
+	}
+
+	for _, item := range report.expired {
+		add("Expired", tcell.ColorRed, item, "expired "+formatDate(item.GetExpiresAt()))
+	}
+	for _, item := range report.due {
+		add("Rotation due", tcell.ColorOrange, item, fmt.Sprintf("every %d days, last changed %s",
+			item.GetRotationDays(), formatDate(item.GetUpdatedAt())))
+	}
+	for _, item := range report.old {
+		add("Old", tcell.ColorYellow, item, fmt.Sprintf("not changed for %d days",
+			int(now.Sub(time.Unix(item.GetUpdatedAt(), 0)).Hours()/24)))
+	}
+	for _, item := range report.weak {
+		add("Weak", tcell.ColorRed, item, "")
+	}
+	for _, group := range report.reused {
+		for _, item := range group {
+			add("Reused", tcell.ColorOrange, item, fmt.Sprintf("same password in %d items", len(group)))
+		}
+	}
+
+	if row == 1 {
+		table.SetCell(1, 0, tview.NewTableCell("No issues found").SetTextColor(tcell.ColorGreen))
+	}
+}
+
+// formatDate дата из секунд Unix
+func formatDate(sec int64) string {
+	return time.Unix(sec, 0).Format(time.DateOnly)
+}
+
+// showRotationDialog модальное окно срока действия и периода смены данных
+func showRotationDialog(app *tview.Application, userUID, token, itemID string, message *tview.TextView) {
+	var expires, days string
+	// Текущие значения есть в отчёте о хранилище, если они заданы
+	if resp, err := dataClient.GetVaultHealth(authContext(userUID, token), &pb.GetVaultHealthRequest{}); err == nil {
+		for _, item := range resp.GetItems() {
+			if item.GetId() != itemID {
+				continue
+			}
+			if item.GetExpiresAt() != 0 {
+				expires = formatDate(item.GetExpiresAt())
+			}
+			if item.GetRotationDays() != 0 {
+				days = strconv.FormatUint(uint64(item.GetRotationDays()), 10)
+			}
+		}
+	}
+
+	expiresField := tview.NewInputField().
+		SetLabel("Expires on (YYYY-MM-DD): ").
+		SetText(expires).
+		SetFieldWidth(12)
+
+	daysField := tview.NewInputField().
+		SetLabel("Rotate every (days): ").
+		SetText(days).
+		SetAcceptanceFunc(tview.InputFieldInteger).
+		SetFieldWidth(6)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(expiresField).
+		AddFormItem(daysField).
+		AddButton("Save", func() {
+			setRotation(userUID, token, itemID, expiresField.GetText(), daysField.GetText(), message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_rotation")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Expiry and rotation (empty to disable) ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_rotation", dialogForm, true, true)
+	pages.SwitchToPage("dialog_rotation")
+	app.SetFocus(dialogForm)
+}
+
+// setRotation запрос на установку срока действия и периода смены данных
+func setRotation(userUID, token, itemID, expires, days string, message *tview.TextView) {
+	expiresAt, rotationDays, err := parseRotation(expires, days)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Rotation error: %v", err))
+		return
+	}
+
+	_, err = dataClient.SetDataRotation(authContext(userUID, token), &pb.SetDataRotationRequest{
+		Id:           itemID,
+		ExpiresAt:    expiresAt,
+		RotationDays: rotationDays,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Rotation error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Rotation saved!")
+	}
+	closeDialog("dialog_rotation")
+}
+
+// parseRotation разбор даты окончания срока действия и периода смены, пустые значения — без ограничений
+func parseRotation(expires, days string) (int64, uint32, error) {
+	var expiresAt int64
+	if expires = strings.TrimSpace(expires); expires != "" {
+		date, err := time.ParseInLocation(time.DateOnly, expires, time.Local)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid expiry date %q, expected YYYY-MM-DD", expires)
+		}
+		expiresAt = date.Unix()
+	}
+
+	var rotationDays uint64
+	if days = strings.TrimSpace(days); days != "" {
+		var err error
+		if rotationDays, err = strconv.ParseUint(days, 10, 32); err != nil {
+			return 0, 0, errInvalidRotationDays
+		}
+	}
+	return expiresAt, uint32(rotationDays), nil
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHealthReport(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	day := int64(24 * 60 * 60)
+	items := []*pb.HealthItem{
+		{Id: "expired", Type: "text", UpdatedAt: now.Unix(), ExpiresAt: now.Unix() - day},
+		{Id: "flagged", Type: "password", UpdatedAt: now.Unix(), RotationDays: 30, RotationDue: true},
+		{Id: "period", Type: "password", UpdatedAt: now.Unix() - 31*day, RotationDays: 30},
+		{Id: "fresh", Type: "password", UpdatedAt: now.Unix() - 10*day, RotationDays: 30, ExpiresAt: now.Unix() + day},
+		{Id: "old", Type: "password", UpdatedAt: now.Unix() - 400*day},
+	}
+	passwords := map[string]string{
+		"flagged": "Xk#9vQ!m2Lp$7rTz",
+		"period":  "password",
+		"fresh":   "Xk#9vQ!m2Lp$7rTz",
+		"old":     "correct-horse-battery-staple-42",
+	}
+
+	report := buildHealthReport(items, passwords, now)
+	assert.Equal(t, []*pb.HealthItem{items[0]}, report.expired)
+	assert.Equal(t, []*pb.HealthItem{items[1], items[2]}, report.due)
+	assert.Equal(t, []*pb.HealthItem{items[4]}, report.old)
+	assert.Equal(t, []*pb.HealthItem{items[2]}, report.weak)
+	assert.Equal(t, [][]*pb.HealthItem{{items[1], items[3]}}, report.reused)
+}
+
+func TestParseRotation(t *testing.T) {
+	expiresAt, days, err := parseRotation(" 2026-01-02 ", "90")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local).Unix(), expiresAt)
+	assert.Equal(t, uint32(90), days)
+
+	expiresAt, days, err = parseRotation("", "")
+	require.NoError(t, err)
+	assert.Zero(t, expiresAt)
+	assert.Zero(t, days)
+
+	_, _, err = parseRotation("02.01.2026", "")
+	assert.ErrorContains(t, err, "YYYY-MM-DD")
+	_, _, err = parseRotation("", "-1")
+	assert.ErrorIs(t, err, errInvalidRotationDays)
+}
+
+func TestSetRotation(t *testing.T) {
+	client := &fakeDataClient{}
+	dataClient = client
+	pages = tview.NewPages()
+	message := tview.NewTextView()
+
+	setRotation("u", "t", "item", "", "30", message)
+	assert.Equal(t, &pb.SetDataRotationRequest{Id: "item", RotationDays: 30}, client.lastRotationRequest)
+	assert.Equal(t, "Rotation saved!", message.GetText(true))
+
+	client.lastRotationRequest = nil
+	setRotation("u", "t", "item", "tomorrow", "", message)
+	assert.Nil(t, client.lastRotationRequest)
+	assert.Contains(t, message.GetText(true), "invalid expiry date")
+
+	client.returnErr = errors.New("not found")
+	setRotation("u", "t", "item", "", "", message)
+	assert.Equal(t, "Rotation error: not found", message.GetText(true))
+}
+
+func TestShowHealthScreen(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	stubNow(t, now)
+	aes = "1234567891234567"
+	itemKeys = make(map[string]string)
+	weak, err := utils.Encrypt("qwerty", aes)
+	require.NoError(t, err)
+
+	dataClient = &fakeDataClient{
+		vaultHealthResp: &pb.GetVaultHealthResponse{Items: []*pb.HealthItem{
+			{Id: "a", Name: "bank", Type: "password", UpdatedAt: now.Unix()},
+			{Id: "b", Name: "mail", Type: "password", UpdatedAt: now.Unix()},
+		}},
+		getDataResp: &pb.GetDataResponse{Type: "password", Content: &pb.GetDataResponse_TextData{TextData: weak}},
+	}
+	pages = tview.NewPages()
+
+	showHealthScreen(tview.NewApplication(), "u", "t", tview.NewTextView())
+	name, page := pages.GetFrontPage()
+	assert.Equal(t, "health_screen", name)
+	table := page.(*tview.Flex).GetItem(0).(*tview.Table)
+	// Оба пароля слабые и совпадают
+	require.Equal(t, 5, table.GetRowCount())
+	assert.Equal(t, "Weak", table.GetCell(1, 0).Text)
+	assert.Equal(t, "Weak", table.GetCell(2, 0).Text)
+	assert.Equal(t, "Reused", table.GetCell(3, 0).Text)
+	assert.Equal(t, "same password in 2 items", table.GetCell(4, 2).Text)
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fngoc/gault/internal/importer"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// importFormatLabel подпись формата выгрузки в выпадающем списке
+func importFormatLabel(format importer.Format) string {
+	switch format {
+	case importer.FormatKeePassXML:
+		return "KeePass XML"
+	case importer.FormatBitwardenJSON:
+		return "Bitwarden JSON"
+	case importer.Format1PasswordCSV:
+		return "1Password CSV"
+	default:
+		return "CSV (column mapping)"
+	}
+}
+
+// showImportDialog модальное окно импорта выгрузки другого менеджера паролей
+func showImportDialog(app *tview.Application, userUID, token string, message *tview.TextView, table *tview.Table) {
+	labels := make([]string, 0, len(importer.Formats))
+	for _, format := range importer.Formats {
+		labels = append(labels, importFormatLabel(format))
+	}
+
+	formatField := tview.NewDropDown().
+		SetLabel("Format: ").
+		SetOptions(labels, nil).
+		SetCurrentOption(0)
+	pathField := tview.NewInputField().
+		SetLabel("File: ").
+		SetFieldWidth(40)
+	mappingField := tview.NewInputField().
+		SetLabel("CSV columns: ").
+		SetPlaceholder("name=Title, username=Login, password=Password").
+		SetFieldWidth(60)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(formatField).
+		AddFormItem(pathField).
+		AddFormItem(mappingField).
+		AddTextView("", "Fields: name, username, password, url, notes, card_number, card_date, card_cvc", 0, 1, true, false).
+		AddButton("Preview", func() {
+			index, _ := formatField.GetCurrentOption()
+			items, err := parseImportFile(importer.Formats[index], pathField.GetText(), mappingField.GetText())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Import error: %v", err))
+				closeDialog("dialog_import")
+				return
+			}
+			pages.RemovePage("dialog_import")
+			showImportPreview(app, userUID, token, items, message, table)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_import")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Import ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_import", dialogForm, true, true)
+	pages.SwitchToPage("dialog_import")
+	app.SetFocus(dialogForm)
+}
+
+// showImportPreview предварительный просмотр элементов импорта, до подтверждения ничего не сохраняется
+func showImportPreview(app *tview.Application, userUID, token string, items []importer.Item, message *tview.TextView, table *tview.Table) {
+	counts := make(map[string]int)
+	preview := tview.NewTable().SetBorders(true)
+	preview.SetCell(0, 0, tview.NewTableCell("TYPE").SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("NAME").SetSelectable(false))
+	for i, item := range items {
+		counts[item.Type]++
+		preview.SetCell(i+1, 0, tview.NewTableCell(item.Type))
+		preview.SetCell(i+1, 1, tview.NewTableCell(item.Name))
+	}
+	preview.SetSelectable(true, false)
+
+	summary := tview.NewTextView().
+		SetText(fmt.Sprintf("Dry run: %d items (%d passwords, %d cards, %d notes). Nothing is saved until Import",
+			len(items), counts[importer.TypePassword], counts[importer.TypeCard], counts[importer.TypeText])).
+		SetTextAlign(tview.AlignCenter)
+
+	form := tview.NewForm().
+		AddButton("Import", func() {
+			count, err := importItems(userUID, token, items)
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Imported %d of %d items, error: %v", count, len(items), err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText(fmt.Sprintf("Imported %d items", count))
+			}
+			_ = loadUserData(table, userUID, token)
+			closeDialog("import_preview")
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("import_preview")
+		})
+
+	preview.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(preview, 0, 1, false).
+		AddItem(summary, 1, 1, false).
+		AddItem(form, 3, 1, true)
+
+	flex.SetBorder(true).
+		SetTitle(" Import preview ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("import_preview", flex, true, true)
+	pages.SwitchToPage("import_preview")
+	app.SetFocus(form)
+}
+
+// parseImportFile разбор файла выгрузки, сопоставление колонок нужно только для CSV
+func parseImportFile(format importer.Format, path, mapping string) ([]importer.Item, error) {
+	var m importer.Mapping
+	if format == importer.FormatCSV {
+		var err error
+		if m, err = importer.ParseMapping(mapping); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	return importer.Parse(format, f, m)
+}
+
+// importItems сохранение элементов в открытое хранилище через saveData, возвращает число сохранённых
+func importItems(userUID, token string, items []importer.Item) (int, error) {
+	for i, item := range items {
+		if err := saveData(userUID, token, item.Type, item.Name, "", []byte(item.Content)); err != nil {
+			return i, fmt.Errorf("failed to import %q: %w", item.Name, err)
+		}
+	}
+	return len(items), nil
+}
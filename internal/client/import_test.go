@@ -0,0 +1,73 @@
+package client
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/internal/importer"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImportFile_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Site,Login,Pass\nforum,dave,secret\n"), 0600))
+
+	items, err := parseImportFile(importer.FormatCSV, path, "name=Site, username=Login, password=Pass")
+	require.NoError(t, err)
+	assert.Equal(t, []importer.Item{{Type: "password", Name: "forum (dave)", Content: "secret"}}, items)
+
+	_, err = parseImportFile(importer.FormatCSV, path, "")
+	assert.ErrorIs(t, err, importer.ErrInvalidMapping)
+
+	_, err = parseImportFile(importer.FormatBitwardenJSON, filepath.Join(t.TempDir(), "missing.json"), "")
+	assert.Error(t, err)
+}
+
+func TestImportItems(t *testing.T) {
+	aes = "1234567891234567"
+	currentOrg, currentCollection = nil, ""
+	client := &fakeDataClient{}
+	dataClient = client
+
+	count, err := importItems("user1", "token1", []importer.Item{
+		{Type: "password", Name: "forum (dave)", Content: "secret"},
+		{Type: "text", Name: "wifi", Content: "ssid: home"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	require.Len(t, client.receivedChunks, 2)
+	assert.Equal(t, "forum (dave)", client.receivedChunks[0].Name)
+	plain, err := utils.Decrypt(string(client.receivedChunks[0].Data), aes)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plain)
+	assert.Equal(t, "ssid: home", string(client.receivedChunks[1].Data))
+}
+
+func TestImportItems_StopsOnError(t *testing.T) {
+	currentOrg, currentCollection = nil, ""
+	dataClient = &fakeDataClient{saveDataSendErr: errors.New("send failed")}
+
+	count, err := importItems("user1", "token1", []importer.Item{{Type: "text", Name: "a", Content: "b"}})
+	assert.Equal(t, 0, count)
+	assert.ErrorContains(t, err, "send failed")
+}
+
+func TestShowImportPreview(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	dataClient = &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+
+	showImportDialog(app, "user1", "token1", tview.NewTextView(), tview.NewTable())
+	assert.True(t, pages.HasPage("dialog_import"))
+
+	showImportPreview(app, "user1", "token1", []importer.Item{{Type: "card", Name: "Visa"}}, tview.NewTextView(), tview.NewTable())
+	assert.True(t, pages.HasPage("import_preview"))
+}
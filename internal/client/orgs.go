@@ -0,0 +1,533 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var (
+	// currentOrg организация открытого командного хранилища, nil для личного хранилища
+	currentOrg *pb.Organization
+	// currentOrgKey расшифрованный ключ организации открытого командного хранилища
+	currentOrgKey string
+	// currentCollection коллекция, в которую сохраняются новые данные командного хранилища
+	currentCollection string
+)
+
+// orgRoles варианты роли участника для выпадающего списка
+var orgRoles = []pb.OrgRole{
+	pb.OrgRole_ORG_ROLE_MEMBER,
+	pb.OrgRole_ORG_ROLE_READ_ONLY,
+	pb.OrgRole_ORG_ROLE_ADMIN,
+	pb.OrgRole_ORG_ROLE_OWNER,
+}
+
+// errNoCollection в командном хранилище данные сохраняются только в коллекцию
+var errNoCollection = errors.New("select a collection to save data into team vault")
+
+// roleLabel подпись роли участника организации
+func roleLabel(role pb.OrgRole) string {
+	switch role {
+	case pb.OrgRole_ORG_ROLE_OWNER:
+		return "owner"
+	case pb.OrgRole_ORG_ROLE_ADMIN:
+		return "admin"
+	case pb.OrgRole_ORG_ROLE_MEMBER:
+		return "member"
+	default:
+		return "read-only"
+	}
+}
+
+// vaultKey ключ шифрования данных открытого хранилища
+func vaultKey() string {
+	if currentOrg != nil {
+		return currentOrgKey
+	}
+	return aes
+}
+
+// vaultTitle заголовок экрана данных открытого хранилища
+func vaultTitle() string {
+	if currentOrg != nil {
+		return fmt.Sprintf(" Team vault: %s (%s) ", currentOrg.GetName(), roleLabel(currentOrg.GetRole()))
+	}
+	return " Your data "
+}
+
+// openOrgKey расшифровка ключа организации приватным ключом пользователя
+func openOrgKey(org *pb.Organization) (string, error) {
+	key, err := utils.OpenKey(org.GetWrappedKey(), userPublicKey, userPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to open organization key: %w", err)
+	}
+	return key, nil
+}
+
+// switchVault переключение между личным (org == nil) и командным хранилищем
+func switchVault(org *pb.Organization) error {
+	if org == nil {
+		currentOrg, currentOrgKey, currentCollection = nil, "", ""
+		return nil
+	}
+	key, err := openOrgKey(org)
+	if err != nil {
+		return err
+	}
+	currentOrg, currentOrgKey, currentCollection = org, key, ""
+	return nil
+}
+
+// createOrganization создание организации со случайным ключом, зашифрованным публичным ключом создателя
+func createOrganization(userUID, token, name string) error {
+	orgKey, err := utils.GenerateDataKey()
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := utils.SealKey(orgKey, userPublicKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = orgClient.CreateOrganization(authContext(userUID, token), &pb.CreateOrganizationRequest{
+		Name:       strings.TrimSpace(name),
+		WrappedKey: wrappedKey,
+	})
+	return err
+}
+
+// inviteMember приглашение пользователя по логину, ключ организации шифруется его публичным ключом
+func inviteMember(userUID, token string, org *pb.Organization, login string, role pb.OrgRole) error {
+	ctx := authContext(userUID, token)
+	invitee, err := shareClient.GetPublicKey(ctx, &pb.GetPublicKeyRequest{Login: login})
+	if err != nil {
+		return err
+	}
+
+	orgKey, err := openOrgKey(org)
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := utils.SealKey(orgKey, invitee.GetPublicKey())
+	if err != nil {
+		return err
+	}
+
+	_, err = orgClient.InviteMember(ctx, &pb.InviteMemberRequest{
+		OrgId:      org.GetId(),
+		InviteeUid: invitee.GetUserUid(),
+		Role:       role,
+		WrappedKey: wrappedKey,
+	})
+	return err
+}
+
+// findMember поиск участника организации по логину
+func findMember(userUID, token, orgID, login string) (*pb.OrgMember, error) {
+	resp, err := orgClient.ListMembers(authContext(userUID, token), &pb.ListMembersRequest{OrgId: orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range resp.GetMembers() {
+		if member.GetLogin() == login {
+			return member, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a member of organization", login)
+}
+
+// setMemberRole изменение роли участника по логину
+func setMemberRole(userUID, token, orgID, login string, role pb.OrgRole) error {
+	member, err := findMember(userUID, token, orgID, login)
+	if err != nil {
+		return err
+	}
+	_, err = orgClient.SetMemberRole(authContext(userUID, token), &pb.SetMemberRoleRequest{
+		OrgId:   orgID,
+		UserUid: member.GetUserUid(),
+		Role:    role,
+	})
+	return err
+}
+
+// removeMember исключение участника по логину
+func removeMember(userUID, token, orgID, login string) error {
+	member, err := findMember(userUID, token, orgID, login)
+	if err != nil {
+		return err
+	}
+	_, err = orgClient.RemoveMember(authContext(userUID, token), &pb.RemoveMemberRequest{
+		OrgId:   orgID,
+		UserUid: member.GetUserUid(),
+	})
+	return err
+}
+
+// membersText список участников организации для отображения
+func membersText(userUID, token, orgID string) string {
+	resp, err := orgClient.ListMembers(authContext(userUID, token), &pb.ListMembersRequest{OrgId: orgID})
+	if err != nil {
+		return fmt.Sprintf("Error loading members: %v", err)
+	}
+	lines := make([]string, 0, len(resp.GetMembers()))
+	for _, member := range resp.GetMembers() {
+		lines = append(lines, fmt.Sprintf("%s (%s)", member.GetLogin(), roleLabel(member.GetRole())))
+	}
+	return "Members: " + strings.Join(lines, ", ")
+}
+
+// loadCollectionTree загрузка коллекций открытой организации в дерево вместо папок
+func loadCollectionTree(tree *tview.TreeView, userUID, token string) error {
+	resp, err := orgClient.ListCollections(authContext(userUID, token), &pb.ListCollectionsRequest{OrgId: currentOrg.GetId()})
+	if err != nil {
+		return err
+	}
+
+	root := tview.NewTreeNode(rootFolderName).
+		SetReference("").
+		SetColor(tcell.ColorYellow)
+	for _, collection := range resp.GetCollections() {
+		root.AddChild(tview.NewTreeNode(collection.GetName()).
+			SetReference(collection.GetId()).
+			SetSelectable(true))
+	}
+
+	tree.SetRoot(root).SetCurrentNode(root)
+	return nil
+}
+
+// loadVaultTree загрузка дерева открытого хранилища: папок личного или коллекций командного
+func loadVaultTree(tree *tview.TreeView, userUID, token string) error {
+	if currentOrg != nil {
+		return loadCollectionTree(tree, userUID, token)
+	}
+	return loadFolderTree(tree, userUID, token)
+}
+
+// showAddCollectionDialog модальное окно для создания коллекции
+func showAddCollectionDialog(app *tview.Application, userUID, token string, tree *tview.TreeView, message *tview.TextView) {
+	nameField := tview.NewInputField().
+		SetLabel("Collection name: ").
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(nameField).
+		AddButton("Save", func() {
+			_, err := orgClient.CreateCollection(authContext(userUID, token), &pb.CreateCollectionRequest{
+				OrgId: currentOrg.GetId(),
+				Name:  strings.TrimSpace(nameField.GetText()),
+			})
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Create collection error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText("Collection created!")
+				_ = loadCollectionTree(tree, userUID, token)
+			}
+			closeDialog("dialog_collection")
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_collection")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" New collection ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_collection", dialogForm, true, true)
+	pages.SwitchToPage("dialog_collection")
+	app.SetFocus(dialogForm)
+}
+
+// deleteCollection запрос на удаление коллекции вместе с данными
+func deleteCollection(userUID, token, collectionID string, tree *tview.TreeView, table *tview.Table, message *tview.TextView) {
+	_, err := orgClient.DeleteCollection(authContext(userUID, token), &pb.DeleteCollectionRequest{
+		OrgId: currentOrg.GetId(),
+		Id:    collectionID,
+	})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Delete collection error: %v", err))
+		return
+	}
+	message.SetTextColor(tcell.ColorGreen).SetText("Collection deleted!")
+	currentCollection = ""
+	listFilter.CollectionId = ""
+	_ = loadCollectionTree(tree, userUID, token)
+	_ = loadUserData(table, userUID, token)
+}
+
+// showVaultsScreen экран выбора хранилища: личное, командные и приглашения в организации
+func showVaultsScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	var (
+		orgs        []*pb.Organization
+		invitations []*pb.Invitation
+	)
+
+	table := tview.NewTable()
+	table.SetBorders(true)
+
+	reload := func() {
+		var err error
+		orgs, invitations, err = loadVaults(table, userUID, token)
+		if err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading vaults: %v", err))
+		}
+	}
+
+	table.SetSelectable(true, false).
+		SetSelectedFunc(func(row, _ int) {
+			switch {
+			case row == 1:
+				openVault(app, userUID, token, nil, message)
+			case row >= 2 && row < 2+len(orgs):
+				openVault(app, userUID, token, orgs[row-2], message)
+			case row >= 2+len(orgs):
+				showInvitationDialog(app, userUID, token, invitations[row-2-len(orgs)], reload, message)
+			}
+		})
+
+	form := tview.NewForm().
+		AddButton("New organization", func() {
+			showAddOrganizationDialog(app, userUID, token, reload, message)
+		}).
+		AddButton("Members", func() {
+			row, _ := table.GetSelection()
+			if row < 2 || row >= 2+len(orgs) {
+				message.SetTextColor(tcell.ColorRed).SetText("Select an organization")
+				return
+			}
+			showMembersDialog(app, userUID, token, orgs[row-2], message)
+		}).
+		AddButton("Back", func() {
+			pages.RemovePage("vaults_screen")
+			pages.SwitchToPage("data_screen")
+		})
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyBacktab {
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	reload()
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(form, 3, 1, false).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Vaults ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("vaults_screen", flex, true, true)
+	pages.SwitchToPage("vaults_screen")
+	app.SetFocus(table)
+}
+
+// loadVaults загрузка хранилищ и приглашений в таблицу: личное хранилище, организации, затем приглашения
+func loadVaults(table *tview.Table, userUID, token string) ([]*pb.Organization, []*pb.Invitation, error) {
+	ctx := authContext(userUID, token)
+	orgsResp, err := orgClient.ListOrganizations(ctx, &pb.ListOrganizationsRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+	invitationsResp, err := orgClient.ListInvitations(ctx, &pb.ListInvitationsRequest{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	table.Clear()
+
+	table.SetCell(0, 0, tview.NewTableCell("VAULT").SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("ROLE").SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("STATUS").SetSelectable(false))
+
+	table.SetCell(1, 0, tview.NewTableCell("Personal")).
+		SetCell(1, 1, tview.NewTableCell("owner")).
+		SetCell(1, 2, tview.NewTableCell(vaultStatus(nil)))
+
+	row := 2
+	for _, org := range orgsResp.GetOrganizations() {
+		table.SetCell(row, 0, tview.NewTableCell(org.GetName())).
+			SetCell(row, 1, tview.NewTableCell(roleLabel(org.GetRole()))).
+			SetCell(row, 2, tview.NewTableCell(vaultStatus(org)))
+		row++
+	}
+	for _, invitation := range invitationsResp.GetInvitations() {
+		table.SetCell(row, 0, tview.NewTableCell(invitation.GetOrgName())).
+			SetCell(row, 1, tview.NewTableCell(roleLabel(invitation.GetRole()))).
+			SetCell(row, 2, tview.NewTableCell("invited by "+invitation.GetInviterLogin()))
+		row++
+	}
+	return orgsResp.GetOrganizations(), invitationsResp.GetInvitations(), nil
+}
+
+// vaultStatus отметка открытого хранилища
+func vaultStatus(org *pb.Organization) string {
+	if org.GetId() == currentOrg.GetId() {
+		return "opened"
+	}
+	return ""
+}
+
+// openVault переключение хранилища и возврат на экран данных
+func openVault(app *tview.Application, userUID, token string, org *pb.Organization, message *tview.TextView) {
+	if err := switchVault(org); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Open vault error: %v", err))
+		return
+	}
+	pages.RemovePage("vaults_screen")
+	pages.RemovePage("data_screen")
+	showDataScreen(app, userUID, token, message)
+}
+
+// showInvitationDialog модальное окно принятия или отклонения приглашения
+func showInvitationDialog(app *tview.Application, userUID, token string, invitation *pb.Invitation, reload func(), message *tview.TextView) {
+	closeInvitation := func() {
+		pages.RemovePage("dialog_invitation")
+		pages.SwitchToPage("vaults_screen")
+		reload()
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s invited you to %s as %s",
+			invitation.GetInviterLogin(), invitation.GetOrgName(), roleLabel(invitation.GetRole()))).
+		AddButtons([]string{"Accept", "Decline", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			ctx := authContext(userUID, token)
+			var err error
+			switch label {
+			case "Accept":
+				_, err = orgClient.AcceptInvitation(ctx, &pb.AcceptInvitationRequest{Id: invitation.GetId()})
+			case "Decline":
+				_, err = orgClient.DeclineInvitation(ctx, &pb.DeclineInvitationRequest{Id: invitation.GetId()})
+			}
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Invitation error: %v", err))
+			}
+			closeInvitation()
+		})
+
+	pages.AddPage("dialog_invitation", modal, true, true)
+	pages.SwitchToPage("dialog_invitation")
+	app.SetFocus(modal)
+}
+
+// showAddOrganizationDialog модальное окно для создания организации
+func showAddOrganizationDialog(app *tview.Application, userUID, token string, reload func(), message *tview.TextView) {
+	closeOrg := func() {
+		pages.RemovePage("dialog_organization")
+		pages.SwitchToPage("vaults_screen")
+	}
+
+	nameField := tview.NewInputField().
+		SetLabel("Organization name: ").
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(nameField).
+		AddButton("Save", func() {
+			if err := createOrganization(userUID, token, nameField.GetText()); err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Create organization error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText("Organization created!")
+			}
+			closeOrg()
+			reload()
+		}).
+		AddButton("Cancel", closeOrg)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" New organization ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_organization", dialogForm, true, true)
+	pages.SwitchToPage("dialog_organization")
+	app.SetFocus(dialogForm)
+}
+
+// showMembersDialog модальное окно управления участниками организации
+func showMembersDialog(app *tview.Application, userUID, token string, org *pb.Organization, message *tview.TextView) {
+	closeMembers := func() {
+		pages.RemovePage("dialog_members")
+		pages.SwitchToPage("vaults_screen")
+	}
+
+	membersView := tview.NewTextView().
+		SetText(membersText(userUID, token, org.GetId())).
+		SetWrap(true)
+
+	loginField := tview.NewInputField().
+		SetLabel("Login: ").
+		SetFieldWidth(40)
+
+	labels := make([]string, 0, len(orgRoles))
+	for _, role := range orgRoles {
+		labels = append(labels, roleLabel(role))
+	}
+	roleField := tview.NewDropDown().
+		SetLabel("Role: ").
+		SetOptions(labels, nil).
+		SetCurrentOption(0)
+
+	selectedRole := func() pb.OrgRole {
+		index, _ := roleField.GetCurrentOption()
+		if index < 0 {
+			index = 0
+		}
+		return orgRoles[index]
+	}
+	report := func(err error, success string) {
+		if err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Members error: %v", err))
+		} else {
+			message.SetTextColor(tcell.ColorGreen).SetText(success)
+		}
+		closeMembers()
+	}
+
+	dialogForm := tview.NewForm().
+		AddFormItem(loginField).
+		AddFormItem(roleField).
+		AddButton("Invite", func() {
+			login := strings.TrimSpace(loginField.GetText())
+			report(inviteMember(userUID, token, org, login, selectedRole()), "Invitation sent!")
+		}).
+		AddButton("Set role", func() {
+			login := strings.TrimSpace(loginField.GetText())
+			report(setMemberRole(userUID, token, org.GetId(), login, selectedRole()), "Role changed!")
+		}).
+		AddButton("Remove", func() {
+			login := strings.TrimSpace(loginField.GetText())
+			report(removeMember(userUID, token, org.GetId(), login), "Member removed!")
+		}).
+		AddButton("Cancel", closeMembers)
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(membersView, 2, 1, false).
+		AddItem(dialogForm, 0, 1, true)
+
+	dialogFlex.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Members of %s ", org.GetName())).
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_members", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_members")
+	app.SetFocus(dialogForm)
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type fakeOrgClient struct {
+	orgsResp        *pb.ListOrganizationsResponse
+	invitationsResp *pb.ListInvitationsResponse
+	membersResp     *pb.ListMembersResponse
+	collectionsResp *pb.ListCollectionsResponse
+	lastCreate      *pb.CreateOrganizationRequest
+	lastInvite      *pb.InviteMemberRequest
+	lastSetRole     *pb.SetMemberRoleRequest
+	lastRemove      *pb.RemoveMemberRequest
+	returnErr       error
+}
+
+func (f *fakeOrgClient) CreateOrganization(ctx context.Context, in *pb.CreateOrganizationRequest, opts ...grpc.CallOption) (*pb.CreateOrganizationResponse, error) {
+	f.lastCreate = in
+	return &pb.CreateOrganizationResponse{Id: "org-id"}, f.returnErr
+}
+
+func (f *fakeOrgClient) ListOrganizations(ctx context.Context, in *pb.ListOrganizationsRequest, opts ...grpc.CallOption) (*pb.ListOrganizationsResponse, error) {
+	return f.orgsResp, f.returnErr
+}
+
+func (f *fakeOrgClient) InviteMember(ctx context.Context, in *pb.InviteMemberRequest, opts ...grpc.CallOption) (*pb.InviteMemberResponse, error) {
+	f.lastInvite = in
+	return &pb.InviteMemberResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) ListInvitations(ctx context.Context, in *pb.ListInvitationsRequest, opts ...grpc.CallOption) (*pb.ListInvitationsResponse, error) {
+	return f.invitationsResp, f.returnErr
+}
+
+func (f *fakeOrgClient) AcceptInvitation(ctx context.Context, in *pb.AcceptInvitationRequest, opts ...grpc.CallOption) (*pb.AcceptInvitationResponse, error) {
+	return &pb.AcceptInvitationResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) DeclineInvitation(ctx context.Context, in *pb.DeclineInvitationRequest, opts ...grpc.CallOption) (*pb.DeclineInvitationResponse, error) {
+	return &pb.DeclineInvitationResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) ListMembers(ctx context.Context, in *pb.ListMembersRequest, opts ...grpc.CallOption) (*pb.ListMembersResponse, error) {
+	return f.membersResp, f.returnErr
+}
+
+func (f *fakeOrgClient) SetMemberRole(ctx context.Context, in *pb.SetMemberRoleRequest, opts ...grpc.CallOption) (*pb.SetMemberRoleResponse, error) {
+	f.lastSetRole = in
+	return &pb.SetMemberRoleResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) RemoveMember(ctx context.Context, in *pb.RemoveMemberRequest, opts ...grpc.CallOption) (*pb.RemoveMemberResponse, error) {
+	f.lastRemove = in
+	return &pb.RemoveMemberResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) CreateCollection(ctx context.Context, in *pb.CreateCollectionRequest, opts ...grpc.CallOption) (*pb.CreateCollectionResponse, error) {
+	return &pb.CreateCollectionResponse{}, f.returnErr
+}
+
+func (f *fakeOrgClient) ListCollections(ctx context.Context, in *pb.ListCollectionsRequest, opts ...grpc.CallOption) (*pb.ListCollectionsResponse, error) {
+	return f.collectionsResp, f.returnErr
+}
+
+func (f *fakeOrgClient) DeleteCollection(ctx context.Context, in *pb.DeleteCollectionRequest, opts ...grpc.CallOption) (*pb.DeleteCollectionResponse, error) {
+	return &pb.DeleteCollectionResponse{}, f.returnErr
+}
+
+// newTestOrg организация с ключом, зашифрованным публичным ключом текущего пользователя
+func newTestOrg(t *testing.T) (*pb.Organization, string) {
+	var err error
+	userPublicKey, userPrivateKey, err = utils.GenerateKeyPair()
+	assert.NoError(t, err)
+	orgKey, err := utils.GenerateDataKey()
+	assert.NoError(t, err)
+	wrapped, err := utils.SealKey(orgKey, userPublicKey)
+	assert.NoError(t, err)
+	return &pb.Organization{Id: "org-id", Name: "team", Role: pb.OrgRole_ORG_ROLE_ADMIN, WrappedKey: wrapped}, orgKey
+}
+
+func TestSwitchVault(t *testing.T) {
+	aes = "1234567891234567"
+	org, orgKey := newTestOrg(t)
+
+	assert.NoError(t, switchVault(org))
+	assert.Equal(t, orgKey, vaultKey())
+	assert.Equal(t, orgKey, itemKey("unknown-item"))
+	assert.Contains(t, vaultTitle(), "team")
+
+	assert.NoError(t, switchVault(nil))
+	assert.Equal(t, aes, vaultKey())
+	assert.Nil(t, currentOrg)
+
+	assert.Error(t, switchVault(&pb.Organization{WrappedKey: []byte("broken")}))
+	assert.Nil(t, currentOrg)
+}
+
+func TestCreateOrganization_SealsKeyForCreator(t *testing.T) {
+	_, _ = newTestOrg(t)
+	client := &fakeOrgClient{}
+	orgClient = client
+
+	assert.NoError(t, createOrganization("user1", "token1", " team "))
+	assert.Equal(t, "team", client.lastCreate.Name)
+
+	orgKey, err := utils.OpenKey(client.lastCreate.WrappedKey, userPublicKey, userPrivateKey)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, orgKey)
+}
+
+func TestInviteMember_WrapsOrgKeyForInvitee(t *testing.T) {
+	org, orgKey := newTestOrg(t)
+	inviteePublic, inviteePrivate, err := utils.GenerateKeyPair()
+	assert.NoError(t, err)
+
+	shareClient = &fakeShareClient{publicKeyResp: &pb.GetPublicKeyResponse{UserUid: "friend-uid", PublicKey: inviteePublic}}
+	client := &fakeOrgClient{}
+	orgClient = client
+
+	err = inviteMember("user1", "token1", org, "bob", pb.OrgRole_ORG_ROLE_READ_ONLY)
+	assert.NoError(t, err)
+	assert.Equal(t, "friend-uid", client.lastInvite.InviteeUid)
+	assert.Equal(t, pb.OrgRole_ORG_ROLE_READ_ONLY, client.lastInvite.Role)
+
+	opened, err := utils.OpenKey(client.lastInvite.WrappedKey, inviteePublic, inviteePrivate)
+	assert.NoError(t, err)
+	assert.Equal(t, orgKey, opened)
+}
+
+func TestSetMemberRoleAndRemoveMember(t *testing.T) {
+	client := &fakeOrgClient{membersResp: &pb.ListMembersResponse{
+		Members: []*pb.OrgMember{{UserUid: "friend-uid", Login: "bob", Role: pb.OrgRole_ORG_ROLE_MEMBER}},
+	}}
+	orgClient = client
+
+	assert.NoError(t, setMemberRole("user1", "token1", "org-id", "bob", pb.OrgRole_ORG_ROLE_ADMIN))
+	assert.Equal(t, "friend-uid", client.lastSetRole.UserUid)
+	assert.Equal(t, pb.OrgRole_ORG_ROLE_ADMIN, client.lastSetRole.Role)
+
+	assert.NoError(t, removeMember("user1", "token1", "org-id", "bob"))
+	assert.Equal(t, "friend-uid", client.lastRemove.UserUid)
+
+	assert.Error(t, removeMember("user1", "token1", "org-id", "alice"))
+}
+
+func TestLoadVaults(t *testing.T) {
+	defer func() { currentOrg = nil }()
+	currentOrg = &pb.Organization{Id: "org-id"}
+	table := tview.NewTable()
+	orgClient = &fakeOrgClient{
+		orgsResp: &pb.ListOrganizationsResponse{
+			Organizations: []*pb.Organization{{Id: "org-id", Name: "team", Role: pb.OrgRole_ORG_ROLE_OWNER}},
+		},
+		invitationsResp: &pb.ListInvitationsResponse{
+			Invitations: []*pb.Invitation{{Id: "inv-id", OrgName: "ops", InviterLogin: "alice", Role: pb.OrgRole_ORG_ROLE_MEMBER}},
+		},
+	}
+
+	orgs, invitations, err := loadVaults(table, "user1", "token1")
+	assert.NoError(t, err)
+	assert.Len(t, orgs, 1)
+	assert.Len(t, invitations, 1)
+	assert.Equal(t, 4, table.GetRowCount())
+	assert.Equal(t, "Personal", table.GetCell(1, 0).Text)
+	assert.Equal(t, "team", table.GetCell(2, 0).Text)
+	assert.Equal(t, "opened", table.GetCell(2, 2).Text)
+	assert.Equal(t, "invited by alice", table.GetCell(3, 2).Text)
+}
+
+func TestSaveData_TeamVaultRequiresCollection(t *testing.T) {
+	defer func() { currentOrg, currentCollection = nil, "" }()
+	currentOrg, currentCollection = &pb.Organization{Id: "org-id"}, ""
+
+	err := saveData("user1", "token1", "text", "note", "", []byte("text"))
+	assert.ErrorIs(t, err, errNoCollection)
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader заголовок с идентификатором запроса, по нему записи клиента
+// сопоставляются с журналом сервера
+const requestIDHeader = "x-request-id"
+
+// withRequestID контекст вызова с новым идентификатором, если он не задан
+func withRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(requestIDHeader)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, requestIDHeader, uuid.NewString())
+}
+
+// requestIDUnaryInterceptor добавляет идентификатор к каждому вызову
+func requestIDUnaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// requestIDStreamInterceptor добавляет идентификатор к потоковым вызовам
+func requestIDStreamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
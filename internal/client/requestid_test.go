@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	var ids []string
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		ids = append(ids, md.Get(requestIDHeader)...)
+		return nil
+	}
+
+	require.NoError(t, requestIDUnaryInterceptor(authContext("user", "token"), "/m", nil, nil, nil, invoker))
+	require.NoError(t, requestIDUnaryInterceptor(context.Background(), "/m", nil, nil, nil, invoker))
+	preset := metadata.AppendToOutgoingContext(context.Background(), requestIDHeader, "fixed-id")
+	require.NoError(t, requestIDUnaryInterceptor(preset, "/m", nil, nil, nil, invoker))
+
+	require.Len(t, ids, 3)
+	for _, id := range ids[:2] {
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err)
+	}
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Equal(t, "fixed-id", ids[2])
+}
+
+func TestRequestIDStreamInterceptor(t *testing.T) {
+	var id string
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		id = md.Get(requestIDHeader)[0]
+		return nil, nil
+	}
+
+	_, err := requestIDStreamInterceptor(authContext("user", "token"), nil, nil, "/m", streamer)
+	require.NoError(t, err)
+	_, err = uuid.Parse(id)
+	assert.NoError(t, err)
+}
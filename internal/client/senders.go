@@ -20,7 +20,7 @@ func sendSaveTextToServer(ctx context.Context, userUID, dataType, name string, d
 	}
 
 	if isEncrypted {
-		password, err := utils.Encrypt(string(dataText), aes)
+		password, err := utils.Encrypt(string(dataText), vaultKey())
 		if err != nil {
 			return err
 		}
@@ -29,12 +29,13 @@ func sendSaveTextToServer(ctx context.Context, userUID, dataType, name string, d
 
 	// Посылаем один чанк
 	req := &pb.SaveDataRequest{
-		UserUid:     userUID,
-		Type:        dataType,
-		Name:        name,
-		Data:        dataText,
-		ChunkNumber: 1,
-		TotalChunks: 1,
+		UserUid:      userUID,
+		Type:         dataType,
+		Name:         name,
+		Data:         dataText,
+		ChunkNumber:  1,
+		TotalChunks:  1,
+		CollectionId: currentCollection,
 	}
 	if err = stream.Send(req); err != nil {
 		return err
@@ -57,7 +58,7 @@ func sendUpdateTextToServer(ctx context.Context, userUID, dataType, itemID strin
 	}
 
 	if isEncrypt {
-		password, err := utils.Encrypt(string(dataText), aes)
+		password, err := utils.Encrypt(string(dataText), itemKey(itemID))
 		if err != nil {
 			return err
 		}
@@ -115,10 +116,11 @@ func sendSaveBigFileToServer(ctx context.Context, filePath, userUID, dataType, d
 		}
 
 		req := &pb.SaveDataRequest{
-			UserUid: userUID,
-			Type:    dataType,
-			Name:    dataName,
-			Data:    buf[:n],
+			UserUid:      userUID,
+			Type:         dataType,
+			Name:         dataName,
+			Data:         buf[:n],
+			CollectionId: currentCollection,
 		}
 		// Отправляем чанк в стрим
 		if errSend := stream.Send(req); errSend != nil {
@@ -192,3 +194,34 @@ func sendUpdateBigFileToServer(ctx context.Context, userUID, dataType, itemID, f
 	fmt.Println("File uploaded successfully. UpdateDataResponse:", resp)
 	return nil
 }
+
+// sendImportedItem отправляет содержимое восстановленного элемента чанками через SaveData
+// и возвращает идентификатор созданных данных
+func sendImportedItem(ctx context.Context, userUID, dataType, name string, data []byte) (string, error) {
+	stream, err := dataClient.SaveData(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not create stream: %w", err)
+	}
+
+	const chunkSize = 1024 * 1024
+	// Пустое содержимое тоже отправляется одним чанком, чтобы сервер создал запись
+	for first := true; first || len(data) > 0; first = false {
+		n := min(len(data), chunkSize)
+		req := &pb.SaveDataRequest{
+			UserUid: userUID,
+			Type:    dataType,
+			Name:    name,
+			Data:    data[:n],
+		}
+		if err := stream.Send(req); err != nil {
+			return "", fmt.Errorf("send chunk error: %w", err)
+		}
+		data = data[n:]
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return "", fmt.Errorf("CloseAndRecv error: %w", err)
+	}
+	return resp.GetId(), nil
+}
@@ -0,0 +1,440 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// userPublicKey публичный ключ пользователя для получения общих данных
+	userPublicKey []byte
+	// userPrivateKey приватный ключ пользователя для расшифровки ключей общих данных
+	userPrivateKey []byte
+	// itemKeys расшифрованные ключи данных по id, данные без своего ключа шифруются ключом открытого хранилища
+	itemKeys = make(map[string]string)
+)
+
+// sharePermissions варианты уровня доступа для выпадающего списка
+var sharePermissions = []pb.SharePermission{
+	pb.SharePermission_SHARE_PERMISSION_READ,
+	pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+}
+
+// isEncryptedType данные этого типа шифруются на клиенте
+func isEncryptedType(dataType string) bool {
+	switch dataType {
+	case "password", "card", "totp", "ssh_key":
+		return true
+	default:
+		return false
+	}
+}
+
+// permissionLabel подпись уровня доступа
+func permissionLabel(permission pb.SharePermission) string {
+	if permission == pb.SharePermission_SHARE_PERMISSION_READ_WRITE {
+		return "read/write"
+	}
+	return "read"
+}
+
+// loadUserKeys загрузка ключевой пары пользователя, при первом входе пара создаётся и сохраняется на сервере.
+// Приватный ключ хранится на сервере только в зашифрованном ключом хранилища виде
+func loadUserKeys(userUID, token string) error {
+	ctx := authContext(userUID, token)
+	keys, err := shareClient.GetUserKeys(ctx, &pb.GetUserKeysRequest{})
+	if status.Code(err) == codes.NotFound {
+		publicKey, privateKey, err := utils.GenerateKeyPair()
+		if err != nil {
+			return err
+		}
+		encrypted, err := utils.Encrypt(string(privateKey), aes)
+		if err != nil {
+			return err
+		}
+		_, err = shareClient.SetUserKeys(ctx, &pb.SetUserKeysRequest{
+			PublicKey:           publicKey,
+			EncryptedPrivateKey: encrypted,
+		})
+		if err != nil {
+			return err
+		}
+		userPublicKey, userPrivateKey = publicKey, privateKey
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := utils.Decrypt(keys.GetEncryptedPrivateKey(), aes)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt private key: %w", err)
+	}
+	userPublicKey, userPrivateKey = keys.GetPublicKey(), []byte(privateKey)
+	return nil
+}
+
+// itemKey ключ шифрования содержимого данных
+func itemKey(itemID string) string {
+	if key, ok := itemKeys[itemID]; ok {
+		return key
+	}
+	return vaultKey()
+}
+
+// rememberItemKey расшифровка и запоминание ключа данных из ответа GetData
+func rememberItemKey(itemID string, resp *pb.GetDataResponse) error {
+	var (
+		key string
+		err error
+	)
+	switch {
+	case len(resp.GetSharedKey()) > 0:
+		key, err = utils.OpenKey(resp.GetSharedKey(), userPublicKey, userPrivateKey)
+	case resp.GetDataKey() != "":
+		key, err = utils.Decrypt(resp.GetDataKey(), aes)
+	default:
+		delete(itemKeys, itemID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	itemKeys[itemID] = key
+	return nil
+}
+
+// ensureDataKey ключ данных владельца для передачи получателю. Данные, зашифрованные ключом хранилища,
+// перешифровываются собственным ключом, чтобы не раскрывать получателю ключ хранилища
+func ensureDataKey(userUID, token, itemID string) (string, error) {
+	ctx := authContext(userUID, token)
+	resp, err := dataClient.GetData(ctx, &pb.GetDataRequest{Id: itemID})
+	if err != nil {
+		return "", err
+	}
+	if !isEncryptedType(resp.GetType()) {
+		return "", nil
+	}
+	if resp.GetDataKey() != "" {
+		if err := rememberItemKey(itemID, resp); err != nil {
+			return "", err
+		}
+		return itemKeys[itemID], nil
+	}
+
+	plain, err := utils.Decrypt(resp.GetTextData(), aes)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := utils.GenerateDataKey()
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := utils.Encrypt(plain, dataKey)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := utils.Encrypt(dataKey, aes)
+	if err != nil {
+		return "", err
+	}
+
+	// Содержимое и ключ данных обновляются в одной транзакции
+	stream, err := dataClient.UpdateData(ctx)
+	if err != nil {
+		return "", err
+	}
+	req := &pb.UpdateDataRequest{
+		UserUid:     userUID,
+		Type:        resp.GetType(),
+		DataUid:     itemID,
+		Data:        []byte(encrypted),
+		DataKey:     wrapped,
+		ChunkNumber: 1,
+		TotalChunks: 1,
+	}
+	if err = stream.Send(req); err != nil {
+		return "", err
+	}
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return "", err
+	}
+
+	itemKeys[itemID] = dataKey
+	return dataKey, nil
+}
+
+// shareItem выдача доступа к данным пользователю по логину
+func shareItem(userUID, token, itemID, login string, permission pb.SharePermission) error {
+	ctx := authContext(userUID, token)
+	recipient, err := shareClient.GetPublicKey(ctx, &pb.GetPublicKeyRequest{Login: login})
+	if err != nil {
+		return err
+	}
+
+	dataKey, err := ensureDataKey(userUID, token, itemID)
+	if err != nil {
+		return err
+	}
+	var wrappedKey []byte
+	if dataKey != "" {
+		wrappedKey, err = utils.SealKey(dataKey, recipient.GetPublicKey())
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = shareClient.ShareData(ctx, &pb.ShareDataRequest{
+		DataId:       itemID,
+		RecipientUid: recipient.GetUserUid(),
+		Permission:   permission,
+		WrappedKey:   wrappedKey,
+	})
+	return err
+}
+
+// unshareItem отзыв доступа к данным у пользователя по логину
+func unshareItem(userUID, token, itemID, login string) error {
+	ctx := authContext(userUID, token)
+	resp, err := shareClient.ListShares(ctx, &pb.ListSharesRequest{DataId: itemID})
+	if err != nil {
+		return err
+	}
+	for _, grant := range resp.GetGrants() {
+		if grant.GetRecipientLogin() == login {
+			_, err = shareClient.UnshareData(ctx, &pb.UnshareDataRequest{
+				DataId:       itemID,
+				RecipientUid: grant.GetRecipientUid(),
+			})
+			return err
+		}
+	}
+	return fmt.Errorf("item is not shared with %s", login)
+}
+
+// shareGrantsText список получателей данных для отображения
+func shareGrantsText(userUID, token, itemID string) string {
+	resp, err := shareClient.ListShares(authContext(userUID, token), &pb.ListSharesRequest{DataId: itemID})
+	if err != nil {
+		return fmt.Sprintf("Error loading shares: %v", err)
+	}
+	if len(resp.GetGrants()) == 0 {
+		return "Not shared yet"
+	}
+	lines := make([]string, 0, len(resp.GetGrants()))
+	for _, grant := range resp.GetGrants() {
+		lines = append(lines, fmt.Sprintf("%s (%s)", grant.GetRecipientLogin(), permissionLabel(grant.GetPermission())))
+	}
+	return "Shared with: " + strings.Join(lines, ", ")
+}
+
+// showShareDialog модальное окно выдачи и отзыва доступа к данным
+func showShareDialog(app *tview.Application, userUID, token, itemID string, message *tview.TextView) {
+	grantsView := tview.NewTextView().
+		SetText(shareGrantsText(userUID, token, itemID)).
+		SetWrap(true)
+
+	loginField := tview.NewInputField().
+		SetLabel("Login: ").
+		SetFieldWidth(40)
+
+	labels := make([]string, 0, len(sharePermissions))
+	for _, permission := range sharePermissions {
+		labels = append(labels, permissionLabel(permission))
+	}
+	permissionField := tview.NewDropDown().
+		SetLabel("Access: ").
+		SetOptions(labels, nil).
+		SetCurrentOption(0)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(loginField).
+		AddFormItem(permissionField).
+		AddButton("Share", func() {
+			index, _ := permissionField.GetCurrentOption()
+			if index < 0 {
+				index = 0
+			}
+			login := strings.TrimSpace(loginField.GetText())
+			if err := shareItem(userUID, token, itemID, login, sharePermissions[index]); err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Share error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText("Item shared!")
+			}
+			closeDialog("dialog_share")
+		}).
+		AddButton("Unshare", func() {
+			login := strings.TrimSpace(loginField.GetText())
+			if err := unshareItem(userUID, token, itemID, login); err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Unshare error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText("Access revoked!")
+			}
+			closeDialog("dialog_share")
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_share")
+		})
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(grantsView, 2, 1, false).
+		AddItem(dialogForm, 0, 1, true)
+
+	dialogFlex.SetBorder(true).
+		SetTitle(" Share item ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_share", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_share")
+	app.SetFocus(dialogForm)
+}
+
+// showSharedScreen экран с данными других пользователей, к которым выдан доступ
+func showSharedScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	table := tview.NewTable()
+	table.SetBorders(true)
+
+	table.SetSelectable(true, false).
+		SetSelectedFunc(func(row, col int) {
+			if row == 0 {
+				return
+			}
+			itemID := table.GetCell(row, 0).Text
+			writable := table.GetCell(row, 4).Text == permissionLabel(pb.SharePermission_SHARE_PERMISSION_READ_WRITE)
+			showSharedItemDialog(app, userUID, token, itemID, writable, message)
+		})
+
+	if err := loadSharedData(table, userUID, token); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading shared data: %v", err))
+	}
+
+	form := tview.NewForm().
+		AddButton("Back", func() {
+			pages.RemovePage("shared_screen")
+			pages.SwitchToPage("data_screen")
+		})
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyBacktab {
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(form, 3, 1, false).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Shared with me ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("shared_screen", flex, true, true)
+	pages.SwitchToPage("shared_screen")
+	app.SetFocus(table)
+}
+
+// loadSharedData загрузка данных, к которым выдан доступ, в таблицу
+func loadSharedData(table *tview.Table, userUID, token string) error {
+	resp, err := shareClient.ListSharedWithMe(authContext(userUID, token), &pb.ListSharedWithMeRequest{})
+	if err != nil {
+		return err
+	}
+
+	table.Clear()
+
+	table.SetCell(0, 0, tview.NewTableCell("ID").SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("TYPE").SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("NAME").SetSelectable(false)).
+		SetCell(0, 3, tview.NewTableCell("OWNER").SetSelectable(false)).
+		SetCell(0, 4, tview.NewTableCell("ACCESS").SetSelectable(false))
+
+	for i, item := range resp.GetItems() {
+		table.SetCell(i+1, 0, tview.NewTableCell(item.GetId()))
+		table.SetCell(i+1, 1, tview.NewTableCell(item.GetType()))
+		table.SetCell(i+1, 2, tview.NewTableCell(item.GetName()))
+		table.SetCell(i+1, 3, tview.NewTableCell(item.GetOwnerLogin()))
+		table.SetCell(i+1, 4, tview.NewTableCell(permissionLabel(item.GetPermission())))
+	}
+	return nil
+}
+
+// showSharedItemDialog модальное окно просмотра и изменения общих данных
+func showSharedItemDialog(app *tview.Application, userUID, token, itemID string, writable bool, message *tview.TextView) {
+	resp, err := dataClient.GetData(authContext(userUID, token), &pb.GetDataRequest{Id: itemID})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error getting data: %v", err))
+		return
+	}
+	if err := rememberItemKey(itemID, resp); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting data key: %v", err))
+		return
+	}
+
+	closeShared := func() {
+		pages.RemovePage("dialog_shared_item")
+		pages.SwitchToPage("shared_screen")
+	}
+
+	form := tview.NewForm()
+	if resp.GetType() == "file" {
+		filePathField := tview.NewInputField().
+			SetLabel("Save to file path: ").
+			SetFieldWidth(40)
+		form.AddFormItem(filePathField).
+			AddButton("Save", func() {
+				downloadFile(filePathField.GetText(), resp.GetFileData(), message)
+			})
+	} else {
+		content := resp.GetTextData()
+		if isEncryptedType(resp.GetType()) {
+			content, err = utils.Decrypt(content, itemKey(itemID))
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting data: %v", err))
+				return
+			}
+		}
+		contentField := tview.NewTextArea().
+			SetLabel("Content: ").
+			SetText(content, false)
+		contentField.SetDisabled(!writable)
+		form.AddFormItem(contentField)
+		if writable {
+			form.AddButton("Save", func() {
+				err := updateData(userUID, token, itemID, resp.GetType(), "", []byte(contentField.GetText()))
+				if err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Update error: %v", err))
+				} else {
+					message.SetTextColor(tcell.ColorGreen).SetText("Update success!")
+				}
+				closeShared()
+			})
+		}
+	}
+	form.AddButton("Close", closeShared)
+
+	form.SetBorder(true).
+		SetTitle(" Shared item ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_shared_item", form, true, true)
+	pages.SwitchToPage("dialog_shared_item")
+	app.SetFocus(form)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeShareClient struct {
+	userKeysResp  *pb.GetUserKeysResponse
+	userKeysErr   error
+	lastSetKeys   *pb.SetUserKeysRequest
+	publicKeyResp *pb.GetPublicKeyResponse
+	lastShare     *pb.ShareDataRequest
+	lastUnshare   *pb.UnshareDataRequest
+	sharesResp    *pb.ListSharesResponse
+	sharedResp    *pb.ListSharedWithMeResponse
+	returnErr     error
+}
+
+func (f *fakeShareClient) SetUserKeys(ctx context.Context, in *pb.SetUserKeysRequest, opts ...grpc.CallOption) (*pb.SetUserKeysResponse, error) {
+	f.lastSetKeys = in
+	return &pb.SetUserKeysResponse{}, f.returnErr
+}
+
+func (f *fakeShareClient) GetUserKeys(ctx context.Context, in *pb.GetUserKeysRequest, opts ...grpc.CallOption) (*pb.GetUserKeysResponse, error) {
+	return f.userKeysResp, f.userKeysErr
+}
+
+func (f *fakeShareClient) GetPublicKey(ctx context.Context, in *pb.GetPublicKeyRequest, opts ...grpc.CallOption) (*pb.GetPublicKeyResponse, error) {
+	return f.publicKeyResp, f.returnErr
+}
+
+func (f *fakeShareClient) ShareData(ctx context.Context, in *pb.ShareDataRequest, opts ...grpc.CallOption) (*pb.ShareDataResponse, error) {
+	f.lastShare = in
+	return &pb.ShareDataResponse{}, f.returnErr
+}
+
+func (f *fakeShareClient) UnshareData(ctx context.Context, in *pb.UnshareDataRequest, opts ...grpc.CallOption) (*pb.UnshareDataResponse, error) {
+	f.lastUnshare = in
+	return &pb.UnshareDataResponse{}, f.returnErr
+}
+
+func (f *fakeShareClient) ListShares(ctx context.Context, in *pb.ListSharesRequest, opts ...grpc.CallOption) (*pb.ListSharesResponse, error) {
+	return f.sharesResp, f.returnErr
+}
+
+func (f *fakeShareClient) ListSharedWithMe(ctx context.Context, in *pb.ListSharedWithMeRequest, opts ...grpc.CallOption) (*pb.ListSharedWithMeResponse, error) {
+	return f.sharedResp, f.returnErr
+}
+
+func TestLoadUserKeys_GeneratesOnFirstLogin(t *testing.T) {
+	aes = "1234567891234567"
+	client := &fakeShareClient{userKeysErr: status.Error(codes.NotFound, "not found")}
+	shareClient = client
+
+	err := loadUserKeys("user1", "token1")
+	assert.NoError(t, err)
+	assert.NotNil(t, client.lastSetKeys)
+	assert.Equal(t, userPublicKey, client.lastSetKeys.PublicKey)
+
+	privateKey, err := utils.Decrypt(client.lastSetKeys.EncryptedPrivateKey, aes)
+	assert.NoError(t, err)
+	assert.Equal(t, userPrivateKey, []byte(privateKey))
+}
+
+func TestLoadUserKeys_Existing(t *testing.T) {
+	aes = "1234567891234567"
+	publicKey, privateKey, err := utils.GenerateKeyPair()
+	assert.NoError(t, err)
+	encrypted, err := utils.Encrypt(string(privateKey), aes)
+	assert.NoError(t, err)
+
+	client := &fakeShareClient{userKeysResp: &pb.GetUserKeysResponse{PublicKey: publicKey, EncryptedPrivateKey: encrypted}}
+	shareClient = client
+
+	err = loadUserKeys("user1", "token1")
+	assert.NoError(t, err)
+	assert.Nil(t, client.lastSetKeys)
+	assert.Equal(t, publicKey, userPublicKey)
+	assert.Equal(t, privateKey, userPrivateKey)
+}
+
+func TestRememberItemKey(t *testing.T) {
+	aes = "1234567891234567"
+	dataKey, err := utils.GenerateDataKey()
+	assert.NoError(t, err)
+
+	// Ключ владельца зашифрован ключом хранилища
+	wrapped, err := utils.Encrypt(dataKey, aes)
+	assert.NoError(t, err)
+	assert.NoError(t, rememberItemKey("owned", &pb.GetDataResponse{DataKey: wrapped}))
+	assert.Equal(t, dataKey, itemKey("owned"))
+
+	// Ключ получателя зашифрован его публичным ключом
+	userPublicKey, userPrivateKey, err = utils.GenerateKeyPair()
+	assert.NoError(t, err)
+	sealed, err := utils.SealKey(dataKey, userPublicKey)
+	assert.NoError(t, err)
+	assert.NoError(t, rememberItemKey("shared", &pb.GetDataResponse{SharedKey: sealed}))
+	assert.Equal(t, dataKey, itemKey("shared"))
+
+	// Данные без своего ключа шифруются ключом хранилища
+	assert.NoError(t, rememberItemKey("owned", &pb.GetDataResponse{}))
+	assert.Equal(t, aes, itemKey("owned"))
+
+	assert.Error(t, rememberItemKey("broken", &pb.GetDataResponse{SharedKey: []byte("broken")}))
+}
+
+func TestShareItem_WrapsDataKeyForRecipient(t *testing.T) {
+	aes = "1234567891234567"
+	dataKey, err := utils.GenerateDataKey()
+	assert.NoError(t, err)
+	wrapped, err := utils.Encrypt(dataKey, aes)
+	assert.NoError(t, err)
+	recipientPublic, recipientPrivate, err := utils.GenerateKeyPair()
+	assert.NoError(t, err)
+
+	dataClient = &fakeDataClient{getDataResp: &pb.GetDataResponse{Type: "password", DataKey: wrapped}}
+	client := &fakeShareClient{publicKeyResp: &pb.GetPublicKeyResponse{UserUid: "friend-uid", PublicKey: recipientPublic}}
+	shareClient = client
+
+	err = shareItem("user1", "token1", "item1", "bob", pb.SharePermission_SHARE_PERMISSION_READ_WRITE)
+	assert.NoError(t, err)
+	assert.Equal(t, "friend-uid", client.lastShare.RecipientUid)
+	assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ_WRITE, client.lastShare.Permission)
+
+	opened, err := utils.OpenKey(client.lastShare.WrappedKey, recipientPublic, recipientPrivate)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, opened)
+}
+
+func TestShareItem_PlainType(t *testing.T) {
+	dataClient = &fakeDataClient{getDataResp: &pb.GetDataResponse{Type: "text"}}
+	client := &fakeShareClient{publicKeyResp: &pb.GetPublicKeyResponse{UserUid: "friend-uid"}}
+	shareClient = client
+
+	err := shareItem("user1", "token1", "item1", "bob", pb.SharePermission_SHARE_PERMISSION_READ)
+	assert.NoError(t, err)
+	assert.Empty(t, client.lastShare.WrappedKey)
+}
+
+func TestUnshareItem(t *testing.T) {
+	client := &fakeShareClient{sharesResp: &pb.ListSharesResponse{
+		Grants: []*pb.ShareGrant{{RecipientUid: "friend-uid", RecipientLogin: "bob"}},
+	}}
+	shareClient = client
+
+	assert.NoError(t, unshareItem("user1", "token1", "item1", "bob"))
+	assert.Equal(t, "friend-uid", client.lastUnshare.RecipientUid)
+
+	assert.Error(t, unshareItem("user1", "token1", "item1", "alice"))
+}
+
+func TestLoadSharedData(t *testing.T) {
+	table := tview.NewTable()
+	shareClient = &fakeShareClient{sharedResp: &pb.ListSharedWithMeResponse{
+		Items: []*pb.SharedItem{{
+			Id:         "id1",
+			Type:       "password",
+			Name:       "vpn",
+			OwnerLogin: "alice",
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+		}},
+	}}
+
+	err := loadSharedData(table, "user1", "token1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OWNER", table.GetCell(0, 3).Text)
+	assert.Equal(t, "alice", table.GetCell(1, 3).Text)
+	assert.Equal(t, "read/write", table.GetCell(1, 4).Text)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/logger"
+	"github.com/fngoc/gault/pkg/sshkey"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// RunSSHAgent вход в учётную запись, загрузка SSH-ключей личного хранилища в память и работа
+// в роли ssh-agent на Unix-сокете до отмены ctx. Ключи не записываются на диск, сессия
+// отзывается сразу после загрузки. Пустой socketPath — сокет во временном каталоге
+func RunSSHAgent(ctx context.Context, w io.Writer, aesKey, login, password, socketPath string, readCode func() (string, error)) error {
+	keyring, count, err := loadAgentKeys(aesKey, login, password, readCode)
+	if err != nil {
+		return err
+	}
+
+	if socketPath == "" {
+		dir, err := os.MkdirTemp("", "gault-agent-")
+		if err != nil {
+			return fmt.Errorf("failed to create socket directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		socketPath = filepath.Join(dir, "agent.sock")
+	}
+
+	listener, err := listenAgentSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	fmt.Fprintf(w, "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", socketPath)
+	fmt.Fprintf(w, "echo Agent is serving %d keys from the vault;\n", count)
+	return serveAgent(ctx, listener, keyring)
+}
+
+// loadAgentKeys загрузка и расшифровка всех SSH-ключей личного хранилища
+func loadAgentKeys(aesKey, login, password string, readCode func() (string, error)) (agent.Agent, int, error) {
+	ctx, logout, err := cliSession(aesKey, login, password, readCode)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer logout()
+
+	items, err := listAllItems(ctx, &pb.GetUserDataListRequest{Type: "ssh_key"})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list ssh keys: %w", err)
+	}
+
+	keyring := agent.NewKeyring()
+	for _, item := range items {
+		content, err := readItemText(ctx, item)
+		if err != nil {
+			return nil, 0, err
+		}
+		key, err := sshkey.Decode(content)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%q: %w", item.GetName(), err)
+		}
+		added, err := key.AddedKeys()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%q: %w", item.GetName(), err)
+		}
+		for _, k := range added {
+			if k.Comment == "" {
+				k.Comment = item.GetName()
+			}
+			if err := keyring.Add(k); err != nil {
+				return nil, 0, fmt.Errorf("failed to add %q to agent: %w", item.GetName(), err)
+			}
+		}
+	}
+	return keyring, len(items), nil
+}
+
+// listenAgentSocket Unix-сокет агента, доступный только владельцу. Сокет создаётся в собственном
+// каталоге с правами 0700 рядом с socketPath, сужает права и только затем связывается с socketPath,
+// поэтому по пути socketPath никогда не бывает сокета с правами по umask процесса
+func listenAgentSocket(socketPath string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".gault-agent-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "agent.sock")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	// Сокет удаляет RunSSHAgent по итоговому пути
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	// Жёсткая ссылка, в отличие от rename, не заменяет существующий файл
+	if err := os.Link(tmpPath, socketPath); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	return listener, nil
+}
+
+// serveAgent обслуживание подключений к агенту до отмены ctx
+func serveAgent(ctx context.Context, listener net.Listener, keyring agent.Agent) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to accept agent connection: %w", err)
+		}
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(keyring, conn); err != nil && !errors.Is(err, io.EOF) {
+				logger.LogError("ssh-agent connection error", zap.Error(err))
+			}
+		}()
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fngoc/gault/pkg/sshkey"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showAddSSHKeyDialog модальное окно для добавления SSH-ключа и, если есть, его сертификата
+func showAddSSHKeyDialog(app *tview.Application, userUID, token string, message *tview.TextView, table *tview.Table) {
+	inputNameField := tview.NewInputField().
+		SetLabel("Enter name: ").
+		SetFieldWidth(40)
+
+	inputKeyPathField := tview.NewInputField().
+		SetLabel("Private key file: ").
+		SetFieldWidth(40)
+
+	inputPassphraseField := tview.NewInputField().
+		SetLabel("Key passphrase: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	inputCommentField := tview.NewInputField().
+		SetLabel("Comment: ").
+		SetFieldWidth(40)
+
+	inputCertPathField := tview.NewInputField().
+		SetLabel("Certificate file (optional): ").
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(inputNameField).
+		AddFormItem(inputKeyPathField).
+		AddFormItem(inputPassphraseField).
+		AddFormItem(inputCommentField).
+		AddFormItem(inputCertPathField).
+		AddButton("Save", func() {
+			saveSSHKey(inputKeyPathField.GetText(), inputPassphraseField.GetText(), inputCommentField.GetText(),
+				inputCertPathField.GetText(), inputNameField.GetText(), userUID, token, table, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_add_text")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Add SSH key ").
+		SetTitleAlign(tview.AlignCenter)
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(dialogForm, 0, 1, true)
+
+	pages.AddPage("dialog_add_text", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_add_text")
+	app.SetFocus(dialogForm)
+}
+
+// readSSHKey чтение и проверка ключа и сертификата из файлов
+func readSSHKey(keyPath, passphrase, comment, certPath string) (sshkey.Key, error) {
+	privateKey, err := os.ReadFile(keyPath)
+	if err != nil {
+		return sshkey.Key{}, fmt.Errorf("failed to read private key: %w", err)
+	}
+	var certificate []byte
+	if certPath != "" {
+		if certificate, err = os.ReadFile(certPath); err != nil {
+			return sshkey.Key{}, fmt.Errorf("failed to read certificate: %w", err)
+		}
+	}
+	return sshkey.Parse(privateKey, []byte(passphrase), comment, certificate)
+}
+
+// saveSSHKey запрос на сохранение SSH-ключа, ключ проверяется и сохраняется без пароля внутри зашифрованного элемента
+func saveSSHKey(keyPath, passphrase, comment, certPath, name, userUID, token string, table *tview.Table, message *tview.TextView) {
+	key, err := readSSHKey(keyPath, passphrase, comment, certPath)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Save error: %v", err))
+		return
+	}
+	content, err := key.Encode()
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Save error: %v", err))
+		return
+	}
+
+	if err := saveData(userUID, token, "ssh_key", name, "", []byte(content)); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Save error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText(fmt.Sprintf("SSH key %s saved!", key.Fingerprint))
+		_ = loadUserData(table, userUID, token)
+	}
+	closeDialog("dialog_add_text")
+}
+
+// sshKeyText описание ключа без закрытой части
+func sshKeyText(key sshkey.Key) string {
+	certificate := "no"
+	if key.Certificate != "" {
+		certificate = "yes"
+	}
+	return fmt.Sprintf("Type: %s\nFingerprint: %s\nComment: %s\nCertificate: %s\n\n%s",
+		key.Type(), key.Fingerprint, key.Comment, certificate, key.PublicKey)
+}
+
+// showSSHKeyContentModal модальное окно SSH-ключа, закрытый ключ не показывается
+func showSSHKeyContentModal(app *tview.Application, userUID, token, itemID string, textData string, table *tview.Table, message *tview.TextView) {
+	content, err := utils.Decrypt(textData, itemKey(itemID))
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting SSH key: %v", err))
+		return
+	}
+	key, err := sshkey.Decode(content)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error reading SSH key: %v", err))
+		return
+	}
+
+	textView := tview.NewTextView().
+		SetText(sshKeyText(key)).
+		SetWrap(true).
+		SetScrollable(true)
+
+	textView.SetBorder(true).
+		SetTitle(" SSH key ").
+		SetTitleAlign(tview.AlignCenter)
+
+	form := tview.NewForm().
+		AddButton("Copy public key", func() {
+			copySecret(app, "Public key", key.PublicKey, message)
+		}).
+		AddButton("Delete", func() {
+			confirmDelete(app, func() {
+				deleteText(userUID, token, itemID, table, message)
+			})
+		}).
+		AddButton("Close", func() {
+			closeDialog("dialog_view_text")
+		})
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, false).
+		AddItem(form, 3, 1, true)
+
+	pages.AddPage("dialog_view_text", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_view_text")
+	app.SetFocus(form)
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/sshkey"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// writeTestSSHKey закрытый ключ ed25519 в файле формата OpenSSH
+func writeTestSSHKey(t *testing.T) string {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func TestSaveSSHKey(t *testing.T) {
+	client := &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+	dataClient = client
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	message := tview.NewTextView()
+
+	saveSSHKey("/no/such/key", "", "", "", "laptop", "u", "t", tview.NewTable(), message)
+	assert.Contains(t, message.GetText(true), "failed to read private key")
+	assert.Empty(t, client.receivedChunks)
+
+	saveSSHKey(writeTestSSHKey(t), "", "alice@laptop", "", "laptop", "u", "t", tview.NewTable(), message)
+	assert.Contains(t, message.GetText(true), "SSH key SHA256:")
+	require.Len(t, client.receivedChunks, 1)
+	assert.Equal(t, "ssh_key", client.receivedChunks[0].Type)
+
+	plain, err := utils.Decrypt(string(client.receivedChunks[0].Data), aes)
+	require.NoError(t, err)
+	key, err := sshkey.Decode(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@laptop", key.Comment)
+}
+
+func TestShowSSHKeyContentModal(t *testing.T) {
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	key, err := readSSHKey(writeTestSSHKey(t), "", "alice@laptop", "")
+	require.NoError(t, err)
+	content, err := key.Encode()
+	require.NoError(t, err)
+	enc, err := utils.Encrypt(content, aes)
+	require.NoError(t, err)
+
+	showSSHKeyContentModal(tview.NewApplication(), "u", "t", "item", enc, tview.NewTable(), tview.NewTextView())
+	_, page := pages.GetFrontPage()
+	text := page.(*tview.Flex).GetItem(0).(*tview.TextView).GetText(true)
+	assert.Contains(t, text, key.Fingerprint)
+	assert.Contains(t, text, key.PublicKey)
+	assert.NotContains(t, text, "PRIVATE KEY")
+}
+
+func TestRunSSHAgent(t *testing.T) {
+	key, err := readSSHKey(writeTestSSHKey(t), "", "", "")
+	require.NoError(t, err)
+	content, err := key.Encode()
+	require.NoError(t, err)
+	enc, err := utils.Encrypt(content, "1234567891234567")
+	require.NoError(t, err)
+
+	auth := &fakeAuthClient{loginResp: &pb.LoginResponse{UserUid: "u", Token: "t"}}
+	autClient = auth
+	dataClient = &fakeDataClient{
+		getUserDataResp: &pb.GetUserDataListResponse{Items: []*pb.UserDataItem{{Id: "key", Name: "laptop", Type: "ssh_key"}}},
+		getDataResp:     &pb.GetDataResponse{Type: "ssh_key", Content: &pb.GetDataResponse_TextData{TextData: enc}},
+	}
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	var out bytes.Buffer
+	go func() {
+		done <- RunSSHAgent(ctx, &out, "1234567891234567", "alice", "pass", socket, nil)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	defer conn.Close()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	keys, err := agent.NewClient(conn).List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "laptop", keys[0].Comment)
+	assert.Equal(t, key.Fingerprint, ssh.FingerprintSHA256(keys[0]))
+
+	cancel()
+	require.NoError(t, <-done)
+	// Сессия отзывается сразу после загрузки ключей
+	assert.True(t, auth.logoutCalled)
+	assert.Contains(t, out.String(), "SSH_AUTH_SOCK="+socket)
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestListenAgentSocket(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "agent.sock")
+
+	listener, err := listenAgentSocket(socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	// Временный каталог сокета удаляется
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Существующий файл не заменяется
+	_, err = listenAgentSocket(socket)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// totpRefresh интервал обновления кода TOTP на экране
+const totpRefresh = time.Second
+
+// переменная для замены в тестах
+var timeNow = time.Now
+
+// totpText текст с текущим кодом TOTP и временем до его смены
+func totpText(auth utils.OTPAuth, now time.Time) (string, error) {
+	code, err := auth.Code(now)
+	if err != nil {
+		return "", err
+	}
+	text := fmt.Sprintf("Code: %s\nExpires in: %ds", code, auth.Remaining(now))
+	switch {
+	case auth.Issuer != "" && auth.Account != "":
+		text = fmt.Sprintf("%s (%s)\n%s", auth.Issuer, auth.Account, text)
+	case auth.Issuer != "" || auth.Account != "":
+		text = auth.Issuer + auth.Account + "\n" + text
+	}
+	return text, nil
+}
+
+// showAddTOTPDialog модальное окно для добавления секрета TOTP
+func showAddTOTPDialog(app *tview.Application, userUID, token string, message *tview.TextView, table *tview.Table) {
+	inputNameField := tview.NewInputField().
+		SetLabel("Enter name: ").
+		SetFieldWidth(40)
+
+	inputSecretField := tview.NewInputField().
+		SetLabel("Enter otpauth URI or secret: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(inputNameField).
+		AddFormItem(inputSecretField).
+		AddButton("Save", func() {
+			saveTOTP(inputSecretField.GetText(), inputNameField.GetText(), userUID, token, table, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_add_text")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Add TOTP ").
+		SetTitleAlign(tview.AlignCenter)
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(dialogForm, 0, 1, true)
+
+	pages.AddPage("dialog_add_text", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_add_text")
+	app.SetFocus(dialogForm)
+}
+
+// showTOTPContentModal модальное окно с текущим кодом TOTP, код и обратный отсчёт обновляются каждую секунду
+func showTOTPContentModal(app *tview.Application, userUID, token, itemID string, textData string, table *tview.Table, message *tview.TextView) {
+	secret, err := utils.Decrypt(textData, itemKey(itemID))
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting TOTP: %v", err))
+		return
+	}
+	auth, err := utils.ParseOTPAuth(secret)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error reading TOTP: %v", err))
+		return
+	}
+
+	textView := tview.NewTextView().
+		SetWrap(true).
+		SetScrollable(true)
+
+	textView.SetBorder(true).
+		SetTitle(" TOTP ").
+		SetTitleAlign(tview.AlignCenter)
+
+	render := func() {
+		text, err := totpText(auth, timeNow())
+		if err != nil {
+			text = err.Error()
+		}
+		textView.SetText(text)
+	}
+	render()
+
+	form := tview.NewForm().
+		AddButton("Copy code", func() {
+			code, err := auth.Code(timeNow())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error generating code: %v", err))
+				return
+			}
+			copySecret(app, "Code", code, message)
+		}).
+		AddButton("Edit", func() {
+			showEditTOTPDialog(app, userUID, token, itemID, table, message)
+		}).
+		AddButton("Delete", func() {
+			confirmDelete(app, func() {
+				deleteText(userUID, token, itemID, table, message)
+			})
+		}).
+		AddButton("Close", func() {
+			closeDialog("dialog_view_text")
+		})
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(textView, 0, 1, false).
+		AddItem(form, 3, 1, true)
+
+	pages.AddPage("dialog_view_text", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_view_text")
+	app.SetFocus(form)
+
+	go refreshTOTP(app, dialogFlex, render)
+}
+
+// refreshTOTP обновление кода, пока окно открыто. Окно закрыто, если страница удалена или заменена другой
+func refreshTOTP(app *tview.Application, dialog tview.Primitive, render func()) {
+	ticker := time.NewTicker(totpRefresh)
+	defer ticker.Stop()
+	for range ticker.C {
+		open := false
+		app.QueueUpdateDraw(func() {
+			name, front := pages.GetFrontPage()
+			if open = name == "dialog_view_text" && front == dialog; open {
+				render()
+			}
+		})
+		if !open {
+			return
+		}
+	}
+}
+
+// showEditTOTPDialog модальное окно для замены секрета TOTP
+func showEditTOTPDialog(app *tview.Application, userUID, token, itemID string, table *tview.Table, message *tview.TextView) {
+	inputField := tview.NewInputField().
+		SetLabel("New otpauth URI or secret: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(inputField).
+		AddButton("Save", func() {
+			updateTOTP(inputField.GetText(), userUID, token, itemID, table, message)
+		}).
+		AddButton("Cancel", func() {
+			closeDialog("dialog_edit_text")
+		})
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Edit TOTP ").
+		SetTitleAlign(tview.AlignCenter)
+
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(dialogForm, 0, 1, true)
+
+	pages.AddPage("dialog_edit_text", dialogFlex, true, true)
+	pages.SwitchToPage("dialog_edit_text")
+	app.SetFocus(dialogForm)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret секрет тестовых векторов RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// stubNow подмена текущего времени
+func stubNow(t *testing.T, now time.Time) {
+	old := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = old })
+}
+
+func TestTOTPText(t *testing.T) {
+	auth, err := utils.ParseOTPAuth("otpauth://totp/ACME:alice?secret=" + rfcSecret + "&issuer=ACME&digits=8")
+	require.NoError(t, err)
+
+	text, err := totpText(auth, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "ACME (alice)\nCode: 94287082\nExpires in: 1s", text)
+
+	auth.Issuer = ""
+	text, err = totpText(auth, time.Unix(30, 0))
+	require.NoError(t, err)
+	assert.Contains(t, text, "alice\nCode: ")
+	assert.Contains(t, text, "Expires in: 30s")
+}
+
+func TestShowTOTPContentModal(t *testing.T) {
+	stubNow(t, time.Unix(59, 0))
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	enc, err := utils.Encrypt("otpauth://totp/ACME:alice?secret="+rfcSecret+"&digits=8", aes)
+	require.NoError(t, err)
+	message := tview.NewTextView()
+
+	showTOTPContentModal(tview.NewApplication(), "u", "t", "item", enc, tview.NewTable(), message)
+	name, page := pages.GetFrontPage()
+	require.Equal(t, "dialog_view_text", name)
+	textView := page.(*tview.Flex).GetItem(0).(*tview.TextView)
+	assert.Contains(t, textView.GetText(true), "Code: 94287082")
+	assert.NotContains(t, textView.GetText(true), rfcSecret)
+}
+
+func TestShowTOTPContentModal_InvalidSecret(t *testing.T) {
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	enc, err := utils.Encrypt("not a secret!", aes)
+	require.NoError(t, err)
+	message := tview.NewTextView()
+
+	showTOTPContentModal(tview.NewApplication(), "u", "t", "item", enc, tview.NewTable(), message)
+	assert.Contains(t, message.GetText(true), "Error reading TOTP")
+	assert.Equal(t, 0, pages.GetPageCount())
+}
+
+func TestSaveTOTP(t *testing.T) {
+	client := &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+	dataClient = client
+	pages = tview.NewPages()
+	aes = "1234567891234567"
+	message := tview.NewTextView()
+
+	saveTOTP("not base32!", "GitHub", "u", "t", tview.NewTable(), message)
+	assert.Contains(t, message.GetText(true), "Save error")
+	assert.Empty(t, client.receivedChunks)
+
+	saveTOTP(" "+rfcSecret+" ", "GitHub", "u", "t", tview.NewTable(), message)
+	assert.Equal(t, "TOTP saved!", message.GetText(true))
+	require.Len(t, client.receivedChunks, 1)
+	assert.Equal(t, "totp", client.receivedChunks[0].Type)
+	plain, err := utils.Decrypt(string(client.receivedChunks[0].Data), aes)
+	require.NoError(t, err)
+	assert.Equal(t, rfcSecret, plain)
+}
+
+func TestPrintTOTP(t *testing.T) {
+	stubNow(t, time.Unix(59, 0))
+	enc, err := utils.Encrypt(rfcSecret, "1234567891234567")
+	require.NoError(t, err)
+
+	auth := &fakeAuthClient{
+		loginResp:  &pb.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"},
+		verifyResp: &pb.VerifyTwoFactorResponse{UserUid: "u", Token: "t"},
+	}
+	autClient = auth
+	dataClient = &fakeDataClient{
+		getUserDataResp: &pb.GetUserDataListResponse{Items: []*pb.UserDataItem{
+			{Id: "other", Name: "GitHub backup", Type: "totp"},
+			{Id: "item", Name: "GitHub", Type: "totp"},
+		}},
+		getDataResp: &pb.GetDataResponse{Type: "totp", Content: &pb.GetDataResponse_TextData{TextData: enc}},
+	}
+
+	var out bytes.Buffer
+	err = PrintTOTP(&out, "1234567891234567", "alice", "pass", "GitHub", func() (string, error) { return "123456", nil })
+	require.NoError(t, err)
+
+	code, err := utils.TOTPCode(rfcSecret, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, code+" (1s)\n", out.String())
+	assert.Equal(t, "123456", auth.lastVerifyRequest.Code)
+	assert.True(t, auth.logoutCalled)
+}
+
+func TestPrintTOTP_NotFound(t *testing.T) {
+	autClient = &fakeAuthClient{loginResp: &pb.LoginResponse{UserUid: "u", Token: "t"}}
+	dataClient = &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+
+	err := PrintTOTP(&bytes.Buffer{}, "1234567891234567", "alice", "pass", "GitHub", nil)
+	assert.True(t, errors.Is(err, errItemNotFound))
+}
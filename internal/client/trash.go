@@ -0,0 +1,157 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showConfirmDialog модальное окно подтверждения действия, onConfirm вызывается после закрытия окна
+func showConfirmDialog(app *tview.Application, text, confirmLabel string, onConfirm func()) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{confirmLabel, "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			// Возврат на страницу, из которой открыто окно
+			pages.RemovePage("dialog_confirm")
+			if label == confirmLabel {
+				onConfirm()
+			}
+		})
+
+	pages.AddPage("dialog_confirm", modal, true, true)
+	app.SetFocus(modal)
+}
+
+// confirmDelete подтверждение перемещения данных в корзину
+func confirmDelete(app *tview.Application, onConfirm func()) {
+	showConfirmDialog(app, "Move this item to trash?", "Delete", onConfirm)
+}
+
+// showTrashScreen экран корзины открытого хранилища: восстановление и окончательное удаление данных
+func showTrashScreen(app *tview.Application, userUID, token string, dataTable *tview.Table, message *tview.TextView) {
+	table := tview.NewTable()
+	table.SetBorders(true).
+		SetSelectable(true, false)
+
+	reload := func() {
+		if err := loadTrash(table, userUID, token); err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading trash: %v", err))
+		}
+	}
+
+	form := tview.NewForm().
+		AddButton("Restore", func() {
+			if itemID := selectedTrashItem(table); itemID != "" {
+				restoreFromTrash(userUID, token, itemID, message)
+				reload()
+			}
+		}).
+		AddButton("Purge", func() {
+			itemID := selectedTrashItem(table)
+			if itemID == "" {
+				return
+			}
+			showConfirmDialog(app, "Permanently delete this item? This cannot be undone.", "Purge", func() {
+				purgeFromTrash(userUID, token, itemID, message)
+				reload()
+			})
+		}).
+		AddButton("Back", func() {
+			pages.RemovePage("trash_screen")
+			pages.SwitchToPage("data_screen")
+			_ = loadUserData(dataTable, userUID, token)
+		})
+
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab || key == tcell.KeyEscape {
+			app.SetFocus(form)
+		}
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyBacktab {
+			app.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	reload()
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(form, 3, 1, false).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Trash ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("trash_screen", flex, true, true)
+	pages.SwitchToPage("trash_screen")
+	app.SetFocus(table)
+}
+
+// loadTrash загрузка содержимого корзины в таблицу, идентификатор хранится в ссылке ячейки имени
+func loadTrash(table *tview.Table, userUID, token string) error {
+	req := &pb.ListTrashRequest{}
+	if currentOrg != nil {
+		req.OrgId = currentOrg.GetId()
+	}
+	resp, err := dataClient.ListTrash(authContext(userUID, token), req)
+	if err != nil {
+		return err
+	}
+
+	table.Clear()
+	table.SetCell(0, 0, tview.NewTableCell("NAME").SetSelectable(false)).
+		SetCell(0, 1, tview.NewTableCell("TYPE").SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("DELETED").SetSelectable(false)).
+		SetCell(0, 3, tview.NewTableCell("PURGE ON").SetSelectable(false))
+
+	for row, item := range resp.GetItems() {
+		table.SetCell(row+1, 0, tview.NewTableCell(item.GetName()).SetReference(item.GetId())).
+			SetCell(row+1, 1, tview.NewTableCell(item.GetType())).
+			SetCell(row+1, 2, tview.NewTableCell(time.Unix(item.GetDeletedAt(), 0).Format(time.DateTime))).
+			SetCell(row+1, 3, tview.NewTableCell(formatDate(item.GetPurgeAt())))
+	}
+	if len(resp.GetItems()) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("Trash is empty").SetSelectable(false))
+	}
+	return nil
+}
+
+// selectedTrashItem идентификатор выбранных в корзине данных, пустая строка, если ничего не выбрано
+func selectedTrashItem(table *tview.Table) string {
+	row, _ := table.GetSelection()
+	if row < 1 {
+		return ""
+	}
+	itemID, _ := table.GetCell(row, 0).GetReference().(string)
+	return itemID
+}
+
+// restoreFromTrash запрос на восстановление данных из корзины
+func restoreFromTrash(userUID, token, itemID string, message *tview.TextView) {
+	_, err := dataClient.RestoreData(authContext(userUID, token), &pb.RestoreDataRequest{Id: itemID})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Restore error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Item restored!")
+	}
+}
+
+// purgeFromTrash запрос на окончательное удаление данных из корзины
+func purgeFromTrash(userUID, token, itemID string, message *tview.TextView) {
+	_, err := dataClient.PurgeData(authContext(userUID, token), &pb.PurgeDataRequest{Id: itemID})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Purge error: %v", err))
+	} else {
+		message.SetTextColor(tcell.ColorGreen).SetText("Item permanently deleted!")
+	}
+}
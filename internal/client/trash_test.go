@@ -0,0 +1,109 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pressModalButton нажатие кнопки модального окна по номеру
+func pressModalButton(modal *tview.Modal, index int) {
+	// Как в tview.Application: фокус снимается с прежнего элемента
+	var focused tview.Primitive
+	var setFocus func(p tview.Primitive)
+	setFocus = func(p tview.Primitive) {
+		if focused != nil {
+			focused.Blur()
+		}
+		focused = p
+		p.Focus(setFocus)
+	}
+	modal.Focus(setFocus)
+
+	handler := modal.InputHandler()
+	for i := 0; i < index; i++ {
+		handler(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone), setFocus)
+	}
+	handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), setFocus)
+}
+
+func TestConfirmDelete(t *testing.T) {
+	pages = tview.NewPages()
+	pages.AddPage("dialog_view_text", tview.NewBox(), true, true)
+	client := &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+	dataClient = client
+	deleteItem := func() {
+		deleteText("u", "t", "item", tview.NewTable(), tview.NewTextView())
+	}
+
+	confirmDelete(tview.NewApplication(), deleteItem)
+	name, page := pages.GetFrontPage()
+	require.Equal(t, "dialog_confirm", name)
+
+	// Отмена возвращает на окно, из которого открыт диалог
+	pressModalButton(page.(*tview.Modal), 1)
+	assert.Nil(t, client.lastDeleteRequest)
+	name, _ = pages.GetFrontPage()
+	assert.Equal(t, "dialog_view_text", name)
+
+	confirmDelete(tview.NewApplication(), deleteItem)
+	_, page = pages.GetFrontPage()
+	pressModalButton(page.(*tview.Modal), 0)
+	assert.Equal(t, &pb.DeleteDataRequest{Id: "item"}, client.lastDeleteRequest)
+	assert.False(t, pages.HasPage("dialog_confirm"))
+}
+
+func TestShowTrashScreen(t *testing.T) {
+	deleted := time.Date(2025, 7, 1, 12, 0, 0, 0, time.Local)
+	client := &fakeDataClient{trashResp: &pb.ListTrashResponse{Items: []*pb.TrashItem{
+		{Id: "item", Name: "bank", Type: "password", DeletedAt: deleted.Unix(), PurgeAt: deleted.AddDate(0, 0, 30).Unix()},
+	}}}
+	dataClient = client
+	currentOrg = &pb.Organization{Id: "org-id"}
+	defer func() { currentOrg = nil }()
+	pages = tview.NewPages()
+
+	showTrashScreen(tview.NewApplication(), "u", "t", tview.NewTable(), tview.NewTextView())
+	name, page := pages.GetFrontPage()
+	assert.Equal(t, "trash_screen", name)
+	assert.Equal(t, &pb.ListTrashRequest{OrgId: "org-id"}, client.lastTrashRequest)
+
+	table := page.(*tview.Flex).GetItem(0).(*tview.Table)
+	require.Equal(t, 2, table.GetRowCount())
+	assert.Equal(t, "bank", table.GetCell(1, 0).Text)
+	assert.Equal(t, "2025-07-31", table.GetCell(1, 3).Text)
+	table.Select(1, 0)
+	assert.Equal(t, "item", selectedTrashItem(table))
+
+	client.trashResp = &pb.ListTrashResponse{}
+	require.NoError(t, loadTrash(table, "u", "t"))
+	assert.Equal(t, "Trash is empty", table.GetCell(1, 0).Text)
+	assert.Empty(t, selectedTrashItem(table))
+}
+
+func TestRestoreAndPurgeFromTrash(t *testing.T) {
+	client := &fakeDataClient{}
+	dataClient = client
+	message := tview.NewTextView()
+
+	restoreFromTrash("u", "t", "item", message)
+	assert.Equal(t, &pb.RestoreDataRequest{Id: "item"}, client.lastRestoreRequest)
+	assert.Equal(t, "Item restored!", message.GetText(true))
+
+	purgeFromTrash("u", "t", "item", message)
+	assert.Equal(t, &pb.PurgeDataRequest{Id: "item"}, client.lastPurgeRequest)
+	assert.Equal(t, "Item permanently deleted!", message.GetText(true))
+
+	client.returnErr = errors.New("not found")
+	restoreFromTrash("u", "t", "item", message)
+	assert.Equal(t, "Restore error: not found", message.GetText(true))
+	purgeFromTrash("u", "t", "item", message)
+	assert.Equal(t, "Purge error: not found", message.GetText(true))
+}
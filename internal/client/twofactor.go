@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/skip2/go-qrcode"
+)
+
+// showTwoFactorDialog модальное окно второго шага авторизации
+func showTwoFactorDialog(app *tview.Application, challengeToken string, message *tview.TextView) {
+	closeTwoFactor := func() {
+		pages.RemovePage("dialog_two_factor")
+		pages.SwitchToPage("login")
+	}
+
+	codeField := tview.NewInputField().
+		SetLabel("Code: ").
+		SetFieldWidth(20)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(codeField).
+		AddButton("Verify", func() {
+			verifyTwoFactor(app, challengeToken, codeField.GetText(), message)
+		}).
+		AddButton("Cancel", closeTwoFactor)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Authenticator or recovery code ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_two_factor", dialogForm, true, true)
+	pages.SwitchToPage("dialog_two_factor")
+	app.SetFocus(dialogForm)
+}
+
+// verifyTwoFactor запрос второго шага авторизации
+func verifyTwoFactor(app *tview.Application, challengeToken, code string, message *tview.TextView) {
+	response, err := autClient.VerifyTwoFactor(
+		context.Background(),
+		&pb.VerifyTwoFactorRequest{
+			ChallengeToken: challengeToken,
+			Code:           strings.TrimSpace(code),
+		},
+	)
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Login error: %v", err))
+		return
+	}
+	pages.RemovePage("dialog_two_factor")
+	message.SetTextColor(tcell.ColorGreen).SetText("Login successful!")
+	showDataScreen(app, response.UserUid, response.Token, message)
+}
+
+// twoFactorStatusText описание состояния 2FA для экрана безопасности
+func twoFactorStatusText(userUID, token string) (string, bool, error) {
+	resp, err := autClient.GetTwoFactorStatus(authContext(userUID, token), &pb.GetTwoFactorStatusRequest{})
+	if err != nil {
+		return "", false, err
+	}
+	if !resp.GetEnabled() {
+		return "Two-factor authentication is disabled", false, nil
+	}
+	return fmt.Sprintf("Two-factor authentication is enabled\nRecovery codes left: %d", resp.GetRecoveryCodesLeft()), true, nil
+}
+
+// qrText QR-код otpauth URI в виде текста для терминала
+func qrText(uri string) (string, error) {
+	qr, err := qrcode.New(uri, qrcode.Low)
+	if err != nil {
+		return "", fmt.Errorf("failed to build qr code: %w", err)
+	}
+	return qr.ToSmallString(false), nil
+}
+
+// recoveryCodesText коды восстановления с предупреждением, что они показываются один раз
+func recoveryCodesText(codes []string) string {
+	return "Save these recovery codes, they will not be shown again.\n" +
+		"Each code can be used once instead of an authenticator code.\n\n" +
+		strings.Join(codes, "\n")
+}
+
+// showSecurityScreen экран управления двухфакторной аутентификацией
+func showSecurityScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	statusView := tview.NewTextView().
+		SetTextAlign(tview.AlignCenter)
+
+	reload := func() {
+		text, _, err := twoFactorStatusText(userUID, token)
+		if err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading 2FA status: %v", err))
+			return
+		}
+		statusView.SetText(text)
+	}
+
+	form := tview.NewForm().
+		AddButton("Enable 2FA", func() {
+			enrollTwoFactor(app, userUID, token, statusView, reload, message)
+		}).
+		AddButton("Disable 2FA", func() {
+			showTwoFactorCodeDialog(app, " Disable 2FA ", func(code string) {
+				_, err := autClient.DisableTwoFactor(authContext(userUID, token), &pb.DisableTwoFactorRequest{Code: code})
+				if err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Disable 2FA error: %v", err))
+					return
+				}
+				message.SetTextColor(tcell.ColorGreen).SetText("Two-factor authentication disabled")
+				reload()
+			})
+		}).
+		AddButton("New recovery codes", func() {
+			showTwoFactorCodeDialog(app, " New recovery codes ", func(code string) {
+				resp, err := autClient.RegenerateRecoveryCodes(authContext(userUID, token), &pb.RegenerateRecoveryCodesRequest{Code: code})
+				if err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Recovery codes error: %v", err))
+					return
+				}
+				message.SetTextColor(tcell.ColorGreen).SetText("New recovery codes issued")
+				statusView.SetText(recoveryCodesText(resp.GetRecoveryCodes()))
+			})
+		}).
+		AddButton("Back", func() {
+			pages.RemovePage("security_screen")
+			pages.SwitchToPage("data_screen")
+		})
+
+	reload()
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(statusView, 0, 1, false).
+		AddItem(form, 3, 1, true).
+		AddItem(message, 1, 1, false)
+
+	flex.SetBorder(true).
+		SetTitle(" Security ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("security_screen", flex, true, true)
+	pages.SwitchToPage("security_screen")
+	app.SetFocus(form)
+}
+
+// enrollTwoFactor создание секрета TOTP и показ QR-кода с полем для подтверждающего кода
+func enrollTwoFactor(app *tview.Application, userUID, token string, statusView *tview.TextView, reload func(), message *tview.TextView) {
+	resp, err := autClient.EnrollTwoFactor(authContext(userUID, token), &pb.EnrollTwoFactorRequest{})
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Enable 2FA error: %v", err))
+		return
+	}
+	qr, err := qrText(resp.GetOtpauthUri())
+	if err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Enable 2FA error: %v", err))
+		return
+	}
+	statusView.SetText(fmt.Sprintf("%s\nSecret: %s\n%s", qr, resp.GetSecret(), resp.GetOtpauthUri()))
+
+	showTwoFactorCodeDialog(app, " Code from authenticator ", func(code string) {
+		confirm, err := autClient.ConfirmTwoFactor(authContext(userUID, token), &pb.ConfirmTwoFactorRequest{Code: code})
+		if err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Enable 2FA error: %v", err))
+			reload()
+			return
+		}
+		message.SetTextColor(tcell.ColorGreen).SetText("Two-factor authentication enabled")
+		statusView.SetText(recoveryCodesText(confirm.GetRecoveryCodes()))
+	})
+}
+
+// showTwoFactorCodeDialog модальное окно ввода кода поверх экрана безопасности
+func showTwoFactorCodeDialog(app *tview.Application, title string, onCode func(code string)) {
+	closeCode := func() {
+		pages.RemovePage("dialog_two_factor_code")
+		pages.SwitchToPage("security_screen")
+	}
+
+	codeField := tview.NewInputField().
+		SetLabel("Code: ").
+		SetFieldWidth(20)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(codeField).
+		AddButton("OK", func() {
+			code := strings.TrimSpace(codeField.GetText())
+			closeCode()
+			onCode(code)
+		}).
+		AddButton("Cancel", closeCode)
+
+	dialogForm.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Окно не перекрывает QR-код на экране безопасности
+	dialogFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(dialogForm, 7, 1, true)
+
+	pages.AddPage("dialog_two_factor_code", dialogFlex, true, true)
+	app.SetFocus(dialogForm)
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin_TwoFactorRequired(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	message := tview.NewTextView()
+
+	autClient = &fakeAuthClient{
+		loginResp: &pb.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"},
+	}
+
+	login(app, "test_user", "pass123", message)
+
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "dialog_two_factor", name)
+	assert.Empty(t, message.GetText(true))
+}
+
+func TestVerifyTwoFactor_Error(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	message := tview.NewTextView()
+
+	client := &fakeAuthClient{returnErr: errors.New("invalid code")}
+	autClient = client
+
+	verifyTwoFactor(app, "challenge", " 123456 ", message)
+
+	assert.Equal(t, "challenge", client.lastVerifyRequest.ChallengeToken)
+	assert.Equal(t, "123456", client.lastVerifyRequest.Code)
+	assert.Contains(t, message.GetText(true), "Login error: invalid code")
+}
+
+func TestTwoFactorStatusText(t *testing.T) {
+	autClient = &fakeAuthClient{twoFactorStatusResp: &pb.GetTwoFactorStatusResponse{}}
+	text, enabled, err := twoFactorStatusText("user1", "token1")
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	assert.Contains(t, text, "disabled")
+
+	autClient = &fakeAuthClient{twoFactorStatusResp: &pb.GetTwoFactorStatusResponse{Enabled: true, RecoveryCodesLeft: 7}}
+	text, enabled, err = twoFactorStatusText("user1", "token1")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Contains(t, text, "Recovery codes left: 7")
+}
+
+func TestQRText(t *testing.T) {
+	text, err := qrText("otpauth://totp/Gault:alice?secret=JBSWY3DPEHPK3PXP&issuer=Gault")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, text)
+}
+
+func TestRecoveryCodesText(t *testing.T) {
+	text := recoveryCodesText([]string{"AAAA-BBBB", "CCCC-DDDD"})
+	assert.Contains(t, text, "AAAA-BBBB\nCCCC-DDDD")
+}
+
+func TestShowSecurityScreen(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	message := tview.NewTextView()
+	autClient = &fakeAuthClient{twoFactorStatusResp: &pb.GetTwoFactorStatusResponse{Enabled: true, RecoveryCodesLeft: 3}}
+
+	showSecurityScreen(app, "user1", "token1", message)
+
+	name, _ := pages.GetFrontPage()
+	assert.Equal(t, "security_screen", name)
+}
+
+func TestEnrollTwoFactor_ShowsRecoveryCodes(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	message := tview.NewTextView()
+	statusView := tview.NewTextView()
+	client := &fakeAuthClient{
+		enrollResp: &pb.EnrollTwoFactorResponse{
+			Secret:     "JBSWY3DPEHPK3PXP",
+			OtpauthUri: "otpauth://totp/Gault:alice?secret=JBSWY3DPEHPK3PXP&issuer=Gault",
+		},
+		recoveryCodes: []string{"AAAA-BBBB"},
+	}
+	autClient = client
+
+	enrollTwoFactor(app, "user1", "token1", statusView, func() {}, message)
+	assert.Contains(t, statusView.GetText(true), "JBSWY3DPEHPK3PXP")
+	assert.True(t, pages.HasPage("dialog_two_factor_code"))
+
+	_, item := pages.GetFrontPage()
+	form := item.(*tview.Flex).GetItem(1).(*tview.Form)
+	form.GetFormItem(0).(*tview.InputField).SetText("123456")
+	form.GetButton(0).InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+
+	assert.Equal(t, "123456", client.lastConfirmRequest.Code)
+	assert.Contains(t, statusView.GetText(true), "AAAA-BBBB")
+	assert.Contains(t, message.GetText(true), "enabled")
+}
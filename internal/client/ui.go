@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/fngoc/gault/internal/importer"
 	"github.com/fngoc/gault/pkg/utils"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
@@ -17,6 +18,20 @@ import (
 // Ключ шифрования
 var aes string
 
+// listFilter текущие фильтры списка данных (папка, тег, поиск, сортировка)
+var listFilter = &pb.GetUserDataListRequest{}
+
+// nextPageToken курсор следующей страницы списка данных, пустой если страниц больше нет
+var nextPageToken string
+
+// sortOptions варианты сортировки списка данных для выпадающего списка
+var sortOptions = []pb.SortOrder{
+	pb.SortOrder_SORT_ORDER_NAME,
+	pb.SortOrder_SORT_ORDER_CREATED,
+	pb.SortOrder_SORT_ORDER_UPDATED,
+	pb.SortOrder_SORT_ORDER_SIZE,
+}
+
 // showLoginMenu экран логина/регистрации
 func showLoginMenu(app *tview.Application, aesKey string) tview.Primitive {
 	aes = aesKey
@@ -59,11 +74,147 @@ func showLoginMenu(app *tview.Application, aesKey string) tview.Primitive {
 	return flex
 }
 
-// showDataScreen экран с таблицей данных и кнопками добавления/чтения/скачивания данных
+// showDataScreen экран с деревом папок, таблицей данных и кнопками добавления/чтения/скачивания данных
 func showDataScreen(app *tview.Application, userUID, token string, message *tview.TextView) {
+	listFilter = &pb.GetUserDataListRequest{Sort: sortOptions[0], OrgId: currentOrg.GetId()}
+
+	tree := tview.NewTreeView()
+	searchField := tview.NewInputField()
+	tagFilter := tview.NewInputField()
+	sortField := tview.NewDropDown()
 	table := tview.NewTable()
 	form := tview.NewForm()
 
+	treeTitle := " Folders "
+	if currentOrg != nil {
+		treeTitle = " Collections "
+	}
+	tree.SetBorder(true).
+		SetTitle(treeTitle).
+		SetTitleAlign(tview.AlignCenter)
+
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		folderID, _ := node.GetReference().(string)
+		if currentOrg != nil {
+			// В командном хранилище дерево содержит коллекции, новые данные сохраняются в выбранную
+			listFilter.CollectionId = folderID
+			currentCollection = folderID
+		} else {
+			listFilter.FolderId = folderID
+		}
+		if err := loadUserData(table, userUID, token); err != nil {
+			message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
+		}
+	}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyTab {
+				app.SetFocus(searchField)
+			}
+		})
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		var folderID string
+		if node := tree.GetCurrentNode(); node != nil {
+			folderID, _ = node.GetReference().(string)
+		}
+		if currentOrg != nil {
+			switch event.Rune() {
+			case 'n':
+				showAddCollectionDialog(app, userUID, token, tree, message)
+				return nil
+			case 'd':
+				if folderID != "" {
+					deleteCollection(userUID, token, folderID, tree, table, message)
+				}
+				return nil
+			}
+			return event
+		}
+		switch event.Rune() {
+		case 'n':
+			showAddFolderDialog(app, userUID, token, folderID, tree, message)
+			return nil
+		case 'r':
+			if folderID != "" {
+				showRenameFolderDialog(app, userUID, token, folderID, tree, message)
+			}
+			return nil
+		case 'm':
+			if folderID != "" {
+				showMoveFolderDialog(app, userUID, token, folderID, tree, message)
+			}
+			return nil
+		case 'd':
+			if folderID != "" {
+				deleteFolder(userUID, token, folderID, tree, table, message)
+			}
+			return nil
+		}
+		return event
+	})
+
+	searchField.SetLabel("Search: ").
+		SetFieldWidth(30).
+		SetChangedFunc(func(text string) {
+			listFilter.NameQuery = strings.TrimSpace(text)
+			if err := loadUserData(table, userUID, token); err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
+			}
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			switch key {
+			case tcell.KeyEnter:
+				app.SetFocus(table)
+			case tcell.KeyTab:
+				app.SetFocus(tagFilter)
+			case tcell.KeyBacktab:
+				app.SetFocus(tree)
+			}
+		})
+
+	tagFilter.SetLabel(" Tag: ").
+		SetFieldWidth(20).
+		SetDoneFunc(func(key tcell.Key) {
+			switch key {
+			case tcell.KeyEnter, tcell.KeyTab:
+				listFilter.Tag = strings.TrimSpace(tagFilter.GetText())
+				if err := loadUserData(table, userUID, token); err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
+				}
+				if key == tcell.KeyEnter {
+					app.SetFocus(table)
+				} else {
+					app.SetFocus(sortField)
+				}
+			case tcell.KeyBacktab:
+				app.SetFocus(searchField)
+			}
+		})
+
+	sortLabels := make([]string, 0, len(sortOptions))
+	for _, sort := range sortOptions {
+		sortLabels = append(sortLabels, sortLabel(sort))
+	}
+	sortField.SetLabel(" Sort: ").
+		SetOptions(sortLabels, func(_ string, index int) {
+			if index < 0 || sortOptions[index] == listFilter.GetSort() {
+				return
+			}
+			listFilter.Sort = sortOptions[index]
+			if err := loadUserData(table, userUID, token); err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
+			}
+		}).
+		SetCurrentOption(0).
+		SetDoneFunc(func(key tcell.Key) {
+			switch key {
+			case tcell.KeyTab, tcell.KeyEnter:
+				app.SetFocus(table)
+			case tcell.KeyBacktab:
+				app.SetFocus(tagFilter)
+			}
+		})
+
 	table.SetBorders(true)
 
 	table.SetSelectable(true, false).
@@ -75,11 +226,50 @@ func showDataScreen(app *tview.Application, userUID, token string, message *tvie
 			showItemDataDialog(app, userUID, token, itemID, table, message)
 		}).
 		SetDoneFunc(func(key tcell.Key) {
-			if key == tcell.KeyTab {
+			switch key {
+			case tcell.KeyTab:
 				app.SetFocus(form)
+			case tcell.KeyBacktab:
+				app.SetFocus(sortField)
+			}
+		}).
+		SetSelectionChangedFunc(func(row, _ int) {
+			// Подгрузка следующей страницы при достижении последней строки
+			if row == table.GetRowCount()-1 && nextPageToken != "" {
+				if err := loadMoreUserData(table, userUID, token); err != nil {
+					message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
+				}
 			}
 		})
 
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		if row == 0 {
+			return event
+		}
+		switch event.Rune() {
+		case 'm':
+			showMoveDataDialog(app, userUID, token, table.GetCell(row, 0).Text, table, message)
+			return nil
+		case 't':
+			showTagsDialog(app, userUID, token, table.GetCell(row, 0).Text, table.GetCell(row, 3).Text, table, message)
+			return nil
+		case 's':
+			showShareDialog(app, userUID, token, table.GetCell(row, 0).Text, message)
+			return nil
+		case 'e':
+			showRotationDialog(app, userUID, token, table.GetCell(row, 0).Text, message)
+			return nil
+		}
+		return event
+	})
+
+	if err := loadUserKeys(userUID, token); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading keys: %v", err))
+	}
+	if err := loadVaultTree(tree, userUID, token); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading folders: %v", err))
+	}
 	if err := loadUserData(table, userUID, token); err != nil {
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error loading data: %v", err))
 	}
@@ -97,6 +287,36 @@ func showDataScreen(app *tview.Application, userUID, token string, message *tvie
 		AddButton("Add Card", func() {
 			showAddCardDialog(app, userUID, token, message, table)
 		}).
+		AddButton("Add TOTP", func() {
+			showAddTOTPDialog(app, userUID, token, message, table)
+		}).
+		AddButton("Add SSH Key", func() {
+			showAddSSHKeyDialog(app, userUID, token, message, table)
+		}).
+		AddButton("Import", func() {
+			showImportDialog(app, userUID, token, message, table)
+		}).
+		AddButton("Shared", func() {
+			showSharedScreen(app, userUID, token, message)
+		}).
+		AddButton("Vaults", func() {
+			showVaultsScreen(app, userUID, token, message)
+		}).
+		AddButton("Activity", func() {
+			showActivityScreen(app, userUID, token, message)
+		}).
+		AddButton("Health", func() {
+			showHealthScreen(app, userUID, token, message)
+		}).
+		AddButton("Trash", func() {
+			showTrashScreen(app, userUID, token, table, message)
+		}).
+		AddButton("Account", func() {
+			showAccountScreen(app, userUID, token, message)
+		}).
+		AddButton("Logout", func() {
+			logout(userUID, token, message)
+		}).
 		AddButton("Exit", func() {
 			app.Stop()
 		})
@@ -110,22 +330,39 @@ func showDataScreen(app *tview.Application, userUID, token string, message *tvie
 	})
 
 	messageHint := tview.NewTextView().
-		SetText("Use ↑/↓ for change select item in table. [Tab] to switch on menu. [Shift+Tab] to switch on table]").
+		SetText("Use ↑/↓ for change select item. [Tab]/[Shift+Tab] to switch folders, search, filters, table and menu. " +
+			"Folders: [n]ew, [r]ename, [m]ove, [d]elete. Collections: [n]ew, [d]elete. Table: [m]ove item, [t]ags, [s]hare, [e]xpiry and rotation").
 		SetTextAlign(tview.AlignCenter)
 
+	filterFlex := tview.NewFlex().
+		AddItem(searchField, 0, 2, false).
+		AddItem(tagFilter, 0, 1, false).
+		AddItem(sortField, 0, 1, false)
+
+	dataFlex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filterFlex, 1, 1, false).
+		AddItem(table, 0, 1, true)
+
+	contentFlex := tview.NewFlex().
+		AddItem(tree, 30, 1, false).
+		AddItem(dataFlex, 0, 1, true)
+
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(table, 0, 2, true).
+		AddItem(contentFlex, 0, 2, true).
 		AddItem(form, 7, 1, false).
 		AddItem(message, 1, 1, false).
-		AddItem(messageHint, 1, 1, false)
+		AddItem(messageHint, 2, 1, false)
 
 	flex.SetBorder(true).
-		SetTitle(" Your data ").
+		SetTitle(vaultTitle()).
 		SetTitleAlign(tview.AlignCenter)
 
+	pages.RemovePage("lock_screen")
 	pages.AddPage("data_screen", flex, true, true)
 	pages.SwitchToPage("data_screen")
+	startIdleTimer(app, userUID, token)
 
 	app.SetFocus(table)
 }
@@ -177,9 +414,11 @@ func showAddLoginPasswordDialog(app *tview.Application, userUID, token string, m
 	dialogForm := tview.NewForm().
 		AddFormItem(inputLoginField).
 		AddFormItem(inputPasswordField).
+		AddFormItem(passwordStrengthView(inputPasswordField)).
 		AddButton("Save", func() {
 			saveLoginAndPassword(inputPasswordField.GetText(), inputLoginField.GetText(), userUID, token, table, message)
-		}).
+		})
+	addGeneratorButtons(dialogForm, inputPasswordField, message).
 		AddButton("Cancel", func() {
 			closeDialog("dialog_add_text")
 		})
@@ -223,8 +462,7 @@ func showAddCardDialog(app *tview.Application, userUID, token string, message *t
 		AddFormItem(inputCvcField).
 		AddButton("Save", func() {
 			saveCard(
-				fmt.Sprintf("Number: [%s];\nDate number: [%s];\nCVC number: [%s];",
-					inputCardNumberField.GetText(), inputDateNumberField.GetText(), inputCvcField.GetText()),
+				importer.FormatCard(inputCardNumberField.GetText(), inputDateNumberField.GetText(), inputCvcField.GetText()),
 				inputNameField.GetText(), userUID, token, table, message)
 		}).
 		AddButton("Cancel", func() {
@@ -290,6 +528,10 @@ func showItemDataDialog(app *tview.Application, userUID, token, itemID string, t
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error getting data: %v", err))
 		return
 	}
+	if err := rememberItemKey(itemID, resp); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting data key: %v", err))
+		return
+	}
 
 	switch resp.Type {
 	case "text":
@@ -304,6 +546,10 @@ func showItemDataDialog(app *tview.Application, userUID, token, itemID string, t
 	case "card":
 		cardData := resp.GetTextData()
 		showCardContentModal(app, userUID, token, itemID, cardData, table, message)
+	case "totp":
+		showTOTPContentModal(app, userUID, token, itemID, resp.GetTextData(), table, message)
+	case "ssh_key":
+		showSSHKeyContentModal(app, userUID, token, itemID, resp.GetTextData(), table, message)
 	default:
 		message.SetTextColor(tcell.ColorYellow).SetText(fmt.Sprintf("Unknown data type: %s", resp.Type))
 	}
@@ -325,7 +571,9 @@ func showTextContentModal(app *tview.Application, userUID, token, itemID string,
 			showEditTextDialog(app, userUID, token, itemID, textData, table, message)
 		}).
 		AddButton("Delete", func() {
-			deleteText(userUID, token, itemID, table, message)
+			confirmDelete(app, func() {
+				deleteText(userUID, token, itemID, table, message)
+			})
 		}).
 		AddButton("Close", func() {
 			closeDialog("dialog_view_text")
@@ -341,14 +589,15 @@ func showTextContentModal(app *tview.Application, userUID, token, itemID string,
 	app.SetFocus(form)
 }
 
-// showPasswordContentModal модальное окно для логина и пароля
+// showPasswordContentModal модальное окно для логина и пароля. Пароль скрыт, пока не нажата Reveal
 func showPasswordContentModal(app *tview.Application, userUID, token, itemID string, textData string, table *tview.Table, message *tview.TextView) {
-	passData, err := utils.Decrypt(textData, aes)
+	passData, err := utils.Decrypt(textData, itemKey(itemID))
 	if err != nil {
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting password: %v", err))
 	}
+	login := tableItemName(table, itemID)
+
 	textView := tview.NewTextView().
-		SetText(fmt.Sprintf("Password: %s", passData)).
 		SetWrap(true).
 		SetScrollable(true)
 
@@ -356,12 +605,31 @@ func showPasswordContentModal(app *tview.Application, userUID, token, itemID str
 		SetTitle(" Text content ").
 		SetTitleAlign(tview.AlignCenter)
 
-	form := tview.NewForm().
+	revealed := false
+	render := func() {
+		textView.SetText(fmt.Sprintf("Login: %s\nPassword: %s", login, maskSecret(passData, revealed)))
+	}
+	render()
+
+	form := tview.NewForm()
+	form.AddButton("Reveal", func() {
+		revealed = !revealed
+		form.GetButton(0).SetLabel(revealLabel(revealed))
+		render()
+	}).
+		AddButton("Copy login", func() {
+			copySecret(app, "Login", login, message)
+		}).
+		AddButton("Copy password", func() {
+			copySecret(app, "Password", passData, message)
+		}).
 		AddButton("Edit", func() {
 			showEditPasswordDialog(app, userUID, token, itemID, passData, table, message)
 		}).
 		AddButton("Delete", func() {
-			deleteText(userUID, token, itemID, table, message)
+			confirmDelete(app, func() {
+				deleteText(userUID, token, itemID, table, message)
+			})
 		}).
 		AddButton("Close", func() {
 			closeDialog("dialog_view_text")
@@ -377,14 +645,15 @@ func showPasswordContentModal(app *tview.Application, userUID, token, itemID str
 	app.SetFocus(form)
 }
 
-// showCardContentModal модальное окно для логина и пароля
+// showCardContentModal модальное окно для карты. Номер и CVC скрыты, пока не нажата Reveal
 func showCardContentModal(app *tview.Application, userUID, token, itemID string, textData string, table *tview.Table, message *tview.TextView) {
-	cardData, err := utils.Decrypt(textData, aes)
+	cardData, err := utils.Decrypt(textData, itemKey(itemID))
 	if err != nil {
 		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error decrypting card: %v", err))
 	}
+	number, date, cvc, parsed := importer.ParseCard(cardData)
+
 	textView := tview.NewTextView().
-		SetText(cardData).
 		SetWrap(true).
 		SetScrollable(true)
 
@@ -392,12 +661,39 @@ func showCardContentModal(app *tview.Application, userUID, token, itemID string,
 		SetTitle(" Text content ").
 		SetTitleAlign(tview.AlignCenter)
 
-	form := tview.NewForm().
+	revealed := false
+	render := func() {
+		switch {
+		case revealed:
+			textView.SetText(cardData)
+		case parsed:
+			textView.SetText(importer.FormatCard(maskCardNumber(number), date, maskSecret(cvc, false)))
+		default:
+			textView.SetText(maskSecret(cardData, false))
+		}
+	}
+	render()
+
+	form := tview.NewForm()
+	form.AddButton("Reveal", func() {
+		revealed = !revealed
+		form.GetButton(0).SetLabel(revealLabel(revealed))
+		render()
+	}).
+		AddButton("Copy number", func() {
+			if !parsed {
+				message.SetTextColor(tcell.ColorRed).SetText("Card number not found")
+				return
+			}
+			copySecret(app, "Card number", number, message)
+		}).
 		AddButton("Edit", func() {
 			showEditCardDialog(app, userUID, token, itemID, strings.Replace(cardData, "\n", " ", len(cardData)), table, message)
 		}).
 		AddButton("Delete", func() {
-			deleteText(userUID, token, itemID, table, message)
+			confirmDelete(app, func() {
+				deleteText(userUID, token, itemID, table, message)
+			})
 		}).
 		AddButton("Close", func() {
 			closeDialog("dialog_view_text")
@@ -413,6 +709,51 @@ func showCardContentModal(app *tview.Application, userUID, token, itemID string,
 	app.SetFocus(form)
 }
 
+// copySecret копирование значения в буфер обмена с сообщением о результате
+func copySecret(app *tview.Application, what, value string, message *tview.TextView) {
+	if err := copyToClipboard(app, value); err != nil {
+		message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Error copying to clipboard: %v", err))
+		return
+	}
+	message.SetTextColor(tcell.ColorGreen).
+		SetText(fmt.Sprintf("%s copied, clipboard will be cleared in %s", what, clipboardConf.ClearAfter))
+}
+
+// tableItemName имя элемента в таблице данных по идентификатору
+func tableItemName(table *tview.Table, itemID string) string {
+	for row := 1; row < table.GetRowCount(); row++ {
+		if table.GetCell(row, 0).Text == itemID {
+			return table.GetCell(row, 2).Text
+		}
+	}
+	return ""
+}
+
+// maskSecret секрет или маска фиксированной длины, чтобы не раскрывать длину секрета
+func maskSecret(secret string, revealed bool) string {
+	if revealed {
+		return secret
+	}
+	return "********"
+}
+
+// maskCardNumber номер карты, в котором видны только последние четыре цифры
+func maskCardNumber(number string) string {
+	digits := []rune(strings.ReplaceAll(number, " ", ""))
+	if len(digits) <= 4 {
+		return "****"
+	}
+	return "**** " + string(digits[len(digits)-4:])
+}
+
+// revealLabel подпись кнопки показа секрета
+func revealLabel(revealed bool) string {
+	if revealed {
+		return "Hide"
+	}
+	return "Reveal"
+}
+
 // showEditTextDialog модальное окно для редактирования текста
 func showEditTextDialog(app *tview.Application, userUID, token, itemID string, oldText string, table *tview.Table, message *tview.TextView) {
 	inputField := tview.NewInputField().
@@ -451,9 +792,11 @@ func showEditPasswordDialog(app *tview.Application, userUID, token, itemID strin
 
 	dialogForm := tview.NewForm().
 		AddFormItem(inputField).
+		AddFormItem(passwordStrengthView(inputField)).
 		AddButton("Save", func() {
 			updatePass(inputField.GetText(), userUID, token, itemID, table, message)
-		}).
+		})
+	addGeneratorButtons(dialogForm, inputField, message).
 		AddButton("Cancel", func() {
 			closeDialog("dialog_edit_text")
 		})
@@ -515,7 +858,9 @@ func showFileContentModal(app *tview.Application, userUID, token, itemID string,
 			showReplaceFileDialog(app, userUID, token, itemID, fileData, table, message)
 		}).
 		AddButton("Delete", func() {
-			deleteFile(userUID, token, itemID, table, message)
+			confirmDelete(app, func() {
+				deleteFile(userUID, token, itemID, table, message)
+			})
 		}).
 		AddButton("Cancel", func() {
 			closeDialog("dialog_view_file")
@@ -567,18 +912,10 @@ func showReplaceFileDialog(app *tview.Application, userUID, token, itemID string
 	app.SetFocus(dialogForm)
 }
 
-// loadUserData загрузка данных пользователя для таблицы
+// loadUserData загрузка первой страницы данных пользователя для таблицы
 func loadUserData(table *tview.Table, userUID, token string) error {
-	md := metadata.Pairs(
-		"userUID", userUID,
-		"authorization", token,
-	)
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
-
-	resp, err := dataClient.GetUserDataList(
-		ctx,
-		&pb.GetUserDataListRequest{},
-	)
+	listFilter.PageToken = ""
+	resp, err := dataClient.GetUserDataList(authContext(userUID, token), listFilter)
 	if err != nil {
 		return err
 	}
@@ -587,18 +924,60 @@ func loadUserData(table *tview.Table, userUID, token string) error {
 
 	table.SetCell(0, 0, tview.NewTableCell("ID").SetSelectable(false)).
 		SetCell(0, 1, tview.NewTableCell("TYPE").SetSelectable(false)).
-		SetCell(0, 2, tview.NewTableCell("NAME").SetSelectable(false))
+		SetCell(0, 2, tview.NewTableCell("NAME").SetSelectable(false)).
+		SetCell(0, 3, tview.NewTableCell("TAGS").SetSelectable(false))
 
-	for i, item := range resp.Items {
-		table.SetCell(i+1, 0, tview.NewTableCell(item.Id))
-		table.SetCell(i+1, 1, tview.NewTableCell(item.Type))
-		table.SetCell(i+1, 2, tview.NewTableCell(item.Name))
+	appendUserData(table, resp)
+	return nil
+}
+
+// loadMoreUserData загрузка следующей страницы данных пользователя в конец таблицы
+func loadMoreUserData(table *tview.Table, userUID, token string) error {
+	listFilter.PageToken = nextPageToken
+	resp, err := dataClient.GetUserDataList(authContext(userUID, token), listFilter)
+	listFilter.PageToken = ""
+	if err != nil {
+		return err
 	}
+
+	appendUserData(table, resp)
 	return nil
 }
 
+// appendUserData добавление строк страницы в таблицу и запоминание курсора следующей страницы
+func appendUserData(table *tview.Table, resp *pb.GetUserDataListResponse) {
+	nextPageToken = resp.GetNextPageToken()
+
+	row := table.GetRowCount()
+	for _, item := range resp.GetItems() {
+		table.SetCell(row, 0, tview.NewTableCell(item.Id))
+		table.SetCell(row, 1, tview.NewTableCell(item.Type))
+		table.SetCell(row, 2, tview.NewTableCell(item.Name))
+		table.SetCell(row, 3, tview.NewTableCell(strings.Join(item.Tags, ", ")))
+		row++
+	}
+}
+
+// sortLabel подпись варианта сортировки в выпадающем списке
+func sortLabel(sort pb.SortOrder) string {
+	switch sort {
+	case pb.SortOrder_SORT_ORDER_CREATED:
+		return "Created"
+	case pb.SortOrder_SORT_ORDER_UPDATED:
+		return "Updated"
+	case pb.SortOrder_SORT_ORDER_SIZE:
+		return "Size"
+	default:
+		return "Name"
+	}
+}
+
 // saveData делает запрос на сохранение данных
 func saveData(userUID, token, dataType, name, filePath string, data []byte) error {
+	if currentOrg != nil && currentCollection == "" {
+		return errNoCollection
+	}
+
 	md := metadata.Pairs(
 		"userUID", userUID,
 		"authorization", token,
@@ -611,6 +990,10 @@ func saveData(userUID, token, dataType, name, filePath string, data []byte) erro
 		return sendSaveTextToServer(ctx, userUID, dataType, name, data, true)
 	} else if dataType == "card" {
 		return sendSaveTextToServer(ctx, userUID, dataType, name, data, true)
+	} else if dataType == "totp" {
+		return sendSaveTextToServer(ctx, userUID, dataType, name, data, true)
+	} else if dataType == "ssh_key" {
+		return sendSaveTextToServer(ctx, userUID, dataType, name, data, true)
 	}
 	return sendSaveBigFileToServer(ctx, filePath, userUID, dataType, name)
 }
@@ -629,6 +1012,10 @@ func updateData(userUID, token, itemID, dataType, newPath string, data []byte) e
 		return sendUpdateTextToServer(ctx, userUID, dataType, itemID, data, true)
 	} else if dataType == "card" {
 		return sendUpdateTextToServer(ctx, userUID, dataType, itemID, data, true)
+	} else if dataType == "totp" {
+		return sendUpdateTextToServer(ctx, userUID, dataType, itemID, data, true)
+	} else if dataType == "ssh_key" {
+		return sendUpdateTextToServer(ctx, userUID, dataType, itemID, data, true)
 	}
 	return sendUpdateBigFileToServer(ctx, userUID, dataType, itemID, newPath)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"testing"
 
@@ -21,6 +22,7 @@ type fakeDataClient struct {
 	lastUpdateRequest     *pb.UpdateDataRequest
 	lastDeleteRequest     *pb.DeleteDataRequest
 	lastGetUserDataCalled bool
+	lastPageToken         string
 	getUserDataResp       *pb.GetUserDataListResponse
 	lastGetDataRequest    *pb.GetDataRequest
 	getDataResp           *pb.GetDataResponse
@@ -31,6 +33,36 @@ type fakeDataClient struct {
 	saveDataCloseAndRecvErr error
 
 	receivedChunks []*pb.SaveDataRequest
+	exportResps    []*pb.ExportAllResponse
+
+	lastRotationRequest *pb.SetDataRotationRequest
+	vaultHealthResp     *pb.GetVaultHealthResponse
+
+	lastTrashRequest   *pb.ListTrashRequest
+	trashResp          *pb.ListTrashResponse
+	lastRestoreRequest *pb.RestoreDataRequest
+	lastPurgeRequest   *pb.PurgeDataRequest
+}
+
+func (f *fakeDataClient) ExportAll(ctx context.Context, in *pb.ExportAllRequest, opts ...grpc.CallOption) (pb.ContentManagerV1Service_ExportAllClient, error) {
+	if f.returnErr != nil {
+		return nil, f.returnErr
+	}
+	return &fakeExportAllStream{resps: f.exportResps}, nil
+}
+
+type fakeExportAllStream struct {
+	grpc.ClientStream
+	resps []*pb.ExportAllResponse
+}
+
+func (s *fakeExportAllStream) Recv() (*pb.ExportAllResponse, error) {
+	if len(s.resps) == 0 {
+		return nil, io.EOF
+	}
+	resp := s.resps[0]
+	s.resps = s.resps[1:]
+	return resp, nil
 }
 
 func (f *fakeDataClient) SaveData(ctx context.Context, opts ...grpc.CallOption) (pb.ContentManagerV1Service_SaveDataClient, error) {
@@ -62,14 +94,49 @@ func (f *fakeDataClient) DeleteData(ctx context.Context, in *pb.DeleteDataReques
 
 func (f *fakeDataClient) GetUserDataList(ctx context.Context, in *pb.GetUserDataListRequest, opts ...grpc.CallOption) (*pb.GetUserDataListResponse, error) {
 	f.lastGetUserDataCalled = true
+	f.lastPageToken = in.GetPageToken()
 	return f.getUserDataResp, f.returnErr
 }
 
+func (f *fakeDataClient) SetDataRotation(ctx context.Context, in *pb.SetDataRotationRequest, opts ...grpc.CallOption) (*pb.SetDataRotationResponse, error) {
+	f.lastRotationRequest = in
+	return &pb.SetDataRotationResponse{}, f.returnErr
+}
+
+func (f *fakeDataClient) GetVaultHealth(ctx context.Context, in *pb.GetVaultHealthRequest, opts ...grpc.CallOption) (*pb.GetVaultHealthResponse, error) {
+	return f.vaultHealthResp, f.returnErr
+}
+
+func (f *fakeDataClient) ListTrash(ctx context.Context, in *pb.ListTrashRequest, opts ...grpc.CallOption) (*pb.ListTrashResponse, error) {
+	f.lastTrashRequest = in
+	return f.trashResp, f.returnErr
+}
+
+func (f *fakeDataClient) RestoreData(ctx context.Context, in *pb.RestoreDataRequest, opts ...grpc.CallOption) (*pb.RestoreDataResponse, error) {
+	f.lastRestoreRequest = in
+	return &pb.RestoreDataResponse{}, f.returnErr
+}
+
+func (f *fakeDataClient) PurgeData(ctx context.Context, in *pb.PurgeDataRequest, opts ...grpc.CallOption) (*pb.PurgeDataResponse, error) {
+	f.lastPurgeRequest = in
+	return &pb.PurgeDataResponse{}, f.returnErr
+}
+
 type fakeAuthClient struct {
 	lastLoginRequest        *pb.LoginRequest
 	loginResp               *pb.LoginResponse
 	lastRegistrationRequest *pb.RegistrationRequest
 	registrationResp        *pb.RegistrationResponse
+	logoutCalled            bool
+	lastVerifyRequest       *pb.VerifyTwoFactorRequest
+	verifyResp              *pb.VerifyTwoFactorResponse
+	twoFactorStatusResp     *pb.GetTwoFactorStatusResponse
+	enrollResp              *pb.EnrollTwoFactorResponse
+	lastConfirmRequest      *pb.ConfirmTwoFactorRequest
+	lastDisableRequest      *pb.DisableTwoFactorRequest
+	recoveryCodes           []string
+	lastChangePassword      *pb.ChangePasswordRequest
+	lastDeleteAccount       *pb.DeleteAccountRequest
 	returnErr               error
 }
 
@@ -83,6 +150,48 @@ func (f *fakeAuthClient) Registration(ctx context.Context, in *pb.RegistrationRe
 	return f.registrationResp, f.returnErr
 }
 
+func (f *fakeAuthClient) Logout(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	f.logoutCalled = true
+	return &pb.LogoutResponse{}, f.returnErr
+}
+
+func (f *fakeAuthClient) VerifyTwoFactor(ctx context.Context, in *pb.VerifyTwoFactorRequest, opts ...grpc.CallOption) (*pb.VerifyTwoFactorResponse, error) {
+	f.lastVerifyRequest = in
+	return f.verifyResp, f.returnErr
+}
+
+func (f *fakeAuthClient) GetTwoFactorStatus(ctx context.Context, in *pb.GetTwoFactorStatusRequest, opts ...grpc.CallOption) (*pb.GetTwoFactorStatusResponse, error) {
+	return f.twoFactorStatusResp, f.returnErr
+}
+
+func (f *fakeAuthClient) EnrollTwoFactor(ctx context.Context, in *pb.EnrollTwoFactorRequest, opts ...grpc.CallOption) (*pb.EnrollTwoFactorResponse, error) {
+	return f.enrollResp, f.returnErr
+}
+
+func (f *fakeAuthClient) ConfirmTwoFactor(ctx context.Context, in *pb.ConfirmTwoFactorRequest, opts ...grpc.CallOption) (*pb.ConfirmTwoFactorResponse, error) {
+	f.lastConfirmRequest = in
+	return &pb.ConfirmTwoFactorResponse{RecoveryCodes: f.recoveryCodes}, f.returnErr
+}
+
+func (f *fakeAuthClient) DisableTwoFactor(ctx context.Context, in *pb.DisableTwoFactorRequest, opts ...grpc.CallOption) (*pb.DisableTwoFactorResponse, error) {
+	f.lastDisableRequest = in
+	return &pb.DisableTwoFactorResponse{}, f.returnErr
+}
+
+func (f *fakeAuthClient) RegenerateRecoveryCodes(ctx context.Context, in *pb.RegenerateRecoveryCodesRequest, opts ...grpc.CallOption) (*pb.RegenerateRecoveryCodesResponse, error) {
+	return &pb.RegenerateRecoveryCodesResponse{RecoveryCodes: f.recoveryCodes}, f.returnErr
+}
+
+func (f *fakeAuthClient) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest, opts ...grpc.CallOption) (*pb.ChangePasswordResponse, error) {
+	f.lastChangePassword = in
+	return &pb.ChangePasswordResponse{}, f.returnErr
+}
+
+func (f *fakeAuthClient) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest, opts ...grpc.CallOption) (*pb.DeleteAccountResponse, error) {
+	f.lastDeleteAccount = in
+	return &pb.DeleteAccountResponse{}, f.returnErr
+}
+
 type fakeSaveDataStream struct {
 	parent *fakeDataClient
 }
@@ -103,7 +212,7 @@ func (s *fakeSaveDataStream) CloseAndRecv() (*pb.SaveDataResponse, error) {
 	if s.parent.saveDataCloseAndRecvErr != nil {
 		return nil, s.parent.saveDataCloseAndRecvErr
 	}
-	return &pb.SaveDataResponse{}, nil
+	return &pb.SaveDataResponse{Id: "new-item"}, nil
 }
 
 func (s *fakeSaveDataStream) RecvMsg(m interface{}) error  { return nil }
@@ -194,6 +303,36 @@ func TestLoadUserData_Success(t *testing.T) {
 	assert.Equal(t, "report.pdf", table.GetCell(2, 2).Text)
 }
 
+func TestLoadMoreUserData_AppendsNextPage(t *testing.T) {
+	table := tview.NewTable()
+	client := &fakeDataClient{
+		getUserDataResp: &pb.GetUserDataListResponse{
+			Items:         []*pb.UserDataItem{{Id: "1", Type: "text", Name: "a"}},
+			NextPageToken: "next",
+		},
+	}
+	dataClient = client
+
+	err := loadUserData(table, "user1", "token1")
+	assert.NoError(t, err)
+	assert.Empty(t, client.lastPageToken)
+	assert.Equal(t, "next", nextPageToken)
+
+	client.getUserDataResp = &pb.GetUserDataListResponse{
+		Items: []*pb.UserDataItem{{Id: "2", Type: "file", Name: "b", Tags: []string{"x", "y"}}},
+	}
+	err = loadMoreUserData(table, "user1", "token1")
+	assert.NoError(t, err)
+	assert.Equal(t, "next", client.lastPageToken)
+	assert.Empty(t, listFilter.GetPageToken())
+	assert.Empty(t, nextPageToken)
+
+	assert.Equal(t, 3, table.GetRowCount())
+	assert.Equal(t, "1", table.GetCell(1, 0).Text)
+	assert.Equal(t, "2", table.GetCell(2, 0).Text)
+	assert.Equal(t, "x, y", table.GetCell(2, 3).Text)
+}
+
 func TestLoadUserData_Error(t *testing.T) {
 	table := tview.NewTable()
 	client := &fakeDataClient{
@@ -795,6 +934,7 @@ func TestShowAddCardDialog_SaveError(t *testing.T) {
 }
 
 func TestShowDataScreen_Success(t *testing.T) {
+	shareClient = &fakeShareClient{userKeysErr: errors.New("unavailable")}
 	app := tview.NewApplication()
 	message := tview.NewTextView()
 
@@ -817,6 +957,7 @@ func TestShowDataScreen_Success(t *testing.T) {
 }
 
 func TestShowDataScreen_LoadError(t *testing.T) {
+	shareClient = &fakeShareClient{userKeysErr: errors.New("unavailable")}
 	app := tview.NewApplication()
 	message := tview.NewTextView()
 
@@ -1006,7 +1147,7 @@ func TestDeleteFile_Success(t *testing.T) {
 	assert.Equal(t, "item42", client.lastDeleteRequest.Id)
 
 	text := message.GetText(true)
-	assert.Contains(t, text, "Delete success!")
+	assert.Contains(t, text, "Moved to trash!")
 }
 
 func TestDeleteFile_Error(t *testing.T) {
@@ -0,0 +1,376 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+	"github.com/fngoc/gault/pkg/vaultfile"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// vaultPageSize размер страницы списка данных при резервном копировании
+const vaultPageSize = 200
+
+var (
+	// errEmptyArchivePassword пароль архива не задан
+	errEmptyArchivePassword = errors.New("archive password is empty")
+	// errArchivePasswordMismatch пароль архива и его повтор не совпадают
+	errArchivePasswordMismatch = errors.New("archive passwords do not match")
+)
+
+// importReport итог восстановления хранилища из архива
+type importReport struct {
+	Imported int
+	// Duplicates пути пропущенных элементов, которые уже есть в хранилище
+	Duplicates []string
+}
+
+// showBackupDialog модальное окно резервного копирования хранилища в зашифрованный архив
+func showBackupDialog(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeBackup := func() {
+		pages.RemovePage("dialog_backup")
+		pages.SwitchToPage("account_screen")
+	}
+
+	pathField := tview.NewInputField().
+		SetLabel("File: ").
+		SetText("gault-backup.gvault").
+		SetFieldWidth(40)
+	passField := tview.NewInputField().
+		SetLabel("Archive password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+	repeatField := tview.NewInputField().
+		SetLabel("Repeat password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(pathField).
+		AddFormItem(passField).
+		AddFormItem(repeatField).
+		AddButton("Backup", func() {
+			count, err := backupVault(userUID, token, pathField.GetText(), passField.GetText(), repeatField.GetText())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Backup error: %v", err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText(fmt.Sprintf("Backed up %d items to %s", count, pathField.GetText()))
+			}
+			closeBackup()
+		}).
+		AddButton("Cancel", closeBackup)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Backup vault ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_backup", dialogForm, true, true)
+	pages.SwitchToPage("dialog_backup")
+	app.SetFocus(dialogForm)
+}
+
+// showRestoreDialog модальное окно восстановления хранилища из зашифрованного архива
+func showRestoreDialog(app *tview.Application, userUID, token string, message *tview.TextView) {
+	closeRestore := func() {
+		pages.RemovePage("dialog_restore")
+		pages.SwitchToPage("account_screen")
+	}
+
+	pathField := tview.NewInputField().
+		SetLabel("File: ").
+		SetText("gault-backup.gvault").
+		SetFieldWidth(40)
+	passField := tview.NewInputField().
+		SetLabel("Archive password: ").
+		SetMaskCharacter('*').
+		SetFieldWidth(40)
+
+	dialogForm := tview.NewForm().
+		AddFormItem(pathField).
+		AddFormItem(passField).
+		AddTextView("", "Items with the same name, type and folder are skipped", 0, 1, true, false).
+		AddButton("Restore", func() {
+			report, err := restoreVault(userUID, token, pathField.GetText(), passField.GetText())
+			if err != nil {
+				message.SetTextColor(tcell.ColorRed).SetText(fmt.Sprintf("Restore error after %d items: %v", report.Imported, err))
+			} else {
+				message.SetTextColor(tcell.ColorGreen).SetText(report.String())
+			}
+			closeRestore()
+		}).
+		AddButton("Cancel", closeRestore)
+
+	dialogForm.SetBorder(true).
+		SetTitle(" Restore vault ").
+		SetTitleAlign(tview.AlignCenter)
+
+	pages.AddPage("dialog_restore", dialogForm, true, true)
+	pages.SwitchToPage("dialog_restore")
+	app.SetFocus(dialogForm)
+}
+
+// String сообщение об итогах восстановления
+func (r importReport) String() string {
+	if len(r.Duplicates) == 0 {
+		return fmt.Sprintf("Restored %d items", r.Imported)
+	}
+	return fmt.Sprintf("Restored %d items, skipped %d duplicates: %s",
+		r.Imported, len(r.Duplicates), strings.Join(r.Duplicates, ", "))
+}
+
+// backupVault запись всех данных личного хранилища в архив, доступный только владельцу.
+// Содержимое получается через GetData, пароли и карты расшифровываются и защищаются паролем архива
+func backupVault(userUID, token, path, password, repeatPassword string) (int, error) {
+	if password == "" {
+		return 0, errEmptyArchivePassword
+	}
+	if password != repeatPassword {
+		return 0, errArchivePasswordMismatch
+	}
+
+	ctx := authContext(userUID, token)
+	items, err := listAllItems(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	folders, err := folderClient.ListFolders(ctx, &pb.ListFoldersRequest{})
+	if err != nil {
+		return 0, err
+	}
+	paths := folderPaths(folders.GetFolders())
+
+	manifest := vaultfile.Manifest{Items: make([]vaultfile.Entry, 0, len(items))}
+	for _, item := range items {
+		manifest.Items = append(manifest.Items, vaultfile.Entry{
+			Name:      item.GetName(),
+			Type:      item.GetType(),
+			Folder:    paths[item.GetFolderId()],
+			Tags:      item.GetTags(),
+			CreatedAt: item.GetCreatedAt(),
+			UpdatedAt: item.GetUpdatedAt(),
+		})
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	if err := writeArchive(ctx, f, password, manifest, items); err != nil {
+		_ = f.Close()
+		_ = os.Remove(path)
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return len(items), nil
+}
+
+// writeArchive запись манифеста и содержимого элементов в архив
+func writeArchive(ctx context.Context, w io.Writer, password string, manifest vaultfile.Manifest, items []*pb.UserDataItem) error {
+	bw := bufio.NewWriter(w)
+	vw, err := vaultfile.NewWriter(bw, password, manifest)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		content, err := itemContent(ctx, item)
+		if err != nil {
+			return err
+		}
+		if err := vw.WriteItem(content); err != nil {
+			return err
+		}
+	}
+	if err := vw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// itemContent расшифрованное содержимое элемента личного хранилища
+func itemContent(ctx context.Context, item *pb.UserDataItem) ([]byte, error) {
+	resp, err := dataClient.GetData(ctx, &pb.GetDataRequest{Id: item.GetId()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %q: %w", item.GetName(), err)
+	}
+	if item.GetType() == "file" {
+		return resp.GetFileData(), nil
+	}
+	if !isEncryptedType(item.GetType()) {
+		return []byte(resp.GetTextData()), nil
+	}
+	plain, err := decryptContent(item.GetName(), resp.GetDataKey(), resp.GetTextData())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plain), nil
+}
+
+// restoreVault восстановление элементов архива в личное хранилище с сохранением имён, типов, папок и тегов.
+// Элементы, которые уже есть в хранилище, пропускаются и попадают в отчёт
+func restoreVault(userUID, token, path, password string) (importReport, error) {
+	var report importReport
+
+	f, err := os.Open(path)
+	if err != nil {
+		return report, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	vr, err := vaultfile.NewReader(bufio.NewReader(f), password)
+	if err != nil {
+		return report, err
+	}
+
+	ctx := authContext(userUID, token)
+	items, err := listAllItems(ctx, nil)
+	if err != nil {
+		return report, err
+	}
+	folders, err := folderClient.ListFolders(ctx, &pb.ListFoldersRequest{})
+	if err != nil {
+		return report, err
+	}
+	paths := folderPaths(folders.GetFolders())
+	folderIDs := make(map[string]string, len(paths))
+	for id, p := range paths {
+		folderIDs[p] = id
+	}
+	existing := make(map[string]bool, len(items))
+	for _, item := range items {
+		existing[duplicateKey(item.GetType(), paths[item.GetFolderId()], item.GetName())] = true
+	}
+
+	for _, entry := range vr.Manifest().Items {
+		content, err := vr.Next()
+		if err != nil {
+			return report, err
+		}
+		key := duplicateKey(entry.Type, entry.Folder, entry.Name)
+		if existing[key] {
+			report.Duplicates = append(report.Duplicates, entry.Folder+"/"+entry.Name)
+			continue
+		}
+		if err := restoreItem(ctx, userUID, entry, content, folderIDs); err != nil {
+			return report, err
+		}
+		existing[key] = true
+		report.Imported++
+	}
+	if _, err := vr.Next(); err != io.EOF {
+		return report, err
+	}
+	return report, nil
+}
+
+// restoreItem создание элемента архива, перенос его в папку и установка тегов
+func restoreItem(ctx context.Context, userUID string, entry vaultfile.Entry, content []byte, folderIDs map[string]string) error {
+	switch entry.Type {
+	case "text", "file":
+	case "password", "card", "totp", "ssh_key":
+		enc, err := utils.Encrypt(string(content), aes)
+		if err != nil {
+			return err
+		}
+		content = []byte(enc)
+	default:
+		return fmt.Errorf("unsupported type %q of %q", entry.Type, entry.Name)
+	}
+
+	folderID, err := ensureFolderPath(ctx, entry.Folder, folderIDs)
+	if err != nil {
+		return err
+	}
+	itemID, err := sendImportedItem(ctx, userUID, entry.Type, entry.Name, content)
+	if err != nil {
+		return fmt.Errorf("failed to save %q: %w", entry.Name, err)
+	}
+	if folderID != "" {
+		if _, err := folderClient.MoveData(ctx, &pb.MoveDataRequest{Id: itemID, FolderId: folderID}); err != nil {
+			return fmt.Errorf("failed to move %q: %w", entry.Name, err)
+		}
+	}
+	if len(entry.Tags) > 0 {
+		if _, err := folderClient.SetDataTags(ctx, &pb.SetDataTagsRequest{Id: itemID, Tags: entry.Tags}); err != nil {
+			return fmt.Errorf("failed to set tags of %q: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// ensureFolderPath идентификатор папки по пути, недостающие папки создаются
+func ensureFolderPath(ctx context.Context, path string, folderIDs map[string]string) (string, error) {
+	parentID, current := "", ""
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		current += "/" + name
+		if id, ok := folderIDs[current]; ok {
+			parentID = id
+			continue
+		}
+		resp, err := folderClient.CreateFolder(ctx, &pb.CreateFolderRequest{ParentId: parentID, Name: name})
+		if err != nil {
+			return "", fmt.Errorf("failed to create folder %s: %w", current, err)
+		}
+		parentID = resp.GetId()
+		folderIDs[current] = parentID
+	}
+	return parentID, nil
+}
+
+// listAllItems все элементы личного хранилища по страницам, filter задаёт фильтры списка
+func listAllItems(ctx context.Context, filter *pb.GetUserDataListRequest) ([]*pb.UserDataItem, error) {
+	req := &pb.GetUserDataListRequest{
+		Type:      filter.GetType(),
+		NameQuery: filter.GetNameQuery(),
+		PageSize:  vaultPageSize,
+	}
+	var items []*pb.UserDataItem
+	for {
+		resp, err := dataClient.GetUserDataList(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, resp.GetItems()...)
+		if resp.GetNextPageToken() == "" {
+			return items, nil
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+}
+
+// folderPaths пути папок вида "/work/bank" по их идентификаторам
+func folderPaths(folders []*pb.Folder) map[string]string {
+	byID := make(map[string]*pb.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.GetId()] = folder
+	}
+
+	paths := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		path := "/" + folder.GetName()
+		// Ограничение глубины защищает от циклов в ответе сервера
+		for parent, depth := byID[folder.GetParentId()], 0; parent != nil && depth < len(folders); parent, depth = byID[parent.GetParentId()], depth+1 {
+			path = "/" + parent.GetName() + path
+		}
+		paths[folder.GetId()] = path
+	}
+	return paths
+}
+
+// duplicateKey ключ поиска одинаковых элементов при восстановлении
+func duplicateKey(dataType, folder, name string) string {
+	return dataType + "\x00" + folder + "\x00" + name
+}
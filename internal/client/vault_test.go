@@ -0,0 +1,155 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	"github.com/fngoc/gault/pkg/utils"
+	"github.com/fngoc/gault/pkg/vaultfile"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testVaultFolders() *fakeFolderClient {
+	return &fakeFolderClient{listResp: &pb.ListFoldersResponse{Folders: []*pb.Folder{
+		{Id: "f1", Name: "work"},
+		{Id: "f2", ParentId: "f1", Name: "bank"},
+	}}}
+}
+
+func writeTestBackup(t *testing.T, path string) {
+	t.Helper()
+	aes = "1234567891234567"
+	enc, err := utils.Encrypt("login:secret", aes)
+	require.NoError(t, err)
+
+	dataClient = &fakeDataClient{
+		getUserDataResp: &pb.GetUserDataListResponse{Items: []*pb.UserDataItem{
+			{Id: "1", Name: "mail", Type: "password", FolderId: "f2", Tags: []string{"email"}, CreatedAt: 10},
+		}},
+		getDataResp: &pb.GetDataResponse{Type: "password", Content: &pb.GetDataResponse_TextData{TextData: enc}},
+	}
+	folderClient = testVaultFolders()
+
+	count, err := backupVault("user1", "token1", path, "archive-pass", "archive-pass")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBackupVault_WritesEncryptedArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.gvault")
+	writeTestBackup(t, path)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(data, []byte("login:secret")))
+
+	vr, err := vaultfile.NewReader(bytes.NewReader(data), "archive-pass")
+	require.NoError(t, err)
+	assert.Equal(t, []vaultfile.Entry{
+		{Name: "mail", Type: "password", Folder: "/work/bank", Tags: []string{"email"}, CreatedAt: 10},
+	}, vr.Manifest().Items)
+	content, err := vr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "login:secret", string(content))
+	_, err = vr.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestBackupVault_InvalidPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.gvault")
+
+	_, err := backupVault("user1", "token1", path, "", "")
+	assert.ErrorIs(t, err, errEmptyArchivePassword)
+	_, err = backupVault("user1", "token1", path, "a", "b")
+	assert.ErrorIs(t, err, errArchivePasswordMismatch)
+	assert.NoFileExists(t, path)
+}
+
+func TestRestoreVault_CreatesItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.gvault")
+	writeTestBackup(t, path)
+
+	client := &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{}}
+	dataClient = client
+	folders := &fakeFolderClient{listResp: &pb.ListFoldersResponse{}}
+	folderClient = folders
+
+	report, err := restoreVault("user1", "token1", path, "archive-pass")
+	require.NoError(t, err)
+	assert.Equal(t, importReport{Imported: 1}, report)
+
+	require.Len(t, client.receivedChunks, 1)
+	assert.Equal(t, "mail", client.receivedChunks[0].Name)
+	assert.Equal(t, "password", client.receivedChunks[0].Type)
+	plain, err := utils.Decrypt(string(client.receivedChunks[0].Data), aes)
+	require.NoError(t, err)
+	assert.Equal(t, "login:secret", plain)
+
+	assert.Equal(t, "bank", folders.lastCreate.Name)
+	assert.Equal(t, "new-folder", folders.lastMoveData.FolderId)
+	assert.Equal(t, "new-item", folders.lastMoveData.Id)
+	assert.Equal(t, []string{"email"}, folders.lastSetDataTags.Tags)
+}
+
+func TestRestoreVault_SkipsDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.gvault")
+	writeTestBackup(t, path)
+
+	client := &fakeDataClient{getUserDataResp: &pb.GetUserDataListResponse{Items: []*pb.UserDataItem{
+		{Id: "1", Name: "mail", Type: "password", FolderId: "f2"},
+	}}}
+	dataClient = client
+	folderClient = testVaultFolders()
+
+	report, err := restoreVault("user1", "token1", path, "archive-pass")
+	require.NoError(t, err)
+	assert.Equal(t, importReport{Duplicates: []string{"/work/bank/mail"}}, report)
+	assert.Equal(t, "Restored 0 items, skipped 1 duplicates: /work/bank/mail", report.String())
+	assert.Empty(t, client.receivedChunks)
+}
+
+func TestRestoreVault_WrongPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.gvault")
+	writeTestBackup(t, path)
+
+	_, err := restoreVault("user1", "token1", path, "other")
+	assert.ErrorIs(t, err, vaultfile.ErrWrongPassword)
+}
+
+func TestEnsureFolderPath(t *testing.T) {
+	folders := &fakeFolderClient{}
+	folderClient = folders
+	folderIDs := map[string]string{"/work": "f1"}
+
+	id, err := ensureFolderPath(authContext("user1", "token1"), "/work/bank", folderIDs)
+	require.NoError(t, err)
+	assert.Equal(t, "new-folder", id)
+	assert.Equal(t, "f1", folders.lastCreate.ParentId)
+	assert.Equal(t, "new-folder", folderIDs["/work/bank"])
+
+	id, err = ensureFolderPath(authContext("user1", "token1"), "", folderIDs)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestShowBackupAndRestoreDialogs(t *testing.T) {
+	pages = tview.NewPages()
+	app := tview.NewApplication()
+	message := tview.NewTextView()
+
+	showBackupDialog(app, "user1", "token1", message)
+	assert.True(t, pages.HasPage("dialog_backup"))
+	showRestoreDialog(app, "user1", "token1", message)
+	assert.True(t, pages.HasPage("dialog_restore"))
+}
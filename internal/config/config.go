@@ -1,19 +1,190 @@
 package config
 
 import (
+	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/fngoc/gault/pkg/logger"
-
-	"github.com/spf13/viper"
 )
 
 // Config структура файла конфигурации
 type Config struct {
+	Host           string         `mapstructure:"host"`
 	Port           int            `mapstructure:"port" default:"8080"`
-	Aes            string         `mapstructure:"aes" default:"00000000000000000000000000000000"`
-	DB             string         `mapstructure:"db" default:"host=localhost user=postgres password=postgres dbname=test_db sslmode=disable"`
+	Aes            string         `mapstructure:"aes" secret:"true"`
+	DB             string         `mapstructure:"db" secret:"true"`
 	AllowEndpoints []EndpointRule `mapstructure:"allowEndpoints"`
+	TLS            TLS            `mapstructure:"tls"`
+	MTLS           MTLS           `mapstructure:"mtls"`
+	PasswordPolicy PasswordPolicy `mapstructure:"passwordPolicy"`
+	Clipboard      Clipboard      `mapstructure:"clipboard"`
+	AutoLock       AutoLock       `mapstructure:"autoLock"`
+	Rotation       Rotation       `mapstructure:"rotation"`
+	Trash          Trash          `mapstructure:"trash"`
+	Shutdown       Shutdown       `mapstructure:"shutdown"`
+	Health         Health         `mapstructure:"health"`
+	Metrics        Metrics        `mapstructure:"metrics"`
+	Tracing        Tracing        `mapstructure:"tracing"`
+	Log            Log            `mapstructure:"log"`
+	// Reflection включает gRPC reflection для grpcurl и подобных инструментов
+	Reflection bool `mapstructure:"reflection"`
+	// KMS адрес сервиса ключей для ссылок kms:, например file:///etc/gault/kms
+	KMS string `mapstructure:"kms"`
+	// Encryption шифрование данных на сервере
+	Encryption Encryption `mapstructure:"encryption"`
+}
+
+// TLS настройки TLS-соединения
+type TLS struct {
+	// CertFile и KeyFile сертификат этой стороны: серверный или клиентский для mTLS
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+	// CAFile корневой сертификат для проверки другой стороны
+	CAFile string `mapstructure:"caFile"`
+	// ServerName имя в сертификате сервера, пустое — адрес из host, для ./gault healthcheck — localhost
+	ServerName string `mapstructure:"serverName"`
+	// MinVersion минимальная версия протокола: 1.2 или 1.3
+	MinVersion string `mapstructure:"minVersion" default:"1.2"`
+	// CipherSuites разрешённые наборы шифров TLS 1.2, пустой список — набор Go по умолчанию
+	CipherSuites []string `mapstructure:"cipherSuites"`
+	// Insecure отключает TLS, только для локальной разработки
+	Insecure bool `mapstructure:"insecure"`
+}
+
+// MTLS настройки проверки клиентских сертификатов сервером
+type MTLS struct {
+	// Mode режим проверки: off, optional (проверяется, если предъявлен) или required
+	Mode string `mapstructure:"mode" default:"off"`
+	// CertAuth разрешает вход по сертификату без токена сессии, логин берётся из CN
+	CertAuth bool `mapstructure:"certAuth"`
+}
+
+// PasswordPolicy требования к паролю учётной записи при регистрации и смене пароля
+type PasswordPolicy struct {
+	// MinLength минимальная длина, 0 — без ограничения
+	MinLength int `mapstructure:"minLength" default:"8"`
+	// MinScore минимальная оценка надёжности: 0 very weak, 1 weak, 2 fair, 3 strong, 4 very strong
+	MinScore int `mapstructure:"minScore" default:"2"`
+}
+
+// Clipboard настройки буфера обмена клиента
+type Clipboard struct {
+	// ClearAfter через сколько стирается скопированный секрет, 0 — через 30 секунд
+	ClearAfter time.Duration `mapstructure:"clearAfter" default:"30s"`
+	// Fallback копирование также через wl-copy, xclip или xsel для терминалов без OSC 52
+	Fallback bool `mapstructure:"fallback"`
+}
+
+// AutoLock настройки блокировки клиента при бездействии
+type AutoLock struct {
+	// Timeout время без нажатий клавиш до блокировки, 0 — 5 минут
+	Timeout time.Duration `mapstructure:"timeout" default:"5m"`
+}
+
+// Rotation настройки планировщика, отмечающего данные с истёкшим сроком действия или периодом смены
+type Rotation struct {
+	// CheckInterval интервал проверки сроков, 0 — раз в час
+	CheckInterval time.Duration `mapstructure:"checkInterval" default:"1h"`
+}
+
+// Trash настройки корзины удалённых данных
+type Trash struct {
+	// Retention сколько данные хранятся в корзине до окончательного удаления, 0 — 30 дней
+	Retention time.Duration `mapstructure:"retention" default:"720h"`
+	// PurgeInterval интервал очистки корзины, 0 — раз в час
+	PurgeInterval time.Duration `mapstructure:"purgeInterval" default:"1h"`
+}
+
+// Shutdown настройки остановки сервера по SIGTERM или SIGINT
+type Shutdown struct {
+	// DrainTimeout сколько ждать завершения текущих запросов перед принудительной остановкой, 0 — 30 секунд
+	DrainTimeout time.Duration `mapstructure:"drainTimeout" default:"30s"`
+}
+
+// Health настройки проверки готовности сервера
+type Health struct {
+	// CheckInterval интервал проверки соединения с базой данных, 0 — раз в 10 секунд
+	CheckInterval time.Duration `mapstructure:"checkInterval" default:"10s"`
+	// CertFile и KeyFile клиентский сертификат ./gault healthcheck, обязателен при mtls.mode: required
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+}
+
+// Metrics настройки экспорта метрик Prometheus
+type Metrics struct {
+	// Addr адрес HTTP-сервера с /metrics, пусто — метрики не публикуются. Эндпоинт без аутентификации,
+	// поэтому по умолчанию выключен
+	Addr string `mapstructure:"addr"`
+}
+
+// Tracing настройки трассировки OpenTelemetry
+type Tracing struct {
+	// Endpoint адрес OTLP gRPC коллектора, например otel-collector:4317, пусто — трассировка выключена
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure отправка спанов коллектору без TLS
+	Insecure bool `mapstructure:"insecure"`
+	// SampleRatio доля трассируемых запросов от 0 до 1, 0 — все запросы
+	SampleRatio float64 `mapstructure:"sampleRatio" default:"1"`
+}
+
+// Encryption настройки шифрования содержимого данных на сервере: каждая запись шифруется
+// своим ключом данных, который хранится в user_data обёрнутым мастер-ключом. Записи, сохранённые
+// до включения шифрования, остаются открытыми до следующего изменения, keys rotate их не шифрует
+type Encryption struct {
+	// KeyID идентификатор мастер-ключа для новых данных, пусто — шифрование выключено
+	KeyID string `mapstructure:"keyId"`
+	// Keys мастер-ключи по идентификатору: 64 hex-символа или ссылка file:, env:, kms:.
+	// Прежний ключ нужен, пока server keys rotate не перешифрует обёрнутые им ключи данных
+	Keys map[string]string `mapstructure:"keys" secret:"true"`
+}
+
+// MasterKeys мастер-ключи в байтах по идентификатору
+func (e Encryption) MasterKeys() (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(e.Keys))
+	for id, value := range e.Keys {
+		key, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not hex encoded", id)
+		}
+		if len(key) != masterKeyLength {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, masterKeyLength, len(key))
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// Log настройки логирования
+type Log struct {
+	// Level уровень логирования: debug, info, warn или error
+	Level string `mapstructure:"level" default:"info"`
+	// Format формат записей: json или console
+	Format string `mapstructure:"format" default:"json"`
+	// Output путь к файлу логов, пусто — stderr
+	Output string `mapstructure:"output"`
+	// Sampling ограничение числа одинаковых записей в секунду
+	Sampling LogSampling `mapstructure:"sampling"`
+}
+
+// LogSampling настройки выборки записей лога
+type LogSampling struct {
+	// Initial сколько одинаковых записей в секунду пишется полностью, 0 — без выборки
+	Initial int `mapstructure:"initial" default:"100"`
+	// Thereafter после превышения Initial пишется каждая N-я запись
+	Thereafter int `mapstructure:"thereafter" default:"100"`
+}
+
+// Options настройки логера из конфигурации
+func (l Log) Options() logger.Options {
+	return logger.Options{
+		Level:              l.Level,
+		Format:             l.Format,
+		Output:             l.Output,
+		SamplingInitial:    l.Sampling.Initial,
+		SamplingThereafter: l.Sampling.Thereafter,
+	}
 }
 
 // EndpointRule доступность ручек
@@ -22,30 +193,36 @@ type EndpointRule struct {
 	Allowed bool   `mapstructure:"allowed"`
 }
 
-// ParseConfig парсинг конфигурации
-func ParseConfig(nameConfig string) (Config, error) {
-	var conf Config
-	viper.SetConfigName(nameConfig)
-	viper.SetConfigType("yml")
-	viper.AddConfigPath(".")
-
-	if err := viper.ReadInConfig(); err != nil {
-		logger.LogInfo("config not found, using defaults port [8080], DB config and allow Login/Registration endpoints")
-		return Config{
-			Port: 8080,
-			Aes:  "00000000000000000000000000000000",
-			DB:   "host=localhost user=postgres password=postgres dbname=test_db sslmode=disable",
-			AllowEndpoints: []EndpointRule{
-				{Path: "/api.proto.v1.AuthV1Service/Login", Allowed: true},
-				{Path: "/api.proto.v1.AuthV1Service/Registration", Allowed: true},
-			},
-		}, nil
+// Version минимальная версия TLS, по умолчанию 1.2
+func (t TLS) Version() (uint16, error) {
+	switch t.MinVersion {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min version %q", t.MinVersion)
+	}
+}
+
+// Ciphers идентификаторы наборов шифров. Допускаются только наборы, которые Go считает безопасными
+func (t TLS) Ciphers() ([]uint16, error) {
+	if len(t.CipherSuites) == 0 {
+		return nil, nil
 	}
 
-	if err := viper.Unmarshal(&conf); err != nil {
-		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
 	}
 
-	logger.LogInfo("loaded config")
-	return conf, nil
+	ids := make([]uint16, 0, len(t.CipherSuites))
+	for _, name := range t.CipherSuites {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
@@ -1,16 +1,40 @@
 package config
 
 import (
+	"crypto/tls"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/fngoc/gault/pkg/logger"
 
-	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestParseConfig_FileNotFound(t *testing.T) {
-	_, err := ParseConfig("non_existing_config")
+	conf, err := ParseConfig("non_existing_config")
 	assert.Nil(t, err)
+	assert.Equal(t, 8080, conf.Port)
+	assert.Empty(t, conf.Aes)
+	assert.Empty(t, conf.DB)
+	assert.Equal(t, []EndpointRule{
+		{Path: "/api.proto.v1.AuthV1Service/Login", Allowed: true},
+		{Path: "/api.proto.v1.AuthV1Service/Registration", Allowed: true},
+		{Path: "/api.proto.v1.AuthV1Service/VerifyTwoFactor", Allowed: true},
+	}, conf.AllowEndpoints)
+	assert.Equal(t, TLS{MinVersion: "1.2"}, conf.TLS)
+	assert.Equal(t, MTLS{Mode: "off"}, conf.MTLS)
+	assert.Equal(t, PasswordPolicy{MinLength: 8, MinScore: 2}, conf.PasswordPolicy)
+	assert.Equal(t, Clipboard{ClearAfter: 30 * time.Second}, conf.Clipboard)
+	assert.Equal(t, AutoLock{Timeout: 5 * time.Minute}, conf.AutoLock)
+	assert.Equal(t, Rotation{CheckInterval: time.Hour}, conf.Rotation)
+	assert.Equal(t, Trash{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour}, conf.Trash)
+	assert.Equal(t, Shutdown{DrainTimeout: 30 * time.Second}, conf.Shutdown)
+	assert.Equal(t, Health{CheckInterval: 10 * time.Second}, conf.Health)
+	assert.False(t, conf.Reflection)
+	assert.Equal(t, Metrics{}, conf.Metrics)
+	assert.Equal(t, Tracing{SampleRatio: 1}, conf.Tracing)
+	assert.Equal(t, Log{Level: "info", Format: "json", Sampling: LogSampling{Initial: 100, Thereafter: 100}}, conf.Log)
 }
 
 func TestParseConfig_UnmarshalFailure(t *testing.T) {
@@ -27,8 +51,6 @@ port:
 	assert.NoError(t, err)
 	tmpFile.Close()
 
-	viper.Reset()
-
 	_, err = ParseConfig("bad_config")
 	assert.Error(t, err)
 }
@@ -44,16 +66,92 @@ db: "host=localhost user=postgres password=postgres dbname=test_db sslmode=disab
 allowEndpoints:
   - path: "/ping"
     allowed: true
+mtls:
+  mode: "required"
+  certAuth: true
+passwordPolicy:
+  minLength: 12
+  minScore: 3
+clipboard:
+  clearAfter: "45s"
+  fallback: true
+autoLock:
+  timeout: "2m"
+rotation:
+  checkInterval: "15m"
+trash:
+  retention: "168h"
+  purgeInterval: "10m"
+shutdown:
+  drainTimeout: "5s"
+health:
+  checkInterval: "3s"
+reflection: true
+metrics:
+  addr: "127.0.0.1:9100"
+tracing:
+  endpoint: "otel-collector:4317"
+  insecure: true
+  sampleRatio: 0.25
+log:
+  level: "debug"
+  format: "console"
+  output: "/var/log/gault.log"
+  sampling:
+    initial: 10
+    thereafter: 50
 `
 	_, err = tmpFile.WriteString(content)
 	assert.NoError(t, err)
 	tmpFile.Close()
 
-	viper.Reset()
-
 	conf, err := ParseConfig("good_config")
 	assert.NoError(t, err)
 	assert.Equal(t, 9090, conf.Port)
 	assert.Equal(t, "host=localhost user=postgres password=postgres dbname=test_db sslmode=disable", conf.DB)
 	assert.Len(t, conf.AllowEndpoints, 1)
+	assert.Equal(t, MTLS{Mode: "required", CertAuth: true}, conf.MTLS)
+	assert.Equal(t, PasswordPolicy{MinLength: 12, MinScore: 3}, conf.PasswordPolicy)
+	assert.Equal(t, Clipboard{ClearAfter: 45 * time.Second, Fallback: true}, conf.Clipboard)
+	assert.Equal(t, AutoLock{Timeout: 2 * time.Minute}, conf.AutoLock)
+	assert.Equal(t, Rotation{CheckInterval: 15 * time.Minute}, conf.Rotation)
+	assert.Equal(t, Trash{Retention: 7 * 24 * time.Hour, PurgeInterval: 10 * time.Minute}, conf.Trash)
+	assert.Equal(t, Shutdown{DrainTimeout: 5 * time.Second}, conf.Shutdown)
+	assert.Equal(t, Health{CheckInterval: 3 * time.Second}, conf.Health)
+	assert.True(t, conf.Reflection)
+	assert.Equal(t, Metrics{Addr: "127.0.0.1:9100"}, conf.Metrics)
+	assert.Equal(t, Tracing{Endpoint: "otel-collector:4317", Insecure: true, SampleRatio: 0.25}, conf.Tracing)
+	assert.Equal(t, logger.Options{
+		Level:              "debug",
+		Format:             "console",
+		Output:             "/var/log/gault.log",
+		SamplingInitial:    10,
+		SamplingThereafter: 50,
+	}, conf.Log.Options())
+}
+
+func TestTLS_Version(t *testing.T) {
+	version, err := TLS{}.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = TLS{MinVersion: "1.3"}.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+
+	_, err = TLS{MinVersion: "1.1"}.Version()
+	assert.Error(t, err)
+}
+
+func TestTLS_Ciphers(t *testing.T) {
+	ciphers, err := TLS{}.Ciphers()
+	assert.NoError(t, err)
+	assert.Nil(t, ciphers)
+
+	ciphers, err = TLS{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}.Ciphers()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, ciphers)
+
+	_, err = TLS{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}.Ciphers()
+	assert.Error(t, err)
 }
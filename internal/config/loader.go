@@ -0,0 +1,184 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fngoc/gault/pkg/logger"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// envPrefix префикс переменных окружения: tls.certFile задаётся через GAULT_TLS_CERTFILE
+const envPrefix = "GAULT"
+
+// configEnv переменная окружения с путём к файлу конфигурации
+const configEnv = envPrefix + "_CONFIG"
+
+// defaultAllowEndpoints ручки без аутентификации, если allowEndpoints не задан
+var defaultAllowEndpoints = []map[string]interface{}{
+	{"path": "/api.proto.v1.AuthV1Service/Login", "allowed": true},
+	{"path": "/api.proto.v1.AuthV1Service/Registration", "allowed": true},
+	{"path": "/api.proto.v1.AuthV1Service/VerifyTwoFactor", "allowed": true},
+}
+
+// durationType тип time.Duration, у которого тот же Kind, что и у int64
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setting параметр конфигурации, доступный через файл, окружение и флаг
+type setting struct {
+	// key путь в YAML через точку, например tls.certFile
+	key string
+	// def значение из тега default
+	def  string
+	kind reflect.Type
+}
+
+// Loader загрузчик конфигурации по слоям: значения из тегов default, YAML-файл,
+// переменные окружения GAULT_* и флаги командной строки, каждый следующий слой важнее
+type Loader struct {
+	name     string
+	path     *string
+	flags    *flag.FlagSet
+	settings []setting
+}
+
+// NewLoader регистрирует в fs флаг -config и флаг для каждого параметра, например -log.level.
+// name имя файла конфигурации без расширения в текущем каталоге, если -config не задан
+func NewLoader(name string, fs *flag.FlagSet) *Loader {
+	l := &Loader{
+		name:     name,
+		flags:    fs,
+		settings: collectSettings(reflect.TypeOf(Config{}), ""),
+	}
+	l.path = fs.String("config", "", "path to the YAML config file (env "+configEnv+")")
+	for _, s := range l.settings {
+		s.register(fs)
+	}
+	return l
+}
+
+// Load собирает конфигурацию и раскрывает ссылки на секреты. Флаги fs должны быть разобраны
+// до вызова. Отсутствие файла по имени допустимо, явно заданный -config обязан существовать
+func (l *Loader) Load() (Config, error) {
+	v := viper.New()
+	for _, s := range l.settings {
+		v.SetDefault(s.key, s.defaultValue())
+	}
+	v.SetDefault("allowEndpoints", defaultAllowEndpoints)
+
+	path := *l.path
+	if path == "" {
+		path = os.Getenv(configEnv)
+	}
+	if path != "" {
+		v.SetConfigFile(path)
+	} else {
+		v.SetConfigName(l.name)
+		v.SetConfigType("yml")
+		v.AddConfigPath(".")
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return Config{}, fmt.Errorf("failed to read config: %w", err)
+		}
+		logger.LogInfo("config file not found, using defaults, environment and flags", zap.String("name", l.name))
+	} else {
+		logger.LogInfo("loaded config", zap.String("file", v.ConfigFileUsed()))
+	}
+
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	l.flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			v.Set(f.Name, f.Value.String())
+		}
+	})
+
+	var conf Config
+	if err := v.Unmarshal(&conf); err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := resolveSecrets(context.Background(), &conf); err != nil {
+		return Config{}, err
+	}
+	return conf, nil
+}
+
+// ParseConfig парсинг конфигурации из файла nameConfig в текущем каталоге и переменных окружения
+func ParseConfig(nameConfig string) (Config, error) {
+	return NewLoader(nameConfig, flag.NewFlagSet(nameConfig, flag.ContinueOnError)).Load()
+}
+
+// collectSettings параметры структуры конфигурации с путями по тегам mapstructure.
+// Списки структур, например allowEndpoints, и словари, например encryption.keys, задаются только в файле
+func collectSettings(t reflect.Type, prefix string) []setting {
+	var settings []setting
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			settings = append(settings, collectSettings(field.Type, key+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.String,
+			field.Type.Kind() == reflect.Map:
+			continue
+		default:
+			settings = append(settings, setting{key: key, def: field.Tag.Get("default"), kind: field.Type})
+		}
+	}
+	return settings
+}
+
+// defaultValue значение из тега default или нулевое значение типа поля
+func (s setting) defaultValue() interface{} {
+	if s.def == "" {
+		return reflect.Zero(s.kind).Interface()
+	}
+	return s.def
+}
+
+// envName имя переменной окружения параметра
+func (s setting) envName() string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// register флаг параметра с типом поля, чтобы неверное значение отклонялось при разборе
+func (s setting) register(fs *flag.FlagSet) {
+	usage := "overrides " + s.key + " (env " + s.envName() + ")"
+	switch {
+	case s.kind == durationType:
+		def, _ := time.ParseDuration(s.def)
+		fs.Duration(s.key, def, usage)
+	case s.kind.Kind() == reflect.Int:
+		def, _ := strconv.Atoi(s.def)
+		fs.Int(s.key, def, usage)
+	case s.kind.Kind() == reflect.Bool:
+		def, _ := strconv.ParseBool(s.def)
+		fs.Bool(s.key, def, usage)
+	case s.kind.Kind() == reflect.Float64:
+		def, _ := strconv.ParseFloat(s.def, 64)
+		fs.Float64(s.key, def, usage)
+	case s.kind.Kind() == reflect.Slice:
+		fs.String(s.key, s.def, usage+", comma separated")
+	default:
+		fs.String(s.key, s.def, usage)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig файл конфигурации во временном каталоге
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gault.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// load загрузка конфигурации с аргументами командной строки
+func load(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewLoader("non_existing_config", fs)
+	require.NoError(t, fs.Parse(args))
+	return loader.Load()
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	path := writeConfig(t, `
+port: 9000
+db: "postgres://file"
+log:
+  level: "warn"
+trash:
+  retention: "48h"
+tls:
+  cipherSuites: ["TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"]
+`)
+	t.Setenv("GAULT_PORT", "9100")
+	t.Setenv("GAULT_LOG_LEVEL", "error")
+	t.Setenv("GAULT_TLS_CIPHERSUITES", "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+
+	conf, err := load(t, "-config", path, "-port", "9200", "-trash.retention", "1h", "-reflection")
+	require.NoError(t, err)
+
+	assert.Equal(t, 9200, conf.Port, "flag overrides env and file")
+	assert.Equal(t, "error", conf.Log.Level, "env overrides file")
+	assert.Equal(t, "postgres://file", conf.DB, "file overrides defaults")
+	assert.Equal(t, "json", conf.Log.Format, "default from tag")
+	assert.Equal(t, time.Hour, conf.Trash.Retention)
+	assert.Equal(t, time.Hour, conf.Trash.PurgeInterval)
+	assert.True(t, conf.Reflection)
+	assert.Equal(t, []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}, conf.TLS.CipherSuites)
+}
+
+func TestLoader_ConfigFromEnv(t *testing.T) {
+	t.Setenv("GAULT_CONFIG", writeConfig(t, `aes: "from-env-config"`))
+
+	conf, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env-config", conf.Aes)
+}
+
+func TestLoader_ExplicitConfigMissing(t *testing.T) {
+	_, err := load(t, "-config", filepath.Join(t.TempDir(), "missing.yml"))
+	assert.ErrorContains(t, err, "failed to read config")
+}
+
+func TestLoader_InvalidFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	NewLoader("non_existing_config", fs)
+	assert.Error(t, fs.Parse([]string{"-port", "not-a-number"}))
+	assert.Error(t, fs.Parse([]string{"-shutdown.drainTimeout", "soon"}))
+}
+
+func TestCollectSettings(t *testing.T) {
+	keys := make(map[string]string)
+	for _, s := range collectSettings(reflect.TypeOf(Config{}), "") {
+		keys[s.key] = s.envName()
+	}
+	assert.Equal(t, "GAULT_TLS_CERTFILE", keys["tls.certFile"])
+	assert.Equal(t, "GAULT_LOG_SAMPLING_INITIAL", keys["log.sampling.initial"])
+	assert.Equal(t, "GAULT_DB", keys["db"])
+	assert.NotContains(t, keys, "allowEndpoints")
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/fngoc/gault/pkg/logger"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted значение скрытого секрета в выводе конфигурации
+const redacted = "[REDACTED]"
+
+// Print выводит действующую конфигурацию в YAML в порядке полей, ключ AES, мастер-ключи
+// и пароль в строке подключения к базе данных скрываются
+func Print(w io.Writer, conf Config) error {
+	conf.Aes = redactValue(conf.Aes)
+	conf.DB = redactDSN(conf.DB)
+	keys := make(map[string]string, len(conf.Encryption.Keys))
+	for id, value := range conf.Encryption.Keys {
+		keys[id] = redactValue(value)
+	}
+	conf.Encryption.Keys = keys
+
+	node, err := toNode(reflect.ValueOf(conf))
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// toNode узел YAML со структурами по тегам mapstructure и интервалами в виде строк
+func toNode(v reflect.Value) (*yaml.Node, error) {
+	switch {
+	case v.Type() == durationType:
+		return scalarNode(v.Interface().(fmt.Stringer).String())
+	case v.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("mapstructure")
+			if name == "" {
+				continue
+			}
+			value, err := toNode(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+		}
+		return node, nil
+	case v.Kind() == reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < v.Len(); i++ {
+			item, err := toNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if item.Kind == yaml.MappingNode {
+				node.Style = 0
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+	default:
+		return scalarNode(v.Interface())
+	}
+}
+
+// scalarNode узел YAML для простого значения
+func scalarNode(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// redactValue скрывает непустое значение
+func redactValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// redactDSN строка подключения без пароля, в форме URL или key=value
+func redactDSN(dsn string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			return u.Redacted()
+		}
+	}
+	return logger.Redact(dsn)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrint(t *testing.T) {
+	conf := Config{
+		Port:           8080,
+		Aes:            "k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2",
+		DB:             "postgres://gault:s3cret@db:5432/gault?sslmode=disable",
+		AllowEndpoints: []EndpointRule{{Path: "/ping", Allowed: true}},
+		TLS:            TLS{MinVersion: "1.3", CipherSuites: []string{"A", "B"}},
+		Trash:          Trash{Retention: 720 * time.Hour},
+		Log:            Log{Level: "debug"},
+		Encryption:     Encryption{KeyID: "k1", Keys: map[string]string{"k1": strings.Repeat("ab", 32)}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Print(&buf, conf))
+	out := buf.String()
+
+	assert.NotContains(t, out, "s3cret")
+	assert.NotContains(t, out, conf.Aes)
+	assert.Contains(t, out, "aes: '[REDACTED]'")
+	assert.Contains(t, out, "db: postgres://gault:xxxxx@db:5432/gault?sslmode=disable")
+	assert.Contains(t, out, "allowEndpoints:\n  - path: /ping\n    allowed: true\n")
+	assert.Contains(t, out, "  cipherSuites: [A, B]\n")
+	assert.Contains(t, out, "  retention: 720h0m0s\n")
+	assert.Contains(t, out, "log:\n  level: debug\n")
+	assert.Contains(t, out, "encryption:\n  keyId: k1\n  keys:\n    k1: '[REDACTED]'\n")
+	assert.Equal(t, strings.Repeat("ab", 32), conf.Encryption.Keys["k1"], "caller's config is not modified")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("port:")), bytes.Index(buf.Bytes(), []byte("tls:")))
+}
+
+func TestRedactDSN(t *testing.T) {
+	assert.Equal(t, "host=db user=gault password=[REDACTED] dbname=gault",
+		redactDSN("host=db user=gault password=hunter2 dbname=gault"))
+	assert.Equal(t, "postgres://gault@db/gault", redactDSN("postgres://gault@db/gault"))
+	assert.Empty(t, redactValue(""))
+}
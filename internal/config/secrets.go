@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fngoc/gault/pkg/logger"
+	"github.com/fngoc/gault/pkg/secrets"
+
+	"go.uber.org/zap"
+)
+
+// resolveSecrets раскрывает ссылки file:, env: и kms: в полях с тегом secret:"true"
+func resolveSecrets(ctx context.Context, conf *Config) error {
+	var resolver secrets.Resolver
+	if conf.KMS != "" {
+		kms, err := secrets.OpenKMS(conf.KMS)
+		if err != nil {
+			return fmt.Errorf("kms: %w", err)
+		}
+		resolver.KMS = kms
+	}
+
+	return resolveStruct(ctx, resolver, reflect.ValueOf(conf).Elem(), "")
+}
+
+// resolveStruct раскрывает ссылки в строках и значениях словарей с тегом secret:"true",
+// вложенные структуры обходятся рекурсивно
+func resolveStruct(ctx context.Context, resolver secrets.Resolver, v reflect.Value, prefix string) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			if err := resolveStruct(ctx, resolver, v.Field(i), key+"."); err != nil {
+				return err
+			}
+		case field.Tag.Get("secret") != "true":
+			continue
+		case field.Type.Kind() == reflect.String:
+			resolved, err := resolveValue(ctx, resolver, key, v.Field(i).String())
+			if err != nil {
+				return err
+			}
+			v.Field(i).SetString(resolved)
+		case field.Type.Kind() == reflect.Map && field.Type.Elem().Kind() == reflect.String:
+			m := v.Field(i)
+			for _, k := range m.MapKeys() {
+				resolved, err := resolveValue(ctx, resolver, key+"."+k.String(), m.MapIndex(k).String())
+				if err != nil {
+					return err
+				}
+				m.SetMapIndex(k, reflect.ValueOf(resolved))
+			}
+		}
+	}
+	return nil
+}
+
+// resolveValue значение ссылки или само значение, если это не ссылка
+func resolveValue(ctx context.Context, resolver secrets.Resolver, key, value string) (string, error) {
+	if !secrets.IsReference(value) {
+		return value, nil
+	}
+	resolved, err := resolver.Resolve(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to resolve secret: %w", key, err)
+	}
+	source, _, _ := strings.Cut(value, ":")
+	logger.LogInfo("secret resolved", zap.String("setting", key), zap.String("source", source))
+	return resolved, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fngoc/gault/pkg/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_ResolvesSecrets(t *testing.T) {
+	dir := t.TempDir()
+	kmsDir := filepath.Join(dir, "kms")
+	require.NoError(t, os.Mkdir(kmsDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(kmsDir, "client"), []byte(strings.Repeat("ab", 32)), 0o600))
+	kms, err := secrets.NewLocalKMS(kmsDir)
+	require.NoError(t, err)
+	ciphertext, err := kms.Encrypt(context.Background(), "client", []byte("k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2"))
+	require.NoError(t, err)
+
+	dsnFile := filepath.Join(dir, "db_dsn")
+	require.NoError(t, os.WriteFile(dsnFile, []byte("postgres://gault:rotated@db/gault\n"), 0o600))
+
+	t.Setenv("GAULT_KMS", "file://"+kmsDir)
+	t.Setenv("GAULT_DB", "file:"+dsnFile)
+	conf, err := load(t, "-aes", secrets.Reference("client", ciphertext))
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://gault:rotated@db/gault", conf.DB)
+	assert.Equal(t, "k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2", conf.Aes)
+
+	// Смена секрета без правки конфигурации: новый файл подхватывается при следующей загрузке
+	require.NoError(t, os.WriteFile(dsnFile, []byte("postgres://gault:next@db/gault\n"), 0o600))
+	conf, err = load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://gault:next@db/gault", conf.DB)
+}
+
+func TestLoader_ResolvesMasterKeys(t *testing.T) {
+	current := strings.Repeat("1f", 32)
+	path := writeConfig(t, `
+encryption:
+  keyId: "k2025"
+  keys:
+    k2024: "`+strings.Repeat("0e", 32)+`"
+    k2025: "env:GAULT_TEST_MASTER_KEY"
+`)
+	t.Setenv("GAULT_TEST_MASTER_KEY", current)
+
+	conf, err := load(t, "-config", path)
+	require.NoError(t, err)
+	assert.Equal(t, "k2025", conf.Encryption.KeyID)
+	assert.Equal(t, map[string]string{"k2024": strings.Repeat("0e", 32), "k2025": current}, conf.Encryption.Keys)
+
+	t.Setenv("GAULT_ENCRYPTION_KEYID", "k2024")
+	conf, err = load(t, "-config", path)
+	require.NoError(t, err)
+	assert.Equal(t, "k2024", conf.Encryption.KeyID)
+}
+
+func TestLoader_SecretErrors(t *testing.T) {
+	t.Setenv("GAULT_DB", "env:GAULT_TEST_MISSING_DSN")
+	_, err := load(t)
+	assert.ErrorContains(t, err, "db: failed to resolve secret")
+
+	t.Setenv("GAULT_DB", "host=db")
+	t.Setenv("GAULT_AES", "kms:client:AAAA")
+	_, err = load(t)
+	assert.ErrorContains(t, err, "aes: failed to resolve secret: kms is not configured")
+
+	t.Setenv("GAULT_KMS", "vault://gault")
+	_, err = load(t)
+	assert.ErrorContains(t, err, "kms: unknown kms provider")
+
+	t.Setenv("GAULT_AES", "")
+	t.Setenv("GAULT_KMS", "")
+	path := writeConfig(t, "encryption:\n  keys:\n    k1: \"env:GAULT_TEST_MISSING_KEY\"\n")
+	_, err = load(t, "-config", path)
+	assert.ErrorContains(t, err, "encryption.keys.k1: failed to resolve secret")
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// aesKeyLength длина ключа AES-256 клиента в байтах
+const aesKeyLength = 32
+
+// masterKeyLength длина мастер-ключа шифрования на сервере в байтах, AES-256
+const masterKeyLength = 32
+
+// validKeyID идентификатор мастер-ключа. Только строчные буквы: viper приводит ключи словарей
+// к нижнему регистру
+var validKeyID = regexp.MustCompile(`^[a-z0-9._-]{1,64}$`)
+
+// minKeyDistinct минимальное число различных символов в ключе, ключи вида 000…0 отклоняются
+const minKeyDistinct = 8
+
+// ValidateServer проверка настроек сервера перед запуском, возвращает все найденные ошибки
+func (c Config) ValidateServer() error {
+	errs := c.validateCommon()
+	if strings.TrimSpace(c.DB) == "" {
+		errs = append(errs, errors.New("db: connection string is required (env GAULT_DB)"))
+	}
+	switch c.MTLS.Mode {
+	case "", "off", "optional", "required":
+	default:
+		errs = append(errs, fmt.Errorf("mtls.mode: unsupported mode %q", c.MTLS.Mode))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sampleRatio: %v is out of range [0, 1]", c.Tracing.SampleRatio))
+	}
+	if c.PasswordPolicy.MinScore < 0 || c.PasswordPolicy.MinScore > 4 {
+		errs = append(errs, fmt.Errorf("passwordPolicy.minScore: %d is out of range [0, 4]", c.PasswordPolicy.MinScore))
+	}
+	errs = append(errs, c.Encryption.validate()...)
+	errs = append(errs, nonNegative(
+		namedDuration{"rotation.checkInterval", c.Rotation.CheckInterval},
+		namedDuration{"trash.retention", c.Trash.Retention},
+		namedDuration{"trash.purgeInterval", c.Trash.PurgeInterval},
+		namedDuration{"shutdown.drainTimeout", c.Shutdown.DrainTimeout},
+		namedDuration{"health.checkInterval", c.Health.CheckInterval},
+	)...)
+	return errors.Join(errs...)
+}
+
+// ValidateClient проверка настроек клиента перед запуском, возвращает все найденные ошибки
+func (c Config) ValidateClient() error {
+	errs := c.validateCommon()
+	if err := validateAESKey(c.Aes); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, nonNegative(
+		namedDuration{"clipboard.clearAfter", c.Clipboard.ClearAfter},
+		namedDuration{"autoLock.timeout", c.AutoLock.Timeout},
+	)...)
+	return errors.Join(errs...)
+}
+
+// validateCommon проверки, общие для сервера и клиента
+func (c Config) validateCommon() []error {
+	var errs []error
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port: %d is out of range [1, 65535]", c.Port))
+	}
+	if _, err := c.TLS.Version(); err != nil {
+		errs = append(errs, fmt.Errorf("tls.minVersion: %w", err))
+	}
+	if _, err := c.TLS.Ciphers(); err != nil {
+		errs = append(errs, fmt.Errorf("tls.cipherSuites: %w", err))
+	}
+	if err := c.Log.Options().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("log: %w", err))
+	}
+	return errs
+}
+
+// validateAESKey ключ должен быть длиной 32 байта и не состоять из повторов одного-двух символов
+func validateAESKey(key string) error {
+	if key == "" {
+		return errors.New("aes: encryption key is required (env GAULT_AES)")
+	}
+	if len(key) != aesKeyLength {
+		return fmt.Errorf("aes: key must be %d bytes, got %d", aesKeyLength, len(key))
+	}
+	distinct := make(map[rune]struct{})
+	for _, r := range key {
+		distinct[r] = struct{}{}
+	}
+	if len(distinct) < minKeyDistinct {
+		return errors.New("aes: key is too weak, generate one with `openssl rand -hex 16`")
+	}
+	return nil
+}
+
+// validate мастер-ключ для новых данных задан и все ключи декодируются
+func (e Encryption) validate() []error {
+	if e.KeyID == "" {
+		if len(e.Keys) > 0 {
+			return []error{errors.New("encryption.keyId: required when encryption.keys are set")}
+		}
+		return nil
+	}
+
+	var errs []error
+	if !validKeyID.MatchString(e.KeyID) {
+		errs = append(errs, fmt.Errorf("encryption.keyId: %q must match %s", e.KeyID, validKeyID))
+	}
+	if _, ok := e.Keys[e.KeyID]; !ok {
+		errs = append(errs, fmt.Errorf("encryption.keys: no key for keyId %q", e.KeyID))
+	}
+	if _, err := e.MasterKeys(); err != nil {
+		errs = append(errs, fmt.Errorf("encryption.keys: %w", err))
+	}
+	return errs
+}
+
+// namedDuration интервал с путём параметра для сообщения об ошибке
+type namedDuration struct {
+	key   string
+	value time.Duration
+}
+
+// nonNegative ошибки для отрицательных интервалов
+func nonNegative(durations ...namedDuration) []error {
+	var errs []error
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", d.key, d.value))
+		}
+	}
+	return errs
+}
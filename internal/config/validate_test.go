@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validServer минимальная корректная конфигурация сервера
+func validServer() Config {
+	return Config{
+		Port:    8080,
+		DB:      "host=db user=gault dbname=gault",
+		MTLS:    MTLS{Mode: "off"},
+		Tracing: Tracing{SampleRatio: 1},
+		Log:     Log{Level: "info", Format: "json"},
+	}
+}
+
+// masterKey корректный мастер-ключ шифрования на сервере
+var masterKey = strings.Repeat("ab", 32)
+
+func TestValidateServer(t *testing.T) {
+	assert.NoError(t, validServer().ValidateServer())
+
+	encrypted := validServer()
+	encrypted.Encryption = Encryption{KeyID: "k2", Keys: map[string]string{"k1": masterKey, "k2": masterKey}}
+	assert.NoError(t, encrypted.ValidateServer())
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"missing db", func(c *Config) { c.DB = " " }, "db: connection string is required"},
+		{"port", func(c *Config) { c.Port = 70000 }, "port: 70000 is out of range"},
+		{"mtls mode", func(c *Config) { c.MTLS.Mode = "sometimes" }, `mtls.mode: unsupported mode "sometimes"`},
+		{"sample ratio", func(c *Config) { c.Tracing.SampleRatio = 2 }, "tracing.sampleRatio"},
+		{"min score", func(c *Config) { c.PasswordPolicy.MinScore = 5 }, "passwordPolicy.minScore"},
+		{"tls version", func(c *Config) { c.TLS.MinVersion = "1.1" }, "tls.minVersion"},
+		{"cipher", func(c *Config) { c.TLS.CipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} }, "tls.cipherSuites"},
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, "log: invalid log level"},
+		{"log format", func(c *Config) { c.Log.Format = "xml" }, "log: unsupported log format"},
+		{"negative interval", func(c *Config) { c.Trash.Retention = -time.Hour }, "trash.retention: must not be negative"},
+		{"master key missing", func(c *Config) { c.Encryption.KeyID = "k1" }, `encryption.keys: no key for keyId "k1"`},
+		{"keys without key id", func(c *Config) { c.Encryption.Keys = map[string]string{"k1": masterKey} }, "encryption.keyId: required"},
+		{"key id case", func(c *Config) {
+			c.Encryption = Encryption{KeyID: "K1", Keys: map[string]string{"K1": masterKey}}
+		}, "encryption.keyId"},
+		{"master key hex", func(c *Config) {
+			c.Encryption = Encryption{KeyID: "k1", Keys: map[string]string{"k1": "zz"}}
+		}, `encryption.keys: key "k1" is not hex encoded`},
+		{"master key length", func(c *Config) {
+			c.Encryption = Encryption{KeyID: "k1", Keys: map[string]string{"k1": "abcd"}}
+		}, `encryption.keys: key "k1" must be 32 bytes, got 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := validServer()
+			tt.modify(&conf)
+			assert.ErrorContains(t, conf.ValidateServer(), tt.want)
+		})
+	}
+}
+
+func TestValidateServer_AllErrors(t *testing.T) {
+	conf := validServer()
+	conf.DB = ""
+	conf.Port = 0
+	err := conf.ValidateServer()
+	assert.ErrorContains(t, err, "db:")
+	assert.ErrorContains(t, err, "port:")
+}
+
+func TestValidateClient(t *testing.T) {
+	conf := Config{Port: 8080, Aes: "k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2"}
+	assert.NoError(t, conf.ValidateClient())
+
+	tests := []struct {
+		name string
+		aes  string
+		want string
+	}{
+		{"missing", "", "aes: encryption key is required"},
+		{"short", "0123456789abcdef", "aes: key must be 32 bytes, got 16"},
+		{"zero key", "00000000000000000000000000000000", "aes: key is too weak"},
+		{"repeated", "abababababababababababababababab", "aes: key is too weak"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.Aes = tt.aes
+			assert.ErrorContains(t, conf.ValidateClient(), tt.want)
+		})
+	}
+
+	conf.Aes = "k3Y9vQ2xLm8Rt4Wz7Pn1Bc6Hd0Fg5Js2"
+	conf.AutoLock.Timeout = -time.Minute
+	assert.ErrorContains(t, conf.ValidateClient(), "autoLock.timeout")
+}
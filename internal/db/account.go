@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlc "github.com/fngoc/gault/gen/go/db"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrLastOrgOwner пользователь единственный владелец организации, в которой есть другие участники
+var ErrLastOrgOwner = errors.New("user is the last owner of an organization with other members")
+
+// CheckPassword проверка пароля пользователя
+func (s *Store) CheckPassword(ctx context.Context, userUID, password string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	hash, err := q.GetUserPasswordHash(ctxDB, stringToNullUUID(userUID).UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("user lookup failed: %w", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// ChangePassword смена хеша пароля с завершением всех сессий, кроме keepToken.
+// Если передан encryptedPrivateKey, он заменяет сохранённый приватный ключ
+func (s *Store) ChangePassword(ctx context.Context, userUID, passwordHash, keepToken, encryptedPrivateKey string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	userID := stringToNullUUID(userUID)
+	if err := q.UpdateUserPassword(ctxDB, sqlc.UpdateUserPasswordParams{ID: userID.UUID, PasswordHash: passwordHash}); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if err := q.DeleteOtherUserSessions(ctxDB, sqlc.DeleteOtherUserSessionsParams{UserID: userID, SessionToken: keepToken}); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	if encryptedPrivateKey != "" {
+		affected, err := q.UpdateEncryptedPrivateKey(ctxDB, sqlc.UpdateEncryptedPrivateKeyParams{
+			UserID:              userID.UUID,
+			EncryptedPrivateKey: encryptedPrivateKey,
+		})
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to update private key: %w", err)
+		}
+		if affected == 0 {
+			_ = tx.Rollback()
+			return ErrNotFound
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccount удаление пользователя с личными данными и их Large Objects.
+// Данные командных хранилищ остаются в организациях, организации без других участников удаляются
+func (s *Store) DeleteAccount(ctx context.Context, userUID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	userID := stringToNullUUID(userUID)
+	abandoned, err := q.CountAbandonedOrgs(ctxDB, userID.UUID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to check organizations: %w", err)
+	}
+	if abandoned > 0 {
+		_ = tx.Rollback()
+		return ErrLastOrgOwner
+	}
+
+	oids, err := q.ListAccountLargeObjects(ctxDB, userID.UUID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to list large objects: %w", err)
+	}
+	for _, oid := range oids {
+		if _, err := tx.ExecContext(ctxDB, `SELECT lo_unlink($1)`, oid); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("lo_unlink failed: %w", err)
+		}
+	}
+
+	if err := q.DeleteSoleMemberOrgs(ctxDB, userID.UUID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete organizations: %w", err)
+	}
+	if err := q.DetachTeamData(ctxDB, userID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to detach team data: %w", err)
+	}
+	// Остальные записи пользователя удаляются каскадно
+	if err := q.DeleteUser(ctxDB, userID.UUID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCheckPassword(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery(`(?i)SELECT\s+password_hash\s+FROM\s+users\s+WHERE\s+id`).
+			WithArgs(testUserUID).
+			WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(string(hashedPassword)))
+	}
+	mock.ExpectQuery(`(?i)SELECT\s+password_hash\s+FROM\s+users\s+WHERE\s+id`).
+		WithArgs(testUserUID).
+		WillReturnError(sql.ErrNoRows)
+
+	assert.NoError(t, store.CheckPassword(context.Background(), testUserUID, "password"))
+	assert.ErrorIs(t, store.CheckPassword(context.Background(), testUserUID, "wrong"), ErrInvalidCredentials)
+	assert.ErrorIs(t, store.CheckPassword(context.Background(), testUserUID, "password"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+users\s+SET\s+password_hash`).
+		WithArgs(testUserUID, "new-hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_sessions\s+WHERE\s+user_id\s*=\s*\$1\s+AND\s+session_token\s*<>\s*\$2`).
+		WithArgs(testUserUID, "current-token").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`(?i)UPDATE\s+user_keys\s+SET\s+encrypted_private_key`).
+		WithArgs(testUserUID, "new-wrapped-key").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.ChangePassword(context.Background(), testUserUID, "new-hash", "current-token", "new-wrapped-key")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword_KeepsPrivateKey(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+users\s+SET\s+password_hash`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_sessions`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := store.ChangePassword(context.Background(), testUserUID, "new-hash", "current-token", "")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAccount(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+COUNT\(\*\)\s+FROM\s+org_members\s+m`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`(?i)SELECT\s+d\.largeobject_oid\s+FROM\s+user_data\s+d`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"largeobject_oid"}).AddRow(uint32(101)).AddRow(uint32(102)))
+	mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).WithArgs(uint32(101)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).WithArgs(uint32(102)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+organizations`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+user_id\s*=\s*NULL`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+users\s+WHERE\s+id`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.DeleteAccount(context.Background(), testUserUID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAccount_LastOrgOwner(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+COUNT\(\*\)\s+FROM\s+org_members\s+m`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.DeleteAccount(context.Background(), testUserUID), ErrLastOrgOwner)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
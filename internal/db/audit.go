@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+// auditActionPrefix префикс значений AuditAction, не хранится в колонке action
+const auditActionPrefix = "AUDIT_ACTION_"
+
+// InsertAuditEvent запись события в журнал действий. Если UID пользователя не известен,
+// событие привязывается к пользователю по логину
+func (s *Store) InsertAuditEvent(ctx context.Context, userUID string, event *pb.AuditEvent) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	err := q.InsertAuditEvent(ctxDB, sqlc.InsertAuditEventParams{
+		UserID:     stringToNullUUID(userUID),
+		Login:      event.GetLogin(),
+		Action:     auditActionToString(event.GetAction()),
+		DataID:     stringToNullUUID(event.GetDataId()),
+		ClientAddr: event.GetClientAddr(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEvents получение страницы журнала действий пользователя от новых событий к старым
+func (s *Store) ListAuditEvents(ctx context.Context, userUID string, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	var beforeID sql.NullInt64
+	if req.GetPageToken() != "" {
+		id, err := strconv.ParseInt(req.GetPageToken(), 10, 64)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		beforeID = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	limit := pageSize(req.GetPageSize())
+	q := sqlc.New(s.db)
+	rows, err := q.ListAuditEvents(ctxDB, sqlc.ListAuditEventsParams{
+		UserID:    stringToNullUUID(userUID),
+		BeforeID:  beforeID,
+		PageLimit: limit + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	// Лишняя запись означает, что есть следующая страница
+	var nextPageToken string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		nextPageToken = strconv.FormatInt(rows[len(rows)-1].ID, 10)
+	}
+
+	events := make([]*pb.AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &pb.AuditEvent{
+			Id:         row.ID,
+			Action:     auditActionFromString(row.Action),
+			DataId:     nullUUIDToString(row.DataID),
+			ClientAddr: row.ClientAddr,
+			CreatedAt:  row.CreatedAt.Unix(),
+		})
+	}
+	return &pb.ListAuditEventsResponse{Events: events, NextPageToken: nextPageToken}, nil
+}
+
+// DeleteSession отзыв сессии пользователя
+func (s *Store) DeleteSession(ctx context.Context, userUID, token string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.DeleteUserSession(ctxDB, sqlc.DeleteUserSessionParams{
+		UserID:       stringToNullUUID(userUID),
+		SessionToken: token,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// auditActionToString перевод типа события в значение колонки action, например login_success
+func auditActionToString(action pb.AuditAction) string {
+	return strings.ToLower(strings.TrimPrefix(action.String(), auditActionPrefix))
+}
+
+// auditActionFromString перевод значения колонки action в тип события
+func auditActionFromString(action string) pb.AuditAction {
+	return pb.AuditAction(pb.AuditAction_value[auditActionPrefix+strings.ToUpper(action)])
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+func TestInsertAuditEvent(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+audit_events`).
+		WithArgs(testUserUID, "", "item_read", testDataUID, "10.0.0.1:5000").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := store.InsertAuditEvent(context.Background(), testUserUID, &pb.AuditEvent{
+		Action:     pb.AuditAction_AUDIT_ACTION_ITEM_READ,
+		DataId:     testDataUID,
+		ClientAddr: "10.0.0.1:5000",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAuditEvent_ByLogin(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+audit_events`).
+		WithArgs(nil, "alice", "login_failure", nil, "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := store.InsertAuditEvent(context.Background(), "", &pb.AuditEvent{
+		Action: pb.AuditAction_AUDIT_ACTION_LOGIN_FAILURE,
+		Login:  "alice",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAuditEvents(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s*action.*FROM\s+audit_events`).
+		WithArgs(testUserUID, int64(10), int32(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "action", "data_id", "client_addr", "created_at"}).
+			AddRow(int64(9), "item_read", testDataUID, "10.0.0.1:5000", now).
+			AddRow(int64(8), "login_success", nil, "", now).
+			AddRow(int64(7), "item_delete", testDataUID, "", now))
+
+	resp, err := store.ListAuditEvents(context.Background(), testUserUID, &pb.ListAuditEventsRequest{
+		PageSize:  2,
+		PageToken: "10",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Events, 2)
+	assert.Equal(t, pb.AuditAction_AUDIT_ACTION_ITEM_READ, resp.Events[0].Action)
+	assert.Equal(t, testDataUID, resp.Events[0].DataId)
+	assert.Equal(t, pb.AuditAction_AUDIT_ACTION_LOGIN_SUCCESS, resp.Events[1].Action)
+	assert.Equal(t, "", resp.Events[1].DataId)
+	assert.Equal(t, "8", resp.NextPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAuditEvents_InvalidPageToken(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	_, err := store.ListAuditEvents(context.Background(), testUserUID, &pb.ListAuditEventsRequest{PageToken: "abc"})
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSession(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_sessions`).
+		WithArgs(testUserUID, "token").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_sessions`).
+		WithArgs(testUserUID, "token").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, store.DeleteSession(context.Background(), testUserUID, "token"))
+	assert.ErrorIs(t, store.DeleteSession(context.Background(), testUserUID, "token"), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
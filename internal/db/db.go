@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
@@ -14,17 +16,36 @@ import (
 	"github.com/google/uuid"
 	"github.com/pressly/goose"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrNotFound запись не найдена или не принадлежит пользователю
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalidCredentials неверный логин или пароль. Ответ одинаковый для обоих случаев,
+// чтобы по нему нельзя было узнать, существует ли пользователь
+var ErrInvalidCredentials = errors.New("invalid login or password")
+
+// ErrUserExists пользователь с таким логином уже зарегистрирован
+var ErrUserExists = errors.New("user already exists")
+
+// dummyPasswordHash хеш для сравнения пароля несуществующего пользователя,
+// выравнивает время ответа с проверкой настоящего пароля
+const dummyPasswordHash = "$2a$10$eKVWF15jBgT2Io/EEYcCIOIBLYcL3IyII2V6fKuXnl4m.ZWDbgHPG"
+
 // Store структура для работы с хранилищем данных
 type Store struct {
 	db *sql.DB
+	// keys мастер-ключи шифрования данных на сервере, nil — данные пишутся как есть
+	keys *Keyring
+	// writers состояние шифрования открытых на запись LO по loHandle
+	writers sync.Map
 }
 
-// InitializePostgresDB инициализация базы данных
-func InitializePostgresDB(dbConf string) (Repository, error) {
+// InitializePostgresDB инициализация базы данных. Если keys не nil, содержимое LO шифруется
+// ключами данных, обёрнутыми текущим мастер-ключом
+func InitializePostgresDB(dbConf string, keys *Keyring) (Repository, error) {
 	postgresInstant, err := sql.Open("postgres", dbConf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
@@ -35,7 +56,7 @@ func InitializePostgresDB(dbConf string) (Repository, error) {
 	}
 
 	logger.LogInfo("connected to postgres database")
-	return &Store{db: postgresInstant}, nil
+	return &Store{db: postgresInstant, keys: keys}, nil
 }
 
 func runMigrations(db *sql.DB) error {
@@ -50,10 +71,13 @@ func (s *Store) GetData(ctx context.Context, id string) (*pb.GetDataResponse, er
 	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
 	defer cancel()
 
-	tx, err := s.db.BeginTx(ctxDB, nil)
+	// Ключ данных и содержимое LO читаются из одного снимка: при read committed между запросами
+	// может зафиксироваться перезапись с новым ключом
+	tx, err := s.db.BeginTx(ctxDB, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
 	if err != nil {
 		return nil, fmt.Errorf("begin transaction: %w", err)
 	}
+	defer func() { _ = tx.Rollback() }()
 
 	q := sqlc.New(tx)
 	info, err := q.GetDataInfoByID(ctxDB, stringToNullUUID(id).UUID)
@@ -63,7 +87,7 @@ func (s *Store) GetData(ctx context.Context, id string) (*pb.GetDataResponse, er
 
 	// Открываем LO
 	var fd int
-	if err = tx.QueryRowContext(ctxDB, `SELECT lo_open($1, 131072)`, info.LargeobjectOid).Scan(&fd); err != nil {
+	if err = tx.QueryRowContext(ctxDB, `SELECT lo_open($1, $2)`, info.LargeobjectOid, invRead).Scan(&fd); err != nil {
 		return nil, fmt.Errorf("lo_open failed: %w", err)
 	}
 	defer func() {
@@ -89,6 +113,13 @@ func (s *Store) GetData(ctx context.Context, id string) (*pb.GetDataResponse, er
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 
+	// Записи без ключа созданы до включения шифрования на сервере
+	if info.KeyID.Valid {
+		if result, err = s.decryptContent(info.LargeobjectOid, info.KeyID.String, info.WrappedKey, result); err != nil {
+			return nil, fmt.Errorf("failed to decrypt data: %w", err)
+		}
+	}
+
 	// Сборка ответа
 	if info.DataType == "file" {
 		return &pb.GetDataResponse{
@@ -106,27 +137,88 @@ func (s *Store) GetData(ctx context.Context, id string) (*pb.GetDataResponse, er
 	}, nil
 }
 
-// GetDataNameList получение листа информации о данных
-func (s *Store) GetDataNameList(ctx context.Context, userUID string) (*pb.GetUserDataListResponse, error) {
+// GetDataNameList получение страницы листа информации о данных с учётом фильтров, поиска и сортировки
+func (s *Store) GetDataNameList(ctx context.Context, userUID string, filter *pb.GetUserDataListRequest) (*pb.GetUserDataListResponse, error) {
 	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
 	defer cancel()
 
+	sort := normalizeSort(filter.GetSort())
+	cursor, err := decodePageToken(filter.GetPageToken(), sort)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pageSize(filter.GetPageSize())
+	params := sqlc.ListUserDataByNameParams{
+		UserID:       stringToNullUUID(userUID),
+		OrgID:        stringToNullUUID(filter.GetOrgId()),
+		CollectionID: stringToNullUUID(filter.GetCollectionId()),
+		FolderID:     stringToNullUUID(filter.GetFolderId()),
+		Tag:          sql.NullString{String: filter.GetTag(), Valid: filter.GetTag() != ""},
+		DataType:     sql.NullString{String: filter.GetType(), Valid: filter.GetType() != ""},
+		PageLimit:    limit + 1,
+	}
+	if filter.GetNameQuery() != "" {
+		params.NamePattern = sql.NullString{String: namePattern(filter.GetNameQuery(), filter.GetNamePrefix()), Valid: true}
+	}
+	if cursor != nil {
+		params.CursorKey = sql.NullString{String: cursor.Key, Valid: true}
+		params.CursorID = stringToNullUUID(cursor.ID)
+	}
+
 	q := sqlc.New(s.db)
-	rows, err := q.ListUserData(ctxDB, stringToNullUUID(userUID))
+	var rows []sqlc.UserDatum
+	switch sort {
+	case pb.SortOrder_SORT_ORDER_CREATED:
+		rows, err = q.ListUserDataByCreated(ctxDB, sqlc.ListUserDataByCreatedParams(params))
+	case pb.SortOrder_SORT_ORDER_UPDATED:
+		rows, err = q.ListUserDataByUpdated(ctxDB, sqlc.ListUserDataByUpdatedParams(params))
+	case pb.SortOrder_SORT_ORDER_SIZE:
+		rows, err = q.ListUserDataBySize(ctxDB, sqlc.ListUserDataBySizeParams(params))
+	default:
+		rows, err = q.ListUserDataByName(ctxDB, params)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 
+	// Лишняя запись означает, что есть следующая страница
+	var nextPageToken string
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		nextPageToken = encodePageToken(sort, rows[len(rows)-1])
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	tags := make(map[uuid.UUID][]string)
+	if len(ids) > 0 {
+		tagRows, err := q.ListDataTagsByIDs(ctxDB, ids)
+		if err != nil {
+			return nil, fmt.Errorf("query tags error: %w", err)
+		}
+		for _, row := range tagRows {
+			tags[row.DataID] = append(tags[row.DataID], row.Tag)
+		}
+	}
+
 	items := make([]*pb.UserDataItem, 0, len(rows))
 	for _, row := range rows {
 		items = append(items, &pb.UserDataItem{
-			Id:   row.ID.String(),
-			Type: row.DataType,
-			Name: row.DataName,
+			Id:        row.ID.String(),
+			Type:      row.DataType,
+			Name:      row.DataName,
+			FolderId:  nullUUIDToString(row.FolderID),
+			Tags:      tags[row.ID],
+			CreatedAt: row.CreatedAt.Unix(),
+			UpdatedAt: row.UpdatedAt.Unix(),
+			Size:      row.SizeBytes,
 		})
 	}
 
-	return &pb.GetUserDataListResponse{Items: items}, nil
+	return &pb.GetUserDataListResponse{Items: items, NextPageToken: nextPageToken}, nil
 }
 
 // CreateUser создание пользователя
@@ -146,6 +238,10 @@ func (s *Store) CreateUser(ctx context.Context, username, passwordHash string) (
 	})
 	if err != nil {
 		_ = tx.Rollback()
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return "", "", ErrUserExists
+		}
 		return "", "", fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -174,6 +270,22 @@ func (s *Store) IsUserCreated(ctx context.Context, username string) (bool, error
 	return isCreated, nil
 }
 
+// GetUserUID получение UID пользователя по логину
+func (s *Store) GetUserUID(ctx context.Context, username string) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	userID, err := q.GetUserIDByUsername(ctxDB, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user uid: %w", err)
+	}
+	return userID.String(), nil
+}
+
 // CheckSessionUser проверка сессии пользователя
 func (s *Store) CheckSessionUser(ctx context.Context, userUID, token string) bool {
 	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
@@ -190,20 +302,28 @@ func (s *Store) CheckSessionUser(ctx context.Context, userUID, token string) boo
 	return isValid
 }
 
-// UpdateSessionUser обновление сессии пользователя
+// UpdateSessionUser обновление сессии пользователя. При включённой 2FA сессия не создаётся,
+// возвращается UID пользователя и ErrTwoFactorRequired
 func (s *Store) UpdateSessionUser(ctx context.Context, username, password string) (string, string, error) {
 	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
 	defer cancel()
 
 	q := sqlc.New(s.db)
 	user, err := q.GetUserCredentialsByUsername(ctxDB, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return "", "", ErrInvalidCredentials
+	}
 	if err != nil {
 		return "", "", fmt.Errorf("user lookup failed: %w", err)
 	}
 
 	// Сравниваем хеш
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", "", fmt.Errorf("invalid password")
+		return "", "", ErrInvalidCredentials
+	}
+	if user.TotpEnabled {
+		return user.ID.String(), "", ErrTwoFactorRequired
 	}
 
 	// Создаём токен
@@ -215,24 +335,18 @@ func (s *Store) UpdateSessionUser(ctx context.Context, username, password string
 	return user.ID.String(), token, nil
 }
 
-// DeleteData удаление данных
+// DeleteData перемещение данных в корзину, содержимое удаляется позже очисткой корзины
 func (s *Store) DeleteData(ctx context.Context, id string) error {
 	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
 	defer cancel()
 
-	tx, err := s.db.BeginTx(ctxDB, nil)
+	q := sqlc.New(s.db)
+	affected, err := q.TrashUserData(ctxDB, stringToNullUUID(id).UUID)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-
-	q := sqlc.New(tx)
-	if err := q.DeleteUserData(ctxDB, stringToNullUUID(id).UUID); err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("failed to delete user data: %w", err)
+		return fmt.Errorf("failed to move user data to trash: %w", err)
 	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if affected == 0 {
+		return ErrNotFound
 	}
 	return nil
 }
@@ -277,6 +391,30 @@ func stringToNullUUID(s string) uuid.NullUUID {
 	return uuid.NullUUID{UUID: u, Valid: true}
 }
 
+// nullUUIDToString перевод UUID в строку, пустая строка для NULL
+func nullUUIDToString(u uuid.NullUUID) string {
+	if !u.Valid {
+		return ""
+	}
+	return u.UUID.String()
+}
+
+// Ping проверка соединения с базой данных
+func (s *Store) Ping(ctx context.Context) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctxDB); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
+
+// Close закрытие пула соединений с базой данных
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
 // BeginTx начало транзакции
 func (s *Store) BeginTx(ctx context.Context) (*sql.Tx, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
@@ -320,18 +458,74 @@ func (s *Store) InsertUserDataRecordTx(ctx context.Context, tx *sql.Tx, userData
 	return nil
 }
 
-// OpenLOForWriting открывает LO один раз
+// UpdateUserDataSizeTx сохраняет размер данных и время обновления записи
+func (s *Store) UpdateUserDataSizeTx(ctx context.Context, tx *sql.Tx, userDataID string, size int64) error {
+	q := sqlc.New(tx)
+	err := q.UpdateUserDataSize(ctx, sqlc.UpdateUserDataSizeParams{
+		SizeBytes: size,
+		ID:        stringToNullUUID(userDataID).UUID,
+	})
+	if err != nil {
+		return fmt.Errorf("update user_data size failed: %w", err)
+	}
+	return nil
+}
+
+// Режимы lo_open. Транзакция чтения GetData только читающая, открытие на запись в ней запрещено
+const (
+	invWrite = 131072
+	invRead  = 262144
+)
+
+// OpenLOForWriting открывает LO один раз. Запись user_data с этим oid должна существовать:
+// в неё сохраняется новый ключ данных, которым шифруются все чанки до CloseLO
 func (s *Store) OpenLOForWriting(ctx context.Context, tx *sql.Tx, oid int) (int, error) {
-	const invWrite = 131072
 	var fd int
 	if err := tx.QueryRowContext(ctx, `SELECT lo_open($1, $2)`, oid, invWrite).Scan(&fd); err != nil {
 		return 0, fmt.Errorf("lo_open failed: %w", err)
 	}
+	aead, err := s.setDataKey(ctx, tx, oid)
+	if err != nil {
+		return 0, err
+	}
+	if aead != nil {
+		s.writers.Store(loHandle{tx: tx, fd: fd}, &loWriter{aead: aead, oid: uint32(oid)})
+	}
 	return fd, nil
 }
 
-// WriteLO записывает чанк в открытый LO
+// WriteLO записывает чанк в открытый LO. При шифровании на сервере чанк записывается
+// зашифрованным кадром со следующим чанком или в FinishLO
 func (s *Store) WriteLO(ctx context.Context, tx *sql.Tx, fd int, chunk []byte) error {
+	if w, ok := s.writers.Load(loHandle{tx: tx, fd: fd}); ok {
+		frame, err := w.(*loWriter).push(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt chunk: %w", err)
+		}
+		if frame == nil {
+			return nil
+		}
+		chunk = frame
+	}
+	return writeLO(ctx, tx, fd, chunk)
+}
+
+// FinishLO завершает запись LO перед фиксацией транзакции: при шифровании на сервере
+// записывает последний кадр с признаком конца содержимого
+func (s *Store) FinishLO(ctx context.Context, tx *sql.Tx, fd int) error {
+	w, ok := s.writers.Load(loHandle{tx: tx, fd: fd})
+	if !ok {
+		return nil
+	}
+	frame, err := w.(*loWriter).finish()
+	if err != nil {
+		return fmt.Errorf("failed to encrypt chunk: %w", err)
+	}
+	return writeLO(ctx, tx, fd, frame)
+}
+
+// writeLO запись байтов в открытый LO целиком
+func writeLO(ctx context.Context, tx *sql.Tx, fd int, chunk []byte) error {
 	var wrote int
 	if err := tx.QueryRowContext(ctx, `SELECT lowrite($1, $2)`, fd, chunk).Scan(&wrote); err != nil {
 		return fmt.Errorf("lowrite failed: %w", err)
@@ -344,14 +538,24 @@ func (s *Store) WriteLO(ctx context.Context, tx *sql.Tx, fd int, chunk []byte) e
 
 // CloseLO закрывает файловый дескриптор LO
 func (s *Store) CloseLO(ctx context.Context, tx *sql.Tx, fd int) {
+	s.writers.Delete(loHandle{tx: tx, fd: fd})
 	_, _ = tx.ExecContext(ctx, `SELECT lo_close($1)`, fd)
 }
 
-// TruncateLO обнуляет содержимое LO, делая его длину равной newSize
+// TruncateLO обнуляет содержимое LO, делая его длину равной newSize. Зашифрованный LO
+// можно только очистить целиком, кадры не делятся по произвольной длине
 func (s *Store) TruncateLO(ctx context.Context, tx *sql.Tx, fd int, newSize int64) error {
+	w, encrypted := s.writers.Load(loHandle{tx: tx, fd: fd})
+	if encrypted && newSize != 0 {
+		return fmt.Errorf("encrypted large object can only be truncated to 0, got %d", newSize)
+	}
 	_, err := tx.ExecContext(ctx, `SELECT lo_truncate($1, $2)`, fd, newSize)
 	if err != nil {
 		return fmt.Errorf("lo_truncate failed: %w", err)
 	}
+	if encrypted {
+		// Содержимое пишется заново с первого кадра
+		w.(*loWriter).reset()
+	}
 	return nil
 }
@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,7 +34,7 @@ func TestInitializePostgresDB(t *testing.T) {
 	mock.ExpectExec(`(?i)CREATE TABLE IF NOT EXISTS user_sessions`).WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectPing()
 
-	_, err = InitializePostgresDB("mock-dsn")
+	_, err = InitializePostgresDB("mock-dsn", nil)
 	assert.Error(t, err)
 }
 
@@ -73,6 +77,21 @@ func TestCreateUserError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCreateUserExists(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)INSERT\s+INTO\s+users`).
+		WithArgs("testuser", "hashed-password").
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	_, _, err := store.CreateUser(context.Background(), "testuser", "hashed-password")
+	assert.ErrorIs(t, err, ErrUserExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestIsUserCreated(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
@@ -102,16 +121,36 @@ func TestIsUserCreatedError(t *testing.T) {
 	assert.False(t, exists)
 }
 
+func TestGetUserUID(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	ctx := context.Background()
+	mock.ExpectQuery(`(?i)SELECT\s+id\s+FROM\s+users\s+WHERE\s+username\s*=\s*\$1`).
+		WithArgs("testuser").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc2"))
+
+	userUID, err := store.GetUserUID(ctx, "testuser")
+	assert.NoError(t, err)
+	assert.Equal(t, "3a0a4950-16e3-4720-814b-17e6b4fd0bc2", userUID)
+
+	mock.ExpectQuery(`(?i)SELECT\s+id\s+FROM\s+users\s+WHERE\s+username\s*=\s*\$1`).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = store.GetUserUID(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteData(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
 
-	mock.ExpectBegin()
 	ctx := context.Background()
-	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data\s+WHERE\s+id\s*=\s*\$1`).
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+deleted_at\s*=\s*NOW\(\)`).
 		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc2").
-		WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectCommit()
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := store.DeleteData(ctx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc2")
 	assert.NoError(t, err)
@@ -122,17 +161,27 @@ func TestDeleteDataError(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
 
-	mock.ExpectBegin()
 	ctx := context.Background()
-	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data\s+WHERE\s+id\s*=\s*\$1`).
-		WithArgs("data-id").
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+deleted_at`).
+		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc2").
 		WillReturnError(errors.New("error"))
-	mock.ExpectRollback()
 
-	err := store.DeleteData(ctx, "data-id")
+	err := store.DeleteData(ctx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc2")
 	assert.Error(t, err)
 }
 
+func TestDeleteData_AlreadyInTrash(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+deleted_at`).
+		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := store.DeleteData(context.Background(), "3a0a4950-16e3-4720-814b-17e6b4fd0bc2")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestUpdateSessionUser(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
@@ -142,10 +191,10 @@ func TestUpdateSessionUser(t *testing.T) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
 	assert.NoError(t, err)
 
-	mock.ExpectQuery(`(?i)SELECT\s+id,\s+password_hash\s+FROM\s+users\s+WHERE\s+username\s*=\s*\$1`).
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
 		WithArgs("testuser").
-		WillReturnRows(sqlmock.NewRows([]string{"3a0a4950-16e3-4720-814b-17e6b4fd0bc5", "password_hash"}).
-			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", string(hashedPassword)))
+		WillReturnRows(sqlmock.NewRows([]string{"3a0a4950-16e3-4720-814b-17e6b4fd0bc5", "password_hash", "totp_enabled"}).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", string(hashedPassword), false))
 
 	mock.ExpectBegin()
 	mock.ExpectExec(`(?i)INSERT\s+INTO\s+user_sessions\s*\(user_id, session_token, expires_at\)`).
@@ -166,7 +215,7 @@ func TestUpdateSessionUserError(t *testing.T) {
 
 	ctx := context.Background()
 
-	mock.ExpectQuery(`(?i)SELECT\s+id,\s+password_hash\s+FROM\s+users\s+WHERE\s+username\s*=\s*\$1`).
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
 		WithArgs("testuser").
 		WillReturnError(errors.New("error"))
 
@@ -174,6 +223,32 @@ func TestUpdateSessionUserError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUpdateSessionUserInvalidCredentials(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	ctx := context.Background()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
+		WithArgs("testuser").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "totp_enabled"}).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", string(hashedPassword), false))
+
+	// Неизвестный пользователь и неверный пароль неотличимы по ошибке
+	_, _, unknownErr := store.UpdateSessionUser(ctx, "unknown", "password")
+	_, _, passwordErr := store.UpdateSessionUser(ctx, "testuser", "wrong")
+	assert.ErrorIs(t, unknownErr, ErrInvalidCredentials)
+	assert.ErrorIs(t, passwordErr, ErrInvalidCredentials)
+	assert.Equal(t, unknownErr.Error(), passwordErr.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateSessionUserTxError(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
@@ -183,10 +258,10 @@ func TestUpdateSessionUserTxError(t *testing.T) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
 	assert.NoError(t, err)
 
-	mock.ExpectQuery(`(?i)SELECT\s+id,\s+password_hash\s+FROM\s+users\s+WHERE\s+username\s*=\s*\$1`).
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
 		WithArgs("testuser").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash"}).
-			AddRow("user-uid", string(hashedPassword)))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "totp_enabled"}).
+			AddRow("user-uid", string(hashedPassword), false))
 
 	mock.ExpectBegin()
 	mock.ExpectExec(`(?i)INSERT\s+INTO\s+user_sessions\s*\(user_id, session_token, expires_at\)`).
@@ -225,26 +300,117 @@ func TestCheckSessionUserError(t *testing.T) {
 	assert.False(t, isValid)
 }
 
+// userDataColumns колонки таблицы user_data в порядке схемы
+var userDataColumns = []string{"id", "user_id", "data_type", "data_name", "largeobject_oid", "created_at", "folder_id", "updated_at", "size_bytes", "data_key", "collection_id", "deleted_at", "key_id", "wrapped_key"}
+
 func TestGetDataNameList(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
 
 	ctx := context.Background()
-	mock.ExpectQuery(`(?i)SELECT\s+id,\s+data_type,\s+data_name\s+FROM\s+user_data\s+WHERE\s+user_id\s*=\s*\$1`).
-		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc1").
-		WillReturnRows(sqlmock.NewRows([]string{"3a0a4950-16e3-4720-814b-17e6b4fd0bc2", "data_type", "data_name"}).
-			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc3", "type1", "name1").
-			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", "type2", "name2"))
+	created := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`(?i)SELECT\s+.+\s+FROM\s+user_data\s+d.+ORDER\s+BY\s+d\.data_name,\s+d\.id`).
+		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc1", nil, nil, nil, nil, nil, nil, nil, nil, int32(defaultPageSize+1)).
+		WillReturnRows(sqlmock.NewRows(userDataColumns).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc3", "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", "type1", "name1", 1, created, nil, created, 10, nil, nil, nil, nil, nil).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", "type2", "name2", 2, created, "3a0a4950-16e3-4720-814b-17e6b4fd0bc5", created, 20, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(`(?i)SELECT\s+data_id,\s+tag\s+FROM\s+data_tags`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"data_id", "tag"}).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc3", "work").
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc3", "bank"))
 
-	resp, err := store.GetDataNameList(ctx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc1")
+	resp, err := store.GetDataNameList(ctx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", &pb.GetUserDataListRequest{})
 	assert.NoError(t, err)
 	assert.Len(t, resp.Items, 2)
+	assert.Empty(t, resp.NextPageToken)
 	assert.Equal(t, "3a0a4950-16e3-4720-814b-17e6b4fd0bc3", resp.Items[0].Id)
 	assert.Equal(t, "type1", resp.Items[0].Type)
 	assert.Equal(t, "name1", resp.Items[0].Name)
+	assert.Empty(t, resp.Items[0].FolderId)
+	assert.Equal(t, []string{"work", "bank"}, resp.Items[0].Tags)
+	assert.Equal(t, created.Unix(), resp.Items[0].CreatedAt)
+	assert.Equal(t, int64(10), resp.Items[0].Size)
 	assert.Equal(t, "3a0a4950-16e3-4720-814b-17e6b4fd0bc4", resp.Items[1].Id)
 	assert.Equal(t, "type2", resp.Items[1].Type)
 	assert.Equal(t, "name2", resp.Items[1].Name)
+	assert.Equal(t, "3a0a4950-16e3-4720-814b-17e6b4fd0bc5", resp.Items[1].FolderId)
+	assert.Empty(t, resp.Items[1].Tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataNameList_FilterAndNextPage(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	ctx := context.Background()
+	created := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`(?i)SELECT\s+.+\s+FROM\s+user_data\s+d.+ORDER\s+BY\s+d\.size_bytes\s+DESC`).
+		WithArgs("3a0a4950-16e3-4720-814b-17e6b4fd0bc1", nil, nil, "3a0a4950-16e3-4720-814b-17e6b4fd0bc5", "work", "file", "%re\\%port%", nil, nil, int32(2)).
+		WillReturnRows(sqlmock.NewRows(userDataColumns).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc3", "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", "file", "re%port", 1, created, nil, created, 30, nil, nil, nil, nil, nil).
+			AddRow("3a0a4950-16e3-4720-814b-17e6b4fd0bc4", "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", "file", "re%port 2", 2, created, nil, created, 20, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(`(?i)SELECT\s+data_id,\s+tag\s+FROM\s+data_tags`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"data_id", "tag"}))
+
+	resp, err := store.GetDataNameList(ctx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", &pb.GetUserDataListRequest{
+		FolderId:  "3a0a4950-16e3-4720-814b-17e6b4fd0bc5",
+		Tag:       "work",
+		Type:      "file",
+		NameQuery: "re%port",
+		Sort:      pb.SortOrder_SORT_ORDER_SIZE,
+		PageSize:  1,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Items, 1)
+	assert.NotEmpty(t, resp.NextPageToken)
+
+	cursor, err := decodePageToken(resp.NextPageToken, pb.SortOrder_SORT_ORDER_SIZE)
+	assert.NoError(t, err)
+	assert.Equal(t, "30", cursor.Key)
+	assert.Equal(t, "3a0a4950-16e3-4720-814b-17e6b4fd0bc3", cursor.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataNameList_InvalidPageToken(t *testing.T) {
+	dbMock, _, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	_, err := store.GetDataNameList(context.Background(), "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", &pb.GetUserDataListRequest{
+		PageToken: "not-a-token",
+	})
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestGetDataNameList_Error(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+.+\s+FROM\s+user_data\s+d.+ORDER\s+BY\s+d\.created_at\s+DESC`).
+		WillReturnError(errors.New("error"))
+
+	_, err := store.GetDataNameList(context.Background(), "3a0a4950-16e3-4720-814b-17e6b4fd0bc1", &pb.GetUserDataListRequest{
+		Sort: pb.SortOrder_SORT_ORDER_CREATED,
+	})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateUserDataSizeTx(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	ctx := context.Background()
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+size_bytes`).
+		WithArgs(int64(42), "3a0a4950-16e3-4720-814b-17e6b4fd0bc3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	tx, err := store.BeginTx(ctx)
+	assert.NoError(t, err)
+	err = store.UpdateUserDataSizeTx(ctx, tx, "3a0a4950-16e3-4720-814b-17e6b4fd0bc3", 42)
+	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -395,6 +561,9 @@ func TestOpenLOForWriting_Success(t *testing.T) {
 	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
 		WithArgs(12345, 131072).
 		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(10))
+	mock.ExpectExec(`UPDATE user_data`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 12345).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	fd, err := store.OpenLOForWriting(ctx, tx, 12345)
 	assert.NoError(t, err)
@@ -537,13 +706,13 @@ func TestGetData_Error_File(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
 		WithArgs(sqlmock.AnyArg()).
-		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid"}).
-			AddRow("file", "some-name", 123))
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("file", "some-name", 123, nil, nil))
 
-	mock.ExpectQuery(`SELECT lo_open\(\$1, 131072\)`).
-		WithArgs(123).
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(123, invRead).
 		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(10))
 
 	chunkRows := []string{"loread"}
@@ -581,13 +750,13 @@ func TestGetData_Error_Text(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
 		WithArgs(sqlmock.AnyArg()).
-		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid"}).
-			AddRow("text", "some-name", 999))
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("text", "some-name", 999, nil, nil))
 
-	mock.ExpectQuery(`SELECT lo_open\(\$1, 131072\)`).
-		WithArgs(999).
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(999, invRead).
 		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(20))
 
 	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
@@ -613,6 +782,27 @@ func TestGetData_Error_Text(t *testing.T) {
 	assert.Nil(t, resp)
 }
 
+func TestGetData_OpensForReading(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("text", "note", 123, nil, nil))
+	// Транзакция только для чтения: LO открывается в режиме INV_READ, INV_WRITE Postgres отклонит
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(123, 262144).
+		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(10))
+	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"loread"}).AddRow([]byte{}))
+	mock.ExpectCommit()
+
+	_, err := store.GetData(context.Background(), testDataUID)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetData_BeginTxError(t *testing.T) {
 	dbMock, mock, store := setupMockDB(t)
 	defer dbMock.Close()
@@ -637,7 +827,7 @@ func TestGetData_NotFoundError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
 		WillReturnError(sql.ErrNoRows)
 
 	resp, err := store.GetData(ctx, "nonexistent-id")
@@ -655,12 +845,12 @@ func TestGetData_loOpenError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
-		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid"}).
-			AddRow("file", "name", 123))
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("file", "name", 123, nil, nil))
 
-	mock.ExpectQuery(`SELECT lo_open\(\$1, 131072\)`).
-		WithArgs(123).
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(123, invRead).
 		WillReturnError(errors.New("lo_open failed"))
 
 	resp, err := store.GetData(ctx, "some-id")
@@ -679,12 +869,12 @@ func TestGetData_loreadError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
-		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid"}).
-			AddRow("file", "name", 777))
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("file", "name", 777, nil, nil))
 
-	mock.ExpectQuery(`SELECT lo_open\(\$1, 131072\)`).
-		WithArgs(777).
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(777, invRead).
 		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(40))
 
 	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
@@ -710,12 +900,12 @@ func TestGetData_CommitError(t *testing.T) {
 
 	mock.ExpectBegin()
 
-	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid FROM user_data WHERE id = \$1`).
-		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid"}).
-			AddRow("file", "name", 555))
+	mock.ExpectQuery(`SELECT data_type, data_name, largeobject_oid, key_id, wrapped_key FROM user_data WHERE id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("file", "name", 555, nil, nil))
 
-	mock.ExpectQuery(`SELECT lo_open\(\$1, 131072\)`).
-		WithArgs(555).
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(555, invRead).
 		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(50))
 
 	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
@@ -801,3 +991,18 @@ func TestGetOidByItemID_Error(t *testing.T) {
 	_, err := store.GetOidByItemID(ctx, "item-id")
 	assert.Error(t, err)
 }
+
+func TestPing(t *testing.T) {
+	dbMock, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	store := &Store{db: dbMock}
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	mock.ExpectClose()
+
+	assert.NoError(t, store.Ping(context.Background()))
+	assert.ErrorContains(t, store.Ping(context.Background()), "database is unavailable")
+	assert.NoError(t, store.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,379 @@
+package db
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlc "github.com/fngoc/gault/gen/go/db"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// masterKeySize размер мастер-ключа и ключа данных, AES-256
+const masterKeySize = 32
+
+// frameHeaderSize длина заголовка кадра с размером nonce и шифротекста
+const frameHeaderSize = 4
+
+// rewrapBatchSize число ключей данных, перешифровываемых за одну транзакцию
+const rewrapBatchSize = 100
+
+// rewrapRetries число повторных проходов по строкам, занятым записью, до ошибки
+const rewrapRetries = 10
+
+// rewrapRetryDelay пауза перед повторным проходом по строкам, занятым записью
+var rewrapRetryDelay = time.Second
+
+// ErrNoMasterKeys данные зашифрованы на сервере, но мастер-ключи не заданы в конфигурации
+var ErrNoMasterKeys = errors.New("server-side encryption keys are not configured")
+
+// ErrUnknownMasterKey ключ данных обёрнут мастер-ключом, которого нет в конфигурации
+var ErrUnknownMasterKey = errors.New("unknown master key")
+
+// ErrRewrapIncomplete после ротации остались ключи данных, обёрнутые прежними мастер-ключами
+var ErrRewrapIncomplete = errors.New("data keys are still wrapped with other master keys")
+
+// Keyring мастер-ключи сервера по идентификатору. Новые ключи данных оборачиваются текущим
+// ключом, остальные нужны для чтения данных до перешифрования командой keys rotate
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring набор мастер-ключей, current идентификатор ключа для новых данных
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMasterKey, current)
+	}
+	k := &Keyring{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(key) != masterKeySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes, got %d", id, masterKeySize, len(key))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// CurrentKeyID идентификатор мастер-ключа для новых данных
+func (k *Keyring) CurrentKeyID() string {
+	return k.current
+}
+
+// wrap шифрует ключ данных текущим мастер-ключом, идентификатор ключа входит в AAD
+func (k *Keyring) wrap(dataKey []byte) (string, []byte, error) {
+	wrapped, err := seal(k.keys[k.current], dataKey, []byte(k.current))
+	if err != nil {
+		return "", nil, err
+	}
+	return k.current, wrapped, nil
+}
+
+// unwrap расшифровывает ключ данных мастер-ключом keyID
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownMasterKey, keyID)
+	}
+	dataKey, err := open(aead, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %q: %w", keyID, err)
+	}
+	return dataKey, nil
+}
+
+// loHandle открытый на запись LO: дескриптор уникален только внутри транзакции
+type loHandle struct {
+	tx *sql.Tx
+	fd int
+}
+
+// loWriter состояние шифрования открытого на запись LO. Последний чанк держится в pending,
+// пока не станет ясно, что он последний: его кадр помечается в AAD как финальный
+type loWriter struct {
+	aead    cipher.AEAD
+	oid     uint32
+	frame   uint64
+	pending []byte
+	started bool
+}
+
+// push принимает очередной чанк и возвращает кадр предыдущего, nil для первого чанка
+func (w *loWriter) push(chunk []byte) ([]byte, error) {
+	var frame []byte
+	if w.started {
+		var err error
+		if frame, err = w.sealChunk(w.pending, false); err != nil {
+			return nil, err
+		}
+	}
+	w.pending = append(w.pending[:0], chunk...)
+	w.started = true
+	return frame, nil
+}
+
+// finish финальный кадр с последним чанком. Пустой LO тоже получает финальный кадр,
+// иначе его нельзя было бы отличить от полностью отрезанного содержимого
+func (w *loWriter) finish() ([]byte, error) {
+	frame, err := w.sealChunk(w.pending, true)
+	if err != nil {
+		return nil, err
+	}
+	w.pending, w.started = nil, false
+	return frame, nil
+}
+
+// setDataKey создаёт ключ данных для LO и сохраняет его обёрнутым в user_data. Без мастер-ключей
+// ключ сбрасывается, и данные пишутся как есть. Возвращает шифр для кадров или nil
+func (s *Store) setDataKey(ctx context.Context, tx *sql.Tx, oid int) (cipher.AEAD, error) {
+	params := sqlc.SetWrappedKeyByOidParams{LargeobjectOid: uint32(oid)}
+	var aead cipher.AEAD
+	if s.keys != nil {
+		dataKey := make([]byte, masterKeySize)
+		if _, err := rand.Read(dataKey); err != nil {
+			return nil, fmt.Errorf("failed to generate data key: %w", err)
+		}
+		keyID, wrapped, err := s.keys.wrap(dataKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		if aead, err = newAEAD(dataKey); err != nil {
+			return nil, err
+		}
+		params.KeyID = sql.NullString{String: keyID, Valid: true}
+		params.WrappedKey = wrapped
+	}
+
+	n, err := sqlc.New(tx).SetWrappedKeyByOid(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store data key: %w", err)
+	}
+	if aead != nil && n != 1 {
+		return nil, fmt.Errorf("failed to store data key: no user_data record for large object %d", oid)
+	}
+	return aead, nil
+}
+
+// reset начало записи с первого кадра после очистки LO
+func (w *loWriter) reset() {
+	w.frame, w.pending, w.started = 0, nil, false
+}
+
+// sealChunk кадр LO: длина, nonce и шифротекст чанка. Номер кадра, oid и признак последнего
+// кадра входят в AAD, поэтому кадры нельзя переставить, перенести в другую запись или отрезать
+func (w *loWriter) sealChunk(chunk []byte, final bool) ([]byte, error) {
+	sealed, err := seal(w.aead, chunk, frameAAD(w.oid, w.frame, final))
+	if err != nil {
+		return nil, err
+	}
+	w.frame++
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(sealed))
+	binary.BigEndian.PutUint32(frame, uint32(len(sealed)))
+	return append(frame, sealed...), nil
+}
+
+// decryptContent содержимое LO, записанное кадрами sealChunk
+func (s *Store) decryptContent(oid uint32, keyID string, wrapped, content []byte) ([]byte, error) {
+	if s.keys == nil {
+		return nil, ErrNoMasterKeys
+	}
+	dataKey, err := s.keys.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(content) == 0 {
+		return nil, errors.New("missing final frame")
+	}
+	result := make([]byte, 0, len(content))
+	for frame := uint64(0); len(content) > 0; frame++ {
+		if len(content) < frameHeaderSize {
+			return nil, fmt.Errorf("frame %d: truncated header", frame)
+		}
+		size := int(binary.BigEndian.Uint32(content))
+		content = content[frameHeaderSize:]
+		if size > len(content) {
+			return nil, fmt.Errorf("frame %d: truncated body", frame)
+		}
+		// Кадр, после которого ничего нет, должен быть записан как финальный
+		final := size == len(content)
+		chunk, err := open(aead, content[:size], frameAAD(oid, frame, final))
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", frame, err)
+		}
+		result = append(result, chunk...)
+		content = content[size:]
+	}
+	return result, nil
+}
+
+// RewrapDataKeys перешифровывает текущим мастер-ключом ключи данных, обёрнутые другими
+// ключами. Работает партиями в отдельных транзакциях, строки, занятые записью, пропускаются,
+// поэтому сервер может обслуживать запросы во время ротации. Пропущенные строки обрабатываются
+// повторными проходами, если они так и не освободились — ErrRewrapIncomplete с их числом
+func (s *Store) RewrapDataKeys(ctx context.Context) (int64, error) {
+	if s.keys == nil {
+		return 0, ErrNoMasterKeys
+	}
+	var rewrapped int64
+	for retries := 0; ; {
+		n, err := s.rewrapBatch(ctx)
+		rewrapped += n
+		if err != nil {
+			return rewrapped, err
+		}
+		if n > 0 {
+			retries = 0
+			continue
+		}
+
+		remaining, err := s.countDataKeysForRewrap(ctx)
+		if err != nil {
+			return rewrapped, err
+		}
+		if remaining == 0 {
+			return rewrapped, nil
+		}
+		if retries == rewrapRetries {
+			return rewrapped, fmt.Errorf("%w: %d items are locked by writes, retry later", ErrRewrapIncomplete, remaining)
+		}
+		retries++
+		select {
+		case <-ctx.Done():
+			return rewrapped, ctx.Err()
+		case <-time.After(rewrapRetryDelay):
+		}
+	}
+}
+
+// countDataKeysForRewrap число ключей данных, обёрнутых не текущим мастер-ключом
+func (s *Store) countDataKeysForRewrap(ctx context.Context) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	n, err := sqlc.New(s.db).CountDataKeysForRewrap(ctxDB, s.keys.CurrentKeyID())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count data keys: %w", err)
+	}
+	return n, nil
+}
+
+// rewrapBatch перешифрование одной партии ключей данных
+func (s *Store) rewrapBatch(ctx context.Context) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	rows, err := q.ListWrappedKeysForRewrap(ctxDB, sqlc.ListWrappedKeysForRewrapParams{
+		CurrentKeyID: s.keys.CurrentKeyID(),
+		BatchSize:    rewrapBatchSize,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to list data keys: %w", err)
+	}
+	for _, row := range rows {
+		dataKey, err := s.keys.unwrap(row.KeyID.String, row.WrappedKey)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("data %s: %w", row.ID, err)
+		}
+		keyID, wrapped, err := s.keys.wrap(dataKey)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("data %s: failed to wrap data key: %w", row.ID, err)
+		}
+		if err := q.SetWrappedKeyByID(ctxDB, sqlc.SetWrappedKeyByIDParams{
+			KeyID:      sql.NullString{String: keyID, Valid: true},
+			WrappedKey: wrapped,
+			ID:         row.ID,
+		}); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("data %s: failed to store data key: %w", row.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if len(rows) > 0 {
+		logger.LogInfo("data keys rewrapped", zap.Int("items", len(rows)), zap.String("key_id", s.keys.CurrentKeyID()))
+	}
+	return int64(len(rows)), nil
+}
+
+// CountDataKeys число записей по идентификатору мастер-ключа, пустой идентификатор —
+// данные, записанные без шифрования на сервере
+func (s *Store) CountDataKeys(ctx context.Context) (map[string]int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	rows, err := sqlc.New(s.db).CountDataKeysByKeyID(ctxDB)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.KeyID] = row.Items
+	}
+	return counts, nil
+}
+
+// frameAAD дополнительные данные кадра: oid LO, номер кадра и признак последнего кадра
+func frameAAD(oid uint32, frame uint64, final bool) []byte {
+	aad := make([]byte, 13)
+	binary.BigEndian.PutUint32(aad, oid)
+	binary.BigEndian.PutUint64(aad[4:], frame)
+	if final {
+		aad[12] = 1
+	}
+	return aad
+}
+
+// newAEAD AES-GCM для ключа
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal шифрует plaintext, nonce записывается перед шифротекстом
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open расшифровывает результат seal
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
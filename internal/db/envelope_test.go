@@ -0,0 +1,318 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureArg аргумент запроса, который запоминает переданные байты
+type captureArg struct {
+	value *[]byte
+}
+
+func (a captureArg) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if ok {
+		*a.value = append(*a.value, b...)
+	}
+	return ok
+}
+
+func testKeyring(t *testing.T, current string, ids ...string) *Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, masterKeySize)
+	}
+	keyring, err := NewKeyring(current, keys)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring("k2", map[string][]byte{"k1": make([]byte, masterKeySize)})
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+
+	_, err = NewKeyring("k1", map[string][]byte{"k1": make([]byte, 16)})
+	assert.ErrorContains(t, err, "must be 32 bytes")
+}
+
+func TestKeyring_WrapUnwrap(t *testing.T) {
+	keyring := testKeyring(t, "k1", "k1", "k2")
+	dataKey := bytes.Repeat([]byte{7}, masterKeySize)
+
+	keyID, wrapped, err := keyring.wrap(dataKey)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+	assert.NotContains(t, string(wrapped), string(dataKey))
+
+	unwrapped, err := keyring.unwrap("k1", wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	_, err = keyring.unwrap("k2", wrapped)
+	assert.ErrorContains(t, err, "failed to unwrap data key")
+	_, err = keyring.unwrap("k3", wrapped)
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+}
+
+func TestEnvelope_WriteAndRead(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+	store := &Store{db: dbMock, keys: testKeyring(t, "k1", "k1")}
+	ctx := context.Background()
+
+	var wrapped, content []byte
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(42, 131072).
+		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(7))
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+key_id`).
+		WithArgs("k1", captureArg{&wrapped}, 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	chunks := []string{"hello, ", "world"}
+	for _, chunk := range chunks {
+		mock.ExpectQuery(`SELECT lowrite\(\$1, \$2\)`).
+			WithArgs(7, captureArg{&content}).
+			WillReturnRows(sqlmock.NewRows([]string{"lowrite"}).AddRow(frameHeaderSize + 12 + len(chunk) + 16))
+	}
+	mock.ExpectExec(`SELECT lo_close\(\$1\)`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+	fd, err := store.OpenLOForWriting(ctx, tx, 42)
+	require.NoError(t, err)
+	for _, chunk := range chunks {
+		require.NoError(t, store.WriteLO(ctx, tx, fd, []byte(chunk)))
+	}
+	require.NoError(t, store.FinishLO(ctx, tx, fd))
+	store.CloseLO(ctx, tx, fd)
+	require.NoError(t, tx.Commit())
+	assert.NotContains(t, string(content), "hello")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?is)SELECT\s+data_type.*FROM\s+user_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"data_type", "data_name", "largeobject_oid", "key_id", "wrapped_key"}).
+			AddRow("text", "note", 42, "k1", wrapped))
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WithArgs(42, invRead).
+		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(8))
+	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"loread"}).AddRow(content))
+	mock.ExpectQuery(`SELECT loread\(\$1, \$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"loread"}).AddRow([]byte{}))
+	mock.ExpectCommit()
+
+	resp, err := store.GetData(ctx, testDataUID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", resp.GetTextData())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenLOForWriting_EncryptedWithoutRecord(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+	store := &Store{db: dbMock, keys: testKeyring(t, "k1", "k1")}
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT lo_open\(\$1, \$2\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"lo_open"}).AddRow(7))
+	mock.ExpectExec(`(?i)UPDATE\s+user_data`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+	_, err = store.OpenLOForWriting(ctx, tx, 42)
+	assert.ErrorContains(t, err, "no user_data record for large object 42")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTruncateLO_Encrypted(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+	store := &Store{db: dbMock, keys: testKeyring(t, "k1", "k1")}
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+	store.writers.Store(loHandle{tx: tx, fd: 7}, &loWriter{})
+
+	err = store.TruncateLO(ctx, tx, 7, 10)
+	assert.ErrorContains(t, err, "can only be truncated to 0")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFinishLO_NotEncrypted(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+
+	// Без шифрования на сервере чанки уже записаны, завершать нечего
+	assert.NoError(t, store.FinishLO(ctx, tx, 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDecryptContent(t *testing.T) {
+	keyring := testKeyring(t, "k1", "k1")
+	store := &Store{keys: keyring}
+	dataKey := bytes.Repeat([]byte{9}, masterKeySize)
+	_, wrapped, err := keyring.wrap(dataKey)
+	require.NoError(t, err)
+	aead, err := newAEAD(dataKey)
+	require.NoError(t, err)
+
+	w := &loWriter{aead: aead, oid: 42}
+	first, err := w.push([]byte("first"))
+	require.NoError(t, err)
+	assert.Nil(t, first)
+	first, err = w.push([]byte("second"))
+	require.NoError(t, err)
+	second, err := w.finish()
+	require.NoError(t, err)
+
+	plain, err := store.decryptContent(42, "k1", wrapped, append(append([]byte{}, first...), second...))
+	assert.NoError(t, err)
+	assert.Equal(t, "firstsecond", string(plain))
+
+	t.Run("reordered frames", func(t *testing.T) {
+		_, err := store.decryptContent(42, "k1", wrapped, append(append([]byte{}, second...), first...))
+		assert.ErrorContains(t, err, "frame 0")
+	})
+	t.Run("other large object", func(t *testing.T) {
+		_, err := store.decryptContent(43, "k1", wrapped, append(append([]byte{}, first...), second...))
+		assert.ErrorContains(t, err, "frame 0")
+	})
+	t.Run("truncated", func(t *testing.T) {
+		_, err := store.decryptContent(42, "k1", wrapped, first[:len(first)-1])
+		assert.ErrorContains(t, err, "truncated body")
+	})
+	t.Run("truncated at frame boundary", func(t *testing.T) {
+		_, err := store.decryptContent(42, "k1", wrapped, first)
+		assert.ErrorContains(t, err, "frame 0")
+	})
+	t.Run("empty", func(t *testing.T) {
+		_, err := store.decryptContent(42, "k1", wrapped, nil)
+		assert.ErrorContains(t, err, "missing final frame")
+
+		// Пустое содержимое записывается одним финальным кадром
+		empty, err := (&loWriter{aead: aead, oid: 42}).finish()
+		require.NoError(t, err)
+		plain, err := store.decryptContent(42, "k1", wrapped, empty)
+		assert.NoError(t, err)
+		assert.Empty(t, plain)
+	})
+	t.Run("no master keys", func(t *testing.T) {
+		_, err := (&Store{}).decryptContent(42, "k1", wrapped, first)
+		assert.ErrorIs(t, err, ErrNoMasterKeys)
+	})
+}
+
+func TestRewrapDataKeys(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+
+	dataKey := bytes.Repeat([]byte{5}, masterKeySize)
+	_, oldWrapped, err := testKeyring(t, "k1", "k1", "k2").wrap(dataKey)
+	require.NoError(t, err)
+	keyring := testKeyring(t, "k2", "k1", "k2")
+	store := &Store{db: dbMock, keys: keyring}
+
+	var newWrapped []byte
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?is)SELECT\s+id,\s+key_id,\s+wrapped_key\s+FROM\s+user_data.*FOR\s+UPDATE\s+SKIP\s+LOCKED`).
+		WithArgs("k2", rewrapBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key_id", "wrapped_key"}).AddRow(testDataUID, "k1", oldWrapped))
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+key_id`).
+		WithArgs("k2", captureArg{&newWrapped}, testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectEmptyRewrapBatch(mock)
+	mock.ExpectQuery(`(?is)SELECT\s+COUNT\(\*\)\s+FROM\s+user_data`).
+		WithArgs("k2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	n, err := store.RewrapDataKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	unwrapped, err := keyring.unwrap("k2", newWrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectEmptyRewrapBatch партия перешифрования без строк, например когда остальные заняты записью
+func expectEmptyRewrapBatch(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+key_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key_id", "wrapped_key"}))
+	mock.ExpectCommit()
+}
+
+func TestRewrapDataKeys_LockedRows(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+	store := &Store{db: dbMock, keys: testKeyring(t, "k2", "k1", "k2")}
+	oldDelay := rewrapRetryDelay
+	rewrapRetryDelay = 0
+	t.Cleanup(func() { rewrapRetryDelay = oldDelay })
+
+	// Строки, занятые записью, пропускаются SKIP LOCKED: партии пустые, но записи остаются
+	for i := 0; i <= rewrapRetries; i++ {
+		expectEmptyRewrapBatch(mock)
+		mock.ExpectQuery(`(?is)SELECT\s+COUNT\(\*\)\s+FROM\s+user_data`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	}
+
+	n, err := store.RewrapDataKeys(context.Background())
+	assert.ErrorIs(t, err, ErrRewrapIncomplete)
+	assert.ErrorContains(t, err, "2 items are locked by writes")
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRewrapDataKeys_UnknownKey(t *testing.T) {
+	dbMock, mock, _ := setupMockDB(t)
+	defer dbMock.Close()
+	store := &Store{db: dbMock, keys: testKeyring(t, "k2", "k2")}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+key_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key_id", "wrapped_key"}).AddRow(testDataUID, "k1", []byte("x")))
+	mock.ExpectRollback()
+
+	n, err := store.RewrapDataKeys(context.Background())
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = (&Store{db: dbMock}).RewrapDataKeys(context.Background())
+	assert.ErrorIs(t, err, ErrNoMasterKeys)
+}
+
+func TestCountDataKeys(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+COALESCE\(key_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "items"}).AddRow("", 3).AddRow("k1", 5))
+
+	counts, err := store.CountDataKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"": 3, "k1": 5}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,255 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+// ErrFolderCycle папку нельзя переместить в саму себя или в свою подпапку
+var ErrFolderCycle = errors.New("folder can not be moved into itself or its subfolder")
+
+// CreateFolder создание папки пользователя
+func (s *Store) CreateFolder(ctx context.Context, userUID, parentID, name string) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	if parentID != "" {
+		if err := s.checkFolderOwner(ctxDB, q, userUID, parentID); err != nil {
+			return "", err
+		}
+	}
+
+	id, err := q.CreateFolder(ctxDB, sqlc.CreateFolderParams{
+		UserID:   stringToNullUUID(userUID),
+		ParentID: stringToNullUUID(parentID),
+		Name:     name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create folder: %w", err)
+	}
+	return id.String(), nil
+}
+
+// RenameFolder переименование папки пользователя
+func (s *Store) RenameFolder(ctx context.Context, userUID, folderID, name string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.RenameFolder(ctxDB, sqlc.RenameFolderParams{
+		Name:   name,
+		ID:     stringToNullUUID(folderID).UUID,
+		UserID: stringToNullUUID(userUID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename folder: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MoveFolder перемещение папки пользователя, пустой parentID переносит папку в корень
+func (s *Store) MoveFolder(ctx context.Context, userUID, folderID, parentID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	if parentID != "" {
+		// Цикл может пройти через любых предков, поэтому блокируются все папки пользователя:
+		// параллельные перемещения в одном дереве выполняются по очереди и видят результат друг друга
+		if err := q.LockUserFolders(ctxDB, stringToNullUUID(userUID)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to lock folders: %w", err)
+		}
+		if err := s.checkFolderOwner(ctxDB, q, userUID, parentID); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		// Защита от циклов: новый родитель не должен лежать внутри перемещаемой папки
+		inSubtree, err := q.IsFolderInSubtree(ctxDB, sqlc.IsFolderInSubtreeParams{
+			RootID:   stringToNullUUID(folderID).UUID,
+			FolderID: stringToNullUUID(parentID).UUID,
+		})
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to check folder subtree: %w", err)
+		}
+		if inSubtree {
+			_ = tx.Rollback()
+			return ErrFolderCycle
+		}
+	}
+
+	affected, err := q.MoveFolder(ctxDB, sqlc.MoveFolderParams{
+		ParentID: stringToNullUUID(parentID),
+		ID:       stringToNullUUID(folderID).UUID,
+		UserID:   stringToNullUUID(userUID),
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to move folder: %w", err)
+	}
+	if affected == 0 {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteFolder удаление папки пользователя вместе с подпапками, данные остаются без папки
+func (s *Store) DeleteFolder(ctx context.Context, userUID, folderID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.DeleteFolder(ctxDB, sqlc.DeleteFolderParams{
+		ID:     stringToNullUUID(folderID).UUID,
+		UserID: stringToNullUUID(userUID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListFolders получение всех папок пользователя
+func (s *Store) ListFolders(ctx context.Context, userUID string) (*pb.ListFoldersResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListFolders(ctxDB, stringToNullUUID(userUID))
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	folders := make([]*pb.Folder, 0, len(rows))
+	for _, row := range rows {
+		folders = append(folders, &pb.Folder{
+			Id:       row.ID.String(),
+			ParentId: nullUUIDToString(row.ParentID),
+			Name:     row.Name,
+		})
+	}
+	return &pb.ListFoldersResponse{Folders: folders}, nil
+}
+
+// MoveData перемещение данных в папку, пустой folderID убирает данные из папки
+func (s *Store) MoveData(ctx context.Context, userUID, dataID, folderID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	if folderID != "" {
+		if err := s.checkFolderOwner(ctxDB, q, userUID, folderID); err != nil {
+			return err
+		}
+	}
+
+	affected, err := q.MoveUserData(ctxDB, sqlc.MoveUserDataParams{
+		FolderID: stringToNullUUID(folderID),
+		ID:       stringToNullUUID(dataID).UUID,
+		UserID:   stringToNullUUID(userUID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move data: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetDataTags замена набора тегов у данных пользователя
+func (s *Store) SetDataTags(ctx context.Context, userUID, dataID string, tags []string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	id := stringToNullUUID(dataID).UUID
+	isOwner, err := q.IsDataOwner(ctxDB, sqlc.IsDataOwnerParams{
+		ID:     id,
+		UserID: stringToNullUUID(userUID),
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to check data owner: %w", err)
+	}
+	if !isOwner {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	if err := q.DeleteDataTags(ctxDB, id); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete tags: %w", err)
+	}
+	for _, tag := range normalizeTags(tags) {
+		if err := q.InsertDataTag(ctxDB, sqlc.InsertDataTagParams{DataID: id, Tag: tag}); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert tag: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// checkFolderOwner проверка, что папка существует и принадлежит пользователю
+func (s *Store) checkFolderOwner(ctx context.Context, q *sqlc.Queries, userUID, folderID string) error {
+	isOwner, err := q.IsFolderOwner(ctx, sqlc.IsFolderOwnerParams{
+		ID:     stringToNullUUID(folderID).UUID,
+		UserID: stringToNullUUID(userUID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check folder owner: %w", err)
+	}
+	if !isOwner {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// normalizeTags обрезка пробелов, удаление пустых и повторяющихся тегов
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testUserUID   = "3a0a4950-16e3-4720-814b-17e6b4fd0bc1"
+	testFolderUID = "3a0a4950-16e3-4720-814b-17e6b4fd0bc2"
+	testParentUID = "3a0a4950-16e3-4720-814b-17e6b4fd0bc3"
+	testDataUID   = "3a0a4950-16e3-4720-814b-17e6b4fd0bc4"
+)
+
+func TestCreateFolder(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+folders`).
+		WithArgs(testParentUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`(?i)INSERT\s+INTO\s+folders\s*\(user_id,\s*parent_id,\s*name\)`).
+		WithArgs(testUserUID, testParentUID, "work").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testFolderUID))
+
+	id, err := store.CreateFolder(context.Background(), testUserUID, testParentUID, "work")
+	assert.NoError(t, err)
+	assert.Equal(t, testFolderUID, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateFolder_ParentNotOwned(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+folders`).
+		WithArgs(testParentUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, err := store.CreateFolder(context.Background(), testUserUID, testParentUID, "work")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenameFolder(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+folders\s+SET\s+name`).
+		WithArgs("home", testFolderUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := store.RenameFolder(context.Background(), testUserUID, testFolderUID, "home")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenameFolder_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+folders\s+SET\s+name`).
+		WithArgs("home", testFolderUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := store.RenameFolder(context.Background(), testUserUID, testFolderUID, "home")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMoveFolder(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)SELECT\s+id\s+FROM\s+folders\s+WHERE\s+user_id\s*=\s*\$1\s+ORDER\s+BY\s+id\s+FOR\s+UPDATE`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+folders`).
+		WithArgs(testParentUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`(?i)WITH\s+RECURSIVE\s+subtree`).
+		WithArgs(testFolderUID, testParentUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`(?i)UPDATE\s+folders\s+SET\s+parent_id`).
+		WithArgs(testParentUID, testFolderUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.MoveFolder(context.Background(), testUserUID, testFolderUID, testParentUID)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveFolder_Cycle(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)SELECT\s+id\s+FROM\s+folders\s+WHERE\s+user_id\s*=\s*\$1\s+ORDER\s+BY\s+id\s+FOR\s+UPDATE`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+folders`).
+		WithArgs(testParentUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`(?i)WITH\s+RECURSIVE\s+subtree`).
+		WithArgs(testFolderUID, testParentUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err := store.MoveFolder(context.Background(), testUserUID, testFolderUID, testParentUID)
+	assert.ErrorIs(t, err, ErrFolderCycle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveFolder_LockError(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)SELECT\s+id\s+FROM\s+folders\s+WHERE\s+user_id\s*=\s*\$1\s+ORDER\s+BY\s+id\s+FOR\s+UPDATE`).
+		WithArgs(testUserUID).
+		WillReturnError(errors.New("lock timeout"))
+	mock.ExpectRollback()
+
+	err := store.MoveFolder(context.Background(), testUserUID, testFolderUID, testParentUID)
+	assert.ErrorContains(t, err, "failed to lock folders")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMoveFolder_ToRoot(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+folders\s+SET\s+parent_id`).
+		WithArgs(nil, testFolderUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.MoveFolder(context.Background(), testUserUID, testFolderUID, "")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFolder_Error(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+folders`).
+		WithArgs(testFolderUID, testUserUID).
+		WillReturnError(errors.New("error"))
+
+	err := store.DeleteFolder(context.Background(), testUserUID, testFolderUID)
+	assert.Error(t, err)
+}
+
+func TestListFolders(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+parent_id,\s+name\s+FROM\s+folders`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "name"}).
+			AddRow(testParentUID, nil, "root").
+			AddRow(testFolderUID, testParentUID, "child"))
+
+	resp, err := store.ListFolders(context.Background(), testUserUID)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Folders, 2)
+	assert.Empty(t, resp.Folders[0].ParentId)
+	assert.Equal(t, testParentUID, resp.Folders[1].ParentId)
+	assert.Equal(t, "child", resp.Folders[1].Name)
+}
+
+func TestMoveData_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+folder_id`).
+		WithArgs(nil, testDataUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := store.MoveData(context.Background(), testUserUID, testDataUID, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSetDataTags(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+data_tags`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+data_tags`).
+		WithArgs(testDataUID, "work").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+data_tags`).
+		WithArgs(testDataUID, "bank").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.SetDataTags(context.Background(), testUserUID, testDataUID, []string{" work ", "", "bank", "work"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetDataTags_NotOwner(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	err := store.SetDataTags(context.Background(), testUserUID, testDataUID, []string{"work"})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
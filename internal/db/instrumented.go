@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//go:generate go run ./instrumentgen -source repository.go -skip Ping,Close -output instrumented_gen.go
+
+// instrumentedRepository обёртка Repository: спан и замер времени на каждый метод хранилища.
+// Методы генерируются по интерфейсу в instrumented_gen.go. Ping и Close не замеряются,
+// чтобы проверки готовности не засоряли трассировку
+type instrumentedRepository struct {
+	next     Repository
+	tracer   trace.Tracer
+	duration *prometheus.HistogramVec
+}
+
+// Instrument обёртка хранилища трассировкой через tp и метриками в reg.
+// Для Store также публикуются метрики пула соединений database/sql
+func Instrument(next Repository, tp trace.TracerProvider, reg prometheus.Registerer) (Repository, error) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gault_db_duration_seconds",
+		Help:    "Duration of storage calls.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "result"})
+	if err := reg.Register(duration); err != nil {
+		return nil, err
+	}
+	if store, ok := next.(*Store); ok {
+		if err := reg.Register(collectors.NewDBStatsCollector(store.db, "gault")); err != nil {
+			return nil, err
+		}
+	}
+
+	return &instrumentedRepository{
+		next:     next,
+		tracer:   tp.Tracer("github.com/fngoc/gault/internal/db"),
+		duration: duration,
+	}, nil
+}
+
+// start начало спана Store.<method>, возвращённая функция завершает спан и записывает время
+func (r *instrumentedRepository) start(ctx context.Context, method string) (context.Context, func(error)) {
+	ctx, span := r.tracer.Start(ctx, "Store."+method, trace.WithSpanKind(trace.SpanKindClient))
+	started := time.Now()
+	return ctx, func(err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		r.duration.WithLabelValues(method, result).Observe(time.Since(started).Seconds())
+		span.End()
+	}
+}
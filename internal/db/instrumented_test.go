@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dbMock "github.com/fngoc/gault/gen/go/db"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	next := dbMock.NewMockRepository(ctrl)
+	recorder := tracetest.NewSpanRecorder()
+	reg := prometheus.NewRegistry()
+	rep, err := Instrument(next, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), reg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	next.EXPECT().DeleteData(gomock.Any(), testDataUID).Return(nil)
+	next.EXPECT().RestoreData(gomock.Any(), testDataUID).Return(ErrNotFound)
+	next.EXPECT().Ping(ctx).Return(nil)
+
+	assert.NoError(t, rep.DeleteData(ctx, testDataUID))
+	assert.ErrorIs(t, rep.RestoreData(ctx, testDataUID), ErrNotFound)
+	assert.NoError(t, rep.Ping(ctx))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "Store.DeleteData", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "Store.RestoreData", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	results := make(map[string]uint64)
+	for _, metric := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		results[labels["method"]+"/"+labels["result"]] = metric.GetHistogram().GetSampleCount()
+	}
+	assert.Equal(t, map[string]uint64{"DeleteData/ok": 1, "RestoreData/error": 1}, results)
+}
+
+func TestInstrument_PoolStats(t *testing.T) {
+	dbConn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer dbConn.Close()
+
+	reg := prometheus.NewRegistry()
+	_, err = Instrument(&Store{db: dbConn}, sdktrace.NewTracerProvider(), reg)
+	require.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(reg, "go_sql_open_connections", "go_sql_in_use_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Повторная регистрация в том же реестре — ошибка, а не паника
+	_, err = Instrument(&Store{db: dbConn}, sdktrace.NewTracerProvider(), reg)
+	assert.True(t, errors.As(err, &prometheus.AlreadyRegisteredError{}))
+}
@@ -0,0 +1,219 @@
+// instrumentgen генерация обёрток instrumentedRepository по интерфейсу Repository:
+// каждый метод с context.Context открывает спан и замеряет время через r.start
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	source := flag.String("source", "repository.go", "file with the interface")
+	iface := flag.String("interface", "Repository", "interface to wrap")
+	typeName := flag.String("type", "instrumentedRepository", "wrapper type")
+	skip := flag.String("skip", "", "comma-separated methods that are passed through without instrumentation")
+	output := flag.String("output", "instrumented_gen.go", "output file")
+	flag.Parse()
+
+	skipped := make(map[string]bool)
+	for _, name := range strings.Split(*skip, ",") {
+		skipped[strings.TrimSpace(name)] = true
+	}
+
+	code, err := generate(*source, *iface, *typeName, skipped)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate исходный код обёрток для методов интерфейса iface из файла source
+func generate(source, iface, typeName string, skipped map[string]bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := interfaceMethods(file, iface)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{fset: fset, typeName: typeName, used: make(map[string]bool)}
+	var body bytes.Buffer
+	for _, method := range methods {
+		if err := g.method(&body, method, skipped[method.Names[0].Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by instrumentgen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package %s\n\n", file.Name.Name)
+	// Стандартная библиотека отдельной группой, как в остальном коде
+	var std, other []string
+	for _, spec := range file.Imports {
+		if !g.used[importName(spec)] {
+			continue
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, g.node(spec))
+		} else {
+			std = append(std, g.node(spec))
+		}
+	}
+	out.WriteString("import (\n" + strings.Join(std, "\n") + "\n\n" + strings.Join(other, "\n") + "\n)\n")
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// interfaceMethods методы интерфейса name, встроенные интерфейсы не поддерживаются
+func interfaceMethods(file *ast.File, name string) ([]*ast.Field, error) {
+	var methods []*ast.Field
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return true
+		}
+		if it, ok := spec.Type.(*ast.InterfaceType); ok {
+			found = true
+			methods = it.Methods.List
+		}
+		return false
+	})
+	if !found {
+		return nil, fmt.Errorf("interface %s not found", name)
+	}
+	for _, method := range methods {
+		if len(method.Names) == 0 {
+			return nil, fmt.Errorf("interface %s: embedded interfaces are not supported", name)
+		}
+	}
+	return methods, nil
+}
+
+// generator состояние генерации: использованные в сигнатурах пакеты попадают в импорты
+type generator struct {
+	fset     *token.FileSet
+	typeName string
+	used     map[string]bool
+}
+
+// method обёртка одного метода. Без context.Context или при passthrough вызов передаётся как есть
+func (g *generator) method(w *bytes.Buffer, field *ast.Field, passthrough bool) error {
+	name := field.Names[0].Name
+	fn, ok := field.Type.(*ast.FuncType)
+	if !ok {
+		return fmt.Errorf("%s is not a method", name)
+	}
+
+	var params, args []string
+	ctxName := ""
+	i := 0
+	for _, param := range fn.Params.List {
+		typ := g.node(param.Type)
+		names := param.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, ident := range names {
+			argName := fmt.Sprintf("p%d", i)
+			switch {
+			case ident != nil && ident.Name != "_":
+				argName = ident.Name
+			case typ == "context.Context" && ctxName == "":
+				argName = "ctx"
+			}
+			if typ == "context.Context" && ctxName == "" {
+				ctxName = argName
+			}
+			params = append(params, argName+" "+typ)
+			if _, variadic := param.Type.(*ast.Ellipsis); variadic {
+				argName += "..."
+			}
+			args = append(args, argName)
+			i++
+		}
+	}
+
+	var results []string
+	if fn.Results != nil {
+		for _, result := range fn.Results.List {
+			n := max(len(result.Names), 1)
+			for range n {
+				results = append(results, g.node(result.Type))
+			}
+		}
+	}
+	returnsErr := len(results) > 0 && results[len(results)-1] == "error"
+	instrumented := !passthrough && ctxName != ""
+
+	var signature string
+	switch {
+	case instrumented && returnsErr:
+		named := make([]string, len(results))
+		for j, typ := range results[:len(results)-1] {
+			named[j] = "_ " + typ
+		}
+		named[len(named)-1] = "err error"
+		signature = "(" + strings.Join(named, ", ") + ")"
+	case len(results) == 1:
+		signature = results[0]
+	case len(results) > 1:
+		signature = "(" + strings.Join(results, ", ") + ")"
+	}
+
+	fmt.Fprintf(w, "\nfunc (r *%s) %s(%s) %s {\n", g.typeName, name, strings.Join(params, ", "), signature)
+	if instrumented {
+		fmt.Fprintf(w, "\t%s, end := r.start(%s, %s)\n", ctxName, ctxName, strconv.Quote(name))
+		if returnsErr {
+			w.WriteString("\tdefer func() { end(err) }()\n")
+		} else {
+			w.WriteString("\tdefer end(nil)\n")
+		}
+	}
+	call := fmt.Sprintf("r.next.%s(%s)", name, strings.Join(args, ", "))
+	if len(results) > 0 {
+		call = "return " + call
+	}
+	fmt.Fprintf(w, "\t%s\n}\n", call)
+	return nil
+}
+
+// node текст узла AST, пакеты выражений вида pkg.Type отмечаются как использованные
+func (g *generator) node(n ast.Node) string {
+	ast.Inspect(n, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				g.used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, g.fset, n)
+	return buf.String()
+}
+
+// importName имя, под которым пакет доступен в файле
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path[strings.LastIndex(path, "/")+1:]
+}
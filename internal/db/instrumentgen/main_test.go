@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSource = `package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	pb "example.com/api/v1"
+)
+
+type Repository interface {
+	Ping(ctx context.Context) error
+	Get(context.Context, string) (*pb.Item, error)
+	Create(ctx context.Context, name string, tags ...string) (string, string, error)
+	Check(ctx context.Context, id string) bool
+	Close(ctx context.Context, tx *sql.Tx, fd int)
+	Version() string
+}
+`
+
+func TestGenerate(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "repository.go")
+	require.NoError(t, os.WriteFile(source, []byte(testSource), 0o600))
+
+	code, err := generate(source, "Repository", "wrapped", map[string]bool{"Ping": true})
+	require.NoError(t, err)
+	out := string(code)
+
+	// Неиспользованный пакет time не импортируется, сторонние пакеты отдельной группой
+	assert.Contains(t, out, "import (\n\t\"context\"\n\t\"database/sql\"\n\n\tpb \"example.com/api/v1\"\n)")
+	assert.Contains(t, out, "func (r *wrapped) Ping(ctx context.Context) error {\n\treturn r.next.Ping(ctx)\n}")
+	assert.Contains(t, out, `func (r *wrapped) Get(ctx context.Context, p1 string) (_ *pb.Item, err error) {
+	ctx, end := r.start(ctx, "Get")
+	defer func() { end(err) }()
+	return r.next.Get(ctx, p1)
+}`)
+	assert.Contains(t, out, `func (r *wrapped) Create(ctx context.Context, name string, tags ...string) (_ string, _ string, err error) {
+	ctx, end := r.start(ctx, "Create")
+	defer func() { end(err) }()
+	return r.next.Create(ctx, name, tags...)
+}`)
+	assert.Contains(t, out, `func (r *wrapped) Check(ctx context.Context, id string) bool {
+	ctx, end := r.start(ctx, "Check")
+	defer end(nil)
+	return r.next.Check(ctx, id)
+}`)
+	assert.Contains(t, out, `func (r *wrapped) Close(ctx context.Context, tx *sql.Tx, fd int) {
+	ctx, end := r.start(ctx, "Close")
+	defer end(nil)
+	r.next.Close(ctx, tx, fd)
+}`)
+	// Методы без контекста передаются как есть
+	assert.Contains(t, out, "func (r *wrapped) Version() string {\n\treturn r.next.Version()\n}")
+
+	_, err = generate(source, "Missing", "wrapped", nil)
+	assert.ErrorContains(t, err, "interface Missing not found")
+}
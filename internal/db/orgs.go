@@ -0,0 +1,418 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+const (
+	// roleOwner владелец организации
+	roleOwner = "owner"
+	// roleAdmin администратор организации
+	roleAdmin = "admin"
+	// roleMember участник организации
+	roleMember = "member"
+	// roleReadOnly участник организации с доступом только на чтение
+	roleReadOnly = "read_only"
+)
+
+// CreateOrganization создание организации, создатель становится её владельцем
+func (s *Store) CreateOrganization(ctx context.Context, userUID, name string, wrappedKey []byte) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	orgID, err := q.CreateOrganization(ctxDB, name)
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	err = q.UpsertOrgMember(ctxDB, sqlc.UpsertOrgMemberParams{
+		OrgID:      orgID,
+		UserID:     stringToNullUUID(userUID).UUID,
+		Role:       roleOwner,
+		WrappedKey: wrappedKey,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return "", fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return orgID.String(), nil
+}
+
+// ListOrganizations получение организаций пользователя
+func (s *Store) ListOrganizations(ctx context.Context, userUID string) (*pb.ListOrganizationsResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListUserOrganizations(ctxDB, stringToNullUUID(userUID).UUID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	orgs := make([]*pb.Organization, 0, len(rows))
+	for _, row := range rows {
+		orgs = append(orgs, &pb.Organization{
+			Id:         row.ID.String(),
+			Name:       row.Name,
+			Role:       roleFromString(row.Role),
+			WrappedKey: row.WrappedKey,
+		})
+	}
+	return &pb.ListOrganizationsResponse{Organizations: orgs}, nil
+}
+
+// GetOrgRole получение роли пользователя в организации, ErrNotFound если он не участник
+func (s *Store) GetOrgRole(ctx context.Context, userUID, orgID string) (pb.OrgRole, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	role, err := q.GetOrgMemberRole(ctxDB, sqlc.GetOrgMemberRoleParams{
+		OrgID:  stringToNullUUID(orgID).UUID,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return pb.OrgRole_ORG_ROLE_UNSPECIFIED, ErrNotFound
+	}
+	if err != nil {
+		return pb.OrgRole_ORG_ROLE_UNSPECIFIED, fmt.Errorf("query error: %w", err)
+	}
+	return roleFromString(role), nil
+}
+
+// GetCollectionRole получение роли пользователя в организации коллекции, ErrNotFound если он не участник
+func (s *Store) GetCollectionRole(ctx context.Context, userUID, collectionID string) (pb.OrgRole, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	role, err := q.GetCollectionMemberRole(ctxDB, sqlc.GetCollectionMemberRoleParams{
+		ID:     stringToNullUUID(collectionID).UUID,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return pb.OrgRole_ORG_ROLE_UNSPECIFIED, ErrNotFound
+	}
+	if err != nil {
+		return pb.OrgRole_ORG_ROLE_UNSPECIFIED, fmt.Errorf("query error: %w", err)
+	}
+	return roleFromString(role), nil
+}
+
+// InviteMember создание или замена приглашения пользователя в организацию
+func (s *Store) InviteMember(ctx context.Context, orgID, inviterUID, inviteeUID string, role pb.OrgRole, wrappedKey []byte) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	id, err := q.UpsertOrgInvitation(ctxDB, sqlc.UpsertOrgInvitationParams{
+		OrgID:      stringToNullUUID(orgID).UUID,
+		InviteeID:  stringToNullUUID(inviteeUID).UUID,
+		InviterID:  stringToNullUUID(inviterUID).UUID,
+		Role:       roleToString(role),
+		WrappedKey: wrappedKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to invite member: %w", err)
+	}
+	return id.String(), nil
+}
+
+// ListInvitations получение приглашений пользователя
+func (s *Store) ListInvitations(ctx context.Context, userUID string) (*pb.ListInvitationsResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListUserInvitations(ctxDB, stringToNullUUID(userUID).UUID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	invitations := make([]*pb.Invitation, 0, len(rows))
+	for _, row := range rows {
+		invitations = append(invitations, &pb.Invitation{
+			Id:           row.ID.String(),
+			OrgId:        row.OrgID.String(),
+			OrgName:      row.OrgName,
+			InviterLogin: row.InviterName,
+			Role:         roleFromString(row.Role),
+		})
+	}
+	return &pb.ListInvitationsResponse{Invitations: invitations}, nil
+}
+
+// AcceptInvitation принятие приглашения, пользователь становится участником организации
+func (s *Store) AcceptInvitation(ctx context.Context, userUID, invitationID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	userID := stringToNullUUID(userUID).UUID
+	invitation, err := q.DeleteUserInvitation(ctxDB, sqlc.DeleteUserInvitationParams{
+		ID:        stringToNullUUID(invitationID).UUID,
+		InviteeID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	err = q.UpsertOrgMember(ctxDB, sqlc.UpsertOrgMemberParams{
+		OrgID:      invitation.OrgID,
+		UserID:     userID,
+		Role:       invitation.Role,
+		WrappedKey: invitation.WrappedKey,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeclineInvitation отклонение приглашения
+func (s *Store) DeclineInvitation(ctx context.Context, userUID, invitationID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	_, err := q.DeleteUserInvitation(ctxDB, sqlc.DeleteUserInvitationParams{
+		ID:        stringToNullUUID(invitationID).UUID,
+		InviteeID: stringToNullUUID(userUID).UUID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete invitation: %w", err)
+	}
+	return nil
+}
+
+// ListMembers получение участников организации
+func (s *Store) ListMembers(ctx context.Context, orgID string) (*pb.ListMembersResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListOrgMembers(ctxDB, stringToNullUUID(orgID).UUID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	members := make([]*pb.OrgMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, &pb.OrgMember{
+			UserUid: row.UserID.String(),
+			Login:   row.Username,
+			Role:    roleFromString(row.Role),
+		})
+	}
+	return &pb.ListMembersResponse{Members: members}, nil
+}
+
+// SetMemberRole изменение роли участника организации
+func (s *Store) SetMemberRole(ctx context.Context, orgID, userUID string, role pb.OrgRole) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.UpdateOrgMemberRole(ctxDB, sqlc.UpdateOrgMemberRoleParams{
+		Role:   roleToString(role),
+		OrgID:  stringToNullUUID(orgID).UUID,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveMember исключение участника из организации
+func (s *Store) RemoveMember(ctx context.Context, orgID, userUID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.DeleteOrgMember(ctxDB, sqlc.DeleteOrgMemberParams{
+		OrgID:  stringToNullUUID(orgID).UUID,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountOrgOwners получение количества владельцев организации
+func (s *Store) CountOrgOwners(ctx context.Context, orgID string) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	count, err := q.CountOrgOwners(ctxDB, stringToNullUUID(orgID).UUID)
+	if err != nil {
+		return 0, fmt.Errorf("query error: %w", err)
+	}
+	return count, nil
+}
+
+// CreateCollection создание коллекции организации
+func (s *Store) CreateCollection(ctx context.Context, orgID, name string) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	id, err := q.CreateCollection(ctxDB, sqlc.CreateCollectionParams{
+		OrgID: stringToNullUUID(orgID).UUID,
+		Name:  name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create collection: %w", err)
+	}
+	return id.String(), nil
+}
+
+// ListCollections получение коллекций организации
+func (s *Store) ListCollections(ctx context.Context, orgID string) (*pb.ListCollectionsResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListOrgCollections(ctxDB, stringToNullUUID(orgID).UUID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	collections := make([]*pb.Collection, 0, len(rows))
+	for _, row := range rows {
+		collections = append(collections, &pb.Collection{
+			Id:   row.ID.String(),
+			Name: row.Name,
+		})
+	}
+	return &pb.ListCollectionsResponse{Collections: collections}, nil
+}
+
+// DeleteCollection удаление коллекции организации вместе с данными и их Large Objects
+func (s *Store) DeleteCollection(ctx context.Context, orgID, collectionID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	params := sqlc.ListCollectionDataParams{
+		ID:    stringToNullUUID(collectionID).UUID,
+		OrgID: stringToNullUUID(orgID).UUID,
+	}
+	// Каскадное удаление строк user_data не удаляет Large Objects, поэтому они удаляются явно
+	rows, err := q.ListCollectionData(ctxDB, params)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to list collection data: %w", err)
+	}
+	for _, row := range rows {
+		if err := purgeRecord(ctxDB, tx, q, row.ID, row.LargeobjectOid); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	affected, err := q.DeleteCollection(ctxDB, sqlc.DeleteCollectionParams{ID: params.ID, OrgID: params.OrgID})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if affected == 0 {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetDataCollectionTx перенос данных в коллекцию командного хранилища
+func (s *Store) SetDataCollectionTx(ctx context.Context, tx *sql.Tx, userDataID, collectionID string) error {
+	q := sqlc.New(tx)
+	err := q.SetUserDataCollection(ctx, sqlc.SetUserDataCollectionParams{
+		CollectionID: stringToNullUUID(collectionID),
+		ID:           stringToNullUUID(userDataID).UUID,
+	})
+	if err != nil {
+		return fmt.Errorf("update user_data collection failed: %w", err)
+	}
+	return nil
+}
+
+// roleToString перевод роли в значение колонки role
+func roleToString(role pb.OrgRole) string {
+	switch role {
+	case pb.OrgRole_ORG_ROLE_OWNER:
+		return roleOwner
+	case pb.OrgRole_ORG_ROLE_ADMIN:
+		return roleAdmin
+	case pb.OrgRole_ORG_ROLE_MEMBER:
+		return roleMember
+	default:
+		return roleReadOnly
+	}
+}
+
+// roleFromString перевод значения колонки role в роль
+func roleFromString(role string) pb.OrgRole {
+	switch role {
+	case roleOwner:
+		return pb.OrgRole_ORG_ROLE_OWNER
+	case roleAdmin:
+		return pb.OrgRole_ORG_ROLE_ADMIN
+	case roleMember:
+		return pb.OrgRole_ORG_ROLE_MEMBER
+	default:
+		return pb.OrgRole_ORG_ROLE_READ_ONLY
+	}
+}
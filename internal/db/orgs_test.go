@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testOrgUID        = "3a0a4950-16e3-4720-814b-17e6b4fd0b01"
+	testCollectionUID = "3a0a4950-16e3-4720-814b-17e6b4fd0b02"
+	testInvitationUID = "3a0a4950-16e3-4720-814b-17e6b4fd0b03"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)INSERT\s+INTO\s+organizations`).
+		WithArgs("team").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testOrgUID))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+org_members`).
+		WithArgs(testOrgUID, testUserUID, "owner", []byte("wrapped")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	orgID, err := store.CreateOrganization(context.Background(), testUserUID, "team", []byte("wrapped"))
+	assert.NoError(t, err)
+	assert.Equal(t, testOrgUID, orgID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListOrganizations(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+o\.id,\s+o\.name,\s+m\.role`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "role", "wrapped_key"}).
+			AddRow(testOrgUID, "team", "admin", []byte("wrapped")))
+
+	resp, err := store.ListOrganizations(context.Background(), testUserUID)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Organizations, 1)
+	assert.Equal(t, "team", resp.Organizations[0].Name)
+	assert.Equal(t, pb.OrgRole_ORG_ROLE_ADMIN, resp.Organizations[0].Role)
+	assert.Equal(t, []byte("wrapped"), resp.Organizations[0].WrappedKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrgRole(t *testing.T) {
+	t.Run("member", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)SELECT\s+role\s+FROM\s+org_members`).
+			WithArgs(testOrgUID, testUserUID).
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("member"))
+
+		role, err := store.GetOrgRole(context.Background(), testUserUID, testOrgUID)
+		assert.NoError(t, err)
+		assert.Equal(t, pb.OrgRole_ORG_ROLE_MEMBER, role)
+	})
+	t.Run("not member", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)SELECT\s+role\s+FROM\s+org_members`).
+			WithArgs(testOrgUID, testUserUID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := store.GetOrgRole(context.Background(), testUserUID, testOrgUID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`(?i)DELETE\s+FROM\s+org_invitations`).
+			WithArgs(testInvitationUID, testUserUID).
+			WillReturnRows(sqlmock.NewRows([]string{"org_id", "role", "wrapped_key"}).
+				AddRow(testOrgUID, "read_only", []byte("wrapped")))
+		mock.ExpectExec(`(?i)INSERT\s+INTO\s+org_members`).
+			WithArgs(testOrgUID, testUserUID, "read_only", []byte("wrapped")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.AcceptInvitation(context.Background(), testUserUID, testInvitationUID)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("not found", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`(?i)DELETE\s+FROM\s+org_invitations`).
+			WithArgs(testInvitationUID, testUserUID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := store.AcceptInvitation(context.Background(), testUserUID, testInvitationUID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetMemberRole_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+org_members\s+SET\s+role`).
+		WithArgs("admin", testOrgUID, testRecipientUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := store.SetMemberRole(context.Background(), testOrgUID, testRecipientUID, pb.OrgRole_ORG_ROLE_ADMIN)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListCollections(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+name\s+FROM\s+collections`).
+		WithArgs(testOrgUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(testCollectionUID, "servers"))
+
+	resp, err := store.ListCollections(context.Background(), testOrgUID)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Collections, 1)
+	assert.Equal(t, testCollectionUID, resp.Collections[0].Id)
+	assert.Equal(t, "servers", resp.Collections[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCollection(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+d\.id,\s+d\.largeobject_oid\s+FROM\s+user_data\s+d`).
+		WithArgs(testCollectionUID, testOrgUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "largeobject_oid"}).AddRow(testDataUID, 42))
+	mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+collections`).
+		WithArgs(testCollectionUID, testOrgUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.DeleteCollection(context.Background(), testOrgUID, testCollectionUID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCollection_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+d\.id,\s+d\.largeobject_oid`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "largeobject_oid"}))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+collections`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := store.DeleteCollection(context.Background(), testOrgUID, testCollectionUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataNameList_OrgCollection(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+.+\s+FROM\s+user_data\s+d.+ORDER\s+BY\s+d\.data_name,\s+d\.id`).
+		WithArgs(testUserUID, testOrgUID, testCollectionUID, nil, nil, nil, nil, nil, nil, int32(defaultPageSize+1)).
+		WillReturnRows(sqlmock.NewRows(userDataColumns))
+
+	resp, err := store.GetDataNameList(context.Background(), testUserUID, &pb.GetUserDataListRequest{
+		OrgId:        testOrgUID,
+		CollectionId: testCollectionUID,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+const (
+	// defaultPageSize размер страницы листа данных по умолчанию
+	defaultPageSize = 50
+	// maxPageSize максимальный размер страницы листа данных
+	maxPageSize = 200
+)
+
+// ErrInvalidPageToken курсор страницы повреждён или выдан для другой сортировки
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageCursor содержимое курсора страницы: сортировка, ключ сортировки и id последней записи
+type pageCursor struct {
+	Sort pb.SortOrder `json:"s"`
+	Key  string       `json:"k"`
+	ID   string       `json:"id"`
+}
+
+// pageSize размер страницы с учётом значения по умолчанию и ограничения сверху
+func pageSize(size uint32) int32 {
+	switch {
+	case size == 0:
+		return defaultPageSize
+	case size > maxPageSize:
+		return maxPageSize
+	default:
+		return int32(size)
+	}
+}
+
+// normalizeSort сортировка по умолчанию — по имени
+func normalizeSort(sort pb.SortOrder) pb.SortOrder {
+	if sort == pb.SortOrder_SORT_ORDER_UNSPECIFIED {
+		return pb.SortOrder_SORT_ORDER_NAME
+	}
+	return sort
+}
+
+// sortKey значение ключа сортировки записи в текстовом виде для курсора
+func sortKey(sort pb.SortOrder, row sqlc.UserDatum) string {
+	switch sort {
+	case pb.SortOrder_SORT_ORDER_CREATED:
+		return row.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case pb.SortOrder_SORT_ORDER_UPDATED:
+		return row.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case pb.SortOrder_SORT_ORDER_SIZE:
+		return strconv.FormatInt(row.SizeBytes, 10)
+	default:
+		return row.DataName
+	}
+}
+
+// encodePageToken кодирование курсора следующей страницы
+func encodePageToken(sort pb.SortOrder, row sqlc.UserDatum) string {
+	raw, _ := json.Marshal(pageCursor{Sort: sort, Key: sortKey(sort, row), ID: row.ID.String()})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodePageToken разбор курсора страницы, пустой токен означает первую страницу
+func decodePageToken(token string, sort pb.SortOrder) (*pageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	if cursor.Sort != sort || !stringToNullUUID(cursor.ID).Valid {
+		return nil, ErrInvalidPageToken
+	}
+	return &cursor, nil
+}
+
+// namePattern шаблон ILIKE для поиска по имени с экранированием спецсимволов
+func namePattern(query string, prefix bool) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	if prefix {
+		return escaped + "%"
+	}
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageSize(t *testing.T) {
+	assert.Equal(t, int32(defaultPageSize), pageSize(0))
+	assert.Equal(t, int32(10), pageSize(10))
+	assert.Equal(t, int32(maxPageSize), pageSize(1000))
+}
+
+func TestPageToken_RoundTrip(t *testing.T) {
+	row := sqlc.UserDatum{
+		ID:        uuid.MustParse("3a0a4950-16e3-4720-814b-17e6b4fd0bc3"),
+		DataName:  "name",
+		CreatedAt: time.Date(2025, 5, 1, 10, 0, 0, 123000, time.UTC),
+		UpdatedAt: time.Date(2025, 5, 2, 10, 0, 0, 0, time.UTC),
+		SizeBytes: 77,
+	}
+
+	tests := []struct {
+		sort pb.SortOrder
+		key  string
+	}{
+		{pb.SortOrder_SORT_ORDER_NAME, "name"},
+		{pb.SortOrder_SORT_ORDER_CREATED, "2025-05-01T10:00:00.000123Z"},
+		{pb.SortOrder_SORT_ORDER_UPDATED, "2025-05-02T10:00:00Z"},
+		{pb.SortOrder_SORT_ORDER_SIZE, "77"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort.String(), func(t *testing.T) {
+			cursor, err := decodePageToken(encodePageToken(tt.sort, row), tt.sort)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.key, cursor.Key)
+			assert.Equal(t, row.ID.String(), cursor.ID)
+		})
+	}
+}
+
+func TestDecodePageToken_Errors(t *testing.T) {
+	cursor, err := decodePageToken("", pb.SortOrder_SORT_ORDER_NAME)
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	_, err = decodePageToken("%%%", pb.SortOrder_SORT_ORDER_NAME)
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+
+	token := encodePageToken(pb.SortOrder_SORT_ORDER_NAME, sqlc.UserDatum{ID: uuid.New()})
+	_, err = decodePageToken(token, pb.SortOrder_SORT_ORDER_SIZE)
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestNamePattern(t *testing.T) {
+	assert.Equal(t, "%bank%", namePattern("bank", false))
+	assert.Equal(t, "bank%", namePattern("bank", true))
+	assert.Equal(t, `%50\%\_off\\%`, namePattern(`50%_off\`, false))
+}
@@ -3,27 +3,101 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
 )
 
 // Repository интерфейс взаимодействия с хранилищем
 type Repository interface {
+	Ping(ctx context.Context) error
+	Close() error
+
 	GetData(context.Context, string) (*pb.GetDataResponse, error)
-	GetDataNameList(context.Context, string) (*pb.GetUserDataListResponse, error)
+	GetDataNameList(context.Context, string, *pb.GetUserDataListRequest) (*pb.GetUserDataListResponse, error)
 	GetOidByItemID(context.Context, string) (int, error)
 	CreateUser(context.Context, string, string) (string, string, error)
 	IsUserCreated(context.Context, string) (bool, error)
+	GetUserUID(context.Context, string) (string, error)
 	CheckSessionUser(context.Context, string, string) bool
 	UpdateSessionUser(context.Context, string, string) (string, string, error)
 	DeleteData(context.Context, string) error
+	ListTrash(ctx context.Context, userUID, orgID string) (*pb.ListTrashResponse, error)
+	GetTrashAccess(ctx context.Context, userUID, dataID string) (*pb.GetDataResponse, error)
+	RestoreData(ctx context.Context, dataID string) error
+	PurgeData(ctx context.Context, dataID string) error
+	PurgeExpiredTrash(ctx context.Context, before time.Time) (int64, error)
+	DeleteSession(ctx context.Context, userUID, token string) error
 
 	BeginTx(context.Context) (*sql.Tx, error)
 	CreateEmptyLO(context.Context, *sql.Tx) (int, error)
 	InsertUserDataRecordTx(context.Context, *sql.Tx, string, string, string, string, int) error
+	UpdateUserDataSizeTx(context.Context, *sql.Tx, string, int64) error
+	SetDataKeyTx(ctx context.Context, tx *sql.Tx, userDataID, dataKey string) error
 
 	OpenLOForWriting(ctx context.Context, tx *sql.Tx, oid int) (int, error)
 	WriteLO(ctx context.Context, tx *sql.Tx, fd int, chunk []byte) error
+	FinishLO(ctx context.Context, tx *sql.Tx, fd int) error
 	CloseLO(ctx context.Context, tx *sql.Tx, fd int)
 	TruncateLO(context.Context, *sql.Tx, int, int64) error
+	RewrapDataKeys(ctx context.Context) (int64, error)
+	CountDataKeys(ctx context.Context) (map[string]int64, error)
+
+	CreateFolder(ctx context.Context, userUID, parentID, name string) (string, error)
+	RenameFolder(ctx context.Context, userUID, folderID, name string) error
+	MoveFolder(ctx context.Context, userUID, folderID, parentID string) error
+	DeleteFolder(ctx context.Context, userUID, folderID string) error
+	ListFolders(ctx context.Context, userUID string) (*pb.ListFoldersResponse, error)
+	MoveData(ctx context.Context, userUID, dataID, folderID string) error
+	SetDataTags(ctx context.Context, userUID, dataID string, tags []string) error
+	SetDataRotation(ctx context.Context, userUID, dataID string, expiresAt int64, rotationDays uint32) error
+	RefreshRotationDue(ctx context.Context, dataID string) (int64, error)
+	GetVaultHealth(ctx context.Context, userUID string) (*pb.GetVaultHealthResponse, error)
+
+	SetUserKeys(ctx context.Context, userUID string, publicKey []byte, encryptedPrivateKey string) error
+	GetUserKeys(ctx context.Context, userUID string) (*pb.GetUserKeysResponse, error)
+	GetPublicKey(ctx context.Context, login string) (*pb.GetPublicKeyResponse, error)
+	ShareData(ctx context.Context, ownerUID, dataID, recipientUID string, permission pb.SharePermission, wrappedKey []byte) error
+	UnshareData(ctx context.Context, ownerUID, dataID, recipientUID string) error
+	ListShares(ctx context.Context, ownerUID, dataID string) (*pb.ListSharesResponse, error)
+	ListSharedWithMe(ctx context.Context, userUID string) (*pb.ListSharedWithMeResponse, error)
+	GetDataAccess(ctx context.Context, userUID, dataID string) (*pb.GetDataResponse, error)
+
+	CreateOrganization(ctx context.Context, userUID, name string, wrappedKey []byte) (string, error)
+	ListOrganizations(ctx context.Context, userUID string) (*pb.ListOrganizationsResponse, error)
+	GetOrgRole(ctx context.Context, userUID, orgID string) (pb.OrgRole, error)
+	GetCollectionRole(ctx context.Context, userUID, collectionID string) (pb.OrgRole, error)
+	InviteMember(ctx context.Context, orgID, inviterUID, inviteeUID string, role pb.OrgRole, wrappedKey []byte) (string, error)
+	ListInvitations(ctx context.Context, userUID string) (*pb.ListInvitationsResponse, error)
+	AcceptInvitation(ctx context.Context, userUID, invitationID string) error
+	DeclineInvitation(ctx context.Context, userUID, invitationID string) error
+	ListMembers(ctx context.Context, orgID string) (*pb.ListMembersResponse, error)
+	SetMemberRole(ctx context.Context, orgID, userUID string, role pb.OrgRole) error
+	RemoveMember(ctx context.Context, orgID, userUID string) error
+	CountOrgOwners(ctx context.Context, orgID string) (int64, error)
+	CreateCollection(ctx context.Context, orgID, name string) (string, error)
+	ListCollections(ctx context.Context, orgID string) (*pb.ListCollectionsResponse, error)
+	DeleteCollection(ctx context.Context, orgID, collectionID string) error
+	SetDataCollectionTx(ctx context.Context, tx *sql.Tx, userDataID, collectionID string) error
+
+	InsertAuditEvent(ctx context.Context, userUID string, event *pb.AuditEvent) error
+	ListAuditEvents(ctx context.Context, userUID string, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error)
+
+	GetUserLogin(ctx context.Context, userUID string) (string, error)
+	SetTwoFactorSecret(ctx context.Context, userUID, secret string) error
+	GetTwoFactor(ctx context.Context, userUID string) (string, bool, error)
+	EnableTwoFactor(ctx context.Context, userUID string, recoveryHashes []string) error
+	ReplaceRecoveryCodes(ctx context.Context, userUID string, recoveryHashes []string) error
+	DisableTwoFactor(ctx context.Context, userUID string) error
+	UseTotpStep(ctx context.Context, userUID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userUID string) (int64, error)
+	CreateLoginChallenge(ctx context.Context, userUID string) (string, error)
+	CheckLoginChallenge(ctx context.Context, token string) (string, int32, error)
+	DeleteLoginChallenge(ctx context.Context, token string) error
+	CreateSession(ctx context.Context, userUID string) (string, error)
+
+	CheckPassword(ctx context.Context, userUID, password string) error
+	ChangePassword(ctx context.Context, userUID, passwordHash, keepToken, encryptedPrivateKey string) error
+	DeleteAccount(ctx context.Context, userUID string) error
 }
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+// SetDataRotation установка срока действия и периода смены данных пользователя.
+// Нулевые expiresAt и rotationDays снимают ограничения, отметка о смене пересчитывается сразу
+func (s *Store) SetDataRotation(ctx context.Context, userUID, dataID string, expiresAt int64, rotationDays uint32) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	id := stringToNullUUID(dataID)
+	isOwner, err := q.IsDataOwner(ctxDB, sqlc.IsDataOwnerParams{
+		ID:     id.UUID,
+		UserID: stringToNullUUID(userUID),
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to check data owner: %w", err)
+	}
+	if !isOwner {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	if expiresAt == 0 && rotationDays == 0 {
+		err = q.DeleteDataRotation(ctxDB, id.UUID)
+	} else {
+		err = q.UpsertDataRotation(ctxDB, sqlc.UpsertDataRotationParams{
+			DataID:       id.UUID,
+			ExpiresAt:    unixToNullTime(expiresAt),
+			RotationDays: int32(rotationDays),
+		})
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to set rotation: %w", err)
+	}
+	if _, err := q.RefreshRotationDue(ctxDB, id); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to refresh rotation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RefreshRotationDue пересчёт отметок о необходимости смены данных, пустой dataID — для всех данных.
+// Возвращает число изменённых отметок
+func (s *Store) RefreshRotationDue(ctx context.Context, dataID string) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.RefreshRotationDue(ctxDB, stringToNullUUID(dataID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh rotation: %w", err)
+	}
+	return affected, nil
+}
+
+// GetVaultHealth сведения для отчёта о состоянии личного хранилища: пароли и данные со сроком действия или периодом смены
+func (s *Store) GetVaultHealth(ctx context.Context, userUID string) (*pb.GetVaultHealthResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListVaultHealth(ctxDB, stringToNullUUID(userUID))
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	items := make([]*pb.HealthItem, 0, len(rows))
+	for _, row := range rows {
+		var expiresAt int64
+		if row.ExpiresAt.Valid {
+			expiresAt = row.ExpiresAt.Time.Unix()
+		}
+		items = append(items, &pb.HealthItem{
+			Id:           row.ID.String(),
+			Name:         row.DataName,
+			Type:         row.DataType,
+			UpdatedAt:    row.UpdatedAt.Unix(),
+			ExpiresAt:    expiresAt,
+			RotationDays: uint32(row.RotationDays),
+			RotationDue:  row.RotationDue,
+		})
+	}
+	return &pb.GetVaultHealthResponse{Items: items}, nil
+}
+
+// unixToNullTime перевод секунд Unix во время, NULL для 0
+func unixToNullTime(sec int64) sql.NullTime {
+	if sec == 0 {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: time.Unix(sec, 0).UTC(), Valid: true}
+}
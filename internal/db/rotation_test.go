@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDataRotation(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	expiresAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+data_rotation`).
+		WithArgs(testDataUID, expiresAt, 90).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)UPDATE\s+data_rotation`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.SetDataRotation(context.Background(), testUserUID, testDataUID, expiresAt.Unix(), 90)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetDataRotation_Clear(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+data_rotation`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)UPDATE\s+data_rotation`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := store.SetDataRotation(context.Background(), testUserUID, testDataUID, 0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetDataRotation_NotOwner(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	err := store.SetDataRotation(context.Background(), testUserUID, testDataUID, 0, 30)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshRotationDue(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+data_rotation\s+r\s+SET\s+rotation_due`).
+		WithArgs(nil).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	affected, err := store.RefreshRotationDue(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetVaultHealth(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	updatedAt := time.Unix(1700000000, 0)
+	expiresAt := time.Unix(1800000000, 0)
+	mock.ExpectQuery(`(?i)SELECT\s+d\.id.*FROM\s+user_data\s+d\s+LEFT\s+JOIN\s+data_rotation`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data_name", "data_type", "updated_at", "expires_at", "rotation_days", "rotation_due"}).
+			AddRow(testDataUID, "bank", "password", updatedAt, expiresAt, 90, true).
+			AddRow(testFolderUID, "notes", "text", updatedAt, sql.NullTime{}, 30, false))
+
+	resp, err := store.GetVaultHealth(context.Background(), testUserUID)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Items, 2) {
+		assert.Equal(t, testDataUID, resp.Items[0].Id)
+		assert.Equal(t, expiresAt.Unix(), resp.Items[0].ExpiresAt)
+		assert.Equal(t, uint32(90), resp.Items[0].RotationDays)
+		assert.True(t, resp.Items[0].RotationDue)
+		assert.Zero(t, resp.Items[1].ExpiresAt)
+		assert.Equal(t, updatedAt.Unix(), resp.Items[1].UpdatedAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
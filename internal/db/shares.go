@@ -0,0 +1,259 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+)
+
+const (
+	// permissionRead доступ только на чтение
+	permissionRead = "read"
+	// permissionReadWrite доступ на чтение и изменение
+	permissionReadWrite = "read_write"
+)
+
+// SetUserKeys сохранение ключевой пары пользователя
+func (s *Store) SetUserKeys(ctx context.Context, userUID string, publicKey []byte, encryptedPrivateKey string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	err := q.UpsertUserKeys(ctxDB, sqlc.UpsertUserKeysParams{
+		UserID:              stringToNullUUID(userUID).UUID,
+		PublicKey:           publicKey,
+		EncryptedPrivateKey: encryptedPrivateKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save user keys: %w", err)
+	}
+	return nil
+}
+
+// GetUserKeys получение ключевой пары пользователя
+func (s *Store) GetUserKeys(ctx context.Context, userUID string) (*pb.GetUserKeysResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	keys, err := q.GetUserKeys(ctxDB, stringToNullUUID(userUID).UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return &pb.GetUserKeysResponse{
+		PublicKey:           keys.PublicKey,
+		EncryptedPrivateKey: keys.EncryptedPrivateKey,
+	}, nil
+}
+
+// GetPublicKey получение публичного ключа пользователя по логину
+func (s *Store) GetPublicKey(ctx context.Context, login string) (*pb.GetPublicKeyResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	row, err := q.GetPublicKeyByUsername(ctxDB, login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return &pb.GetPublicKeyResponse{
+		UserUid:   row.ID.String(),
+		PublicKey: row.PublicKey,
+	}, nil
+}
+
+// ShareData выдача или изменение доступа к данным владельца для получателя
+func (s *Store) ShareData(ctx context.Context, ownerUID, dataID, recipientUID string, permission pb.SharePermission, wrappedKey []byte) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	id := stringToNullUUID(dataID).UUID
+	isOwner, err := q.IsDataOwner(ctxDB, sqlc.IsDataOwnerParams{
+		ID:     id,
+		UserID: stringToNullUUID(ownerUID),
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to check data owner: %w", err)
+	}
+	if !isOwner {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	err = q.UpsertDataShare(ctxDB, sqlc.UpsertDataShareParams{
+		DataID:      id,
+		RecipientID: stringToNullUUID(recipientUID).UUID,
+		Permission:  permissionToString(permission),
+		WrappedKey:  wrappedKey,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to share data: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UnshareData отзыв доступа получателя к данным владельца
+func (s *Store) UnshareData(ctx context.Context, ownerUID, dataID, recipientUID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.DeleteDataShare(ctxDB, sqlc.DeleteDataShareParams{
+		DataID:      stringToNullUUID(dataID).UUID,
+		RecipientID: stringToNullUUID(recipientUID).UUID,
+		UserID:      stringToNullUUID(ownerUID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unshare data: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListShares получение выданных владельцем доступов к данным
+func (s *Store) ListShares(ctx context.Context, ownerUID, dataID string) (*pb.ListSharesResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	id := stringToNullUUID(dataID).UUID
+	isOwner, err := q.IsDataOwner(ctxDB, sqlc.IsDataOwnerParams{
+		ID:     id,
+		UserID: stringToNullUUID(ownerUID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check data owner: %w", err)
+	}
+	if !isOwner {
+		return nil, ErrNotFound
+	}
+
+	rows, err := q.ListDataShares(ctxDB, id)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	grants := make([]*pb.ShareGrant, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, &pb.ShareGrant{
+			RecipientUid:   row.RecipientID.String(),
+			RecipientLogin: row.Username,
+			Permission:     permissionFromString(row.Permission),
+		})
+	}
+	return &pb.ListSharesResponse{Grants: grants}, nil
+}
+
+// ListSharedWithMe получение данных других пользователей, к которым выдан доступ
+func (s *Store) ListSharedWithMe(ctx context.Context, userUID string) (*pb.ListSharedWithMeResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListSharedWithUser(ctxDB, stringToNullUUID(userUID).UUID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	items := make([]*pb.SharedItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &pb.SharedItem{
+			Id:         row.ID.String(),
+			Name:       row.DataName,
+			Type:       row.DataType,
+			OwnerLogin: row.OwnerName,
+			Permission: permissionFromString(row.Permission),
+		})
+	}
+	return &pb.ListSharedWithMeResponse{Items: items}, nil
+}
+
+// GetDataAccess получение прав пользователя на данные, ErrNotFound если доступа нет.
+// Ответ содержит только ключ данных и уровень доступа, для владельца уровень доступа не задан,
+// для данных командного хранилища уровень доступа определяется ролью в организации
+func (s *Store) GetDataAccess(ctx context.Context, userUID, dataID string) (*pb.GetDataResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	row, err := q.GetDataAccess(ctxDB, sqlc.GetDataAccessParams{
+		UserID: stringToNullUUID(userUID).UUID,
+		ID:     stringToNullUUID(dataID).UUID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	if row.IsOwner {
+		return &pb.GetDataResponse{DataKey: row.DataKey.String}, nil
+	}
+	if row.OrgRole.Valid {
+		role := roleFromString(row.OrgRole.String)
+		permission := pb.SharePermission_SHARE_PERMISSION_READ_WRITE
+		if role == pb.OrgRole_ORG_ROLE_READ_ONLY {
+			permission = pb.SharePermission_SHARE_PERMISSION_READ
+		}
+		return &pb.GetDataResponse{Permission: permission, OrgRole: role}, nil
+	}
+	return &pb.GetDataResponse{
+		SharedKey:  row.WrappedKey,
+		Permission: permissionFromString(row.Permission.String),
+	}, nil
+}
+
+// SetDataKeyTx сохранение ключа данных, зашифрованного ключом хранилища владельца
+func (s *Store) SetDataKeyTx(ctx context.Context, tx *sql.Tx, userDataID, dataKey string) error {
+	q := sqlc.New(tx)
+	err := q.SetUserDataKey(ctx, sqlc.SetUserDataKeyParams{
+		DataKey: sql.NullString{String: dataKey, Valid: dataKey != ""},
+		ID:      stringToNullUUID(userDataID).UUID,
+	})
+	if err != nil {
+		return fmt.Errorf("update user_data key failed: %w", err)
+	}
+	return nil
+}
+
+// permissionToString перевод уровня доступа в значение колонки permission
+func permissionToString(permission pb.SharePermission) string {
+	if permission == pb.SharePermission_SHARE_PERMISSION_READ_WRITE {
+		return permissionReadWrite
+	}
+	return permissionRead
+}
+
+// permissionFromString перевод значения колонки permission в уровень доступа
+func permissionFromString(permission string) pb.SharePermission {
+	if permission == permissionReadWrite {
+		return pb.SharePermission_SHARE_PERMISSION_READ_WRITE
+	}
+	return pb.SharePermission_SHARE_PERMISSION_READ
+}
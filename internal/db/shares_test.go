@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRecipientUID = "3a0a4950-16e3-4720-814b-17e6b4fd0bc9"
+
+func TestGetUserKeys_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+public_key,\s+encrypted_private_key\s+FROM\s+user_keys`).
+		WithArgs(testUserUID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := store.GetUserKeys(context.Background(), testUserUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPublicKey(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+k\.public_key\s+FROM\s+users\s+u`).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "public_key"}).AddRow(testRecipientUID, []byte("public")))
+
+	resp, err := store.GetPublicKey(context.Background(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, testRecipientUID, resp.UserUid)
+	assert.Equal(t, []byte("public"), resp.PublicKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareData(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+data_shares`).
+		WithArgs(testDataUID, testRecipientUID, "read_write", []byte("wrapped")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.ShareData(context.Background(), testUserUID, testDataUID, testRecipientUID,
+		pb.SharePermission_SHARE_PERMISSION_READ_WRITE, []byte("wrapped"))
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShareData_NotOwner(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+EXISTS\s*\(SELECT\s+1\s+FROM\s+user_data`).
+		WithArgs(testDataUID, testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectRollback()
+
+	err := store.ShareData(context.Background(), testUserUID, testDataUID, testRecipientUID,
+		pb.SharePermission_SHARE_PERMISSION_READ, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnshareData_NotFound(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+data_shares`).
+		WithArgs(testDataUID, testRecipientUID, testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := store.UnshareData(context.Background(), testUserUID, testDataUID, testRecipientUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListSharedWithMe(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)FROM\s+data_shares\s+s\s+JOIN\s+user_data\s+d`).
+		WithArgs(testRecipientUID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data_type", "data_name", "owner_name", "permission"}).
+			AddRow(testDataUID, "password", "vpn", "alice", "read"))
+
+	resp, err := store.ListSharedWithMe(context.Background(), testRecipientUID)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "alice", resp.Items[0].OwnerLogin)
+	assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ, resp.Items[0].Permission)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDataAccess(t *testing.T) {
+	columns := []string{"is_owner", "data_key", "permission", "wrapped_key", "org_role"}
+
+	t.Run("owner", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)FROM\s+user_data\s+d\s+LEFT\s+JOIN\s+data_shares`).
+			WithArgs(testUserUID, testDataUID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(true, "owner-key", nil, nil, nil))
+
+		access, err := store.GetDataAccess(context.Background(), testUserUID, testDataUID)
+		assert.NoError(t, err)
+		assert.Equal(t, "owner-key", access.DataKey)
+		assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_UNSPECIFIED, access.Permission)
+	})
+	t.Run("recipient", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)FROM\s+user_data\s+d\s+LEFT\s+JOIN\s+data_shares`).
+			WithArgs(testRecipientUID, testDataUID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(false, "owner-key", "read_write", []byte("wrapped"), nil))
+
+		access, err := store.GetDataAccess(context.Background(), testRecipientUID, testDataUID)
+		assert.NoError(t, err)
+		assert.Empty(t, access.DataKey)
+		assert.Equal(t, []byte("wrapped"), access.SharedKey)
+		assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ_WRITE, access.Permission)
+	})
+	t.Run("read only org member", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)FROM\s+user_data\s+d\s+LEFT\s+JOIN\s+data_shares`).
+			WithArgs(testRecipientUID, testDataUID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(false, nil, nil, nil, "read_only"))
+
+		access, err := store.GetDataAccess(context.Background(), testRecipientUID, testDataUID)
+		assert.NoError(t, err)
+		assert.Empty(t, access.DataKey)
+		assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ, access.Permission)
+		assert.Equal(t, pb.OrgRole_ORG_ROLE_READ_ONLY, access.OrgRole)
+	})
+	t.Run("no access", func(t *testing.T) {
+		dbMock, mock, store := setupMockDB(t)
+		defer dbMock.Close()
+
+		mock.ExpectQuery(`(?i)FROM\s+user_data\s+d\s+LEFT\s+JOIN\s+data_shares`).
+			WithArgs(testRecipientUID, testDataUID).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := store.GetDataAccess(context.Background(), testRecipientUID, testDataUID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
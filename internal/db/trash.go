@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	sqlc "github.com/fngoc/gault/gen/go/db"
+
+	"github.com/google/uuid"
+)
+
+// purgeBatchSize число записей, удаляемых очисткой корзины за одну транзакцию
+const purgeBatchSize = 100
+
+// ListTrash содержимое личной корзины пользователя или корзины организации, если задан orgID
+func (s *Store) ListTrash(ctx context.Context, userUID, orgID string) (*pb.ListTrashResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	rows, err := q.ListTrash(ctxDB, sqlc.ListTrashParams{
+		UserID: stringToNullUUID(userUID),
+		OrgID:  stringToNullUUID(orgID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	items := make([]*pb.TrashItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &pb.TrashItem{
+			Id:        row.ID.String(),
+			Name:      row.DataName,
+			Type:      row.DataType,
+			Size:      row.SizeBytes,
+			DeletedAt: row.DeletedAt.Time.Unix(),
+		})
+	}
+	return &pb.ListTrashResponse{Items: items}, nil
+}
+
+// GetTrashAccess уровень доступа пользователя к данным в корзине: владелец личных данных или роль в организации
+func (s *Store) GetTrashAccess(ctx context.Context, userUID, dataID string) (*pb.GetDataResponse, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	row, err := q.GetTrashAccess(ctxDB, sqlc.GetTrashAccessParams{
+		UserID: stringToNullUUID(userUID).UUID,
+		ID:     stringToNullUUID(dataID).UUID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	if row.IsOwner {
+		return &pb.GetDataResponse{}, nil
+	}
+	role := roleFromString(row.OrgRole.String)
+	permission := pb.SharePermission_SHARE_PERMISSION_READ_WRITE
+	if role == pb.OrgRole_ORG_ROLE_READ_ONLY {
+		permission = pb.SharePermission_SHARE_PERMISSION_READ
+	}
+	return &pb.GetDataResponse{Permission: permission, OrgRole: role}, nil
+}
+
+// RestoreData восстановление данных из корзины
+func (s *Store) RestoreData(ctx context.Context, dataID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.RestoreUserData(ctxDB, stringToNullUUID(dataID).UUID)
+	if err != nil {
+		return fmt.Errorf("failed to restore data: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeData окончательное удаление данных из корзины вместе с Large Object
+func (s *Store) PurgeData(ctx context.Context, dataID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	id := stringToNullUUID(dataID).UUID
+	oid, err := q.GetTrashedOid(ctxDB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("query error: %w", err)
+	}
+	purged, err := purgeTrashedRecord(ctxDB, tx, q, id, oid)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if !purged {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredTrash окончательное удаление данных, попавших в корзину раньше before. Возвращает число удалённых записей
+func (s *Store) PurgeExpiredTrash(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for {
+		n, err := s.purgeTrashBatch(ctx, before)
+		purged += n
+		if err != nil || n < purgeBatchSize {
+			return purged, err
+		}
+	}
+}
+
+// purgeTrashBatch удаление одной партии устаревших данных корзины в отдельной транзакции
+func (s *Store) purgeTrashBatch(ctx context.Context, before time.Time) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	rows, err := q.ListExpiredTrash(ctxDB, sqlc.ListExpiredTrashParams{
+		DeletedAt: sql.NullTime{Time: before, Valid: true},
+		Limit:     purgeBatchSize,
+	})
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to list expired trash: %w", err)
+	}
+	var purged int64
+	for _, row := range rows {
+		ok, err := purgeTrashedRecord(ctxDB, tx, q, row.ID, row.LargeobjectOid)
+		if err != nil {
+			_ = tx.Rollback()
+			return 0, err
+		}
+		if ok {
+			purged++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return purged, nil
+}
+
+// purgeTrashedRecord удаление записи из корзины и её Large Object. Запись удаляется, только если
+// она всё ещё в корзине, и LO отвязывается после этого: восстановленные данные не теряются,
+// а LO уже удалённой записи не отвязывается повторно. false — записи в корзине уже нет
+func purgeTrashedRecord(ctx context.Context, tx *sql.Tx, q *sqlc.Queries, id uuid.UUID, oid uint32) (bool, error) {
+	n, err := q.DeleteTrashedUserData(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete user data: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT lo_unlink($1)`, oid); err != nil {
+		return false, fmt.Errorf("lo_unlink failed: %w", err)
+	}
+	return true, nil
+}
+
+// purgeRecord удаление Large Object и записи данных, теги, доступы и сроки удаляются каскадно
+func purgeRecord(ctx context.Context, tx *sql.Tx, q *sqlc.Queries, id uuid.UUID, oid uint32) error {
+	if _, err := tx.ExecContext(ctx, `SELECT lo_unlink($1)`, oid); err != nil {
+		return fmt.Errorf("lo_unlink failed: %w", err)
+	}
+	if err := q.DeleteUserData(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTrash(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	deleted := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`(?i)SELECT\s+d\.id.*FROM\s+user_data\s+d\s+WHERE\s+d\.deleted_at\s+IS\s+NOT\s+NULL`).
+		WithArgs(testUserUID, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data_name", "data_type", "size_bytes", "deleted_at"}).
+			AddRow(testDataUID, "bank", "password", 12, deleted))
+
+	resp, err := store.ListTrash(context.Background(), testUserUID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.TrashItem{{Id: testDataUID, Name: "bank", Type: "password", Size: 12, DeletedAt: deleted.Unix()}}, resp.Items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTrashAccess(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+\(d\.user_id.*AS\s+is_owner.*deleted_at\s+IS\s+NOT\s+NULL`).
+		WithArgs(testUserUID, testDataUID).
+		WillReturnRows(sqlmock.NewRows([]string{"is_owner", "org_role"}).AddRow(false, "read_only"))
+	mock.ExpectQuery(`(?i)SELECT\s+\(d\.user_id.*AS\s+is_owner`).
+		WithArgs(testUserUID, testDataUID).
+		WillReturnError(sql.ErrNoRows)
+
+	access, err := store.GetTrashAccess(context.Background(), testUserUID, testDataUID)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.OrgRole_ORG_ROLE_READ_ONLY, access.OrgRole)
+	assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ, access.Permission)
+
+	_, err = store.GetTrashAccess(context.Background(), testUserUID, testDataUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreData(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+deleted_at\s*=\s*NULL`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)UPDATE\s+user_data\s+SET\s+deleted_at\s*=\s*NULL`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, store.RestoreData(context.Background(), testDataUID))
+	assert.ErrorIs(t, store.RestoreData(context.Background(), testDataUID), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeData(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+largeobject_oid\s+FROM\s+user_data\s+WHERE\s+id\s*=\s*\$1\s+AND\s+deleted_at\s+IS\s+NOT\s+NULL\s+FOR\s+UPDATE`).
+		WithArgs(testDataUID).
+		WillReturnRows(sqlmock.NewRows([]string{"largeobject_oid"}).AddRow(42))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data\s+WHERE\s+id\s*=\s*\$1\s+AND\s+deleted_at\s+IS\s+NOT\s+NULL`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, store.PurgeData(context.Background(), testDataUID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeData_NotInTrash(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+largeobject_oid\s+FROM\s+user_data`).
+		WithArgs(testDataUID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.PurgeData(context.Background(), testDataUID), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	before := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+largeobject_oid\s+FROM\s+user_data\s+WHERE\s+deleted_at\s*<\s*\$1.*FOR\s+UPDATE\s+SKIP\s+LOCKED`).
+		WithArgs(before, purgeBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "largeobject_oid"}).
+			AddRow(testDataUID, 42).
+			AddRow(testFolderUID, 43))
+	for _, row := range []struct {
+		id  string
+		oid int
+	}{{testDataUID, 42}, {testFolderUID, 43}} {
+		mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data\s+WHERE\s+id\s*=\s*\$1\s+AND\s+deleted_at\s+IS\s+NOT\s+NULL`).
+			WithArgs(row.id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).
+			WithArgs(row.oid).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	purged, err := store.PurgeExpiredTrash(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeExpiredTrash_UnlinkError(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+largeobject_oid\s+FROM\s+user_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "largeobject_oid"}).AddRow(testDataUID, 42))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)SELECT\s+lo_unlink`).
+		WillReturnError(errors.New("lo error"))
+	mock.ExpectRollback()
+
+	purged, err := store.PurgeExpiredTrash(context.Background(), time.Now())
+	assert.ErrorContains(t, err, "lo_unlink failed")
+	assert.Zero(t, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeExpiredTrash_RestoredDuringPurge(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+id,\s+largeobject_oid\s+FROM\s+user_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "largeobject_oid"}).AddRow(testDataUID, 42))
+	// Запись восстановлена из корзины: она не удаляется, и её LO не отвязывается
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data`).
+		WithArgs(testDataUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	purged, err := store.PurgeExpiredTrash(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeData_RestoredConcurrently(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`(?i)SELECT\s+largeobject_oid\s+FROM\s+user_data`).
+		WillReturnRows(sqlmock.NewRows([]string{"largeobject_oid"}).AddRow(42))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_data`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, store.PurgeData(context.Background(), testDataUID), ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sqlc "github.com/fngoc/gault/gen/go/db"
+	"github.com/fngoc/gault/pkg/utils"
+)
+
+// ErrTwoFactorRequired пароль верный, но для выдачи сессии нужен второй фактор
+var ErrTwoFactorRequired = errors.New("two-factor authentication required")
+
+// GetUserLogin получение логина пользователя
+func (s *Store) GetUserLogin(ctx context.Context, userUID string) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	login, err := q.GetUsernameByID(ctxDB, stringToNullUUID(userUID).UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user login: %w", err)
+	}
+	return login, nil
+}
+
+// SetTwoFactorSecret сохранение нового секрета TOTP. До подтверждения кодом 2FA остаётся выключенной
+func (s *Store) SetTwoFactorSecret(ctx context.Context, userUID, secret string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	err := q.UpsertUserTotp(ctxDB, sqlc.UpsertUserTotpParams{
+		UserID: stringToNullUUID(userUID).UUID,
+		Secret: secret,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+	return nil
+}
+
+// GetTwoFactor получение секрета TOTP и признака включённой 2FA
+func (s *Store) GetTwoFactor(ctx context.Context, userUID string) (string, bool, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	row, err := q.GetUserTotp(ctxDB, stringToNullUUID(userUID).UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, ErrNotFound
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get totp: %w", err)
+	}
+	return row.Secret, row.Enabled, nil
+}
+
+// EnableTwoFactor включение 2FA и сохранение хешей кодов восстановления
+func (s *Store) EnableTwoFactor(ctx context.Context, userUID string, recoveryHashes []string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	affected, err := q.EnableUserTotp(ctxDB, stringToNullUUID(userUID).UUID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if affected == 0 {
+		_ = tx.Rollback()
+		return ErrNotFound
+	}
+	if err := replaceRecoveryCodes(ctxDB, q, userUID, recoveryHashes); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes замена всех кодов восстановления пользователя новыми
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userUID string, recoveryHashes []string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctxDB, sqlc.New(tx), userUID, recoveryHashes); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// replaceRecoveryCodes удаление старых и вставка новых кодов восстановления в транзакции q
+func replaceRecoveryCodes(ctx context.Context, q *sqlc.Queries, userUID string, recoveryHashes []string) error {
+	userID := stringToNullUUID(userUID).UUID
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range recoveryHashes {
+		err := q.InsertRecoveryCode(ctx, sqlc.InsertRecoveryCodeParams{UserID: userID, CodeHash: hash})
+		if err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// DisableTwoFactor отключение 2FA: удаление секрета TOTP и кодов восстановления
+func (s *Store) DisableTwoFactor(ctx context.Context, userUID string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctxDB, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := sqlc.New(tx)
+	userID := stringToNullUUID(userUID).UUID
+	if err := q.DeleteUserTotp(ctxDB, userID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+	if err := q.DeleteRecoveryCodes(ctxDB, userID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UseTotpStep отметка периода TOTP использованным. false, если код этого или более позднего периода уже использовался
+func (s *Store) UseTotpStep(ctx context.Context, userUID string, step int64) (bool, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.SetTotpLastUsedStep(ctxDB, sqlc.SetTotpLastUsedStepParams{
+		Step:   step,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// UseRecoveryCode погашение кода восстановления по хешу. false, если код не найден или уже использован
+func (s *Store) UseRecoveryCode(ctx context.Context, userUID, codeHash string) (bool, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	affected, err := q.UseRecoveryCode(ctxDB, sqlc.UseRecoveryCodeParams{
+		UserID:   stringToNullUUID(userUID).UUID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// CountRecoveryCodes количество неиспользованных кодов восстановления
+func (s *Store) CountRecoveryCodes(ctx context.Context, userUID string) (int64, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	count, err := q.CountRecoveryCodes(ctxDB, stringToNullUUID(userUID).UUID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+// CreateLoginChallenge создание токена второго шага авторизации, действует 5 минут
+func (s *Store) CreateLoginChallenge(ctx context.Context, userUID string) (string, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	token, err := utils.GenerateToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	q := sqlc.New(s.db)
+	err = q.CreateLoginChallenge(ctxDB, sqlc.CreateLoginChallengeParams{
+		Token:  token,
+		UserID: stringToNullUUID(userUID).UUID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create login challenge: %w", err)
+	}
+	return token, nil
+}
+
+// CheckLoginChallenge учёт попытки второго шага авторизации.
+// Возвращает UID пользователя и номер попытки, ErrNotFound если токен не найден или истёк
+func (s *Store) CheckLoginChallenge(ctx context.Context, token string) (string, int32, error) {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	row, err := q.IncrementLoginChallengeAttempts(ctxDB, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, ErrNotFound
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to check login challenge: %w", err)
+	}
+	return row.UserID.String(), row.Attempts, nil
+}
+
+// DeleteLoginChallenge удаление токена второго шага авторизации
+func (s *Store) DeleteLoginChallenge(ctx context.Context, token string) error {
+	ctxDB, cancel := context.WithTimeout(ctx, 7*time.Second)
+	defer cancel()
+
+	q := sqlc.New(s.db)
+	if err := q.DeleteLoginChallenge(ctxDB, token); err != nil {
+		return fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+	return nil
+}
+
+// CreateSession выдача новой сессии пользователю после второго шага авторизации
+func (s *Store) CreateSession(ctx context.Context, userUID string) (string, error) {
+	return s.createSessionToken(ctx, userUID)
+}
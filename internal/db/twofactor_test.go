@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUpdateSessionUser_TwoFactorRequired(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(`(?i)SELECT\s+u\.id,\s+u\.password_hash.*FROM\s+users\s+u`).
+		WithArgs("testuser").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password_hash", "totp_enabled"}).
+			AddRow(testUserUID, string(hashedPassword), true))
+
+	// Сессия не создаётся до второго шага авторизации
+	uid, token, err := store.UpdateSessionUser(context.Background(), "testuser", "password")
+	assert.ErrorIs(t, err, ErrTwoFactorRequired)
+	assert.Equal(t, testUserUID, uid)
+	assert.Empty(t, token)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTwoFactor(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectQuery(`(?i)SELECT\s+user_id,\s*secret,\s*enabled.*FROM\s+user_totp`).
+		WithArgs(testUserUID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "enabled", "last_used_step", "created_at"}).
+			AddRow(testUserUID, "SECRET", true, int64(10), time.Now()))
+	mock.ExpectQuery(`(?i)SELECT\s+user_id,\s*secret,\s*enabled.*FROM\s+user_totp`).
+		WithArgs(testUserUID).
+		WillReturnError(sql.ErrNoRows)
+
+	secret, enabled, err := store.GetTwoFactor(context.Background(), testUserUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", secret)
+	assert.True(t, enabled)
+
+	_, _, err = store.GetTwoFactor(context.Background(), testUserUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+user_totp\s+SET\s+enabled\s*=\s*TRUE`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+recovery_codes`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+recovery_codes`).
+		WithArgs(testUserUID, "hash-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+recovery_codes`).
+		WithArgs(testUserUID, "hash-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := store.EnableTwoFactor(context.Background(), testUserUID, []string{"hash-1", "hash-2"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnableTwoFactor_NotEnrolled(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)UPDATE\s+user_totp\s+SET\s+enabled\s*=\s*TRUE`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := store.EnableTwoFactor(context.Background(), testUserUID, []string{"hash-1"})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+user_totp`).
+		WithArgs(testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)DELETE\s+FROM\s+recovery_codes`).
+		WithArgs(testUserUID).
+		WillReturnError(errors.New("error"))
+	mock.ExpectRollback()
+
+	err := store.DisableTwoFactor(context.Background(), testUserUID)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUseTotpStepAndRecoveryCode(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)UPDATE\s+user_totp\s+SET\s+last_used_step`).
+		WithArgs(int64(100), testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`(?i)UPDATE\s+user_totp\s+SET\s+last_used_step`).
+		WithArgs(int64(100), testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`(?i)UPDATE\s+recovery_codes\s+SET\s+used_at`).
+		WithArgs(testUserUID, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := store.UseTotpStep(context.Background(), testUserUID, 100)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Повторный код того же периода отклоняется
+	ok, err = store.UseTotpStep(context.Background(), testUserUID, 100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.UseRecoveryCode(context.Background(), testUserUID, "hash")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginChallenge(t *testing.T) {
+	dbMock, mock, store := setupMockDB(t)
+	defer dbMock.Close()
+
+	mock.ExpectExec(`(?i)INSERT\s+INTO\s+login_challenges`).
+		WithArgs(sqlmock.AnyArg(), testUserUID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`(?i)UPDATE\s+login_challenges\s+SET\s+attempts`).
+		WithArgs("challenge").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "attempts"}).AddRow(testUserUID, int32(2)))
+	mock.ExpectQuery(`(?i)UPDATE\s+login_challenges\s+SET\s+attempts`).
+		WithArgs("expired").
+		WillReturnError(sql.ErrNoRows)
+
+	token, err := store.CreateLoginChallenge(context.Background(), testUserUID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	uid, attempts, err := store.CheckLoginChallenge(context.Background(), "challenge")
+	assert.NoError(t, err)
+	assert.Equal(t, testUserUID, uid)
+	assert.Equal(t, int32(2), attempts)
+
+	_, _, err = store.CheckLoginChallenge(context.Background(), "expired")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
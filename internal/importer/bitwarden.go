@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Типы элементов Bitwarden
+const (
+	bitwardenLogin    = 1
+	bitwardenNote     = 2
+	bitwardenCard     = 3
+	bitwardenIdentity = 4
+)
+
+// ErrEncryptedExport выгрузка зашифрована и не может быть разобрана
+var ErrEncryptedExport = errors.New("encrypted export is not supported, export unencrypted JSON")
+
+// bitwardenExport выгрузка Bitwarden в формате JSON без шифрования
+type bitwardenExport struct {
+	Encrypted bool            `json:"encrypted"`
+	Items     []bitwardenItem `json:"items"`
+}
+
+// bitwardenItem элемент выгрузки Bitwarden
+type bitwardenItem struct {
+	Type  int    `json:"type"`
+	Name  string `json:"name"`
+	Notes string `json:"notes"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		Number   string `json:"number"`
+		ExpMonth string `json:"expMonth"`
+		ExpYear  string `json:"expYear"`
+		Code     string `json:"code"`
+	} `json:"card"`
+	Identity map[string]any `json:"identity"`
+}
+
+// identityFields поля личных данных Bitwarden в порядке вывода
+var identityFields = []string{
+	"title", "firstName", "middleName", "lastName", "company", "email", "phone",
+	"address1", "address2", "address3", "city", "state", "postalCode", "country",
+	"username", "ssn", "passportNumber", "licenseNumber",
+}
+
+// ParseBitwardenJSON разбор выгрузки Bitwarden JSON без шифрования
+func ParseBitwardenJSON(r io.Reader) ([]Item, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden JSON: %w", err)
+	}
+	if export.Encrypted {
+		return nil, ErrEncryptedExport
+	}
+
+	var items []Item
+	for _, item := range export.Items {
+		switch item.Type {
+		case bitwardenLogin:
+			var username, password, url string
+			if item.Login != nil {
+				username, password = item.Login.Username, item.Login.Password
+				if len(item.Login.URIs) > 0 {
+					url = item.Login.URIs[0].URI
+				}
+			}
+			items = append(items, credential(item.Name, username, password, url, item.Notes)...)
+		case bitwardenCard:
+			if item.Card == nil {
+				continue
+			}
+			month := item.Card.ExpMonth
+			if len(month) == 1 {
+				month = "0" + month
+			}
+			date := strings.Trim(month+"/"+item.Card.ExpYear, "/")
+			items = append(items, card(item.Name, item.Card.Number, date, item.Card.Code))
+			items = append(items, note(item.Name+" notes", item.Notes)...)
+		case bitwardenIdentity:
+			var lines []string
+			for _, field := range identityFields {
+				if value, ok := item.Identity[field].(string); ok && value != "" {
+					lines = append(lines, fmt.Sprintf("%s: %s", field, value))
+				}
+			}
+			if item.Notes != "" {
+				lines = append(lines, item.Notes)
+			}
+			items = append(items, note(item.Name, strings.Join(lines, "\n"))...)
+		case bitwardenNote:
+			items = append(items, note(item.Name, item.Notes)...)
+		}
+	}
+	return items, nil
+}
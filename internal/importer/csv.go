@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidMapping сопоставление колонок задано неверно
+var ErrInvalidMapping = errors.New("invalid column mapping")
+
+// Mapping сопоставление полей элемента с названиями колонок CSV
+type Mapping struct {
+	Name       string
+	Username   string
+	Password   string
+	URL        string
+	Notes      string
+	CardNumber string
+	CardDate   string
+	CardCVC    string
+}
+
+// onePasswordColumns названия колонок выгрузки 1Password для каждого поля
+var onePasswordColumns = map[string][]string{
+	"name":     {"title", "name"},
+	"username": {"username", "login"},
+	"password": {"password"},
+	"url":      {"url", "website", "login url"},
+	"notes":    {"notes", "notesplain"},
+}
+
+// ParseMapping разбор сопоставления вида "name=Title, password=Pass".
+// Поля: name, username, password, url, notes, card_number, card_date, card_cvc
+func ParseMapping(s string) (Mapping, error) {
+	var m Mapping
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return Mapping{}, fmt.Errorf("%w: %q", ErrInvalidMapping, pair)
+		}
+		if err := m.set(strings.ToLower(strings.TrimSpace(field)), column); err != nil {
+			return Mapping{}, err
+		}
+	}
+	if m.Name == "" && m.Username == "" {
+		return Mapping{}, fmt.Errorf("%w: name or username column is required", ErrInvalidMapping)
+	}
+	return m, nil
+}
+
+// set установка колонки поля
+func (m *Mapping) set(field, column string) error {
+	switch field {
+	case "name":
+		m.Name = column
+	case "username":
+		m.Username = column
+	case "password":
+		m.Password = column
+	case "url":
+		m.URL = column
+	case "notes":
+		m.Notes = column
+	case "card_number":
+		m.CardNumber = column
+	case "card_date":
+		m.CardDate = column
+	case "card_cvc":
+		m.CardCVC = column
+	default:
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+	}
+	return nil
+}
+
+// ParseCSV разбор CSV с заголовком по сопоставлению колонок.
+// Строки с номером карты становятся картами, остальные — учётными записями
+func ParseCSV(r io.Reader, mapping Mapping) ([]Item, error) {
+	header, rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return mapRows(header, rows, mapping)
+}
+
+// mapRows преобразование строк CSV в элементы по сопоставлению колонок
+func mapRows(header []string, rows [][]string, mapping Mapping) ([]Item, error) {
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[normalizeColumn(column)] = i
+	}
+	column := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, ok := index[normalizeColumn(name)]
+		if !ok {
+			return -1, fmt.Errorf("%w: column %q not found", ErrInvalidMapping, name)
+		}
+		return i, nil
+	}
+
+	var cols [8]int
+	for i, name := range []string{
+		mapping.Name, mapping.Username, mapping.Password, mapping.URL,
+		mapping.Notes, mapping.CardNumber, mapping.CardDate, mapping.CardCVC,
+	} {
+		var err error
+		if cols[i], err = column(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var items []Item
+	for _, row := range rows {
+		value := func(i int) string {
+			if i < 0 || i >= len(row) {
+				return ""
+			}
+			return row[i]
+		}
+		name := value(cols[0])
+		if number := strings.TrimSpace(value(cols[5])); number != "" {
+			items = append(items, card(name, number, value(cols[6]), value(cols[7])))
+			items = append(items, note(name+" notes", value(cols[4]))...)
+			continue
+		}
+		items = append(items, credential(name, value(cols[1]), value(cols[2]), value(cols[3]), value(cols[4]))...)
+	}
+	return items, nil
+}
+
+// Parse1PasswordCSV разбор выгрузки 1Password CSV, колонки определяются по заголовку
+func Parse1PasswordCSV(r io.Reader) ([]Item, error) {
+	header, rows, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]string, len(header))
+	for _, column := range header {
+		present[normalizeColumn(column)] = column
+	}
+	var m Mapping
+	for field, candidates := range onePasswordColumns {
+		for _, candidate := range candidates {
+			if column, ok := present[candidate]; ok {
+				_ = m.set(field, column)
+				break
+			}
+		}
+	}
+	if m.Name == "" || m.Password == "" {
+		return nil, fmt.Errorf("%w: 1Password CSV must contain title and password columns", ErrInvalidMapping)
+	}
+	return mapRows(header, rows, m)
+}
+
+// readCSV чтение заголовка и строк CSV
+func readCSV(r io.Reader) ([]string, [][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("failed to parse CSV: header is missing")
+	}
+	return records[0], records[1:], nil
+}
+
+// normalizeColumn название колонки без регистра, пробелов и BOM
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
@@ -0,0 +1,144 @@
+// Package importer разбор выгрузок других менеджеров паролей в элементы Gault.
+//
+// Учётные записи становятся паролями, карты — картами, заметки — текстом.
+// Адрес сайта и заметки учётной записи сохраняются отдельным текстом "<имя> notes",
+// потому что пароль Gault хранит только сам пароль. Секреты TOTP не переносятся.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format формат файла выгрузки
+type Format string
+
+// Поддерживаемые форматы
+const (
+	FormatKeePassXML    Format = "keepass-xml"
+	FormatBitwardenJSON Format = "bitwarden-json"
+	Format1PasswordCSV  Format = "1password-csv"
+	FormatCSV           Format = "csv"
+)
+
+// Formats форматы в порядке отображения в клиенте
+var Formats = []Format{FormatKeePassXML, FormatBitwardenJSON, Format1PasswordCSV, FormatCSV}
+
+// Типы элементов Gault
+const (
+	TypePassword = "password"
+	TypeCard     = "card"
+	TypeText     = "text"
+)
+
+// maxNameLen максимальная длина имени элемента на сервере
+const maxNameLen = 128
+
+// untitled имя элемента без названия
+const untitled = "Untitled"
+
+// ErrUnknownFormat формат выгрузки не поддерживается
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Item элемент Gault, полученный из выгрузки
+type Item struct {
+	Type    string
+	Name    string
+	Content string
+}
+
+// Parse разбор выгрузки в указанном формате. Сопоставление колонок нужно только для FormatCSV
+func Parse(format Format, r io.Reader, mapping Mapping) ([]Item, error) {
+	switch format {
+	case FormatKeePassXML:
+		return ParseKeePassXML(r)
+	case FormatBitwardenJSON:
+		return ParseBitwardenJSON(r)
+	case Format1PasswordCSV:
+		return Parse1PasswordCSV(r)
+	case FormatCSV:
+		return ParseCSV(r, mapping)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// FormatCard текст карты в формате клиента Gault
+func FormatCard(number, date, cvc string) string {
+	return fmt.Sprintf("Number: [%s];\nDate number: [%s];\nCVC number: [%s];", number, date, cvc)
+}
+
+// cardPattern разбор текста карты, строки могут быть разделены переводом строки или пробелом
+var cardPattern = regexp.MustCompile(`Number: \[(.*?)\];\s*Date number: \[(.*?)\];\s*CVC number: \[(.*?)\];`)
+
+// ParseCard номер, срок и CVC из текста карты в формате клиента Gault
+func ParseCard(content string) (number, date, cvc string, ok bool) {
+	m := cardPattern.FindStringSubmatch(content)
+	if m == nil {
+		return "", "", "", false
+	}
+	return m[1], m[2], m[3], true
+}
+
+// credential элементы учётной записи: пароль и, если есть адрес или заметки, текст с ними
+func credential(title, username, password, url, notes string) []Item {
+	title, username = strings.TrimSpace(title), strings.TrimSpace(username)
+
+	name := title
+	switch {
+	case title == "":
+		name = username
+	case username != "" && username != title:
+		name = fmt.Sprintf("%s (%s)", title, username)
+	}
+
+	var items []Item
+	if password != "" || username != "" {
+		items = append(items, Item{Type: TypePassword, Name: itemName(name), Content: password})
+	}
+
+	var details []string
+	if url = strings.TrimSpace(url); url != "" {
+		details = append(details, "URL: "+url)
+	}
+	if notes = strings.TrimSpace(notes); notes != "" {
+		details = append(details, notes)
+	}
+	if len(details) > 0 {
+		noteName := name
+		if len(items) > 0 {
+			noteName += " notes"
+		}
+		items = append(items, Item{Type: TypeText, Name: itemName(noteName), Content: strings.Join(details, "\n")})
+	}
+	return items
+}
+
+// card элемент карты
+func card(title, number, date, cvc string) Item {
+	return Item{Type: TypeCard, Name: itemName(title), Content: FormatCard(number, date, cvc)}
+}
+
+// note текстовый элемент, пустые заметки пропускаются
+func note(title, text string) []Item {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return []Item{{Type: TypeText, Name: itemName(title), Content: text}}
+}
+
+// itemName имя элемента, допустимое на сервере
+func itemName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return untitled
+	}
+	if utf8.RuneCountInString(name) > maxNameLen {
+		name = string([]rune(name)[:maxNameLen])
+	}
+	return name
+}
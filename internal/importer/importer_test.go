@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keePassXML = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta><RecycleBinUUID>bin</RecycleBinUUID></Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>Mail</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value Protected="True">secret</Value></String>
+				<String><Key>URL</Key><Value>https://mail.example.com</Value></String>
+				<String><Key>Notes</Key><Value></Value></String>
+				<History>
+					<Entry>
+						<String><Key>Title</Key><Value>Old mail</Value></String>
+						<String><Key>Password</Key><Value>old</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>work</UUID>
+				<Name>Work</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>VPN</Value></String>
+					<String><Key>Password</Key><Value>vpn-pass</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>bin</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+					<String><Key>Password</Key><Value>gone</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+func TestParseKeePassXML(t *testing.T) {
+	items, err := ParseKeePassXML(strings.NewReader(keePassXML))
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Type: TypePassword, Name: "Mail (alice)", Content: "secret"},
+		{Type: TypeText, Name: "Mail (alice) notes", Content: "URL: https://mail.example.com"},
+		{Type: TypePassword, Name: "VPN", Content: "vpn-pass"},
+	}, items)
+}
+
+func TestParseKeePassXML_Invalid(t *testing.T) {
+	_, err := ParseKeePassXML(strings.NewReader("<KeePassFile><Root>"))
+	assert.Error(t, err)
+}
+
+const bitwardenJSON = `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Bank"}],
+  "items": [
+    {"type": 1, "name": "GitHub", "notes": "2FA on phone",
+     "login": {"username": "bob", "password": "gh-pass", "totp": "JBSWY3DP", "uris": [{"uri": "https://github.com"}]}},
+    {"type": 2, "name": "Wifi", "notes": "ssid: home"},
+    {"type": 2, "name": "Empty", "notes": null},
+    {"type": 3, "name": "Visa", "card": {"cardholderName": "Bob", "number": "4111111111111111", "expMonth": "7", "expYear": "2027", "code": "123"}},
+    {"type": 4, "name": "Passport", "identity": {"firstName": "Bob", "lastName": "Smith", "passportNumber": "X1"}}
+  ]
+}`
+
+func TestParseBitwardenJSON(t *testing.T) {
+	items, err := ParseBitwardenJSON(strings.NewReader(bitwardenJSON))
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Type: TypePassword, Name: "GitHub (bob)", Content: "gh-pass"},
+		{Type: TypeText, Name: "GitHub (bob) notes", Content: "URL: https://github.com\n2FA on phone"},
+		{Type: TypeText, Name: "Wifi", Content: "ssid: home"},
+		{Type: TypeCard, Name: "Visa", Content: FormatCard("4111111111111111", "07/2027", "123")},
+		{Type: TypeText, Name: "Passport", Content: "firstName: Bob\nlastName: Smith\npassportNumber: X1"},
+	}, items)
+}
+
+func TestParseBitwardenJSON_Encrypted(t *testing.T) {
+	_, err := ParseBitwardenJSON(strings.NewReader(`{"encrypted": true, "items": []}`))
+	assert.ErrorIs(t, err, ErrEncryptedExport)
+}
+
+func TestParse1PasswordCSV(t *testing.T) {
+	data := "\ufeffTitle,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+		"Shop,https://shop.example.com,carol,shop-pass,,false,false,,\n" +
+		"Server,,,root-pass,,false,false,,\"line1\nline2\"\n"
+
+	items, err := Parse1PasswordCSV(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Type: TypePassword, Name: "Shop (carol)", Content: "shop-pass"},
+		{Type: TypeText, Name: "Shop (carol) notes", Content: "URL: https://shop.example.com"},
+		{Type: TypePassword, Name: "Server", Content: "root-pass"},
+		{Type: TypeText, Name: "Server notes", Content: "line1\nline2"},
+	}, items)
+}
+
+func TestParse1PasswordCSV_MissingColumns(t *testing.T) {
+	_, err := Parse1PasswordCSV(strings.NewReader("Title,Url\nShop,https://shop\n"))
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+}
+
+func TestParseCSV_Mapping(t *testing.T) {
+	mapping, err := ParseMapping("name=Site, username=Login, password=Secret, card_number=PAN, card_date=Exp, card_cvc=CVV")
+	require.NoError(t, err)
+
+	data := "Site,Login,Secret,PAN,Exp,CVV\n" +
+		"forum,dave,forum-pass,,,\n" +
+		"Amex,,,378282246310005,01/29,4321\n"
+	items, err := ParseCSV(strings.NewReader(data), mapping)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Type: TypePassword, Name: "forum (dave)", Content: "forum-pass"},
+		{Type: TypeCard, Name: "Amex", Content: FormatCard("378282246310005", "01/29", "4321")},
+	}, items)
+}
+
+func TestParseCSV_UnknownColumn(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("a,b\n1,2\n"), Mapping{Name: "title"})
+	assert.ErrorIs(t, err, ErrInvalidMapping)
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	for _, s := range []string{"", "password=Pass", "name", "name=Title, colour=Red"} {
+		_, err := ParseMapping(s)
+		assert.ErrorIs(t, err, ErrInvalidMapping, s)
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	_, err := Parse("lastpass", strings.NewReader(""), Mapping{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestItemName(t *testing.T) {
+	assert.Equal(t, untitled, itemName("  "))
+	assert.Equal(t, maxNameLen, len([]rune(itemName(strings.Repeat("я", 200)))))
+}
+
+func TestParseCard(t *testing.T) {
+	number, date, cvc, ok := ParseCard(FormatCard("4111111111111111", "07/27", "123"))
+	require.True(t, ok)
+	assert.Equal(t, []string{"4111111111111111", "07/27", "123"}, []string{number, date, cvc})
+
+	_, date, _, ok = ParseCard("Number: [1]; Date number: [2]; CVC number: [3];")
+	require.True(t, ok)
+	assert.Equal(t, "2", date)
+
+	_, _, _, ok = ParseCard("free text")
+	assert.False(t, ok)
+}
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// keePassFile выгрузка KeePass 2.x в формате XML
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string `xml:"RecycleBinUUID"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+// keePassGroup группа KeePass с вложенными группами
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+// keePassEntry запись KeePass. История изменений лежит во вложенном History и не читается
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+// ParseKeePassXML разбор выгрузки KeePass 2.x XML. Записи корзины пропускаются
+func ParseKeePassXML(r io.Reader) ([]Item, error) {
+	var file keePassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse KeePass XML: %w", err)
+	}
+
+	var items []Item
+	var walk func(groups []keePassGroup)
+	walk = func(groups []keePassGroup) {
+		for _, group := range groups {
+			if file.Meta.RecycleBinUUID != "" && group.UUID == file.Meta.RecycleBinUUID {
+				continue
+			}
+			for _, entry := range group.Entries {
+				fields := make(map[string]string, len(entry.Strings))
+				for _, s := range entry.Strings {
+					fields[s.Key] = s.Value
+				}
+				items = append(items, credential(
+					fields["Title"], fields["UserName"], fields["Password"], fields["URL"], fields["Notes"],
+				)...)
+			}
+			walk(group.Groups)
+		}
+	}
+	walk(file.Root.Groups)
+	return items, nil
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+const (
+	// exportChunkSize максимальный размер данных в одном сообщении выгрузки
+	exportChunkSize = 1024 * 1024
+	// exportPageSize размер страницы листа данных при выгрузке
+	exportPageSize = 200
+)
+
+// ChangePassword метод смены пароля GaultService. Текущая сессия остаётся, остальные завершаются
+func (g *GaultService) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := sessionTokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.CheckPassword(ctx, userUID, req.GetOldPassword()); err != nil {
+		return nil, passwordError(err)
+	}
+	if err := checkPasswordPolicy(req.GetNewPassword()); err != nil {
+		return nil, err
+	}
+	hash, err := utils.HashPassword(req.GetNewPassword())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.ChangePassword(ctx, userUID, hash, token, req.GetEncryptedPrivateKey()); err != nil {
+		return nil, shareError(err)
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_PASSWORD_CHANGED})
+	return &pb.ChangePasswordResponse{}, nil
+}
+
+// DeleteAccount метод удаления учётной записи GaultService. При включённой 2FA нужен код
+func (g *GaultService) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.CheckPassword(ctx, userUID, req.GetPassword()); err != nil {
+		return nil, passwordError(err)
+	}
+	_, enabled, err := g.rep.GetTwoFactor(ctx, userUID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if enabled {
+		ok, err := g.verifySecondFactor(ctx, userUID, req.GetCode())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "invalid code")
+		}
+	}
+
+	err = g.rep.DeleteAccount(ctx, userUID)
+	if errors.Is(err, db.ErrLastOrgOwner) {
+		return nil, status.Error(codes.FailedPrecondition, "transfer ownership of your organizations before deleting the account")
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ACCOUNT_DELETED})
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+// ExportAll метод выгрузки всех данных личного хранилища GaultService
+func (g *GaultService) ExportAll(_ *pb.ExportAllRequest, stream pb.ContentManagerV1Service_ExportAllServer) error {
+	ctx := stream.Context()
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &pb.GetUserDataListRequest{Sort: pb.SortOrder_SORT_ORDER_CREATED, PageSize: exportPageSize}
+	for {
+		list, err := g.rep.GetDataNameList(ctx, userUID, req)
+		if err != nil {
+			return err
+		}
+		for _, item := range list.GetItems() {
+			if err := g.exportItem(stream, userUID, item); err != nil {
+				return err
+			}
+		}
+		if list.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = list.GetNextPageToken()
+	}
+
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_DATA_EXPORTED})
+	return nil
+}
+
+// exportItem отправка одного элемента выгрузки, содержимое делится на части по exportChunkSize
+func (g *GaultService) exportItem(stream pb.ContentManagerV1Service_ExportAllServer, userUID string, item *pb.UserDataItem) error {
+	ctx := stream.Context()
+	access, err := g.rep.GetDataAccess(ctx, userUID, item.GetId())
+	if err != nil {
+		return shareError(err)
+	}
+	data, err := g.rep.GetData(ctx, item.GetId())
+	if err != nil {
+		return err
+	}
+
+	content := data.GetFileData()
+	if _, ok := data.GetContent().(*pb.GetDataResponse_TextData); ok {
+		content = []byte(data.GetTextData())
+	}
+
+	msg := &pb.ExportAllResponse{Item: item, DataKey: access.GetDataKey()}
+	for {
+		n := min(len(content), exportChunkSize)
+		msg.Data = content[:n]
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+		content = content[n:]
+		if len(content) == 0 {
+			return nil
+		}
+		msg = &pb.ExportAllResponse{}
+	}
+}
+
+// sessionTokenFromContext получение токена сессии из метаданных запроса
+func sessionTokenFromContext(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := md.Get("authorization")
+	if len(token) == 0 {
+		return "", status.Error(codes.Unauthenticated, "token is not provided")
+	}
+	return token[0], nil
+}
+
+// passwordError перевод ошибки проверки пароля в статус gRPC
+func passwordError(err error) error {
+	if errors.Is(err, db.ErrInvalidCredentials) {
+		return status.Error(codes.PermissionDenied, "invalid password")
+	}
+	return shareError(err)
+}
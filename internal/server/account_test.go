@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+// mockExportAllServer заглушка, реализующая интерфейс ContentManagerV1Service_ExportAllServer
+type mockExportAllServer struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.ExportAllResponse
+}
+
+func (m *mockExportAllServer) Send(resp *pb.ExportAllResponse) error {
+	m.sent = append(m.sent, resp)
+	return nil
+}
+
+func (m *mockExportAllServer) Context() context.Context {
+	return m.ctx
+}
+
+func TestGaultService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("useruid", "user-uid", "authorization", "token"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "old-password").Return(nil)
+		repo.EXPECT().ChangePassword(ctx, "user-uid", gomock.Any(), "token", "wrapped").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_PASSWORD_CHANGED,
+		}).Return(nil)
+
+		resp, err := service.ChangePassword(ctx, &pb.ChangePasswordRequest{
+			OldPassword:         "old-password",
+			NewPassword:         "new-password",
+			EncryptedPrivateKey: "wrapped",
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("wrong old password", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "wrong").Return(db.ErrInvalidCredentials)
+
+		_, err := service.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new-password"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("weak new password", func(t *testing.T) {
+		setPasswordPolicy(config.PasswordPolicy{MinLength: 8})
+		defer setPasswordPolicy(config.PasswordPolicy{})
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "old-password").Return(nil)
+
+		_, err := service.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "old-password", NewPassword: "short"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("no token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+		_, err := service.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "old-password", NewPassword: "new-password"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestGaultService_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success without 2FA", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "password").Return(nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+		repo.EXPECT().DeleteAccount(ctx, "user-uid").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_ACCOUNT_DELETED,
+		}).Return(nil)
+
+		_, err := service.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"})
+		assert.NoError(t, err)
+	})
+	t.Run("2FA code required", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "password").Return(nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("JBSWY3DPEHPK3PXP", true, nil).Times(2)
+		repo.EXPECT().UseRecoveryCode(ctx, "user-uid", gomock.Any()).Return(false, nil)
+
+		_, err := service.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "wrong").Return(db.ErrInvalidCredentials)
+
+		_, err := service.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "wrong"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("last organization owner", func(t *testing.T) {
+		repo.EXPECT().CheckPassword(ctx, "user-uid", "password").Return(nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+		repo.EXPECT().DeleteAccount(ctx, "user-uid").Return(db.ErrLastOrgOwner)
+
+		_, err := service.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestGaultService_ExportAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	note := &pb.UserDataItem{Id: "note-id", Name: "note", Type: "text"}
+	file := &pb.UserDataItem{Id: "file-id", Name: "big", Type: "file"}
+	bigFile := bytes.Repeat([]byte("x"), exportChunkSize+10)
+
+	repo.EXPECT().GetDataNameList(ctx, "user-uid", gomock.Any()).
+		Return(&pb.GetUserDataListResponse{Items: []*pb.UserDataItem{note}, NextPageToken: "next"}, nil)
+	repo.EXPECT().GetDataNameList(ctx, "user-uid", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, req *pb.GetUserDataListRequest) (*pb.GetUserDataListResponse, error) {
+			assert.Equal(t, "next", req.GetPageToken())
+			return &pb.GetUserDataListResponse{Items: []*pb.UserDataItem{file}}, nil
+		})
+	repo.EXPECT().GetDataAccess(ctx, "user-uid", "note-id").Return(&pb.GetDataResponse{DataKey: "wrapped-key"}, nil)
+	repo.EXPECT().GetData(ctx, "note-id").Return(&pb.GetDataResponse{
+		Type: "text", Content: &pb.GetDataResponse_TextData{TextData: "hello"},
+	}, nil)
+	repo.EXPECT().GetDataAccess(ctx, "user-uid", "file-id").Return(&pb.GetDataResponse{}, nil)
+	repo.EXPECT().GetData(ctx, "file-id").Return(&pb.GetDataResponse{
+		Type: "file", Content: &pb.GetDataResponse_FileData{FileData: bigFile},
+	}, nil)
+	repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+		Action: pb.AuditAction_AUDIT_ACTION_DATA_EXPORTED,
+	}).Return(nil)
+
+	stream := &mockExportAllServer{ctx: ctx}
+	assert.NoError(t, service.ExportAll(&pb.ExportAllRequest{}, stream))
+
+	assert.Len(t, stream.sent, 3)
+	assert.Equal(t, "note", stream.sent[0].GetItem().GetName())
+	assert.Equal(t, "wrapped-key", stream.sent[0].GetDataKey())
+	assert.Equal(t, []byte("hello"), stream.sent[0].GetData())
+	assert.Equal(t, "big", stream.sent[1].GetItem().GetName())
+	assert.Len(t, stream.sent[1].GetData(), exportChunkSize)
+	assert.Nil(t, stream.sent[2].GetItem())
+	assert.Len(t, stream.sent[2].GetData(), 10)
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mockDB.NewMockRepository(ctrl)
+	oldServer := gaultServer
+	gaultServer = &GaultService{rep: repo}
+	defer func() { gaultServer = oldServer }()
+	setAllowEndpoints(nil)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/api.proto.v1.ContentManagerV1Service/ExportAll"}
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		_, err := userUIDFromContext(stream.Context())
+		return err
+	}
+
+	err := AuthStreamInterceptor(nil, &mockExportAllServer{ctx: context.Background()}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid", "authorization", "token"))
+	repo.EXPECT().CheckSessionUser(gomock.Any(), "user-uid", "token").Return(true)
+	assert.NoError(t, AuthStreamInterceptor(nil, &mockExportAllServer{ctx: ctx}, info, handler))
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// ListAuditEvents метод получения своего журнала действий GaultService
+func (g *GaultService) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := g.rep.ListAuditEvents(ctx, userUID, req)
+	if errors.Is(err, db.ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// audit запись события в журнал действий. Ошибка записи не прерывает обработку запроса
+func (g *GaultService) audit(ctx context.Context, userUID string, event *pb.AuditEvent) {
+	event.ClientAddr = clientAddr(ctx)
+	if err := g.rep.InsertAuditEvent(ctx, userUID, event); err != nil {
+		logger.LogErrorCtx(ctx, "audit write failed", zap.Stringer("action", event.GetAction()), zap.Error(err))
+	}
+}
+
+// clientAddr адрес клиента из контекста запроса
+func clientAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_ListAuditEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		req := &pb.ListAuditEventsRequest{PageSize: 10}
+		repo.EXPECT().ListAuditEvents(ctx, "user-uid", req).Return(&pb.ListAuditEventsResponse{
+			Events:        []*pb.AuditEvent{{Id: 2, Action: pb.AuditAction_AUDIT_ACTION_ITEM_READ}},
+			NextPageToken: "2",
+		}, nil)
+
+		resp, err := service.ListAuditEvents(ctx, req)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Events, 1)
+		assert.Equal(t, "2", resp.NextPageToken)
+	})
+	t.Run("invalid page token", func(t *testing.T) {
+		req := &pb.ListAuditEventsRequest{PageToken: "bad"}
+		repo.EXPECT().ListAuditEvents(ctx, "user-uid", req).Return(nil, db.ErrInvalidPageToken)
+
+		resp, err := service.ListAuditEvents(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("md error", func(t *testing.T) {
+		resp, err := service.ListAuditEvents(context.Background(), &pb.ListAuditEventsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("useruid", "user-uid", "authorization", "token"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().DeleteSession(ctx, "user-uid", "token").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_SESSION_REVOKED,
+		}).Return(nil)
+
+		resp, err := service.Logout(ctx, &pb.LogoutRequest{})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("session not found", func(t *testing.T) {
+		repo.EXPECT().DeleteSession(ctx, "user-uid", "token").Return(db.ErrNotFound)
+
+		resp, err := service.Logout(ctx, &pb.LogoutRequest{})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("token is not provided", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+		resp, err := service.Logout(ctx, &pb.LogoutRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+
+	t.Run("client address from peer", func(t *testing.T) {
+		addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action:     pb.AuditAction_AUDIT_ACTION_ITEM_CREATE,
+			DataId:     "data-id",
+			ClientAddr: "10.0.0.1:5000",
+		}).Return(nil)
+
+		service.audit(ctx, "user-uid", &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_CREATE, DataId: "data-id"})
+	})
+	t.Run("insert error is not returned", func(t *testing.T) {
+		repo.EXPECT().InsertAuditEvent(gomock.Any(), "user-uid", gomock.Any()).Return(fmt.Errorf("error"))
+
+		assert.NotPanics(t, func() {
+			service.audit(context.Background(), "user-uid", &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_READ})
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// CreateFolder метод создания папки GaultService
+func (g *GaultService) CreateFolder(ctx context.Context, req *pb.CreateFolderRequest) (*pb.CreateFolderResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "folder name is empty")
+	}
+
+	id, err := g.rep.CreateFolder(ctx, userUID, req.GetParentId(), req.GetName())
+	if err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.CreateFolderResponse{Id: id}, nil
+}
+
+// RenameFolder метод переименования папки GaultService
+func (g *GaultService) RenameFolder(ctx context.Context, req *pb.RenameFolderRequest) (*pb.RenameFolderResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "folder name is empty")
+	}
+
+	if err := g.rep.RenameFolder(ctx, userUID, req.GetId(), req.GetName()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.RenameFolderResponse{}, nil
+}
+
+// MoveFolder метод перемещения папки GaultService
+func (g *GaultService) MoveFolder(ctx context.Context, req *pb.MoveFolderRequest) (*pb.MoveFolderResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.MoveFolder(ctx, userUID, req.GetId(), req.GetParentId()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.MoveFolderResponse{}, nil
+}
+
+// DeleteFolder метод удаления папки GaultService
+func (g *GaultService) DeleteFolder(ctx context.Context, req *pb.DeleteFolderRequest) (*pb.DeleteFolderResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.DeleteFolder(ctx, userUID, req.GetId()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.DeleteFolderResponse{}, nil
+}
+
+// ListFolders метод получения папок GaultService
+func (g *GaultService) ListFolders(ctx context.Context, _ *pb.ListFoldersRequest) (*pb.ListFoldersResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := g.rep.ListFolders(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// MoveData метод перемещения данных в папку GaultService
+func (g *GaultService) MoveData(ctx context.Context, req *pb.MoveDataRequest) (*pb.MoveDataResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.MoveData(ctx, userUID, req.GetId(), req.GetFolderId()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.MoveDataResponse{}, nil
+}
+
+// SetDataTags метод установки тегов данных GaultService
+func (g *GaultService) SetDataTags(ctx context.Context, req *pb.SetDataTagsRequest) (*pb.SetDataTagsResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.SetDataTags(ctx, userUID, req.GetId(), req.GetTags()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.SetDataTagsResponse{}, nil
+}
+
+// folderError перевод ошибок хранилища в статусы gRPC
+func folderError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrFolderCycle):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
+}
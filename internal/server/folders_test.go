@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_CreateFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().CreateFolder(ctx, "user-uid", "parent-id", "work").Return("folder-id", nil)
+
+		resp, err := service.CreateFolder(ctx, &pb.CreateFolderRequest{ParentId: "parent-id", Name: "work"})
+		assert.NoError(t, err)
+		assert.Equal(t, "folder-id", resp.Id)
+	})
+	t.Run("empty name", func(t *testing.T) {
+		resp, err := service.CreateFolder(ctx, &pb.CreateFolderRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("parent not found", func(t *testing.T) {
+		repo.EXPECT().CreateFolder(ctx, "user-uid", "parent-id", "work").Return("", db.ErrNotFound)
+
+		resp, err := service.CreateFolder(ctx, &pb.CreateFolderRequest{ParentId: "parent-id", Name: "work"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("md error", func(t *testing.T) {
+		resp, err := service.CreateFolder(context.Background(), &pb.CreateFolderRequest{Name: "work"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_MoveFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().MoveFolder(ctx, "user-uid", "folder-id", "parent-id").Return(nil)
+
+		resp, err := service.MoveFolder(ctx, &pb.MoveFolderRequest{Id: "folder-id", ParentId: "parent-id"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		repo.EXPECT().MoveFolder(ctx, "user-uid", "folder-id", "parent-id").Return(db.ErrFolderCycle)
+
+		resp, err := service.MoveFolder(ctx, &pb.MoveFolderRequest{Id: "folder-id", ParentId: "parent-id"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_RenameAndDeleteFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().RenameFolder(ctx, "user-uid", "folder-id", "home").Return(nil)
+	_, err := service.RenameFolder(ctx, &pb.RenameFolderRequest{Id: "folder-id", Name: "home"})
+	assert.NoError(t, err)
+
+	repo.EXPECT().DeleteFolder(ctx, "user-uid", "folder-id").Return(fmt.Errorf("error"))
+	_, err = service.DeleteFolder(ctx, &pb.DeleteFolderRequest{Id: "folder-id"})
+	assert.Error(t, err)
+}
+
+func TestGaultService_ListFolders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().ListFolders(ctx, "user-uid").Return(&pb.ListFoldersResponse{
+		Folders: []*pb.Folder{{Id: "folder-id", Name: "work"}},
+	}, nil)
+
+	resp, err := service.ListFolders(ctx, &pb.ListFoldersRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Folders, 1)
+}
+
+func TestGaultService_MoveDataAndTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().MoveData(ctx, "user-uid", "data-id", "folder-id").Return(nil)
+	_, err := service.MoveData(ctx, &pb.MoveDataRequest{Id: "data-id", FolderId: "folder-id"})
+	assert.NoError(t, err)
+
+	repo.EXPECT().SetDataTags(ctx, "user-uid", "data-id", []string{"work"}).Return(db.ErrNotFound)
+	_, err = service.SetDataTags(ctx, &pb.SetDataTagsRequest{Id: "data-id", Tags: []string{"work"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
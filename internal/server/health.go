@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// defaultHealthInterval интервал проверки соединения с базой данных по умолчанию
+const defaultHealthInterval = 10 * time.Second
+
+// publicServices сервисы, доступные без аутентификации: проверка готовности и reflection
+var publicServices = make(map[string]bool)
+
+// isPublicMethod относится ли метод вида /package.Service/Method к сервису без аутентификации
+func isPublicMethod(fullMethod string) bool {
+	service, _, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return ok && publicServices[service]
+}
+
+// runHealthMonitor проверка базы данных при запуске и далее каждые interval до отмены ctx.
+// Статус выставляется для сервера в целом и для каждого сервиса из services
+func runHealthMonitor(ctx context.Context, hs *health.Server, rep db.Repository, interval time.Duration, services []string) {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := checkHealth(ctx, hs, rep, services)
+		if current != last {
+			logger.LogInfo("health status changed", zap.Stringer("status", current))
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth проверка соединения с базой данных и обновление статуса сервисов
+func checkHealth(ctx context.Context, hs *health.Server, rep db.Repository, services []string) healthpb.HealthCheckResponse_ServingStatus {
+	current := healthpb.HealthCheckResponse_SERVING
+	if err := rep.Ping(ctx); err != nil {
+		logger.LogError("health check failed", zap.Error(err))
+		current = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	hs.SetServingStatus("", current)
+	for _, service := range services {
+		hs.SetServingStatus(service, current)
+	}
+	return current
+}
+
+// defaultHealthServerName имя сервера в сертификате для ./gault healthcheck по умолчанию
+const defaultHealthServerName = "localhost"
+
+// CheckHealth запрос grpc.health.v1 к локально запущенному серверу, ошибка, если сервер не готов.
+// Используется в healthcheck контейнера
+func CheckHealth(conf config.Config) error {
+	creds, err := healthCheckCredentials(conf)
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.NewClient(fmt.Sprintf("127.0.0.1:%d", conf.Port), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("failed to create health client: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return errors.New("server is " + resp.GetStatus().String())
+	}
+	return nil
+}
+
+// healthCheckCredentials учётные данные клиента проверки: сертификат сервера проверяется по CA из конфигурации
+func healthCheckCredentials(conf config.Config) (credentials.TransportCredentials, error) {
+	if conf.TLS.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig, err := healthCheckTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// healthCheckTLSConfig настройки TLS проверки: версия, наборы шифров и имя сервера из блока tls,
+// клиентский сертификат из блока health, если сервер его принимает
+func healthCheckTLSConfig(conf config.Config) (*tls.Config, error) {
+	minVersion, err := conf.TLS.Version()
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := conf.TLS.Ciphers()
+	if err != nil {
+		return nil, err
+	}
+
+	caPEM, err := os.ReadFile(orDefault(conf.TLS.CAFile, defaultCAFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("failed to parse ca")
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:      pool,
+		ServerName:   orDefault(conf.TLS.ServerName, defaultHealthServerName),
+		MinVersion:   minVersion,
+		CipherSuites: ciphers,
+	}
+	switch {
+	case conf.Health.CertFile != "" || conf.Health.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(conf.Health.CertFile, conf.Health.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load health client cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case conf.MTLS.Mode == "required":
+		return nil, errors.New("health.certFile and health.keyFile are required with mtls.mode: required")
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestCheckHealth_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	hs := health.NewServer()
+	ctx := context.Background()
+
+	repo.EXPECT().Ping(ctx).Return(errors.New("connection refused"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, checkHealth(ctx, hs, repo, []string{"api.proto.v1.AuthV1Service"}))
+	resp, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: "api.proto.v1.AuthV1Service"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	repo.EXPECT().Ping(ctx).Return(nil)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, checkHealth(ctx, hs, repo, nil))
+	resp, err = hs.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
+
+func TestIsPublicMethod(t *testing.T) {
+	publicServices[healthpb.Health_ServiceDesc.ServiceName] = true
+
+	assert.True(t, isPublicMethod("/grpc.health.v1.Health/Check"))
+	assert.False(t, isPublicMethod("/api.proto.v1.ContentManagerV1Service/GetData"))
+	assert.False(t, isPublicMethod("grpc.health.v1.Health"))
+}
+
+func TestServe_HealthReflectionAndShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mockDB.NewMockRepository(ctrl)
+	repo.EXPECT().RefreshRotationDue(gomock.Any(), "").Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().PurgeExpiredTrash(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().Ping(gomock.Any()).Return(nil).AnyTimes()
+
+	conf := config.Config{
+		Port:       getFreePort(t),
+		TLS:        config.TLS{Insecure: true},
+		Reflection: true,
+		Shutdown:   config.Shutdown{DrainTimeout: time.Second},
+		Metrics:    config.Metrics{Addr: fmt.Sprintf("127.0.0.1:%d", getFreePort(t))},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, conf, repo)
+	}()
+
+	// Проверка готовности и reflection доступны без токена сессии
+	require.Eventually(t, func() bool { return CheckHealth(conf) == nil }, 3*time.Second, 50*time.Millisecond)
+
+	conn, err := grpc.NewClient(listenAddr(conf), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(),
+		&healthpb.HealthCheckRequest{Service: "api.proto.v1.ContentManagerV1Service"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	reflected, err := stream.Recv()
+	require.NoError(t, err)
+	var names []string
+	for _, service := range reflected.GetListServicesResponse().GetService() {
+		names = append(names, service.GetName())
+	}
+	assert.Contains(t, names, "api.proto.v1.AuthV1Service")
+	require.NoError(t, stream.CloseSend())
+
+	// Запросы проверки готовности видны в метриках
+	metricsResp, err := http.Get("http://" + conf.Metrics.Addr + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(metricsResp.Body)
+	require.NoError(t, err)
+	_ = metricsResp.Body.Close()
+	assert.Contains(t, string(body), `gault_grpc_requests_total{code="OK",method="/grpc.health.v1.Health/Check"}`)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("server did not stop")
+	}
+	assert.Error(t, CheckHealth(conf))
+}
+
+func TestCheckHealth_MissingCA(t *testing.T) {
+	err := CheckHealth(config.Config{Port: getFreePort(t), TLS: config.TLS{CAFile: "missing/ca.crt"}})
+	assert.ErrorContains(t, err, "failed to read ca")
+}
+
+func TestHealthCheckTLSConfig(t *testing.T) {
+	writeTestCerts(t)
+
+	tlsConfig, err := healthCheckTLSConfig(config.Config{TLS: config.TLS{MinVersion: "1.3"}})
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+	assert.Empty(t, tlsConfig.Certificates)
+
+	// При обязательном mTLS проверка без клиентского сертификата не пройдёт рукопожатие
+	_, err = healthCheckTLSConfig(config.Config{MTLS: config.MTLS{Mode: "required"}})
+	assert.ErrorContains(t, err, "health.certFile and health.keyFile are required")
+
+	tlsConfig, err = healthCheckTLSConfig(config.Config{
+		TLS:    config.TLS{ServerName: "gault.internal"},
+		MTLS:   config.MTLS{Mode: "required"},
+		Health: config.Health{CertFile: "certs/server.crt", KeyFile: "certs/server.key"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gault.internal", tlsConfig.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+	assert.Len(t, tlsConfig.Certificates, 1)
+
+	_, err = healthCheckTLSConfig(config.Config{Health: config.Health{CertFile: "missing.crt", KeyFile: "missing.key"}})
+	assert.ErrorContains(t, err, "failed to load health client cert/key")
+}
+
+// listenAddr локальный адрес сервера из конфигурации
+func listenAddr(conf config.Config) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(conf.Port))
+}
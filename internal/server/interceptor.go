@@ -2,11 +2,11 @@ package server
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/fngoc/gault/internal/config"
 	"github.com/fngoc/gault/pkg/logger"
 
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -23,18 +23,63 @@ func AuthInterceptor(
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
-	allowed, ok := unprotectedMethods[info.FullMethod]
-	if ok && allowed {
-		return handler(ctx, req)
+	ctx, err := authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthStreamInterceptor проверяет токен сессии в потоковых запросах
+func AuthStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// authServerStream поток с контекстом после аутентификации
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context контекст потока после аутентификации
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate проверка токена сессии или клиентского сертификата, возвращает контекст для обработчика
+func authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	allowed, ok := unprotectedMethods[fullMethod]
+	if ok && allowed || isPublicMethod(fullMethod) {
+		return ctx, nil
+	}
+
+	certUser, err := certUserUID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
+	if !ok && !(certAuth && certUser != "") {
 		return nil, status.Error(codes.Unauthenticated, "metadata is not provided")
 	}
 
 	authHeader, authExists := md["authorization"]
 	if !authExists || len(authHeader) == 0 {
+		// Сертификат заменяет токен сессии, если это разрешено конфигурацией
+		if certAuth && certUser != "" {
+			ctx = logger.WithFields(withUserUID(ctx, certUser), zap.String("user", certUser))
+			logger.LogInfoCtx(ctx, "authenticated", zap.String("auth", "cert"))
+			return ctx, nil
+		}
 		return nil, status.Error(codes.Unauthenticated, "token is not provided")
 	}
 	authUserUID, userUIDExists := md["useruid"]
@@ -47,9 +92,14 @@ func AuthInterceptor(
 	if !gaultServer.rep.CheckSessionUser(ctx, userUID, token) {
 		return nil, status.Error(codes.Unauthenticated, "user is not authorized")
 	}
-	logger.LogInfo(fmt.Sprintf("%s %s", info.FullMethod, token))
+	// Токен и сертификат должны принадлежать одному пользователю
+	if certUser != "" && certUser != userUID {
+		return nil, status.Error(codes.PermissionDenied, "client certificate does not match user")
+	}
+	ctx = logger.WithFields(ctx, zap.String("user", userUID))
+	logger.LogInfoCtx(ctx, "authenticated", zap.String("auth", "token"))
 
-	return handler(ctx, req)
+	return ctx, nil
 }
 
 func setAllowEndpoints(rule []config.EndpointRule) {
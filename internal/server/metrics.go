@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fngoc/gault/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// serverMetrics метрики gRPC-запросов сервера
+type serverMetrics struct {
+	// requests число завершённых запросов по методу и коду ответа
+	requests *prometheus.CounterVec
+	// duration время обработки запроса
+	duration *prometheus.HistogramVec
+	// bytes объём сообщений по методу и направлению: in — от клиента, out — клиенту
+	bytes *prometheus.CounterVec
+	// streams число открытых потоков
+	streams *prometheus.GaugeVec
+}
+
+// newServerMetrics регистрация метрик запросов и метрик процесса в reg
+func newServerMetrics(reg prometheus.Registerer) (*serverMetrics, error) {
+	m := &serverMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gault_grpc_requests_total",
+			Help: "Number of completed gRPC requests.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gault_grpc_request_duration_seconds",
+			Help:    "Duration of gRPC requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gault_grpc_message_bytes_total",
+			Help: "Size of gRPC messages received and sent.",
+		}, []string{"method", "direction"}),
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "gault_grpc_active_streams",
+			Help: "Number of open gRPC streams.",
+		}, []string{"method"}),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		m.requests, m.duration, m.bytes, m.streams,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := reg.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// UnaryInterceptor замер времени, размера и кода ответа обычных запросов
+func (m *serverMetrics) UnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	started := time.Now()
+	m.bytes.WithLabelValues(info.FullMethod, "in").Add(float64(messageSize(req)))
+
+	resp, err := handler(ctx, req)
+	if err == nil {
+		m.bytes.WithLabelValues(info.FullMethod, "out").Add(float64(messageSize(resp)))
+	}
+	m.observe(info.FullMethod, started, err)
+	return resp, err
+}
+
+// StreamInterceptor замер времени, объёма сообщений и числа открытых потоков
+func (m *serverMetrics) StreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	started := time.Now()
+	active := m.streams.WithLabelValues(info.FullMethod)
+	active.Inc()
+	defer active.Dec()
+
+	err := handler(srv, &metricsServerStream{
+		ServerStream: ss,
+		in:           m.bytes.WithLabelValues(info.FullMethod, "in"),
+		out:          m.bytes.WithLabelValues(info.FullMethod, "out"),
+	})
+	m.observe(info.FullMethod, started, err)
+	return err
+}
+
+// observe запись завершённого запроса
+func (m *serverMetrics) observe(method string, started time.Time, err error) {
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.duration.WithLabelValues(method).Observe(time.Since(started).Seconds())
+}
+
+// metricsServerStream поток с подсчётом объёма принятых и отправленных сообщений
+type metricsServerStream struct {
+	grpc.ServerStream
+	in  prometheus.Counter
+	out prometheus.Counter
+}
+
+// RecvMsg приём сообщения с учётом его размера
+func (s *metricsServerStream) RecvMsg(msg interface{}) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.in.Add(float64(messageSize(msg)))
+	}
+	return err
+}
+
+// SendMsg отправка сообщения с учётом его размера
+func (s *metricsServerStream) SendMsg(msg interface{}) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.out.Add(float64(messageSize(msg)))
+	}
+	return err
+}
+
+// messageSize размер сообщения protobuf в байтах, 0 для других типов
+func messageSize(msg interface{}) int {
+	if m, ok := msg.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	return 0
+}
+
+// serveMetrics HTTP-сервер с /metrics на listener до отмены ctx
+func serveMetrics(ctx context.Context, listener net.Listener, gatherer prometheus.Gatherer) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.LogInfo("start metrics server", zap.Stringer("addr", listener.Addr()))
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.LogError("metrics server failed", zap.Error(err))
+	}
+}
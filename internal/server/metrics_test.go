@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// fakeMetricsStream поток с заранее заданными входящими сообщениями
+type fakeMetricsStream struct {
+	grpc.ServerStream
+	reqs []*pb.SaveDataRequest
+}
+
+func (s *fakeMetricsStream) RecvMsg(msg interface{}) error {
+	if len(s.reqs) == 0 {
+		return io.EOF
+	}
+	proto.Merge(msg.(proto.Message), s.reqs[0])
+	s.reqs = s.reqs[1:]
+	return nil
+}
+
+func (s *fakeMetricsStream) SendMsg(interface{}) error {
+	return nil
+}
+
+func TestServerMetrics_Unary(t *testing.T) {
+	m, err := newServerMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	const method = "/api.proto.v1.ContentManagerV1Service/GetData"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	req := &pb.GetDataRequest{Id: "data-id"}
+	resp := &pb.GetDataResponse{Type: "text"}
+
+	_, err = m.UnaryInterceptor(context.Background(), req, info, func(context.Context, interface{}) (interface{}, error) {
+		return resp, nil
+	})
+	require.NoError(t, err)
+	_, err = m.UnaryInterceptor(context.Background(), req, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(method, "OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(method, "NotFound")))
+	assert.Equal(t, float64(2*proto.Size(req)), testutil.ToFloat64(m.bytes.WithLabelValues(method, "in")))
+	assert.Equal(t, float64(proto.Size(resp)), testutil.ToFloat64(m.bytes.WithLabelValues(method, "out")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.duration))
+}
+
+func TestServerMetrics_Stream(t *testing.T) {
+	m, err := newServerMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	const method = "/api.proto.v1.ContentManagerV1Service/SaveData"
+	chunks := []*pb.SaveDataRequest{{Data: make([]byte, 100)}, {Data: make([]byte, 50)}}
+	stream := &fakeMetricsStream{reqs: chunks}
+	resp := &pb.SaveDataResponse{Id: "new-id"}
+
+	err = m.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: method}, func(_ interface{}, ss grpc.ServerStream) error {
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.streams.WithLabelValues(method)))
+		for {
+			req := &pb.SaveDataRequest{}
+			if err := ss.RecvMsg(req); err == io.EOF {
+				break
+			}
+		}
+		return ss.SendMsg(resp)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.streams.WithLabelValues(method)))
+	assert.Equal(t, float64(proto.Size(chunks[0])+proto.Size(chunks[1])), testutil.ToFloat64(m.bytes.WithLabelValues(method, "in")))
+	assert.Equal(t, float64(proto.Size(resp)), testutil.ToFloat64(m.bytes.WithLabelValues(method, "out")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(method, "OK")))
+}
+
+func TestServeMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := newServerMetrics(reg)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		serveMetrics(ctx, listener, reg)
+		close(done)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "go_goroutines")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// certAuth разрешена ли аутентификация по клиентскому сертификату без токена сессии
+var certAuth bool
+
+// clientAuthType режим проверки клиентских сертификатов по значению mtls.mode
+func clientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch mode {
+	case "", "off":
+		return tls.NoClientCert, nil
+	case "optional":
+		return tls.VerifyClientCertIfGiven, nil
+	case "required":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown mtls mode %q", mode)
+	}
+}
+
+// setMTLS применение настроек mTLS к перехватчику авторизации
+func setMTLS(conf config.MTLS) {
+	certAuth = conf.CertAuth && conf.Mode != "" && conf.Mode != "off"
+}
+
+// certUserUID UID пользователя, которому выдан проверенный клиентский сертификат.
+// Пользователь определяется по CN сертификата, пустая строка означает, что сертификат не предъявлен
+func certUserUID(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", nil
+	}
+
+	login := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	userUID, err := gaultServer.rep.GetUserUID(ctx, login)
+	if errors.Is(err, db.ErrNotFound) {
+		return "", status.Error(codes.Unauthenticated, "client certificate subject is not a known user")
+	}
+	if err != nil {
+		return "", err
+	}
+	return userUID, nil
+}
+
+// withUserUID контекст запроса с UID пользователя в метаданных, как если бы его передал клиент
+func withUserUID(ctx context.Context, userUID string) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	md.Set("useruid", userUID)
+	return metadata.NewIncomingContext(ctx, md)
+}
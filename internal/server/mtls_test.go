@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+// certContext контекст соединения с проверенным клиентским сертификатом на логин
+func certContext(login string) context.Context {
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: login}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 40000},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{leaf}},
+		}},
+	})
+}
+
+// setupCertAuth подмена сервиса и настроек mTLS на время теста
+func setupCertAuth(t *testing.T, conf config.MTLS) *mockDB.MockRepository {
+	ctrl := gomock.NewController(t)
+	repo := mockDB.NewMockRepository(ctrl)
+	oldServer, oldCertAuth := gaultServer, certAuth
+	gaultServer = &GaultService{rep: repo}
+	setMTLS(conf)
+	setAllowEndpoints(nil)
+	t.Cleanup(func() { gaultServer, certAuth = oldServer, oldCertAuth })
+	return repo
+}
+
+func TestClientAuthType(t *testing.T) {
+	for mode, want := range map[string]tls.ClientAuthType{
+		"":         tls.NoClientCert,
+		"off":      tls.NoClientCert,
+		"optional": tls.VerifyClientCertIfGiven,
+		"required": tls.RequireAndVerifyClientCert,
+	} {
+		got, err := clientAuthType(mode)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, mode)
+	}
+
+	_, err := clientAuthType("always")
+	assert.Error(t, err)
+}
+
+func TestSetMTLS(t *testing.T) {
+	defer func() { certAuth = false }()
+
+	setMTLS(config.MTLS{Mode: "off", CertAuth: true})
+	assert.False(t, certAuth)
+
+	setMTLS(config.MTLS{Mode: "required", CertAuth: true})
+	assert.True(t, certAuth)
+}
+
+func TestAuthInterceptor_CertAuthWithoutToken(t *testing.T) {
+	repo := setupCertAuth(t, config.MTLS{Mode: "required", CertAuth: true})
+	repo.EXPECT().GetUserUID(gomock.Any(), "alice").Return("alice-uid", nil)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.AuditV1Service/ListAuditEvents"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return userUIDFromContext(ctx)
+	}
+
+	resp, err := AuthInterceptor(certContext("alice"), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice-uid", resp)
+}
+
+func TestAuthInterceptor_CertWithoutCertAuthNeedsToken(t *testing.T) {
+	repo := setupCertAuth(t, config.MTLS{Mode: "required"})
+	repo.EXPECT().GetUserUID(gomock.Any(), "alice").Return("alice-uid", nil)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.AuditV1Service/ListAuditEvents"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "success", nil
+	}
+
+	_, err := AuthInterceptor(certContext("alice"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthInterceptor_CertMustMatchTokenUser(t *testing.T) {
+	repo := setupCertAuth(t, config.MTLS{Mode: "optional"})
+	repo.EXPECT().GetUserUID(gomock.Any(), "alice").Return("alice-uid", nil).Times(2)
+	repo.EXPECT().CheckSessionUser(gomock.Any(), gomock.Any(), "token").Return(true).Times(2)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.AuditV1Service/ListAuditEvents"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "success", nil
+	}
+
+	ctx := metadata.NewIncomingContext(certContext("alice"), metadata.Pairs("authorization", "token", "useruid", "bob-uid"))
+	_, err := AuthInterceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = metadata.NewIncomingContext(certContext("alice"), metadata.Pairs("authorization", "token", "useruid", "alice-uid"))
+	resp, err := AuthInterceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "success", resp)
+}
+
+func TestAuthInterceptor_UnknownCertSubject(t *testing.T) {
+	repo := setupCertAuth(t, config.MTLS{Mode: "required", CertAuth: true})
+	repo.EXPECT().GetUserUID(gomock.Any(), "mallory").Return("", db.ErrNotFound)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.AuditV1Service/ListAuditEvents"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "success", nil
+	}
+
+	_, err := AuthInterceptor(certContext("mallory"), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// CreateOrganization метод создания организации GaultService
+func (g *GaultService) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.CreateOrganizationResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" || len(req.GetWrappedKey()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name and wrapped key are required")
+	}
+
+	orgID, err := g.rep.CreateOrganization(ctx, userUID, req.GetName(), req.GetWrappedKey())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateOrganizationResponse{Id: orgID}, nil
+}
+
+// ListOrganizations метод получения организаций пользователя GaultService
+func (g *GaultService) ListOrganizations(ctx context.Context, _ *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs, err := g.rep.ListOrganizations(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// InviteMember метод приглашения пользователя в организацию GaultService
+func (g *GaultService) InviteMember(ctx context.Context, req *pb.InviteMemberRequest) (*pb.InviteMemberResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.GetInviteeUid() == userUID:
+		return nil, status.Error(codes.InvalidArgument, "can not invite yourself")
+	case !validRole(req.GetRole()):
+		return nil, status.Error(codes.InvalidArgument, "unknown organization role")
+	case len(req.GetWrappedKey()) == 0:
+		return nil, status.Error(codes.InvalidArgument, "wrapped key is required")
+	}
+
+	role, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetRole() == pb.OrgRole_ORG_ROLE_OWNER && role != pb.OrgRole_ORG_ROLE_OWNER {
+		return nil, status.Error(codes.PermissionDenied, "only owner can invite owners")
+	}
+
+	id, err := g.rep.InviteMember(ctx, req.GetOrgId(), userUID, req.GetInviteeUid(), req.GetRole(), req.GetWrappedKey())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.InviteMemberResponse{Id: id}, nil
+}
+
+// ListInvitations метод получения приглашений пользователя GaultService
+func (g *GaultService) ListInvitations(ctx context.Context, _ *pb.ListInvitationsRequest) (*pb.ListInvitationsResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := g.rep.ListInvitations(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation метод принятия приглашения GaultService
+func (g *GaultService) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.AcceptInvitationResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.AcceptInvitation(ctx, userUID, req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.AcceptInvitationResponse{}, nil
+}
+
+// DeclineInvitation метод отклонения приглашения GaultService
+func (g *GaultService) DeclineInvitation(ctx context.Context, req *pb.DeclineInvitationRequest) (*pb.DeclineInvitationResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.DeclineInvitation(ctx, userUID, req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.DeclineInvitationResponse{}, nil
+}
+
+// ListMembers метод получения участников организации GaultService
+func (g *GaultService) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_READ_ONLY); err != nil {
+		return nil, err
+	}
+
+	members, err := g.rep.ListMembers(ctx, req.GetOrgId())
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SetMemberRole метод изменения роли участника GaultService
+func (g *GaultService) SetMemberRole(ctx context.Context, req *pb.SetMemberRoleRequest) (*pb.SetMemberRoleResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !validRole(req.GetRole()) {
+		return nil, status.Error(codes.InvalidArgument, "unknown organization role")
+	}
+
+	role, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_ADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkManageMember(ctx, role, req.GetOrgId(), req.GetUserUid(), req.GetRole()); err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.SetMemberRole(ctx, req.GetOrgId(), req.GetUserUid(), req.GetRole()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.SetMemberRoleResponse{}, nil
+}
+
+// RemoveMember метод исключения участника или выхода из организации GaultService
+func (g *GaultService) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выйти из организации может любой участник, исключать других — администратор
+	minRole := pb.OrgRole_ORG_ROLE_ADMIN
+	if req.GetUserUid() == userUID {
+		minRole = pb.OrgRole_ORG_ROLE_READ_ONLY
+	}
+	role, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), minRole)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkManageMember(ctx, role, req.GetOrgId(), req.GetUserUid(), pb.OrgRole_ORG_ROLE_UNSPECIFIED); err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.RemoveMember(ctx, req.GetOrgId(), req.GetUserUid()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.RemoveMemberResponse{}, nil
+}
+
+// CreateCollection метод создания коллекции GaultService
+func (g *GaultService) CreateCollection(ctx context.Context, req *pb.CreateCollectionRequest) (*pb.CreateCollectionResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "collection name is required")
+	}
+	if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_ADMIN); err != nil {
+		return nil, err
+	}
+
+	id, err := g.rep.CreateCollection(ctx, req.GetOrgId(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateCollectionResponse{Id: id}, nil
+}
+
+// ListCollections метод получения коллекций организации GaultService
+func (g *GaultService) ListCollections(ctx context.Context, req *pb.ListCollectionsRequest) (*pb.ListCollectionsResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_READ_ONLY); err != nil {
+		return nil, err
+	}
+
+	collections, err := g.rep.ListCollections(ctx, req.GetOrgId())
+	if err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// DeleteCollection метод удаления коллекции вместе с данными GaultService
+func (g *GaultService) DeleteCollection(ctx context.Context, req *pb.DeleteCollectionRequest) (*pb.DeleteCollectionResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_ADMIN); err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.DeleteCollection(ctx, req.GetOrgId(), req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.DeleteCollectionResponse{}, nil
+}
+
+// requireOrgRole проверка, что роль пользователя в организации не ниже требуемой
+func (g *GaultService) requireOrgRole(ctx context.Context, userUID, orgID string, minRole pb.OrgRole) (pb.OrgRole, error) {
+	role, err := g.rep.GetOrgRole(ctx, userUID, orgID)
+	if errors.Is(err, db.ErrNotFound) {
+		return role, status.Error(codes.PermissionDenied, "not a member of organization")
+	}
+	if err != nil {
+		return role, err
+	}
+	if role < minRole {
+		return role, status.Error(codes.PermissionDenied, "insufficient organization role")
+	}
+	return role, nil
+}
+
+// checkManageMember проверка прав на изменение роли или исключение участника:
+// владельцев назначает и исключает только владелец, последний владелец остаётся в организации
+func (g *GaultService) checkManageMember(ctx context.Context, role pb.OrgRole, orgID, memberUID string, newRole pb.OrgRole) error {
+	memberRole, err := g.rep.GetOrgRole(ctx, memberUID, orgID)
+	if err != nil {
+		return shareError(err)
+	}
+	if memberRole != pb.OrgRole_ORG_ROLE_OWNER {
+		if newRole == pb.OrgRole_ORG_ROLE_OWNER && role != pb.OrgRole_ORG_ROLE_OWNER {
+			return status.Error(codes.PermissionDenied, "only owner can assign owners")
+		}
+		return nil
+	}
+	if role != pb.OrgRole_ORG_ROLE_OWNER {
+		return status.Error(codes.PermissionDenied, "only owner can manage owners")
+	}
+	if newRole == pb.OrgRole_ORG_ROLE_OWNER {
+		return nil
+	}
+
+	owners, err := g.rep.CountOrgOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return status.Error(codes.FailedPrecondition, "organization must have at least one owner")
+	}
+	return nil
+}
+
+// saveToCollection перенос новых данных в коллекцию, если роль пользователя позволяет изменять данные
+func (g *GaultService) saveToCollection(ctx context.Context, tx *sql.Tx, userUID, dataID, collectionID string) error {
+	role, err := g.rep.GetCollectionRole(ctx, userUID, collectionID)
+	if errors.Is(err, db.ErrNotFound) {
+		return status.Error(codes.PermissionDenied, "not a member of organization")
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "get collection role failed: %v", err)
+	}
+	if role < pb.OrgRole_ORG_ROLE_MEMBER {
+		return status.Error(codes.PermissionDenied, "read only access")
+	}
+
+	if err := g.rep.SetDataCollectionTx(ctx, tx, dataID, collectionID); err != nil {
+		return status.Errorf(codes.Internal, "set data collection failed: %v", err)
+	}
+	return nil
+}
+
+// validRole роль участника организации задана
+func validRole(role pb.OrgRole) bool {
+	return role >= pb.OrgRole_ORG_ROLE_READ_ONLY && role <= pb.OrgRole_ORG_ROLE_OWNER
+}
+
+// canDelete пользователь владеет данными или может удалять данные командного хранилища
+func canDelete(access *pb.GetDataResponse) bool {
+	return isOwner(access) || access.GetOrgRole() >= pb.OrgRole_ORG_ROLE_MEMBER
+}
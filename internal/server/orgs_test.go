@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_CreateOrganization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().CreateOrganization(ctx, "user-uid", "team", []byte("wrapped")).Return("org-id", nil)
+
+		resp, err := service.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "team", WrappedKey: []byte("wrapped")})
+		assert.NoError(t, err)
+		assert.Equal(t, "org-id", resp.Id)
+	})
+	t.Run("without key", func(t *testing.T) {
+		resp, err := service.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "team"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_InviteMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_ADMIN, nil)
+		repo.EXPECT().InviteMember(ctx, "org-id", "user-uid", "friend-uid", pb.OrgRole_ORG_ROLE_MEMBER, []byte("wrapped")).Return("invitation-id", nil)
+
+		resp, err := service.InviteMember(ctx, &pb.InviteMemberRequest{
+			OrgId:      "org-id",
+			InviteeUid: "friend-uid",
+			Role:       pb.OrgRole_ORG_ROLE_MEMBER,
+			WrappedKey: []byte("wrapped"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "invitation-id", resp.Id)
+	})
+	t.Run("member can not invite", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_MEMBER, nil)
+
+		resp, err := service.InviteMember(ctx, &pb.InviteMemberRequest{
+			OrgId:      "org-id",
+			InviteeUid: "friend-uid",
+			Role:       pb.OrgRole_ORG_ROLE_READ_ONLY,
+			WrappedKey: []byte("wrapped"),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("admin can not invite owner", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_ADMIN, nil)
+
+		resp, err := service.InviteMember(ctx, &pb.InviteMemberRequest{
+			OrgId:      "org-id",
+			InviteeUid: "friend-uid",
+			Role:       pb.OrgRole_ORG_ROLE_OWNER,
+			WrappedKey: []byte("wrapped"),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("not member", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_UNSPECIFIED, db.ErrNotFound)
+
+		resp, err := service.InviteMember(ctx, &pb.InviteMemberRequest{
+			OrgId:      "org-id",
+			InviteeUid: "friend-uid",
+			Role:       pb.OrgRole_ORG_ROLE_MEMBER,
+			WrappedKey: []byte("wrapped"),
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_AcceptInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().AcceptInvitation(ctx, "user-uid", "invitation-id").Return(db.ErrNotFound)
+
+	resp, err := service.AcceptInvitation(ctx, &pb.AcceptInvitationRequest{Id: "invitation-id"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Nil(t, resp)
+}
+
+func TestGaultService_SetMemberRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_ADMIN, nil)
+		repo.EXPECT().GetOrgRole(ctx, "friend-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_MEMBER, nil)
+		repo.EXPECT().SetMemberRole(ctx, "org-id", "friend-uid", pb.OrgRole_ORG_ROLE_READ_ONLY).Return(nil)
+
+		resp, err := service.SetMemberRole(ctx, &pb.SetMemberRoleRequest{
+			OrgId: "org-id", UserUid: "friend-uid", Role: pb.OrgRole_ORG_ROLE_READ_ONLY,
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("admin can not demote owner", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_ADMIN, nil)
+		repo.EXPECT().GetOrgRole(ctx, "friend-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_OWNER, nil)
+
+		resp, err := service.SetMemberRole(ctx, &pb.SetMemberRoleRequest{
+			OrgId: "org-id", UserUid: "friend-uid", Role: pb.OrgRole_ORG_ROLE_MEMBER,
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("last owner", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_OWNER, nil).Times(2)
+		repo.EXPECT().CountOrgOwners(ctx, "org-id").Return(int64(1), nil)
+
+		resp, err := service.SetMemberRole(ctx, &pb.SetMemberRoleRequest{
+			OrgId: "org-id", UserUid: "user-uid", Role: pb.OrgRole_ORG_ROLE_ADMIN,
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_RemoveMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("leave organization", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_READ_ONLY, nil).Times(2)
+		repo.EXPECT().RemoveMember(ctx, "org-id", "user-uid").Return(nil)
+
+		resp, err := service.RemoveMember(ctx, &pb.RemoveMemberRequest{OrgId: "org-id", UserUid: "user-uid"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("member can not remove others", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_MEMBER, nil)
+
+		resp, err := service.RemoveMember(ctx, &pb.RemoveMemberRequest{OrgId: "org-id", UserUid: "friend-uid"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_OrgDataAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	repo.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("list org data without membership", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_UNSPECIFIED, db.ErrNotFound)
+
+		resp, err := service.GetUserDataList(ctx, &pb.GetUserDataListRequest{OrgId: "org-id"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("list org data", func(t *testing.T) {
+		req := &pb.GetUserDataListRequest{OrgId: "org-id"}
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_READ_ONLY, nil)
+		repo.EXPECT().GetDataNameList(ctx, "user-uid", req).Return(&pb.GetUserDataListResponse{}, nil)
+
+		resp, err := service.GetUserDataList(ctx, req)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("member deletes org data", func(t *testing.T) {
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+			OrgRole:    pb.OrgRole_ORG_ROLE_MEMBER,
+		}, nil)
+		repo.EXPECT().DeleteData(ctx, "data-id").Return(nil)
+
+		resp, err := service.DeleteData(ctx, &pb.DeleteDataRequest{Id: "data-id"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("read only member can not delete", func(t *testing.T) {
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ,
+			OrgRole:    pb.OrgRole_ORG_ROLE_READ_ONLY,
+		}, nil)
+
+		resp, err := service.DeleteData(ctx, &pb.DeleteDataRequest{Id: "data-id"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("read only member saves to collection", func(t *testing.T) {
+		stream := &mockSaveDataServer{
+			ctx:  userContext("user-uid"),
+			reqs: []*pb.SaveDataRequest{{UserUid: "user-uid", Type: "text", Name: "note", CollectionId: "collection-id"}},
+		}
+		mockTx := &sql.Tx{}
+		repo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		repo.EXPECT().CreateEmptyLO(gomock.Any(), mockTx).Return(123, nil)
+		repo.EXPECT().InsertUserDataRecordTx(gomock.Any(), mockTx, gomock.Any(), "user-uid", "text", "note", 123).Return(nil)
+		repo.EXPECT().GetCollectionRole(gomock.Any(), "user-uid", "collection-id").Return(pb.OrgRole_ORG_ROLE_READ_ONLY, nil)
+
+		err := service.SaveData(stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"unicode/utf8"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/passgen"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// passwordPolicy требования к паролю учётной записи
+var passwordPolicy config.PasswordPolicy
+
+func setPasswordPolicy(policy config.PasswordPolicy) {
+	passwordPolicy = policy
+}
+
+// checkPasswordPolicy проверка нового пароля учётной записи на длину и оценку надёжности
+func checkPasswordPolicy(password string) error {
+	if utf8.RuneCountInString(password) < passwordPolicy.MinLength {
+		return status.Errorf(codes.InvalidArgument, "password must be at least %d characters", passwordPolicy.MinLength)
+	}
+	strength := passgen.Estimate(password)
+	if strength.Score < passwordPolicy.MinScore {
+		return status.Errorf(codes.InvalidArgument, "password is too weak (%s, %.0f bits)", strength.Label, strength.Entropy)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/passgen"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	setPasswordPolicy(config.PasswordPolicy{MinLength: 10, MinScore: passgen.ScoreStrong})
+	defer setPasswordPolicy(config.PasswordPolicy{})
+
+	err := checkPasswordPolicy("Sh0rt!")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "at least 10 characters")
+
+	err = checkPasswordPolicy("kittenpaws")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "too weak")
+
+	assert.NoError(t, checkPasswordPolicy("correct-horse-battery-staple"))
+}
+
+func TestCheckPasswordPolicy_Disabled(t *testing.T) {
+	setPasswordPolicy(config.PasswordPolicy{})
+	assert.NoError(t, checkPasswordPolicy(""))
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+const (
+	// userFreeFailures число неудачных входов в аккаунт с одного адреса до начала блокировок
+	userFreeFailures = 3
+	// addrFreeFailures число неудачных попыток с одного адреса до начала блокировок
+	addrFreeFailures = 10
+	// lockoutBase длительность первой блокировки, каждая следующая в два раза дольше
+	lockoutBase = time.Second
+	// lockoutMax максимальная длительность блокировки
+	lockoutMax = 15 * time.Minute
+	// failuresTTL время без неудачных попыток, после которого счётчик сбрасывается
+	failuresTTL = 15 * time.Minute
+)
+
+var (
+	// userLimiter ограничитель попыток входа по паре адрес клиента и логин. Блокировка только по логину
+	// позволила бы любому заблокировать вход чужому пользователю
+	userLimiter = newAttemptLimiter(userFreeFailures)
+	// addrLimiter ограничитель попыток входа и регистрации по адресу клиента
+	addrLimiter = newAttemptLimiter(addrFreeFailures)
+)
+
+// attempt состояние неудачных попыток по ключу
+type attempt struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// attemptLimiter ограничитель неудачных попыток с экспоненциальной задержкой и временной блокировкой
+type attemptLimiter struct {
+	mu           sync.Mutex
+	attempts     map[string]*attempt
+	freeFailures int
+	lastCleanup  time.Time
+	now          func() time.Time
+}
+
+// newAttemptLimiter создание ограничителя, первые freeFailures неудачных попыток не блокируются
+func newAttemptLimiter(freeFailures int) *attemptLimiter {
+	return &attemptLimiter{
+		attempts:     make(map[string]*attempt),
+		freeFailures: freeFailures,
+		now:          time.Now,
+	}
+}
+
+// retryAfter время до снятия блокировки ключа, 0 если попытка разрешена
+func (l *attemptLimiter) retryAfter(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[key]
+	if !ok {
+		return 0
+	}
+	if wait := a.lockedUntil.Sub(l.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// fail учёт неудачной попытки, после freeFailures попыток ключ блокируется на lockoutBase * 2^n
+func (l *attemptLimiter) fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	a, ok := l.attempts[key]
+	if !ok || now.Sub(a.lastFailure) > failuresTTL {
+		a = &attempt{}
+		l.attempts[key] = a
+	}
+	a.failures++
+	a.lastFailure = now
+	if a.failures > l.freeFailures {
+		a.lockedUntil = now.Add(lockoutDuration(a.failures - l.freeFailures - 1))
+	}
+}
+
+// reset сброс счётчика после успешной попытки
+func (l *attemptLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+}
+
+// cleanup удаление устаревших записей не чаще раза в failuresTTL
+func (l *attemptLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < failuresTTL {
+		return
+	}
+	l.lastCleanup = now
+	for key, a := range l.attempts {
+		if now.Sub(a.lastFailure) > failuresTTL && now.After(a.lockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
+
+// lockoutDuration длительность n-й блокировки, не больше lockoutMax
+func lockoutDuration(n int) time.Duration {
+	d := lockoutBase
+	for i := 0; i < n && d < lockoutMax; i++ {
+		d *= 2
+	}
+	return min(d, lockoutMax)
+}
+
+// RateLimitInterceptor ограничивает частоту неудачных попыток входа, второго шага авторизации и регистрации
+// по адресу клиента и логину
+func RateLimitInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	var (
+		userKey string
+		// countSuccess успешные запросы тоже учитываются, чтобы с адреса нельзя было массово создавать аккаунты
+		countSuccess bool
+	)
+	addrKey := clientHost(ctx)
+	switch r := req.(type) {
+	case *pb.LoginRequest:
+		userKey = loginKey(addrKey, r.GetLogin())
+	case *pb.VerifyTwoFactorRequest:
+	case *pb.RegistrationRequest:
+		countSuccess = true
+	default:
+		return handler(ctx, req)
+	}
+
+	wait := addrLimiter.retryAfter(addrKey)
+	if userKey != "" {
+		wait = max(wait, userLimiter.retryAfter(userKey))
+	}
+	if wait > 0 {
+		seconds := int(math.Ceil(wait.Seconds()))
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+		logger.LogWarnCtx(ctx, "rate limited", zap.String("addr", addrKey), zap.Int("retry_after", seconds))
+		return nil, status.Errorf(codes.ResourceExhausted, "too many attempts, retry in %d seconds", seconds)
+	}
+
+	resp, err := handler(ctx, req)
+	switch status.Code(err) {
+	case codes.OK:
+		switch {
+		case countSuccess:
+			addrLimiter.fail(addrKey)
+		case isTwoFactorRequired(resp):
+			// Верный пароль без второго фактора не сбрасывает счётчики
+		default:
+			// Счётчик адреса не сбрасывается: иначе успешный вход в свой аккаунт снимал бы
+			// ограничение на перебор чужих логинов с того же адреса
+			if userKey != "" {
+				userLimiter.reset(userKey)
+			}
+		}
+	case codes.Unauthenticated, codes.AlreadyExists, codes.InvalidArgument:
+		addrLimiter.fail(addrKey)
+		if userKey != "" {
+			userLimiter.fail(userKey)
+		}
+	}
+	return resp, err
+}
+
+// loginKey ключ ограничителя входа для логина с адреса клиента
+func loginKey(addr, login string) string {
+	return addr + "|" + login
+}
+
+// isTwoFactorRequired вход по паролю ждёт второго шага авторизации
+func isTwoFactorRequired(resp interface{}) bool {
+	r, ok := resp.(*pb.LoginResponse)
+	return ok && r.GetTwoFactorRequired()
+}
+
+// clientHost адрес клиента без порта
+func clientHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
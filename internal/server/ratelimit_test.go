@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// fakeClock управляемые часы для ограничителей
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// setupTestLimiters подмена ограничителей на время теста
+func setupTestLimiters(t *testing.T) *fakeClock {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	oldUser, oldAddr := userLimiter, addrLimiter
+	userLimiter, addrLimiter = newAttemptLimiter(userFreeFailures), newAttemptLimiter(addrFreeFailures)
+	userLimiter.now, addrLimiter.now = clock.Now, clock.Now
+	t.Cleanup(func() { userLimiter, addrLimiter = oldUser, oldAddr })
+	return clock
+}
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}})
+}
+
+func TestLockoutDuration(t *testing.T) {
+	assert.Equal(t, time.Second, lockoutDuration(0))
+	assert.Equal(t, 2*time.Second, lockoutDuration(1))
+	assert.Equal(t, 8*time.Second, lockoutDuration(3))
+	assert.Equal(t, lockoutMax, lockoutDuration(20))
+	assert.Equal(t, lockoutMax, lockoutDuration(1000))
+}
+
+func TestAttemptLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := newAttemptLimiter(2)
+	l.now = clock.Now
+
+	l.fail("key")
+	l.fail("key")
+	assert.Zero(t, l.retryAfter("key"))
+
+	l.fail("key")
+	assert.Equal(t, time.Second, l.retryAfter("key"))
+	l.fail("key")
+	assert.Equal(t, 2*time.Second, l.retryAfter("key"))
+
+	clock.now = clock.now.Add(2 * time.Second)
+	assert.Zero(t, l.retryAfter("key"))
+
+	l.reset("key")
+	l.fail("key")
+	assert.Zero(t, l.retryAfter("key"))
+
+	// Счётчик сбрасывается после failuresTTL без неудачных попыток
+	l.fail("key")
+	clock.now = clock.now.Add(failuresTTL + time.Second)
+	l.fail("key")
+	assert.Zero(t, l.retryAfter("key"))
+	assert.Len(t, l.attempts, 1)
+}
+
+func TestRateLimitInterceptor_LoginBurst(t *testing.T) {
+	clock := setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Login_FullMethodName}
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	ctx := peerContext("10.0.0.1")
+	req := &pb.LoginRequest{Login: "alice", Password: "guess"}
+
+	// Атака перебором: после userFreeFailures попыток запросы отклоняются без проверки пароля
+	for i := 0; i < 20; i++ {
+		_, _ = RateLimitInterceptor(ctx, req, info, handler)
+	}
+	assert.Equal(t, userFreeFailures+1, calls)
+
+	_, err := RateLimitInterceptor(ctx, req, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Каждая следующая блокировка дольше предыдущей
+	clock.now = clock.now.Add(time.Second)
+	_, err = RateLimitInterceptor(ctx, req, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, 2*time.Second, userLimiter.retryAfter(loginKey("10.0.0.1", "alice")))
+
+	// Перебор с одного адреса не блокирует вход в аккаунт с других адресов
+	_, err = RateLimitInterceptor(peerContext("10.0.0.2"), req, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRateLimitInterceptor_PasswordSpraying(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Login_FullMethodName}
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	ctx := peerContext("10.0.0.1")
+
+	// Перебор разных логинов с одного адреса ограничивается по адресу
+	for i := 0; i < 30; i++ {
+		_, _ = RateLimitInterceptor(ctx, &pb.LoginRequest{Login: string(rune('a' + i))}, info, handler)
+	}
+	assert.Equal(t, addrFreeFailures+1, calls)
+
+	// Другой адрес не заблокирован
+	_, err := RateLimitInterceptor(peerContext("10.0.0.2"), &pb.LoginRequest{Login: "bob"}, info, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRateLimitInterceptor_SuccessResets(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Login_FullMethodName}
+	ctx := peerContext("10.0.0.1")
+	req := &pb.LoginRequest{Login: "alice"}
+	failHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	okHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.LoginResponse{}, nil
+	}
+
+	for i := 0; i < userFreeFailures; i++ {
+		_, _ = RateLimitInterceptor(ctx, req, info, failHandler)
+	}
+	_, err := RateLimitInterceptor(ctx, req, info, okHandler)
+	assert.NoError(t, err)
+
+	_, _ = RateLimitInterceptor(ctx, req, info, failHandler)
+	assert.Zero(t, userLimiter.retryAfter(loginKey("10.0.0.1", "alice")))
+}
+
+func TestRateLimitInterceptor_SuccessKeepsAddrFailures(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Login_FullMethodName}
+	ctx := peerContext("10.0.0.1")
+	failHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	okHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.LoginResponse{}, nil
+	}
+
+	// Успешный вход в свой аккаунт не снимает ограничение на перебор других логинов с адреса
+	for i := 0; i < addrFreeFailures; i++ {
+		_, _ = RateLimitInterceptor(ctx, &pb.LoginRequest{Login: string(rune('a' + i))}, info, failHandler)
+	}
+	_, err := RateLimitInterceptor(ctx, &pb.LoginRequest{Login: "mallory"}, info, okHandler)
+	assert.NoError(t, err)
+
+	_, _ = RateLimitInterceptor(ctx, &pb.LoginRequest{Login: "bob"}, info, failHandler)
+	assert.Equal(t, time.Second, addrLimiter.retryAfter("10.0.0.1"))
+}
+
+func TestRateLimitInterceptor_Registration(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Registration_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.RegistrationResponse{}, nil
+	}
+	ctx := peerContext("10.0.0.1")
+
+	// Успешные регистрации тоже учитываются, чтобы ограничить массовое создание аккаунтов
+	for i := 0; i < addrFreeFailures; i++ {
+		_, err := RateLimitInterceptor(ctx, &pb.RegistrationRequest{}, info, handler)
+		assert.NoError(t, err)
+	}
+	_, err := RateLimitInterceptor(ctx, &pb.RegistrationRequest{}, info, handler)
+	assert.NoError(t, err)
+	_, err = RateLimitInterceptor(ctx, &pb.RegistrationRequest{}, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestRateLimitInterceptor_OtherMethods(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.ContentManagerV1Service_GetData_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "user is not authorized")
+	}
+
+	for i := 0; i < 30; i++ {
+		_, err := RateLimitInterceptor(peerContext("10.0.0.1"), &pb.GetDataRequest{}, info, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
+
+func TestRateLimitInterceptor_TwoFactorRequiredDoesNotReset(t *testing.T) {
+	setupTestLimiters(t)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthV1Service_Login_FullMethodName}
+	ctx := peerContext("10.0.0.1")
+	req := &pb.LoginRequest{Login: "alice"}
+	failHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	challengeHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil
+	}
+
+	for i := 0; i < userFreeFailures; i++ {
+		_, _ = RateLimitInterceptor(ctx, req, info, failHandler)
+	}
+	_, err := RateLimitInterceptor(ctx, req, info, challengeHandler)
+	assert.NoError(t, err)
+
+	_, _ = RateLimitInterceptor(ctx, req, info, failHandler)
+	assert.Equal(t, time.Second, userLimiter.retryAfter(loginKey("10.0.0.1", "alice")))
+}
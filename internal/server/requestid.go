@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/fngoc/gault/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader заголовок с идентификатором запроса во входящих и исходящих метаданных
+const requestIDHeader = "x-request-id"
+
+// validRequestID допустимый идентификатор от клиента, иначе сервер выдаёт новый
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIDKey ключ идентификатора запроса в контексте
+type requestIDKey struct{}
+
+// RequestIDInterceptor присваивает запросу идентификатор, возвращает его клиенту
+// в заголовке x-request-id и добавляет ко всем записям лога обработчика
+func RequestIDInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx = withRequestID(ctx, info.FullMethod)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, RequestIDFromContext(ctx)))
+	return handler(ctx, req)
+}
+
+// RequestIDStreamInterceptor присваивает идентификатор потоковым запросам
+func RequestIDStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx := withRequestID(ss.Context(), info.FullMethod)
+	_ = ss.SetHeader(metadata.Pairs(requestIDHeader, RequestIDFromContext(ctx)))
+	return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// RequestIDFromContext идентификатор текущего запроса, пусто вне обработчика
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID контекст с идентификатором из метаданных клиента или новым
+func withRequestID(ctx context.Context, fullMethod string) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 && validRequestID.MatchString(values[0]) {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logger.WithFields(ctx, zap.String("request_id", id), zap.String("method", fullMethod))
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeTransportStream запоминает заголовки ответа унарного вызова
+type fakeTransportStream struct {
+	header metadata.MD
+}
+
+func (s *fakeTransportStream) Method() string { return "" }
+
+func (s *fakeTransportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *fakeTransportStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *fakeTransportStream) SetTrailer(metadata.MD) error { return nil }
+
+// fakeHeaderStream потоковый вызов, запоминающий заголовки ответа
+type fakeHeaderStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeHeaderStream) Context() context.Context { return s.ctx }
+
+func (s *fakeHeaderStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRequestIDInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/api.proto.v1.ContentManagerV1Service/GetData"}
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "from client", incoming: "client-req-42", keep: true},
+		{name: "missing"},
+		{name: "invalid", incoming: "bad id\nwith newline"},
+		{name: "too long", incoming: strings.Repeat("a", 65)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &fakeTransportStream{}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), transport)
+			if tt.incoming != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(requestIDHeader, tt.incoming))
+			}
+
+			var got string
+			_, err := RequestIDInterceptor(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+				got = RequestIDFromContext(ctx)
+				return nil, nil
+			})
+			require.NoError(t, err)
+
+			if tt.keep {
+				assert.Equal(t, tt.incoming, got)
+			} else {
+				_, err := uuid.Parse(got)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []string{got}, transport.header.Get(requestIDHeader))
+		})
+	}
+}
+
+func TestRequestIDStreamInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDHeader, "stream-req-1"))
+	stream := &fakeHeaderStream{ctx: ctx}
+	info := &grpc.StreamServerInfo{FullMethod: "/api.proto.v1.ContentManagerV1Service/SaveData"}
+
+	err := RequestIDStreamInterceptor(nil, stream, info, func(_ interface{}, ss grpc.ServerStream) error {
+		assert.Equal(t, "stream-req-1", RequestIDFromContext(ss.Context()))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stream-req-1"}, stream.header.Get(requestIDHeader))
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+const (
+	// maxRotationDays максимальный период смены данных, 10 лет
+	maxRotationDays = 3650
+	// defaultRotationInterval интервал проверки сроков смены данных по умолчанию
+	defaultRotationInterval = time.Hour
+)
+
+// SetDataRotation метод установки срока действия и периода смены данных GaultService
+func (g *GaultService) SetDataRotation(ctx context.Context, req *pb.SetDataRotationRequest) (*pb.SetDataRotationResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetExpiresAt() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "expires_at must not be negative")
+	}
+	if req.GetRotationDays() > maxRotationDays {
+		return nil, status.Errorf(codes.InvalidArgument, "rotation_days must not exceed %d", maxRotationDays)
+	}
+
+	if err := g.rep.SetDataRotation(ctx, userUID, req.GetId(), req.GetExpiresAt(), req.GetRotationDays()); err != nil {
+		return nil, folderError(err)
+	}
+	return &pb.SetDataRotationResponse{}, nil
+}
+
+// GetVaultHealth метод получения сведений о состоянии личного хранилища GaultService
+func (g *GaultService) GetVaultHealth(ctx context.Context, _ *pb.GetVaultHealthRequest) (*pb.GetVaultHealthResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.rep.GetVaultHealth(ctx, userUID)
+}
+
+// runRotationScheduler пересчёт отметок о смене данных при запуске и далее каждые interval до отмены ctx
+func runRotationScheduler(ctx context.Context, rep db.Repository, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRotationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		flagRotationDue(ctx, rep, "")
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// flagRotationDue пересчёт отметок о смене данных, пустой dataID — для всех данных. Ошибка только логируется
+func flagRotationDue(ctx context.Context, rep db.Repository, dataID string) {
+	affected, err := rep.RefreshRotationDue(ctx, dataID)
+	if err != nil {
+		logger.LogError("rotation check failed", zap.Error(err))
+		return
+	}
+	if affected > 0 {
+		logger.LogInfo("rotation check", zap.Int64("changed", affected))
+	}
+}
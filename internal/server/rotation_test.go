@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_SetDataRotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().SetDataRotation(ctx, "user-uid", "data-id", int64(1800000000), uint32(90)).Return(nil)
+
+		resp, err := service.SetDataRotation(ctx, &pb.SetDataRotationRequest{Id: "data-id", ExpiresAt: 1800000000, RotationDays: 90})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("too long period", func(t *testing.T) {
+		resp, err := service.SetDataRotation(ctx, &pb.SetDataRotationRequest{Id: "data-id", RotationDays: maxRotationDays + 1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("negative expiry", func(t *testing.T) {
+		resp, err := service.SetDataRotation(ctx, &pb.SetDataRotationRequest{Id: "data-id", ExpiresAt: -1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("not owner", func(t *testing.T) {
+		repo.EXPECT().SetDataRotation(ctx, "user-uid", "data-id", int64(0), uint32(30)).Return(db.ErrNotFound)
+
+		resp, err := service.SetDataRotation(ctx, &pb.SetDataRotationRequest{Id: "data-id", RotationDays: 30})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("md error", func(t *testing.T) {
+		resp, err := service.SetDataRotation(context.Background(), &pb.SetDataRotationRequest{Id: "data-id"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_GetVaultHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	expected := &pb.GetVaultHealthResponse{Items: []*pb.HealthItem{{Id: "data-id", Type: "password", RotationDue: true}}}
+	repo.EXPECT().GetVaultHealth(ctx, "user-uid").Return(expected, nil)
+
+	resp, err := service.GetVaultHealth(ctx, &pb.GetVaultHealthRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, resp)
+
+	_, err = service.GetVaultHealth(context.Background(), &pb.GetVaultHealthRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestRunRotationScheduler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := make(chan struct{}, 10)
+	repo.EXPECT().RefreshRotationDue(gomock.Any(), "").DoAndReturn(func(context.Context, string) (int64, error) {
+		calls <- struct{}{}
+		return 0, errors.New("db is down")
+	}).MinTimes(2)
+
+	done := make(chan struct{})
+	go func() {
+		runRotationScheduler(ctx, repo, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Проверка при запуске и по таймеру, ошибка не останавливает планировщик
+	<-calls
+	<-calls
+	cancel()
+	<-done
+}
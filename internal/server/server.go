@@ -2,26 +2,34 @@ package server
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/fngoc/gault/internal/config"
 	"github.com/fngoc/gault/internal/db"
 	"github.com/fngoc/gault/pkg/logger"
 	"github.com/fngoc/gault/pkg/utils"
 
-	"google.golang.org/grpc/credentials"
-
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
 
 	"google.golang.org/grpc/metadata"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
@@ -31,76 +39,144 @@ import (
 type GaultService struct {
 	pb.UnimplementedAuthV1ServiceServer
 	pb.UnimplementedContentManagerV1ServiceServer
+	pb.UnimplementedFolderV1ServiceServer
+	pb.UnimplementedShareV1ServiceServer
+	pb.UnimplementedOrgV1ServiceServer
+	pb.UnimplementedAuditV1ServiceServer
 	rep db.Repository
 }
 
 // Login метод авторизации GaultService
 func (g *GaultService) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	isCreate, err := g.rep.IsUserCreated(ctx, req.GetLogin())
-	if err != nil {
-		return nil, err
+	userUID, token, err := g.rep.UpdateSessionUser(ctx, req.GetLogin(), req.GetPassword())
+	if errors.Is(err, db.ErrInvalidCredentials) {
+		g.audit(ctx, "", &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_LOGIN_FAILURE, Login: req.GetLogin()})
+		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
-	if !isCreate {
-		return nil, status.Errorf(codes.PermissionDenied, "login failed, not valid credentials")
+	if errors.Is(err, db.ErrTwoFactorRequired) {
+		challenge, err := g.rep.CreateLoginChallenge(ctx, userUID)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
 	}
-
-	userUID, token, err := g.rep.UpdateSessionUser(ctx, req.GetLogin(), req.GetPassword())
 	if err != nil {
 		return nil, err
 	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_LOGIN_SUCCESS, Login: req.GetLogin()})
 
 	return &pb.LoginResponse{Token: token, UserUid: userUID}, nil
 }
 
 // Registration метод регистрации GaultService
 func (g *GaultService) Registration(ctx context.Context, req *pb.RegistrationRequest) (*pb.RegistrationResponse, error) {
+	if err := checkPasswordPolicy(req.GetPassword()); err != nil {
+		return nil, err
+	}
 	hash, err := utils.HashPassword(req.GetPassword())
 	if err != nil {
 		return nil, err
 	}
 
 	userUID, token, err := g.rep.CreateUser(ctx, req.GetLogin(), hash)
+	if errors.Is(err, db.ErrUserExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_REGISTRATION, Login: req.GetLogin()})
 
 	return &pb.RegistrationResponse{Token: token, UserUid: userUID}, nil
 }
 
+// Logout метод отзыва текущей сессии GaultService
+func (g *GaultService) Logout(ctx context.Context, _ *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := sessionTokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.DeleteSession(ctx, userUID, token); err != nil {
+		return nil, shareError(err)
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_SESSION_REVOKED})
+	return &pb.LogoutResponse{}, nil
+}
+
 // GetUserDataList метод получения листа информации данных GaultService
 func (g *GaultService) GetUserDataList(ctx context.Context, req *pb.GetUserDataListRequest) (*pb.GetUserDataListResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetOrgId() != "" {
+		if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_READ_ONLY); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := g.rep.GetDataNameList(ctx, userUID, req)
+	if errors.Is(err, db.ErrInvalidPageToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list, err
+}
+
+// userUIDFromContext получение UID пользователя из метаданных запроса
+func userUIDFromContext(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "metadata is not provided")
+		return "", status.Error(codes.Unauthenticated, "metadata is not provided")
 	}
 
 	authUserUID, userUIDExists := md["useruid"]
 	if !userUIDExists || len(authUserUID) == 0 {
-		return nil, status.Error(codes.Unauthenticated, "useruid is not provided")
+		return "", status.Error(codes.Unauthenticated, "useruid is not provided")
 	}
-	userUID := authUserUID[0]
+	return authUserUID[0], nil
+}
 
-	list, err := g.rep.GetDataNameList(ctx, userUID)
+// GetData метод получения данных владельцем, получателем общего доступа или участником организации GaultService
+func (g *GaultService) GetData(ctx context.Context, req *pb.GetDataRequest) (*pb.GetDataResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return list, err
-}
 
-// GetData метод получения данных GaultService
-func (g *GaultService) GetData(ctx context.Context, req *pb.GetDataRequest) (*pb.GetDataResponse, error) {
+	access, err := g.rep.GetDataAccess(ctx, userUID, req.GetId())
+	if err != nil {
+		return nil, shareError(err)
+	}
+
 	data, err := g.rep.GetData(ctx, req.GetId())
 	if err != nil {
 		return nil, err
 	}
+	data.DataKey = access.GetDataKey()
+	data.SharedKey = access.GetSharedKey()
+	data.Permission = access.GetPermission()
+	data.OrgRole = access.GetOrgRole()
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_READ, DataId: req.GetId()})
 	return data, nil
 }
 
 // SaveData метод сохранения данных через streaming, используя Large Objects в Postgres
 func (g *GaultService) SaveData(stream pb.ContentManagerV1Service_SaveDataServer) error {
 	ctx := stream.Context()
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
 
-	logger.LogInfo("SaveData: starting transaction")
+	logger.LogDebugCtx(ctx, "SaveData: starting transaction")
 	tx, err := g.rep.BeginTx(ctx)
 	if err != nil {
 		return status.Errorf(codes.Internal, "begin tx failed: %v", err)
@@ -111,33 +187,29 @@ func (g *GaultService) SaveData(stream pb.ContentManagerV1Service_SaveDataServer
 	if err != nil {
 		return status.Errorf(codes.Internal, "CreateEmptyLO failed: %v", err)
 	}
-	logger.LogInfo(fmt.Sprintf("Created empty Large Object with OID: %d", oid))
-
-	// Открываем Large Object для записи
-	fd, err := g.rep.OpenLOForWriting(ctx, tx, oid)
-	if err != nil {
-		return status.Errorf(codes.Internal, "OpenLOForWriting failed: %v", err)
-	}
-	defer func() {
-		logger.LogInfo(fmt.Sprintf("Closing Large Object FD: %d", fd))
-		g.rep.CloseLO(ctx, tx, fd)
-	}()
+	logger.LogDebugCtx(ctx, "created empty large object", zap.Int("oid", oid))
 
 	var (
-		userUID     string
 		dataType    string
 		dataName    string
 		recordID    = uuid.New().String()
 		recordReady bool
+		fd          int
 		chunkCount  uint64
 		totalBytes  uint64
 	)
+	defer func() {
+		if recordReady {
+			logger.LogDebugCtx(ctx, "closing large object", zap.Int("fd", fd))
+			g.rep.CloseLO(ctx, tx, fd)
+		}
+	}()
 
-	logger.LogInfo("Start receiving chunks from client")
+	logger.LogDebugCtx(ctx, "start receiving chunks from client")
 	for {
 		req, recvErr := stream.Recv()
 		if recvErr == io.EOF {
-			logger.LogInfo("Reached end of stream (EOF)")
+			logger.LogDebugCtx(ctx, "reached end of stream")
 			break
 		}
 		if recvErr != nil {
@@ -146,16 +218,32 @@ func (g *GaultService) SaveData(stream pb.ContentManagerV1Service_SaveDataServer
 
 		// При первом чанке создаем запись в user_data
 		if !recordReady {
-			userUID = req.GetUserUid()
+			// Владелец записи — пользователь сессии, user_uid в сообщении только сверяется с ней
+			if req.GetUserUid() != "" && req.GetUserUid() != userUID {
+				return status.Error(codes.PermissionDenied, "user_uid does not match authenticated user")
+			}
 			dataType = req.GetType()
 			dataName = req.GetName()
 
-			logger.LogInfo(fmt.Sprintf("Creating user_data record: ID=%s, UserUID=%s, Type=%s, Name=%s, OID=%d",
-				recordID, userUID, dataType, dataName, oid))
+			logger.LogInfoCtx(ctx, "creating user_data record",
+				zap.String("data_id", recordID),
+				zap.String("user", userUID),
+				zap.String("type", dataType),
+				zap.Int("oid", oid))
 
 			if err := g.rep.InsertUserDataRecordTx(ctx, tx, recordID, userUID, dataType, dataName, oid); err != nil {
 				return status.Errorf(codes.Internal, "insert user_data failed: %v", err)
 			}
+			if req.GetCollectionId() != "" {
+				if err := g.saveToCollection(ctx, tx, userUID, recordID, req.GetCollectionId()); err != nil {
+					return err
+				}
+			}
+
+			// Large Object открывается после создания записи: в неё сохраняется ключ данных
+			if fd, err = g.rep.OpenLOForWriting(ctx, tx, oid); err != nil {
+				return status.Errorf(codes.Internal, "OpenLOForWriting failed: %v", err)
+			}
 			recordReady = true
 		}
 
@@ -163,8 +251,8 @@ func (g *GaultService) SaveData(stream pb.ContentManagerV1Service_SaveDataServer
 		if len(chunk) > 0 {
 			chunkCount++
 			totalBytes += uint64(len(chunk))
-			logger.LogInfo(fmt.Sprintf("Writing chunk #%d, size=%d bytes (total so far: %d bytes)",
-				chunkCount, len(chunk), totalBytes))
+			logger.LogDebugCtx(ctx, "writing chunk",
+				zap.Uint64("chunk", chunkCount), zap.Int("size", len(chunk)), zap.Uint64("total_bytes", totalBytes))
 
 			if err := g.rep.WriteLO(ctx, tx, fd, chunk); err != nil {
 				return status.Errorf(codes.Internal, "failed writing chunk %d: %v", chunkCount, err)
@@ -176,33 +264,62 @@ func (g *GaultService) SaveData(stream pb.ContentManagerV1Service_SaveDataServer
 		return status.Errorf(codes.InvalidArgument, "no data received")
 	}
 
-	logger.LogInfo(fmt.Sprintf("All chunks received. Total chunks: %d, total bytes: %d", chunkCount, totalBytes))
+	logger.LogInfoCtx(ctx, "all chunks received", zap.Uint64("chunks", chunkCount), zap.Uint64("total_bytes", totalBytes))
+
+	if err = g.rep.FinishLO(ctx, tx, fd); err != nil {
+		return status.Errorf(codes.Internal, "finish large object failed: %v", err)
+	}
+	if err = g.rep.UpdateUserDataSizeTx(ctx, tx, recordID, int64(totalBytes)); err != nil {
+		return status.Errorf(codes.Internal, "update size failed: %v", err)
+	}
 
 	if err = tx.Commit(); err != nil {
 		return status.Errorf(codes.Internal, "commit failed: %v", err)
 	}
 
-	logger.LogInfo("Transaction committed successfully. Sending response to client.")
-	return stream.SendAndClose(&pb.SaveDataResponse{})
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_CREATE, DataId: recordID})
+
+	logger.LogDebugCtx(ctx, "SaveData: transaction committed")
+	return stream.SendAndClose(&pb.SaveDataResponse{Id: recordID})
 }
 
-// DeleteData метод удаления данных GaultService
+// DeleteData метод перемещения данных в корзину владельцем или участником организации GaultService
 func (g *GaultService) DeleteData(ctx context.Context, req *pb.DeleteDataRequest) (*pb.DeleteDataResponse, error) {
-	if err := g.rep.DeleteData(ctx, req.GetId()); err != nil {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
 		return nil, err
 	}
+
+	access, err := g.rep.GetDataAccess(ctx, userUID, req.GetId())
+	if err != nil {
+		return nil, shareError(err)
+	}
+	if !canDelete(access) {
+		return nil, status.Error(codes.PermissionDenied, "only owner can delete data")
+	}
+
+	if err := g.rep.DeleteData(ctx, req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_DELETE, DataId: req.GetId()})
 	return &pb.DeleteDataResponse{}, nil
 }
 
 // UpdateData метод обновления данных GaultService
 func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataServer) error {
 	ctx := stream.Context()
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
 
-	logger.LogInfo("UpdateData: starting transaction")
+	logger.LogDebugCtx(ctx, "UpdateData: starting transaction")
 	tx, err := g.rep.BeginTx(ctx)
 	if err != nil {
 		return status.Errorf(codes.Internal, "begin tx failed: %v", err)
 	}
+	// После Commit откат ничего не делает
+	defer func() { _ = tx.Rollback() }()
 
 	firstReq, recvErr := stream.Recv()
 	if recvErr == io.EOF {
@@ -211,13 +328,35 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 	if recvErr != nil {
 		return status.Errorf(codes.Internal, "receive chunk error: %v", recvErr)
 	}
+	// Пользователь определяется по сессии, user_uid в сообщении только сверяется с ней
+	if firstReq.GetUserUid() != "" && firstReq.GetUserUid() != userUID {
+		return status.Error(codes.PermissionDenied, "user_uid does not match authenticated user")
+	}
+
+	// Изменять данные может владелец или получатель с доступом на изменение
+	access, err := g.rep.GetDataAccess(ctx, userUID, firstReq.GetDataUid())
+	if err != nil {
+		return shareError(err)
+	}
+	if !canWrite(access) {
+		return status.Error(codes.PermissionDenied, "read only access")
+	}
+	if firstReq.GetDataKey() != "" {
+		if !isOwner(access) {
+			return status.Error(codes.PermissionDenied, "only owner can change data key")
+		}
+		if err := g.rep.SetDataKeyTx(ctx, tx, firstReq.GetDataUid(), firstReq.GetDataKey()); err != nil {
+			return status.Errorf(codes.Internal, "set data key failed: %v", err)
+		}
+	}
 
 	// Ищем OID в уже существующей записи
 	oid, err := g.rep.GetOidByItemID(ctx, firstReq.GetDataUid())
 	if err != nil {
 		return status.Errorf(codes.Internal, "GetOidByItemID failed: %v", err)
 	}
-	logger.LogInfo(fmt.Sprintf("Updating existing OID = %d", oid))
+	logger.LogInfoCtx(ctx, "updating large object",
+		zap.String("data_id", firstReq.GetDataUid()), zap.Int("oid", oid))
 
 	// Открываем LO на запись
 	fd, err := g.rep.OpenLOForWriting(ctx, tx, oid)
@@ -225,7 +364,7 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 		return status.Errorf(codes.Internal, "OpenLOForWriting failed: %v", err)
 	}
 	defer func() {
-		logger.LogInfo(fmt.Sprintf("Closing Large Object FD: %d", fd))
+		logger.LogDebugCtx(ctx, "closing large object", zap.Int("fd", fd))
 		g.rep.CloseLO(ctx, tx, fd)
 	}()
 
@@ -233,7 +372,7 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 	if errTrunc := g.rep.TruncateLO(ctx, tx, fd, 0); errTrunc != nil {
 		return status.Errorf(codes.Internal, "truncate LO failed: %v", errTrunc)
 	}
-	logger.LogInfo("LO truncated to 0 bytes")
+	logger.LogDebugCtx(ctx, "large object truncated")
 
 	// Записываем первый чанк (который уже прочитали)
 	var chunkCount uint64
@@ -243,20 +382,20 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 	if len(data) > 0 {
 		chunkCount++
 		totalBytes += uint64(len(data))
-		logger.LogInfo(fmt.Sprintf("Writing first chunk #%d: size=%d bytes", chunkCount, len(data)))
+		logger.LogDebugCtx(ctx, "writing chunk", zap.Uint64("chunk", chunkCount), zap.Int("size", len(data)))
 
 		if err := g.rep.WriteLO(ctx, tx, fd, data); err != nil {
 			return status.Errorf(codes.Internal, "failed writing chunk %d: %v", chunkCount, err)
 		}
 	}
 
-	logger.LogInfo("Start receiving subsequent chunks for UpdateData")
+	logger.LogDebugCtx(ctx, "start receiving subsequent chunks")
 
 	// Читаем остальные чанки в цикле
 	for {
 		req, recvErr := stream.Recv()
 		if recvErr == io.EOF {
-			logger.LogInfo("Reached end of stream for update")
+			logger.LogDebugCtx(ctx, "reached end of stream")
 			break
 		}
 		if recvErr != nil {
@@ -267,8 +406,8 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 		if len(chunk) > 0 {
 			chunkCount++
 			totalBytes += uint64(len(chunk))
-			logger.LogInfo(fmt.Sprintf("Writing chunk #%d, size=%d bytes (total so far: %d)",
-				chunkCount, len(chunk), totalBytes))
+			logger.LogDebugCtx(ctx, "writing chunk",
+				zap.Uint64("chunk", chunkCount), zap.Int("size", len(chunk)), zap.Uint64("total_bytes", totalBytes))
 
 			if err := g.rep.WriteLO(ctx, tx, fd, chunk); err != nil {
 				return status.Errorf(codes.Internal, "failed writing chunk %d: %v", chunkCount, err)
@@ -280,64 +419,166 @@ func (g *GaultService) UpdateData(stream pb.ContentManagerV1Service_UpdateDataSe
 		return status.Errorf(codes.InvalidArgument, "no data to update")
 	}
 
-	logger.LogInfo(fmt.Sprintf("All chunks received for update, total chunks=%d, total bytes=%d", chunkCount, totalBytes))
+	logger.LogInfoCtx(ctx, "all chunks received", zap.Uint64("chunks", chunkCount), zap.Uint64("total_bytes", totalBytes))
+	if err := g.rep.FinishLO(ctx, tx, fd); err != nil {
+		return status.Errorf(codes.Internal, "finish large object failed: %v", err)
+	}
+	if err := g.rep.UpdateUserDataSizeTx(ctx, tx, firstReq.GetDataUid(), int64(totalBytes)); err != nil {
+		return status.Errorf(codes.Internal, "update size failed: %v", err)
+	}
 	if err := tx.Commit(); err != nil {
 		return status.Errorf(codes.Internal, "commit failed: %v", err)
 	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_UPDATE, DataId: firstReq.GetDataUid()})
+	// Смена данных сбрасывает напоминание, не дожидаясь планировщика
+	flagRotationDue(ctx, g.rep, firstReq.GetDataUid())
 
-	logger.LogInfo("UpdateData: transaction committed, sending response")
+	logger.LogDebugCtx(ctx, "UpdateData: transaction committed")
 	return stream.SendAndClose(&pb.UpdateDataResponse{})
 }
 
+// defaultDrainTimeout время ожидания текущих запросов при остановке по умолчанию
+const defaultDrainTimeout = 30 * time.Second
+
 // gaultServer инстанс сервиса
 var gaultServer *GaultService
 
-// Run запуск сервиса
-func Run(port int, unprotectedMethods []config.EndpointRule, store db.Repository) error {
-	listen, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+// Run запуск сервиса до сигнала SIGTERM или SIGINT
+func Run(conf config.Config, store db.Repository) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	return serve(ctx, conf, store)
+}
+
+// serve запуск сервиса до отмены ctx, после отмены текущие запросы завершаются в пределах conf.Shutdown.DrainTimeout
+func serve(ctx context.Context, conf config.Config, store db.Repository) error {
+	tracerProvider, shutdownTracing, err := newTracerProvider(ctx, conf.Tracing)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.LogError("tracing shutdown failed", zap.Error(err))
+		}
+	}()
 
-	// Загрузка TLS-сертификата и ключа
-	cert, err := tls.LoadX509KeyPair("certs/server.crt", "certs/server.key")
+	registry := prometheus.NewRegistry()
+	metrics, err := newServerMetrics(registry)
 	if err != nil {
-		return fmt.Errorf("failed to load server cert/key: %w", err)
+		return err
+	}
+	if store, err = db.Instrument(store, tracerProvider, registry); err != nil {
+		return fmt.Errorf("failed to instrument storage: %w", err)
 	}
 
-	// Подгружаем CA для валидации клиента
-	certPool := x509.NewCertPool()
-	ca, err := os.ReadFile("certs/ca.crt")
+	creds, err := serverCredentials(conf)
 	if err != nil {
-		return fmt.Errorf("failed to read CA cert: %w", err)
+		return err
 	}
-	certPool.AppendCertsFromPEM(ca)
 
-	// Настройки TLS
-	creds := credentials.NewTLS(&tls.Config{
-		Certificates: []tls.Certificate{cert},
-		ClientAuth:   tls.NoClientCert,
-		ClientCAs:    certPool,
-	})
+	listen, err := net.Listen("tcp", fmt.Sprintf("%s:%d", conf.Host, conf.Port))
+	if err != nil {
+		return err
+	}
+	if conf.Metrics.Addr != "" {
+		metricsListener, err := net.Listen("tcp", conf.Metrics.Addr)
+		if err != nil {
+			_ = listen.Close()
+			return fmt.Errorf("failed to listen metrics: %w", err)
+		}
+		go serveMetrics(ctx, metricsListener, registry)
+	}
 
 	// Параметры gRPC-сервера
 	serverOptions := []grpc.ServerOption{
 		grpc.Creds(creds),
-		grpc.UnaryInterceptor(AuthInterceptor),
+		grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithTracerProvider(tracerProvider),
+			otelgrpc.WithPropagators(propagation.TraceContext{}),
+		)),
+		grpc.ChainUnaryInterceptor(RequestIDInterceptor, metrics.UnaryInterceptor, RateLimitInterceptor, AuthInterceptor),
+		grpc.ChainStreamInterceptor(RequestIDStreamInterceptor, metrics.StreamInterceptor, AuthStreamInterceptor),
 		grpc.MaxRecvMsgSize(1024 * 1024 * 1024 * 100),
 		grpc.MaxSendMsgSize(1024 * 1024 * 1024 * 100),
 	}
 
 	s := grpc.NewServer(serverOptions...)
 	gaultServer = &GaultService{rep: store}
-	setAllowEndpoints(unprotectedMethods)
+	setAllowEndpoints(conf.AllowEndpoints)
+	setMTLS(conf.MTLS)
+	setPasswordPolicy(conf.PasswordPolicy)
+	setTrash(conf.Trash)
 
 	pb.RegisterAuthV1ServiceServer(s, gaultServer)
 	pb.RegisterContentManagerV1ServiceServer(s, gaultServer)
+	pb.RegisterFolderV1ServiceServer(s, gaultServer)
+	pb.RegisterShareV1ServiceServer(s, gaultServer)
+	pb.RegisterOrgV1ServiceServer(s, gaultServer)
+	pb.RegisterAuditV1ServiceServer(s, gaultServer)
+
+	var services []string
+	for name := range s.GetServiceInfo() {
+		services = append(services, name)
+	}
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	publicServices[healthpb.Health_ServiceDesc.ServiceName] = true
+	if conf.Reflection {
+		reflection.Register(s)
+		for name := range s.GetServiceInfo() {
+			if strings.HasPrefix(name, "grpc.reflection.") {
+				publicServices[name] = true
+			}
+		}
+		logger.LogInfo("gRPC reflection is enabled")
+	}
+
+	go runRotationScheduler(ctx, store, conf.Rotation.CheckInterval)
+	go runTrashPurger(ctx, store, conf.Trash.Retention, conf.Trash.PurgeInterval)
+	go runHealthMonitor(ctx, healthServer, store, conf.Health.CheckInterval, services)
 
-	logger.LogInfo("start gRPC server with TLS")
-	if err = s.Serve(listen); err != nil {
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.LogInfo("start gRPC server", zap.Stringer("addr", listen.Addr()))
+		serveErr <- s.Serve(listen)
+	}()
+
+	select {
+	case err := <-serveErr:
 		return err
+	case <-ctx.Done():
 	}
+
+	logger.LogInfo("shutting down gRPC server")
+	// Проверка готовности сразу сообщает об остановке, новые запросы не принимаются
+	healthServer.Shutdown()
+	gracefulStop(s, conf.Shutdown.DrainTimeout)
 	return nil
 }
+
+// gracefulStop остановка сервера с ожиданием текущих запросов не дольше timeout
+func gracefulStop(s *grpc.Server, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+		logger.LogInfo("gRPC server stopped")
+	case <-timer.C:
+		logger.LogError("requests did not finish, forcing stop", zap.Duration("timeout", timeout))
+		s.Stop()
+		<-stopped
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"time"
 
 	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/passgen"
 
 	"google.golang.org/grpc/codes"
 
@@ -27,12 +29,38 @@ import (
 
 	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	mockDB "github.com/fngoc/gault/gen/go/db"
 )
 
+// userContext контекст аутентифицированного пользователя userUID
+func userContext(userUID string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", userUID))
+}
+
+// newTestTx транзакция на sqlmock: Commit проходит, остальные вызовы возвращают ошибку
+func newTestTx(t *testing.T) *sql.Tx {
+	t.Helper()
+	return beginTestTx(t, nil)
+}
+
+// beginTestTx транзакция на sqlmock, Commit возвращает commitErr
+func beginTestTx(t *testing.T, commitErr error) *sql.Tx {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	mock.ExpectCommit().WillReturnError(commitErr)
+	return tx
+}
+
 // mockSaveDataServer заглушка, реализующая интерфейс ContentManagerV1Service_SaveDataServer
 type mockSaveDataServer struct {
 	grpc.ServerStream
@@ -74,39 +102,36 @@ func TestGaultService_Login(t *testing.T) {
 		ctx := context.Background()
 		login := "testUser"
 		password := "password"
-		repo.EXPECT().IsUserCreated(ctx, login).Return(true, nil)
 		repo.EXPECT().UpdateSessionUser(ctx, login, password).Return("user-uid", "token", nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_LOGIN_SUCCESS,
+			Login:  login,
+		}).Return(nil)
 
 		resp, err := service.Login(ctx, &pb.LoginRequest{Login: login, Password: password})
 		assert.NoError(t, err)
 		assert.Equal(t, "token", resp.Token)
 		assert.Equal(t, "user-uid", resp.UserUid)
 	})
-	t.Run("login error, user is created error", func(t *testing.T) {
+	t.Run("login error, invalid credentials", func(t *testing.T) {
 		ctx := context.Background()
 		login := "testUser"
 		password := "password"
-		repo.EXPECT().IsUserCreated(ctx, login).Return(false, fmt.Errorf("error"))
+		repo.EXPECT().UpdateSessionUser(ctx, login, password).Return("", "", db.ErrInvalidCredentials)
+		repo.EXPECT().InsertAuditEvent(ctx, "", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_LOGIN_FAILURE,
+			Login:  login,
+		}).Return(fmt.Errorf("audit error"))
 
 		resp, err := service.Login(ctx, &pb.LoginRequest{Login: login, Password: password})
-		assert.NotNil(t, err)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, "invalid login or password", status.Convert(err).Message())
 		assert.Nil(t, resp)
 	})
-	t.Run("login error, user is created", func(t *testing.T) {
+	t.Run("login error, storage error", func(t *testing.T) {
 		ctx := context.Background()
 		login := "testUser"
 		password := "password"
-		repo.EXPECT().IsUserCreated(ctx, login).Return(false, nil)
-
-		resp, err := service.Login(ctx, &pb.LoginRequest{Login: login, Password: password})
-		assert.Error(t, err)
-		assert.Nil(t, resp)
-	})
-	t.Run("login error, user is created", func(t *testing.T) {
-		ctx := context.Background()
-		login := "testUser"
-		password := "password"
-		repo.EXPECT().IsUserCreated(ctx, login).Return(true, nil)
 		repo.EXPECT().UpdateSessionUser(ctx, login, password).Return("", "", fmt.Errorf("error"))
 
 		resp, err := service.Login(ctx, &pb.LoginRequest{Login: login, Password: password})
@@ -127,12 +152,21 @@ func TestGaultService_Registration(t *testing.T) {
 		login := "newUser"
 		password := "newPassword"
 		repo.EXPECT().CreateUser(ctx, login, gomock.Any()).Return("user-uid", "token", nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", gomock.Any()).Return(nil)
 
 		resp, err := service.Registration(ctx, &pb.RegistrationRequest{Login: login, Password: password})
 		assert.NoError(t, err)
 		assert.Equal(t, "token", resp.Token)
 		assert.Equal(t, "user-uid", resp.UserUid)
 	})
+	t.Run("login already exists", func(t *testing.T) {
+		ctx := context.Background()
+		repo.EXPECT().CreateUser(ctx, "newUser", gomock.Any()).Return("", "", db.ErrUserExists)
+
+		resp, err := service.Registration(ctx, &pb.RegistrationRequest{Login: "newUser", Password: "newPassword"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		assert.Nil(t, resp)
+	})
 	t.Run("failed registration", func(t *testing.T) {
 		ctx := context.Background()
 		login := "newUser"
@@ -143,6 +177,14 @@ func TestGaultService_Registration(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+	t.Run("weak password", func(t *testing.T) {
+		setPasswordPolicy(config.PasswordPolicy{MinLength: 8, MinScore: passgen.ScoreFair})
+		defer setPasswordPolicy(config.PasswordPolicy{})
+
+		resp, err := service.Registration(context.Background(), &pb.RegistrationRequest{Login: "newUser", Password: "password1"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestGaultService_GetUserDataList(t *testing.T) {
@@ -155,7 +197,7 @@ func TestGaultService_GetUserDataList(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
-		repo.EXPECT().GetDataNameList(ctx, "user-uid").Return(&pb.GetUserDataListResponse{}, nil)
+		repo.EXPECT().GetDataNameList(ctx, "user-uid", gomock.Any()).Return(&pb.GetUserDataListResponse{}, nil)
 
 		resp, err := service.GetUserDataList(ctx, &pb.GetUserDataListRequest{})
 		assert.NoError(t, err)
@@ -177,12 +219,21 @@ func TestGaultService_GetUserDataList(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
-		repo.EXPECT().GetDataNameList(ctx, "user-uid").Return(nil, fmt.Errorf("error"))
+		repo.EXPECT().GetDataNameList(ctx, "user-uid", gomock.Any()).Return(nil, fmt.Errorf("error"))
 
 		resp, err := service.GetUserDataList(ctx, &pb.GetUserDataListRequest{})
 		assert.Error(t, err)
 		assert.Nil(t, resp)
 	})
+	t.Run("invalid page token", func(t *testing.T) {
+		md := metadata.New(map[string]string{"useruid": "user-uid"})
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		repo.EXPECT().GetDataNameList(ctx, "user-uid", gomock.Any()).Return(nil, db.ErrInvalidPageToken)
+
+		resp, err := service.GetUserDataList(ctx, &pb.GetUserDataListRequest{PageToken: "bad"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
 }
 
 func TestGaultService_GetData(t *testing.T) {
@@ -195,7 +246,12 @@ func TestGaultService_GetData(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
 		repo.EXPECT().GetData(ctx, "data-id").Return(&pb.GetDataResponse{Type: "text", Content: &pb.GetDataResponse_TextData{TextData: "content"}}, nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_ITEM_READ,
+			DataId: "data-id",
+		}).Return(nil)
 
 		resp, err := service.GetData(ctx, &pb.GetDataRequest{Id: "data-id"})
 		assert.NoError(t, err)
@@ -205,6 +261,7 @@ func TestGaultService_GetData(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
 		repo.EXPECT().GetData(ctx, "data-id").Return(nil, fmt.Errorf("error"))
 
 		resp, err := service.GetData(ctx, &pb.GetDataRequest{Id: "data-id"})
@@ -223,7 +280,12 @@ func TestGaultService_DeleteData(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
 		repo.EXPECT().DeleteData(ctx, "data-id").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_ITEM_DELETE,
+			DataId: "data-id",
+		}).Return(nil)
 
 		resp, err := service.DeleteData(ctx, &pb.DeleteDataRequest{Id: "data-id"})
 		assert.NoError(t, err)
@@ -232,6 +294,7 @@ func TestGaultService_DeleteData(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		md := metadata.New(map[string]string{"useruid": "user-uid"})
 		ctx := metadata.NewIncomingContext(context.Background(), md)
+		repo.EXPECT().GetDataAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
 		repo.EXPECT().DeleteData(ctx, "data-id").Return(fmt.Errorf("error"))
 
 		resp, err := service.DeleteData(ctx, &pb.DeleteDataRequest{Id: "data-id"})
@@ -250,6 +313,7 @@ func TestGaultService_SaveData(t *testing.T) {
 	t.Run("error: Commit fail", func(t *testing.T) {
 		// Настраиваем входные данные
 		stream := &mockSaveDataServer{
+			ctx: userContext("some-user-uid"),
 			reqs: []*pb.SaveDataRequest{
 				{
 					UserUid: "some-user-uid",
@@ -274,6 +338,8 @@ func TestGaultService_SaveData(t *testing.T) {
 			123,
 		).Return(nil)
 		mockRepo.EXPECT().WriteLO(gomock.Any(), mockTx, 111, []byte("some-binary-data")).Return(nil)
+		mockRepo.EXPECT().FinishLO(gomock.Any(), mockTx, 111).Return(nil)
+		mockRepo.EXPECT().UpdateUserDataSizeTx(gomock.Any(), mockTx, gomock.Any(), int64(len("some-binary-data"))).Return(nil)
 		mockRepo.EXPECT().CloseLO(gomock.Any(), mockTx, 111)
 		// Запускаем тестируемый метод и выходим на ошибке Commit
 		defer func() {
@@ -289,6 +355,7 @@ func TestGaultService_SaveData(t *testing.T) {
 
 	t.Run("error: BeginTx fail", func(t *testing.T) {
 		stream := &mockSaveDataServer{
+			ctx: userContext("some-user-uid"),
 			reqs: []*pb.SaveDataRequest{
 				{
 					UserUid: "some-user-uid",
@@ -309,6 +376,7 @@ func TestGaultService_SaveData(t *testing.T) {
 
 	t.Run("error: CreateEmptyLO fail", func(t *testing.T) {
 		stream := &mockSaveDataServer{
+			ctx: userContext("uid"),
 			reqs: []*pb.SaveDataRequest{
 				{
 					UserUid: "uid",
@@ -335,6 +403,7 @@ func TestGaultService_SaveData(t *testing.T) {
 
 	t.Run("error: OpenLOForWriting fail", func(t *testing.T) {
 		stream := &mockSaveDataServer{
+			ctx: userContext("uid"),
 			reqs: []*pb.SaveDataRequest{
 				{UserUid: "uid", Type: "text", Name: "n", Data: []byte("aaa")},
 			},
@@ -342,6 +411,7 @@ func TestGaultService_SaveData(t *testing.T) {
 		mockTx := &sql.Tx{}
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
 		mockRepo.EXPECT().CreateEmptyLO(gomock.Any(), mockTx).Return(321, nil)
+		mockRepo.EXPECT().InsertUserDataRecordTx(gomock.Any(), mockTx, gomock.Any(), "uid", "text", "n", 321).Return(nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 321).Return(0, fmt.Errorf("open fail"))
 
 		defer func() {
@@ -357,6 +427,7 @@ func TestGaultService_SaveData(t *testing.T) {
 
 	t.Run("error: InsertUserDataRecordTx fail", func(t *testing.T) {
 		stream := &mockSaveDataServer{
+			ctx: userContext("uid"),
 			reqs: []*pb.SaveDataRequest{
 				{UserUid: "uid", Type: "file", Name: "n", Data: []byte("chunk1")},
 			},
@@ -364,10 +435,8 @@ func TestGaultService_SaveData(t *testing.T) {
 		mockTx := &sql.Tx{}
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
 		mockRepo.EXPECT().CreateEmptyLO(gomock.Any(), mockTx).Return(123, nil)
-		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 123).Return(999, nil)
 		mockRepo.EXPECT().InsertUserDataRecordTx(gomock.Any(), mockTx, gomock.Any(), "uid", "file", "n", 123).
 			Return(fmt.Errorf("insert fail"))
-		mockRepo.EXPECT().CloseLO(gomock.Any(), mockTx, 999).AnyTimes()
 
 		defer func() {
 			if r := recover(); r != nil {
@@ -382,6 +451,7 @@ func TestGaultService_SaveData(t *testing.T) {
 
 	t.Run("error: WriteLO fail on chunk", func(t *testing.T) {
 		stream := &mockSaveDataServer{
+			ctx: userContext("uid"),
 			reqs: []*pb.SaveDataRequest{
 				{UserUid: "uid", Type: "file", Name: "n", Data: []byte("chunk1")},
 			},
@@ -405,6 +475,26 @@ func TestGaultService_SaveData(t *testing.T) {
 		st, _ := status.FromError(err)
 		assert.Contains(t, st.Message(), "failed writing chunk 1: write chunk fail")
 	})
+
+	t.Run("error: user_uid differs from session", func(t *testing.T) {
+		stream := &mockSaveDataServer{
+			ctx:  userContext("uid"),
+			reqs: []*pb.SaveDataRequest{{UserUid: "victim-uid", Type: "text", Name: "n", Data: []byte("x")}},
+		}
+		mockTx := &sql.Tx{}
+		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().CreateEmptyLO(gomock.Any(), mockTx).Return(123, nil)
+
+		err := service.SaveData(stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		stream := &mockSaveDataServer{reqs: []*pb.SaveDataRequest{{Type: "text", Name: "n", Data: []byte("x")}}}
+
+		err := service.SaveData(stream)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
 }
 
 type mockUpdateDataServer struct {
@@ -444,9 +534,11 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	mockRepo := mockDB.NewMockRepository(ctrl)
 	service := &GaultService{rep: mockRepo}
+	authCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
 
 	t.Run("success multiple chunks", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{
 					DataUid: "some-data-uid",
@@ -459,9 +551,10 @@ func TestGaultService_UpdateData(t *testing.T) {
 			},
 		}
 
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
 
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "some-data-uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "some-data-uid").Return(1234, nil)
 
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 1234).Return(999, nil)
@@ -469,7 +562,11 @@ func TestGaultService_UpdateData(t *testing.T) {
 		mockRepo.EXPECT().TruncateLO(gomock.Any(), mockTx, 999, int64(0)).Return(nil)
 		mockRepo.EXPECT().WriteLO(gomock.Any(), mockTx, 999, []byte("first-chunk-")).Return(nil)
 		mockRepo.EXPECT().WriteLO(gomock.Any(), mockTx, 999, []byte("second-chunk")).Return(nil)
+		mockRepo.EXPECT().FinishLO(gomock.Any(), mockTx, 999).Return(nil)
+		mockRepo.EXPECT().UpdateUserDataSizeTx(gomock.Any(), mockTx, "some-data-uid", int64(24)).Return(nil)
 		mockRepo.EXPECT().CloseLO(gomock.Any(), mockTx, 999)
+		mockRepo.EXPECT().InsertAuditEvent(gomock.Any(), "user-uid", gomock.Any()).Return(nil)
+		mockRepo.EXPECT().RefreshRotationDue(gomock.Any(), "some-data-uid").Return(int64(0), nil)
 
 		defer func() {
 			if r := recover(); r != nil {
@@ -483,6 +580,7 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: BeginTx fails", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("someData")},
 			},
@@ -498,9 +596,10 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: no data received (first Recv is EOF)", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx:  authCtx,
 			reqs: []*pb.UpdateDataRequest{},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
 
 		defer func() {
@@ -516,9 +615,9 @@ func TestGaultService_UpdateData(t *testing.T) {
 	})
 
 	t.Run("error: first Recv returns some error", func(t *testing.T) {
-		stream := &mockUpdateDataServer{}
+		stream := &mockUpdateDataServer{ctx: authCtx}
 
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
 
 		defer func() {
@@ -533,12 +632,14 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: GetOidByItemID fails", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("chunk")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(0, fmt.Errorf("some oid error"))
 
 		defer func() {
@@ -555,12 +656,14 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: OpenLOForWriting fails", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("chunk")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(333, nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 333).Return(0, fmt.Errorf("open fail"))
 
@@ -578,12 +681,14 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: TruncateLO fails", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("chunk")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(444, nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 444).Return(777, nil)
 		mockRepo.EXPECT().TruncateLO(gomock.Any(), mockTx, 777, int64(0)).
@@ -604,12 +709,14 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: writeLO fails on first chunk", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("first-chunk")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(555, nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 555).Return(999, nil)
 		mockRepo.EXPECT().TruncateLO(gomock.Any(), mockTx, 999, int64(0)).Return(nil)
@@ -631,12 +738,14 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: no data to update (first chunk has 0 bytes, последующие тоже)", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := newTestTx(t)
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(222, nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 222).Return(333, nil)
 		mockRepo.EXPECT().TruncateLO(gomock.Any(), mockTx, 333, int64(0)).Return(nil)
@@ -656,16 +765,20 @@ func TestGaultService_UpdateData(t *testing.T) {
 
 	t.Run("error: commit fails", func(t *testing.T) {
 		stream := &mockUpdateDataServer{
+			ctx: authCtx,
 			reqs: []*pb.UpdateDataRequest{
 				{DataUid: "uid", Data: []byte("some-data")},
 			},
 		}
-		mockTx := &sql.Tx{}
+		mockTx := beginTestTx(t, fmt.Errorf("commit error"))
 		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		mockRepo.EXPECT().GetDataAccess(gomock.Any(), gomock.Any(), "uid").Return(&pb.GetDataResponse{}, nil)
 		mockRepo.EXPECT().GetOidByItemID(gomock.Any(), "uid").Return(1001, nil)
 		mockRepo.EXPECT().OpenLOForWriting(gomock.Any(), mockTx, 1001).Return(888, nil)
 		mockRepo.EXPECT().TruncateLO(gomock.Any(), mockTx, 888, int64(0)).Return(nil)
 		mockRepo.EXPECT().WriteLO(gomock.Any(), mockTx, 888, []byte("some-data")).Return(nil)
+		mockRepo.EXPECT().FinishLO(gomock.Any(), mockTx, 888).Return(nil)
+		mockRepo.EXPECT().UpdateUserDataSizeTx(gomock.Any(), mockTx, "uid", int64(9)).Return(nil)
 		mockRepo.EXPECT().CloseLO(gomock.Any(), mockTx, 888).AnyTimes()
 
 		defer func() {
@@ -679,6 +792,23 @@ func TestGaultService_UpdateData(t *testing.T) {
 		assert.Equal(t, codes.Internal, st.Code())
 		assert.Contains(t, st.Message(), "commit failed:")
 	})
+	t.Run("error: user_uid differs from session", func(t *testing.T) {
+		stream := &mockUpdateDataServer{
+			ctx:  authCtx,
+			reqs: []*pb.UpdateDataRequest{{UserUid: "owner-uid", DataUid: "some-data-uid", Data: []byte("x")}},
+		}
+		mockRepo.EXPECT().BeginTx(gomock.Any()).Return(newTestTx(t), nil)
+
+		err := service.UpdateData(stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("error: unauthenticated", func(t *testing.T) {
+		stream := &mockUpdateDataServer{reqs: []*pb.UpdateDataRequest{{DataUid: "some-data-uid", Data: []byte("x")}}}
+
+		err := service.UpdateData(stream)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
 }
 
 func TestRun_Success(t *testing.T) {
@@ -687,11 +817,14 @@ func TestRun_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	repo := mockDB.NewMockRepository(ctrl)
+	repo.EXPECT().RefreshRotationDue(gomock.Any(), "").Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().PurgeExpiredTrash(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().Ping(gomock.Any()).Return(nil).AnyTimes()
 
 	port := getFreePort(t)
 
 	go func() {
-		err := Run(port, []config.EndpointRule{}, repo)
+		err := Run(config.Config{Port: port}, repo)
 		if err != nil {
 			t.Errorf("Run failed: %v", err)
 		}
@@ -717,7 +850,7 @@ func TestRun_FailToListen(t *testing.T) {
 	defer ctrl.Finish()
 	repo := mockDB.NewMockRepository(ctrl)
 
-	err = Run(50052, nil, repo)
+	err = Run(config.Config{Port: 50052}, repo)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "address already in use")
 }
@@ -731,7 +864,7 @@ func TestRun_FailToLoadCert(t *testing.T) {
 
 	port := getFreePort(t)
 
-	err := Run(port, nil, repo)
+	err := Run(config.Config{Port: port}, repo)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to load server cert/key")
 }
@@ -746,7 +879,7 @@ func TestRun_FailToReadCA(t *testing.T) {
 
 	port := getFreePort(t)
 
-	err := Run(port, nil, repo)
+	err := Run(config.Config{Port: port}, repo)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to read CA cert")
 }
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+// publicKeySize размер публичного ключа X25519
+const publicKeySize = 32
+
+// SetUserKeys метод сохранения ключевой пары пользователя GaultService
+func (g *GaultService) SetUserKeys(ctx context.Context, req *pb.SetUserKeysRequest) (*pb.SetUserKeysResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.GetPublicKey()) != publicKeySize || req.GetEncryptedPrivateKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid key pair")
+	}
+
+	if err := g.rep.SetUserKeys(ctx, userUID, req.GetPublicKey(), req.GetEncryptedPrivateKey()); err != nil {
+		return nil, err
+	}
+	return &pb.SetUserKeysResponse{}, nil
+}
+
+// GetUserKeys метод получения своей ключевой пары GaultService
+func (g *GaultService) GetUserKeys(ctx context.Context, _ *pb.GetUserKeysRequest) (*pb.GetUserKeysResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := g.rep.GetUserKeys(ctx, userUID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return keys, nil
+}
+
+// GetPublicKey метод получения публичного ключа пользователя по логину GaultService
+func (g *GaultService) GetPublicKey(ctx context.Context, req *pb.GetPublicKeyRequest) (*pb.GetPublicKeyResponse, error) {
+	if _, err := userUIDFromContext(ctx); err != nil {
+		return nil, err
+	}
+
+	key, err := g.rep.GetPublicKey(ctx, req.GetLogin())
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return key, nil
+}
+
+// ShareData метод выдачи доступа к данным GaultService
+func (g *GaultService) ShareData(ctx context.Context, req *pb.ShareDataRequest) (*pb.ShareDataResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.GetRecipientUid() == userUID:
+		return nil, status.Error(codes.InvalidArgument, "can not share data with yourself")
+	case req.GetPermission() != pb.SharePermission_SHARE_PERMISSION_READ &&
+		req.GetPermission() != pb.SharePermission_SHARE_PERMISSION_READ_WRITE:
+		return nil, status.Error(codes.InvalidArgument, "unknown share permission")
+	}
+
+	err = g.rep.ShareData(ctx, userUID, req.GetDataId(), req.GetRecipientUid(), req.GetPermission(), req.GetWrappedKey())
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.ShareDataResponse{}, nil
+}
+
+// UnshareData метод отзыва доступа к данным GaultService
+func (g *GaultService) UnshareData(ctx context.Context, req *pb.UnshareDataRequest) (*pb.UnshareDataResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.UnshareData(ctx, userUID, req.GetDataId(), req.GetRecipientUid()); err != nil {
+		return nil, shareError(err)
+	}
+	return &pb.UnshareDataResponse{}, nil
+}
+
+// ListShares метод получения выданных доступов к данным GaultService
+func (g *GaultService) ListShares(ctx context.Context, req *pb.ListSharesRequest) (*pb.ListSharesResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := g.rep.ListShares(ctx, userUID, req.GetDataId())
+	if err != nil {
+		return nil, shareError(err)
+	}
+	return grants, nil
+}
+
+// ListSharedWithMe метод получения данных, доступных пользователю GaultService
+func (g *GaultService) ListSharedWithMe(ctx context.Context, _ *pb.ListSharedWithMeRequest) (*pb.ListSharedWithMeResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := g.rep.ListSharedWithMe(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// canWrite пользователь владеет данными или получил доступ на изменение
+func canWrite(access *pb.GetDataResponse) bool {
+	return access.GetPermission() == pb.SharePermission_SHARE_PERMISSION_UNSPECIFIED ||
+		access.GetPermission() == pb.SharePermission_SHARE_PERMISSION_READ_WRITE
+}
+
+// isOwner пользователь владеет данными
+func isOwner(access *pb.GetDataResponse) bool {
+	return access.GetPermission() == pb.SharePermission_SHARE_PERMISSION_UNSPECIFIED
+}
+
+// shareError перевод ошибок хранилища в статусы gRPC
+func shareError(err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
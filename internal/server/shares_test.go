@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_SetUserKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+	publicKey := make([]byte, publicKeySize)
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().SetUserKeys(ctx, "user-uid", publicKey, "encrypted").Return(nil)
+
+		resp, err := service.SetUserKeys(ctx, &pb.SetUserKeysRequest{PublicKey: publicKey, EncryptedPrivateKey: "encrypted"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("invalid key", func(t *testing.T) {
+		resp, err := service.SetUserKeys(ctx, &pb.SetUserKeysRequest{PublicKey: []byte("short"), EncryptedPrivateKey: "encrypted"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_GetUserKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().GetUserKeys(ctx, "user-uid").Return(&pb.GetUserKeysResponse{EncryptedPrivateKey: "encrypted"}, nil)
+
+		resp, err := service.GetUserKeys(ctx, &pb.GetUserKeysRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, "encrypted", resp.EncryptedPrivateKey)
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().GetUserKeys(ctx, "user-uid").Return(nil, db.ErrNotFound)
+
+		resp, err := service.GetUserKeys(ctx, &pb.GetUserKeysRequest{})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_ShareData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().ShareData(ctx, "user-uid", "data-id", "friend-uid", pb.SharePermission_SHARE_PERMISSION_READ, []byte("wrapped")).Return(nil)
+
+		resp, err := service.ShareData(ctx, &pb.ShareDataRequest{
+			DataId:       "data-id",
+			RecipientUid: "friend-uid",
+			Permission:   pb.SharePermission_SHARE_PERMISSION_READ,
+			WrappedKey:   []byte("wrapped"),
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("share with yourself", func(t *testing.T) {
+		resp, err := service.ShareData(ctx, &pb.ShareDataRequest{
+			DataId:       "data-id",
+			RecipientUid: "user-uid",
+			Permission:   pb.SharePermission_SHARE_PERMISSION_READ,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("unknown permission", func(t *testing.T) {
+		resp, err := service.ShareData(ctx, &pb.ShareDataRequest{DataId: "data-id", RecipientUid: "friend-uid"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("not owner", func(t *testing.T) {
+		repo.EXPECT().ShareData(ctx, "user-uid", "data-id", "friend-uid", pb.SharePermission_SHARE_PERMISSION_READ_WRITE, nil).Return(db.ErrNotFound)
+
+		resp, err := service.ShareData(ctx, &pb.ShareDataRequest{
+			DataId:       "data-id",
+			RecipientUid: "friend-uid",
+			Permission:   pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_UnshareData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().UnshareData(ctx, "user-uid", "data-id", "friend-uid").Return(nil)
+
+		resp, err := service.UnshareData(ctx, &pb.UnshareDataRequest{DataId: "data-id", RecipientUid: "friend-uid"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("not found", func(t *testing.T) {
+		repo.EXPECT().UnshareData(ctx, "user-uid", "data-id", "friend-uid").Return(db.ErrNotFound)
+
+		resp, err := service.UnshareData(ctx, &pb.UnshareDataRequest{DataId: "data-id", RecipientUid: "friend-uid"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("md error", func(t *testing.T) {
+		resp, err := service.UnshareData(context.Background(), &pb.UnshareDataRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_ListSharedWithMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().ListSharedWithMe(ctx, "user-uid").Return(&pb.ListSharedWithMeResponse{
+		Items: []*pb.SharedItem{{Id: "data-id", OwnerLogin: "alice"}},
+	}, nil)
+
+	resp, err := service.ListSharedWithMe(ctx, &pb.ListSharedWithMeRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "alice", resp.Items[0].OwnerLogin)
+}
+
+func TestGaultService_SharedDataAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	repo.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "friend-uid"))
+
+	t.Run("get shared data", func(t *testing.T) {
+		repo.EXPECT().GetDataAccess(ctx, "friend-uid", "data-id").Return(&pb.GetDataResponse{
+			SharedKey:  []byte("wrapped"),
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ,
+		}, nil)
+		repo.EXPECT().GetData(ctx, "data-id").Return(&pb.GetDataResponse{Type: "password"}, nil)
+
+		resp, err := service.GetData(ctx, &pb.GetDataRequest{Id: "data-id"})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("wrapped"), resp.SharedKey)
+		assert.Equal(t, pb.SharePermission_SHARE_PERMISSION_READ, resp.Permission)
+	})
+	t.Run("get data without access", func(t *testing.T) {
+		repo.EXPECT().GetDataAccess(ctx, "friend-uid", "data-id").Return(nil, db.ErrNotFound)
+
+		resp, err := service.GetData(ctx, &pb.GetDataRequest{Id: "data-id"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("delete shared data", func(t *testing.T) {
+		repo.EXPECT().GetDataAccess(ctx, "friend-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+		}, nil)
+
+		resp, err := service.DeleteData(ctx, &pb.DeleteDataRequest{Id: "data-id"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("update read only data", func(t *testing.T) {
+		stream := &mockUpdateDataServer{
+			ctx:  ctx,
+			reqs: []*pb.UpdateDataRequest{{DataUid: "data-id", UserUid: "friend-uid", Data: []byte("chunk")}},
+		}
+		repo.EXPECT().BeginTx(gomock.Any()).Return(newTestTx(t), nil)
+		repo.EXPECT().GetDataAccess(gomock.Any(), "friend-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ,
+		}, nil)
+
+		err := service.UpdateData(stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("recipient changes data key", func(t *testing.T) {
+		stream := &mockUpdateDataServer{
+			ctx:  ctx,
+			reqs: []*pb.UpdateDataRequest{{DataUid: "data-id", UserUid: "friend-uid", DataKey: "key", Data: []byte("chunk")}},
+		}
+		repo.EXPECT().BeginTx(gomock.Any()).Return(newTestTx(t), nil)
+		repo.EXPECT().GetDataAccess(gomock.Any(), "friend-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+		}, nil)
+
+		err := service.UpdateData(stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("owner sets data key", func(t *testing.T) {
+		stream := &mockUpdateDataServer{
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid")),
+			reqs: []*pb.UpdateDataRequest{{DataUid: "data-id", UserUid: "user-uid", DataKey: "key", Data: []byte("chunk")}},
+		}
+		mockTx := newTestTx(t)
+		repo.EXPECT().BeginTx(gomock.Any()).Return(mockTx, nil)
+		repo.EXPECT().GetDataAccess(gomock.Any(), "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
+		repo.EXPECT().SetDataKeyTx(gomock.Any(), mockTx, "data-id", "key").Return(nil)
+		repo.EXPECT().GetOidByItemID(gomock.Any(), "data-id").Return(0, db.ErrNotFound)
+
+		err := service.UpdateData(stream)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
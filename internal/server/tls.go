@@ -0,0 +1,180 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Пути к TLS-материалу сервера по умолчанию
+const (
+	defaultCertFile = "certs/server.crt"
+	defaultKeyFile  = "certs/server.key"
+	defaultCAFile   = "certs/ca.crt"
+)
+
+// certReloader сертификат сервера и CA клиентов, перечитываемые при изменении файлов
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	base     *tls.Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  [3]time.Time
+}
+
+// newCertReloader загрузка TLS-материала сервера. Ошибка при старте не даёт запустить сервер
+func newCertReloader(certFile, keyFile, caFile string, base *tls.Config) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile, base: base}
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err = r.load(modTimes); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// fileModTimes время изменения сертификата, ключа и CA
+func (r *certReloader) fileModTimes() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			if i == 2 {
+				return modTimes, fmt.Errorf("failed to read CA cert: %w", err)
+			}
+			return modTimes, fmt.Errorf("failed to load server cert/key: %w", err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// load чтение сертификата, ключа и CA с диска
+func (r *certReloader) load(modTimes [3]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load server cert/key: %w", err)
+	}
+
+	ca, err := os.ReadFile(r.caFile)
+	if err != nil {
+		return fmt.Errorf("failed to read CA cert: %w", err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(ca) {
+		return errors.New("failed to read CA cert: no certificates found")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert, r.clientCAs, r.modTimes = &cert, certPool, modTimes
+	return nil
+}
+
+// reloadIfChanged перечитывание файлов, если они изменились. При ошибке остаётся прежний материал,
+// попытка повторяется при следующем рукопожатии
+func (r *certReloader) reloadIfChanged() {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		logger.LogError("tls reload failed", zap.Error(err))
+		return
+	}
+
+	r.mu.RLock()
+	changed := modTimes != r.modTimes
+	r.mu.RUnlock()
+	if !changed {
+		return
+	}
+
+	if err = r.load(modTimes); err != nil {
+		logger.LogError("tls reload failed", zap.Error(err))
+		return
+	}
+	logger.LogInfo("tls certificates reloaded")
+}
+
+// configForClient конфигурация TLS для нового соединения с актуальными сертификатами
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	conf := r.base.Clone()
+	conf.Certificates = []tls.Certificate{*r.cert}
+	conf.ClientCAs = r.clientCAs
+	// gRPC требует согласования HTTP/2 через ALPN
+	conf.NextProtos = []string{"h2"}
+	return conf, nil
+}
+
+// serverCredentials учётные данные транспорта сервера по конфигурации
+func serverCredentials(conf config.Config) (credentials.TransportCredentials, error) {
+	clientAuth, err := clientAuthType(conf.MTLS.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.TLS.Insecure {
+		if clientAuth != tls.NoClientCert {
+			return nil, errors.New("mtls requires tls, disable tls.insecure")
+		}
+		logger.LogError("INSECURE MODE: TLS is disabled, traffic is sent in plaintext. Use only for local development")
+		return insecure.NewCredentials(), nil
+	}
+
+	minVersion, err := conf.TLS.Version()
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := conf.TLS.Ciphers()
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := newCertReloader(
+		orDefault(conf.TLS.CertFile, defaultCertFile),
+		orDefault(conf.TLS.KeyFile, defaultKeyFile),
+		orDefault(conf.TLS.CAFile, defaultCAFile),
+		&tls.Config{
+			ClientAuth:   clientAuth,
+			MinVersion:   minVersion,
+			CipherSuites: ciphers,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.LogInfo("tls configured",
+		zap.String("min_version", tls.VersionName(minVersion)), zap.Stringer("client_certificates", clientAuth))
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:         minVersion,
+		GetConfigForClient: reloader.configForClient,
+	}), nil
+}
+
+// orDefault значение или значение по умолчанию, если оно пустое
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+// dialTLS рукопожатие с сервером, возвращает предъявленный сервером сертификат
+func dialTLS(t *testing.T, port int) *x509.Certificate {
+	// Тестовый сертификат без SAN, сравнивается только сам сертификат
+	conn, err := tls.Dial("tcp", fmt.Sprintf("localhost:%d", port), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	assert.Equal(t, "h2", state.NegotiatedProtocol)
+	return state.PeerCertificates[0]
+}
+
+func TestRun_ReloadsCertificates(t *testing.T) {
+	writeTestCerts(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mockDB.NewMockRepository(ctrl)
+	repo.EXPECT().RefreshRotationDue(gomock.Any(), "").Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().PurgeExpiredTrash(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	repo.EXPECT().Ping(gomock.Any()).Return(nil).AnyTimes()
+
+	port := getFreePort(t)
+	go func() {
+		_ = Run(config.Config{Port: port}, repo)
+	}()
+	time.Sleep(300 * time.Millisecond)
+
+	before := dialTLS(t, port)
+
+	// Новый сертификат с более поздним временем изменения файлов
+	writeTestCerts(t)
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{"certs/server.crt", "certs/server.key", "certs/ca.crt"} {
+		assert.NoError(t, os.Chtimes(path, future, future))
+	}
+
+	after := dialTLS(t, port)
+	assert.False(t, before.Equal(after))
+}
+
+func TestCertReloader_KeepsCertificateOnBrokenFiles(t *testing.T) {
+	writeTestCerts(t)
+
+	reloader, err := newCertReloader(defaultCertFile, defaultKeyFile, defaultCAFile, &tls.Config{})
+	assert.NoError(t, err)
+	conf, err := reloader.configForClient(nil)
+	assert.NoError(t, err)
+	before := conf.Certificates[0].Certificate[0]
+
+	future := time.Now().Add(time.Minute)
+	assert.NoError(t, os.WriteFile(defaultKeyFile, []byte("broken"), 0600))
+	assert.NoError(t, os.Chtimes(defaultKeyFile, future, future))
+
+	conf, err = reloader.configForClient(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, before, conf.Certificates[0].Certificate[0])
+	assert.Equal(t, []string{"h2"}, conf.NextProtos)
+}
+
+func TestServerCredentials(t *testing.T) {
+	creds, err := serverCredentials(config.Config{TLS: config.TLS{Insecure: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+
+	_, err = serverCredentials(config.Config{TLS: config.TLS{Insecure: true}, MTLS: config.MTLS{Mode: "required"}})
+	assert.Error(t, err)
+
+	_, err = serverCredentials(config.Config{TLS: config.TLS{MinVersion: "1.0"}})
+	assert.Error(t, err)
+
+	_, err = serverCredentials(config.Config{MTLS: config.MTLS{Mode: "always"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+// serviceName имя сервиса в трассировке
+const serviceName = "gault-server"
+
+// newTracerProvider провайдер трассировки с экспортом спанов в OTLP коллектор.
+// Без conf.Endpoint трассировка выключена. Возвращённая функция отправляет оставшиеся спаны
+func newTracerProvider(ctx context.Context, conf config.Tracing) (trace.TracerProvider, func(context.Context) error, error) {
+	if conf.Endpoint == "" {
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(conf.Endpoint)}
+	if conf.Insecure {
+		options = append(options, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, options...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio(conf.SampleRatio)))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	logger.LogInfo("tracing: exporting spans", zap.String("endpoint", conf.Endpoint))
+	return provider, provider.Shutdown, nil
+}
+
+// sampleRatio доля трассируемых запросов, значения вне (0, 1] означают все запросы
+func sampleRatio(ratio float64) float64 {
+	if ratio <= 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
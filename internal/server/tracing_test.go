@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fngoc/gault/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	provider, shutdown, err := newTracerProvider(context.Background(), config.Tracing{})
+	require.NoError(t, err)
+	assert.IsType(t, noop.TracerProvider{}, provider)
+	assert.NoError(t, shutdown(context.Background()))
+
+	// Экспортёр подключается к коллектору лениво, при отправке спанов
+	provider, shutdown, err = newTracerProvider(context.Background(), config.Tracing{Endpoint: "127.0.0.1:4317", Insecure: true})
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, provider)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSampleRatio(t *testing.T) {
+	assert.Equal(t, 1.0, sampleRatio(0))
+	assert.Equal(t, 1.0, sampleRatio(1.5))
+	assert.Equal(t, 0.25, sampleRatio(0.25))
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+const (
+	// defaultTrashRetention срок хранения данных в корзине по умолчанию
+	defaultTrashRetention = 30 * 24 * time.Hour
+	// defaultTrashPurgeInterval интервал очистки корзины по умолчанию
+	defaultTrashPurgeInterval = time.Hour
+)
+
+// trashRetention срок хранения данных в корзине до окончательного удаления
+var trashRetention = defaultTrashRetention
+
+func setTrash(conf config.Trash) {
+	trashRetention = conf.Retention
+	if trashRetention <= 0 {
+		trashRetention = defaultTrashRetention
+	}
+}
+
+// ListTrash метод получения содержимого личной корзины или корзины организации GaultService
+func (g *GaultService) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetOrgId() != "" {
+		// Видеть корзину организации может тот, кто может удалять её данные
+		if _, err := g.requireOrgRole(ctx, userUID, req.GetOrgId(), pb.OrgRole_ORG_ROLE_MEMBER); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := g.rep.ListTrash(ctx, userUID, req.GetOrgId())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range resp.GetItems() {
+		item.PurgeAt = time.Unix(item.GetDeletedAt(), 0).Add(trashRetention).Unix()
+	}
+	return resp, nil
+}
+
+// RestoreData метод восстановления данных из корзины GaultService
+func (g *GaultService) RestoreData(ctx context.Context, req *pb.RestoreDataRequest) (*pb.RestoreDataResponse, error) {
+	userUID, err := g.trashAccess(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.RestoreData(ctx, req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_RESTORE, DataId: req.GetId()})
+	return &pb.RestoreDataResponse{}, nil
+}
+
+// PurgeData метод окончательного удаления данных из корзины GaultService
+func (g *GaultService) PurgeData(ctx context.Context, req *pb.PurgeDataRequest) (*pb.PurgeDataResponse, error) {
+	userUID, err := g.trashAccess(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.rep.PurgeData(ctx, req.GetId()); err != nil {
+		return nil, shareError(err)
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_ITEM_PURGE, DataId: req.GetId()})
+	return &pb.PurgeDataResponse{}, nil
+}
+
+// trashAccess проверка права на восстановление и удаление данных из корзины, те же права, что и на удаление
+func (g *GaultService) trashAccess(ctx context.Context, dataID string) (string, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	access, err := g.rep.GetTrashAccess(ctx, userUID, dataID)
+	if err != nil {
+		return "", shareError(err)
+	}
+	if !canDelete(access) {
+		return "", status.Error(codes.PermissionDenied, "only owner can manage trash")
+	}
+	return userUID, nil
+}
+
+// runTrashPurger окончательное удаление данных с истёкшим сроком хранения при запуске и далее каждые interval до отмены ctx
+func runTrashPurger(ctx context.Context, rep db.Repository, retention, interval time.Duration) {
+	if retention <= 0 {
+		retention = defaultTrashRetention
+	}
+	if interval <= 0 {
+		interval = defaultTrashPurgeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := rep.PurgeExpiredTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.LogError("trash purge failed", zap.Error(err))
+		} else if purged > 0 {
+			logger.LogInfo("trash purge", zap.Int64("removed", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/config"
+	"github.com/fngoc/gault/internal/db"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+func TestGaultService_ListTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+	setTrash(config.Trash{Retention: 48 * time.Hour})
+	defer setTrash(config.Trash{})
+
+	t.Run("personal", func(t *testing.T) {
+		repo.EXPECT().ListTrash(ctx, "user-uid", "").Return(&pb.ListTrashResponse{
+			Items: []*pb.TrashItem{{Id: "data-id", DeletedAt: 1700000000}},
+		}, nil)
+
+		resp, err := service.ListTrash(ctx, &pb.ListTrashRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1700000000+2*24*60*60), resp.GetItems()[0].GetPurgeAt())
+	})
+	t.Run("org read only", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_READ_ONLY, nil)
+
+		resp, err := service.ListTrash(ctx, &pb.ListTrashRequest{OrgId: "org-id"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("org member", func(t *testing.T) {
+		repo.EXPECT().GetOrgRole(ctx, "user-uid", "org-id").Return(pb.OrgRole_ORG_ROLE_MEMBER, nil)
+		repo.EXPECT().ListTrash(ctx, "user-uid", "org-id").Return(&pb.ListTrashResponse{}, nil)
+
+		resp, err := service.ListTrash(ctx, &pb.ListTrashRequest{OrgId: "org-id"})
+		assert.NoError(t, err)
+		assert.Empty(t, resp.GetItems())
+	})
+	t.Run("md error", func(t *testing.T) {
+		_, err := service.ListTrash(context.Background(), &pb.ListTrashRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestGaultService_RestoreData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().GetTrashAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
+		repo.EXPECT().RestoreData(ctx, "data-id").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_ITEM_RESTORE,
+			DataId: "data-id",
+		}).Return(nil)
+
+		resp, err := service.RestoreData(ctx, &pb.RestoreDataRequest{Id: "data-id"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("read only org member", func(t *testing.T) {
+		repo.EXPECT().GetTrashAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ,
+			OrgRole:    pb.OrgRole_ORG_ROLE_READ_ONLY,
+		}, nil)
+
+		resp, err := service.RestoreData(ctx, &pb.RestoreDataRequest{Id: "data-id"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("not in trash", func(t *testing.T) {
+		repo.EXPECT().GetTrashAccess(ctx, "user-uid", "data-id").Return(nil, db.ErrNotFound)
+
+		resp, err := service.RestoreData(ctx, &pb.RestoreDataRequest{Id: "data-id"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_PurgeData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("success", func(t *testing.T) {
+		repo.EXPECT().GetTrashAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{
+			Permission: pb.SharePermission_SHARE_PERMISSION_READ_WRITE,
+			OrgRole:    pb.OrgRole_ORG_ROLE_ADMIN,
+		}, nil)
+		repo.EXPECT().PurgeData(ctx, "data-id").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_ITEM_PURGE,
+			DataId: "data-id",
+		}).Return(nil)
+
+		resp, err := service.PurgeData(ctx, &pb.PurgeDataRequest{Id: "data-id"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("error", func(t *testing.T) {
+		repo.EXPECT().GetTrashAccess(ctx, "user-uid", "data-id").Return(&pb.GetDataResponse{}, nil)
+		repo.EXPECT().PurgeData(ctx, "data-id").Return(errors.New("lo error"))
+
+		resp, err := service.PurgeData(ctx, &pb.PurgeDataRequest{Id: "data-id"})
+		assert.Error(t, err)
+		assert.Nil(t, resp)
+	})
+}
+
+func TestRunTrashPurger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	cutoffs := make(chan time.Time, 10)
+	repo.EXPECT().PurgeExpiredTrash(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+		cutoffs <- before
+		return 0, errors.New("db is down")
+	}).MinTimes(2)
+
+	done := make(chan struct{})
+	go func() {
+		runTrashPurger(ctx, repo, time.Hour, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// Очистка при запуске и по таймеру, ошибка не останавливает очистку
+	before := <-cutoffs
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Minute)
+	<-cutoffs
+	cancel()
+	<-done
+}
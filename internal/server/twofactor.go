@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+)
+
+const (
+	// totpIssuer название сервиса в приложении-аутентификаторе
+	totpIssuer = "Gault"
+	// recoveryCodesCount количество выдаваемых кодов восстановления
+	recoveryCodesCount = 10
+	// maxChallengeAttempts число попыток ввода кода на один вход по паролю
+	maxChallengeAttempts = 5
+)
+
+// timeNow текущее время, подменяется в тестах
+var timeNow = time.Now
+
+// VerifyTwoFactor метод второго шага авторизации GaultService
+func (g *GaultService) VerifyTwoFactor(ctx context.Context, req *pb.VerifyTwoFactorRequest) (*pb.VerifyTwoFactorResponse, error) {
+	userUID, attempts, err := g.rep.CheckLoginChallenge(ctx, req.GetChallengeToken())
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Error(codes.Unauthenticated, "login challenge is expired, login again")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attempts > maxChallengeAttempts {
+		if err := g.rep.DeleteLoginChallenge(ctx, req.GetChallengeToken()); err != nil {
+			return nil, err
+		}
+		return nil, status.Error(codes.Unauthenticated, "too many attempts, login again")
+	}
+
+	ok, err := g.verifySecondFactor(ctx, userUID, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_LOGIN_FAILURE})
+		return nil, status.Error(codes.Unauthenticated, "invalid code")
+	}
+
+	if err := g.rep.DeleteLoginChallenge(ctx, req.GetChallengeToken()); err != nil {
+		return nil, err
+	}
+	token, err := g.rep.CreateSession(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_LOGIN_SUCCESS})
+
+	return &pb.VerifyTwoFactorResponse{Token: token, UserUid: userUID}, nil
+}
+
+// GetTwoFactorStatus метод получения состояния 2FA GaultService
+func (g *GaultService) GetTwoFactorStatus(ctx context.Context, _ *pb.GetTwoFactorStatusRequest) (*pb.GetTwoFactorStatusResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, enabled, err := g.rep.GetTwoFactor(ctx, userUID)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && !enabled) {
+		return &pb.GetTwoFactorStatusResponse{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	left, err := g.rep.CountRecoveryCodes(ctx, userUID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTwoFactorStatusResponse{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// EnrollTwoFactor метод создания секрета TOTP GaultService
+func (g *GaultService) EnrollTwoFactor(ctx context.Context, _ *pb.EnrollTwoFactorRequest) (*pb.EnrollTwoFactorResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, enabled, err := g.rep.GetTwoFactor(ctx, userUID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	login, err := g.rep.GetUserLogin(ctx, userUID)
+	if err != nil {
+		return nil, shareError(err)
+	}
+	secret, uri, err := utils.GenerateTOTPSecret(totpIssuer, login)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if err := g.rep.SetTwoFactorSecret(ctx, userUID, secret); err != nil {
+		return nil, err
+	}
+	return &pb.EnrollTwoFactorResponse{Secret: secret, OtpauthUri: uri}, nil
+}
+
+// ConfirmTwoFactor метод включения 2FA по первому коду из приложения GaultService
+func (g *GaultService) ConfirmTwoFactor(ctx context.Context, req *pb.ConfirmTwoFactorRequest) (*pb.ConfirmTwoFactorResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, enabled, err := g.rep.GetTwoFactor(ctx, userUID)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	ok, err := g.verifyTOTP(ctx, userUID, secret, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.rep.EnableTwoFactor(ctx, userUID, hashes); err != nil {
+		return nil, err
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_TWO_FACTOR_ENABLED})
+
+	return &pb.ConfirmTwoFactorResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// DisableTwoFactor метод отключения 2FA GaultService
+func (g *GaultService) DisableTwoFactor(ctx context.Context, req *pb.DisableTwoFactorRequest) (*pb.DisableTwoFactorResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := g.verifySecondFactor(ctx, userUID, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	if err := g.rep.DisableTwoFactor(ctx, userUID); err != nil {
+		return nil, err
+	}
+	g.audit(ctx, userUID, &pb.AuditEvent{Action: pb.AuditAction_AUDIT_ACTION_TWO_FACTOR_DISABLED})
+
+	return &pb.DisableTwoFactorResponse{}, nil
+}
+
+// RegenerateRecoveryCodes метод выпуска новых кодов восстановления GaultService
+func (g *GaultService) RegenerateRecoveryCodes(ctx context.Context, req *pb.RegenerateRecoveryCodesRequest) (*pb.RegenerateRecoveryCodesResponse, error) {
+	userUID, err := userUIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := g.verifySecondFactor(ctx, userUID, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid code")
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.rep.ReplaceRecoveryCodes(ctx, userUID, hashes); err != nil {
+		return nil, err
+	}
+	return &pb.RegenerateRecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// verifySecondFactor проверка кода TOTP или кода восстановления пользователя с включённой 2FA
+func (g *GaultService) verifySecondFactor(ctx context.Context, userUID, code string) (bool, error) {
+	secret, enabled, err := g.rep.GetTwoFactor(ctx, userUID)
+	if errors.Is(err, db.ErrNotFound) || (err == nil && !enabled) {
+		return false, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if isTOTPCode(code) {
+		return g.verifyTOTP(ctx, userUID, secret, code)
+	}
+	return g.rep.UseRecoveryCode(ctx, userUID, utils.HashRecoveryCode(code))
+}
+
+// verifyTOTP проверка кода TOTP. Каждый код принимается только один раз
+func (g *GaultService) verifyTOTP(ctx context.Context, userUID, secret, code string) (bool, error) {
+	step, ok := utils.ValidateTOTP(secret, code, timeNow())
+	if !ok {
+		return false, nil
+	}
+	return g.rep.UseTotpStep(ctx, userUID, step)
+}
+
+// isTOTPCode код из шести цифр считается кодом TOTP, остальные коды — кодами восстановления
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes создание кодов восстановления и их хешей для хранения
+func newRecoveryCodes() ([]string, []string, error) {
+	recoveryCodes, err := utils.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to generate recovery codes: %v", err)
+	}
+	hashes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		hashes = append(hashes, utils.HashRecoveryCode(code))
+	}
+	return recoveryCodes, hashes, nil
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fngoc/gault/internal/db"
+	"github.com/fngoc/gault/pkg/utils"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/fngoc/gault/gen/go/api/proto/v1"
+	mockDB "github.com/fngoc/gault/gen/go/db"
+)
+
+// testTOTPSecret секрет TOTP для тестов
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// setupTestTime фиксация текущего времени на время теста
+func setupTestTime(t *testing.T) time.Time {
+	now := time.Unix(1700000000, 0)
+	old := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = old })
+	return now
+}
+
+func TestGaultService_LoginTwoFactorRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := context.Background()
+
+	repo.EXPECT().UpdateSessionUser(ctx, "alice", "password").Return("user-uid", "", db.ErrTwoFactorRequired)
+	repo.EXPECT().CreateLoginChallenge(ctx, "user-uid").Return("challenge", nil)
+
+	resp, err := service.Login(ctx, &pb.LoginRequest{Login: "alice", Password: "password"})
+	assert.NoError(t, err)
+	assert.True(t, resp.TwoFactorRequired)
+	assert.Equal(t, "challenge", resp.ChallengeToken)
+	assert.Empty(t, resp.Token)
+	assert.Empty(t, resp.UserUid)
+}
+
+func TestGaultService_VerifyTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	repo.EXPECT().InsertAuditEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ctx := context.Background()
+	now := setupTestTime(t)
+	code, err := utils.TOTPCode(testTOTPSecret, now)
+	require.NoError(t, err)
+
+	t.Run("success with totp", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("user-uid", int32(1), nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseTotpStep(ctx, "user-uid", now.Unix()/30).Return(true, nil)
+		repo.EXPECT().DeleteLoginChallenge(ctx, "challenge").Return(nil)
+		repo.EXPECT().CreateSession(ctx, "user-uid").Return("token", nil)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: code})
+		assert.NoError(t, err)
+		assert.Equal(t, "token", resp.Token)
+		assert.Equal(t, "user-uid", resp.UserUid)
+	})
+	t.Run("success with recovery code", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("user-uid", int32(1), nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseRecoveryCode(ctx, "user-uid", utils.HashRecoveryCode("ABCDEFGH-IJKLMNOP")).Return(true, nil)
+		repo.EXPECT().DeleteLoginChallenge(ctx, "challenge").Return(nil)
+		repo.EXPECT().CreateSession(ctx, "user-uid").Return("token", nil)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: "abcdefgh-ijklmnop"})
+		assert.NoError(t, err)
+		assert.Equal(t, "token", resp.Token)
+	})
+	t.Run("replayed code", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("user-uid", int32(2), nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseTotpStep(ctx, "user-uid", now.Unix()/30).Return(false, nil)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: code})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("wrong code", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("user-uid", int32(2), nil)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: "000000"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("too many attempts", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("user-uid", int32(maxChallengeAttempts+1), nil)
+		repo.EXPECT().DeleteLoginChallenge(ctx, "challenge").Return(nil)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: code})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("expired challenge", func(t *testing.T) {
+		repo.EXPECT().CheckLoginChallenge(ctx, "challenge").Return("", int32(0), db.ErrNotFound)
+
+		resp, err := service.VerifyTwoFactor(ctx, &pb.VerifyTwoFactorRequest{ChallengeToken: "challenge", Code: code})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_EnrollAndConfirmTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+	now := setupTestTime(t)
+
+	t.Run("enroll", func(t *testing.T) {
+		var secret string
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+		repo.EXPECT().GetUserLogin(ctx, "user-uid").Return("alice", nil)
+		repo.EXPECT().SetTwoFactorSecret(ctx, "user-uid", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, s string) error {
+				secret = s
+				return nil
+			})
+
+		resp, err := service.EnrollTwoFactor(ctx, &pb.EnrollTwoFactorRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, secret, resp.Secret)
+		assert.Contains(t, resp.OtpauthUri, "otpauth://totp/Gault:alice")
+	})
+	t.Run("enroll when enabled", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+
+		resp, err := service.EnrollTwoFactor(ctx, &pb.EnrollTwoFactorRequest{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("confirm", func(t *testing.T) {
+		code, err := utils.TOTPCode(testTOTPSecret, now)
+		require.NoError(t, err)
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, false, nil)
+		repo.EXPECT().UseTotpStep(ctx, "user-uid", now.Unix()/30).Return(true, nil)
+		var hashes []string
+		repo.EXPECT().EnableTwoFactor(ctx, "user-uid", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, h []string) error {
+				hashes = h
+				return nil
+			})
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_TWO_FACTOR_ENABLED,
+		}).Return(nil)
+
+		resp, err := service.ConfirmTwoFactor(ctx, &pb.ConfirmTwoFactorRequest{Code: code})
+		assert.NoError(t, err)
+		assert.Len(t, resp.RecoveryCodes, recoveryCodesCount)
+		require.Len(t, hashes, recoveryCodesCount)
+		// Сервер хранит только хеши кодов восстановления
+		assert.Equal(t, utils.HashRecoveryCode(resp.RecoveryCodes[0]), hashes[0])
+		assert.NotContains(t, hashes, resp.RecoveryCodes[0])
+	})
+	t.Run("confirm invalid code", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, false, nil)
+
+		resp, err := service.ConfirmTwoFactor(ctx, &pb.ConfirmTwoFactorRequest{Code: "000000"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("confirm without enroll", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+
+		resp, err := service.ConfirmTwoFactor(ctx, &pb.ConfirmTwoFactorRequest{Code: "000000"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Nil(t, resp)
+	})
+}
+
+func TestGaultService_DisableTwoFactorAndRegenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	t.Run("disable with recovery code", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseRecoveryCode(ctx, "user-uid", utils.HashRecoveryCode("ABCDEFGH-IJKLMNOP")).Return(true, nil)
+		repo.EXPECT().DisableTwoFactor(ctx, "user-uid").Return(nil)
+		repo.EXPECT().InsertAuditEvent(ctx, "user-uid", &pb.AuditEvent{
+			Action: pb.AuditAction_AUDIT_ACTION_TWO_FACTOR_DISABLED,
+		}).Return(nil)
+
+		resp, err := service.DisableTwoFactor(ctx, &pb.DisableTwoFactorRequest{Code: "ABCDEFGH-IJKLMNOP"})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+	t.Run("disable with used recovery code", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseRecoveryCode(ctx, "user-uid", gomock.Any()).Return(false, nil)
+
+		resp, err := service.DisableTwoFactor(ctx, &pb.DisableTwoFactorRequest{Code: "ABCDEFGH-IJKLMNOP"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("disable when not enabled", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+
+		resp, err := service.DisableTwoFactor(ctx, &pb.DisableTwoFactorRequest{Code: "123456"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Nil(t, resp)
+	})
+	t.Run("regenerate", func(t *testing.T) {
+		repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+		repo.EXPECT().UseRecoveryCode(ctx, "user-uid", gomock.Any()).Return(true, nil)
+		repo.EXPECT().ReplaceRecoveryCodes(ctx, "user-uid", gomock.Len(recoveryCodesCount)).Return(nil)
+
+		resp, err := service.RegenerateRecoveryCodes(ctx, &pb.RegenerateRecoveryCodesRequest{Code: "ABCDEFGH-IJKLMNOP"})
+		assert.NoError(t, err)
+		assert.Len(t, resp.RecoveryCodes, recoveryCodesCount)
+	})
+}
+
+func TestGaultService_GetTwoFactorStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mockDB.NewMockRepository(ctrl)
+	service := &GaultService{rep: repo}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("useruid", "user-uid"))
+
+	repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return("", false, db.ErrNotFound)
+	resp, err := service.GetTwoFactorStatus(ctx, &pb.GetTwoFactorStatusRequest{})
+	assert.NoError(t, err)
+	assert.False(t, resp.Enabled)
+
+	repo.EXPECT().GetTwoFactor(ctx, "user-uid").Return(testTOTPSecret, true, nil)
+	repo.EXPECT().CountRecoveryCodes(ctx, "user-uid").Return(int64(7), nil)
+	resp, err = service.GetTwoFactorStatus(ctx, &pb.GetTwoFactorStatusRequest{})
+	assert.NoError(t, err)
+	assert.True(t, resp.Enabled)
+	assert.Equal(t, int64(7), resp.RecoveryCodesLeft)
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	assert.True(t, isTOTPCode("123456"))
+	assert.False(t, isTOTPCode("12345"))
+	assert.False(t, isTOTPCode("12345a"))
+	assert.False(t, isTOTPCode("ABCDEFGH-IJKLMNOP"))
+}
@@ -1,43 +1,208 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // Log будет доступен всему коду как синглтон
 // По умолчанию установлен no-op-логер, который не выводит никаких сообщений
 var log = zap.NewNop()
 
-// loglevel уровень логирования по умолчанию
-const loglevel = "INFO"
+// closeOutput закрывает файл вывода текущего логера
+var closeOutput = func() {}
+
+// Options настройки логера
+type Options struct {
+	// Level уровень логирования: debug, info, warn или error, пусто — info
+	Level string
+	// Format формат записей: json или console, пусто — json
+	Format string
+	// Output путь к файлу логов, пусто — stderr
+	Output string
+	// SamplingInitial сколько одинаковых записей в секунду пишется полностью, 0 — без выборки
+	SamplingInitial int
+	// SamplingThereafter после превышения SamplingInitial пишется каждая N-я запись
+	SamplingThereafter int
+}
+
+// defaultOptions настройки до чтения конфигурации
+var defaultOptions = Options{Level: "info", Format: "json", SamplingInitial: 100, SamplingThereafter: 100}
 
-// NewLogger инициализирует синглтон логера с необходимым уровнем логирования
+// NewLogger инициализирует синглтон логера с настройками по умолчанию
 func NewLogger() error {
-	lvl, err := zap.ParseAtomicLevel(loglevel)
+	return Configure(defaultOptions)
+}
+
+// Configure пересоздаёт синглтон логера по настройкам из конфигурации
+func Configure(opts Options) error {
+	zl, closeFn, err := build(opts)
 	if err != nil {
 		return err
 	}
-	cfg := zap.NewProductionConfig()
-	cfg.Level = lvl
-	zl, err := cfg.Build()
-	if err != nil {
+
+	prev, prevClose := log, closeOutput
+	log, closeOutput = zl, closeFn
+
+	_ = prev.Sync()
+	prevClose()
+	return nil
+}
+
+// Sync сбрасывает буферы логера, вызывается перед завершением программы
+func Sync() error {
+	return log.Sync()
+}
+
+// Validate проверка уровня и формата без создания логера
+func (o Options) Validate() error {
+	if _, err := o.level(); err != nil {
 		return err
 	}
-	log = zl
-	return nil
+	switch o.Format {
+	case "", "json", "console":
+		return nil
+	default:
+		return fmt.Errorf("unsupported log format %q", o.Format)
+	}
+}
+
+// level уровень логирования, пусто — info
+func (o Options) level() (zapcore.Level, error) {
+	if o.Level == "" {
+		return zapcore.InfoLevel, nil
+	}
+	level, err := zapcore.ParseLevel(o.Level)
+	if err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", o.Level, err)
+	}
+	return level, nil
+}
+
+// build собирает логер: кодировщик, вывод, маскирование секретов и выборку
+func build(opts Options) (*zap.Logger, func(), error) {
+	level, err := opts.level()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	switch opts.Format {
+	case "", "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, nil, fmt.Errorf("unsupported log format %q", opts.Format)
+	}
+
+	output, closeFn := zapcore.Lock(os.Stderr), func() {}
+	if opts.Output != "" {
+		file, err := os.OpenFile(opts.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+		output, closeFn = zapcore.Lock(file), func() { _ = file.Close() }
+	}
+
+	var core zapcore.Core = redactCore{zapcore.NewCore(encoder, output, level)}
+	if opts.SamplingInitial > 0 {
+		thereafter := opts.SamplingThereafter
+		if thereafter <= 0 {
+			thereafter = opts.SamplingInitial
+		}
+		core = zapcore.NewSamplerWithOptions(core, time.Second, opts.SamplingInitial, thereafter)
+	}
+
+	zl := zap.New(core,
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+	)
+	return zl, closeFn, nil
+}
+
+// fieldsKey ключ полей логера в контексте
+type fieldsKey struct{}
+
+// WithFields контекст с полями, которые добавляются к каждой записи *Ctx-функций,
+// например идентификатор запроса
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := contextFields(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// contextFields поля логера из контекста
+func contextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// withContext поля контекста перед полями записи
+func withContext(ctx context.Context, fields []zap.Field) []zap.Field {
+	ctxFields := contextFields(ctx)
+	if len(ctxFields) == 0 {
+		return fields
+	}
+	return append(append(make([]zap.Field, 0, len(ctxFields)+len(fields)), ctxFields...), fields...)
+}
+
+// LogDebug логирование с уровнем debug
+func LogDebug(msg string, fields ...zap.Field) {
+	log.Debug(msg, fields...)
 }
 
 // LogInfo логирование с уровнем info
-func LogInfo(msg string) {
-	log.Info(msg)
+func LogInfo(msg string, fields ...zap.Field) {
+	log.Info(msg, fields...)
+}
+
+// LogWarn логирование с уровнем warn
+func LogWarn(msg string, fields ...zap.Field) {
+	log.Warn(msg, fields...)
 }
 
 // LogError логирование с уровнем error
-func LogError(msg string) {
-	log.Error(msg)
+func LogError(msg string, fields ...zap.Field) {
+	log.Error(msg, fields...)
 }
 
 // LogFatal логирование с завершением программы
-func LogFatal(msg string) {
-	log.Fatal(msg)
+func LogFatal(msg string, fields ...zap.Field) {
+	log.Fatal(msg, fields...)
+}
+
+// LogDebugCtx логирование с уровнем debug и полями из контекста
+func LogDebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	log.Debug(msg, withContext(ctx, fields)...)
+}
+
+// LogInfoCtx логирование с уровнем info и полями из контекста
+func LogInfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	log.Info(msg, withContext(ctx, fields)...)
+}
+
+// LogWarnCtx логирование с уровнем warn и полями из контекста
+func LogWarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	log.Warn(msg, withContext(ctx, fields)...)
+}
+
+// LogErrorCtx логирование с уровнем error и полями из контекста
+func LogErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	log.Error(msg, withContext(ctx, fields)...)
 }
@@ -1,9 +1,17 @@
 package logger
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestInitialize(t *testing.T) {
@@ -22,3 +30,91 @@ func TestLogError(t *testing.T) {
 	assert.NoError(t, err)
 	LogError("test_message")
 }
+
+// readEntries записи JSON-лога из файла
+func readEntries(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	require.NoError(t, Sync())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestConfigure_LevelAndFields(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "gault.log")
+	require.NoError(t, Configure(Options{Level: "warn", Output: output}))
+	t.Cleanup(func() { _ = NewLogger() })
+
+	LogInfo("skipped")
+	LogWarn("kept", zap.Int("attempt", 3))
+
+	entries := readEntries(t, output)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "kept", entries[0]["msg"])
+	assert.Equal(t, "warn", entries[0]["level"])
+	assert.EqualValues(t, 3, entries[0]["attempt"])
+	assert.Contains(t, entries[0]["caller"], "logger_test.go")
+}
+
+func TestConfigure_Console(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "gault.log")
+	require.NoError(t, Configure(Options{Format: "console", Output: output}))
+	t.Cleanup(func() { _ = NewLogger() })
+
+	LogInfo("console message", zap.String("user", "alice"))
+	require.NoError(t, Sync())
+
+	raw, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "INFO")
+	assert.Contains(t, string(raw), "console message")
+	assert.Contains(t, string(raw), `"user": "alice"`)
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	assert.Error(t, Configure(Options{Level: "verbose"}))
+	assert.Error(t, Configure(Options{Format: "xml"}))
+	assert.Error(t, Configure(Options{Output: filepath.Join(t.TempDir(), "missing", "gault.log")}))
+}
+
+func TestConfigure_Sampling(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "gault.log")
+	require.NoError(t, Configure(Options{Output: output, SamplingInitial: 2, SamplingThereafter: 100}))
+	t.Cleanup(func() { _ = NewLogger() })
+
+	for i := 0; i < 10; i++ {
+		LogInfo("repeated")
+	}
+	assert.Len(t, readEntries(t, output), 2)
+}
+
+func TestLogCtx_ContextFields(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "gault.log")
+	require.NoError(t, Configure(Options{Level: "debug", Output: output}))
+	t.Cleanup(func() { _ = NewLogger() })
+
+	ctx := WithFields(context.Background(), zap.String("request_id", "req-1"))
+	ctx = WithFields(ctx, zap.String("user", "alice"))
+	LogInfoCtx(ctx, "with context", zap.String("step", "save"))
+	LogDebugCtx(ctx, "debug")
+	LogErrorCtx(context.Background(), "without context", zap.Error(errors.New("boom")))
+
+	entries := readEntries(t, output)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, "alice", entries[0]["user"])
+	assert.Equal(t, "save", entries[0]["step"])
+	assert.Equal(t, "req-1", entries[1]["request_id"])
+	assert.NotContains(t, entries[2], "request_id")
+	assert.Equal(t, "boom", entries[2]["error"])
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redacted значение, которым заменяются секреты
+const redacted = "[REDACTED]"
+
+// sensitiveKeys фрагменты имён полей с секретами, сравниваются без регистра, '_' и '-'
+var sensitiveKeys = []string{
+	"token", "password", "passwd", "secret", "authorization", "cookie",
+	"privatekey", "apikey", "datakey", "recoverycode",
+}
+
+// sensitiveNames короткие имена полей с секретами, совпадающие целиком
+var sensitiveNames = map[string]bool{"aes": true, "key": true, "otp": true}
+
+// sensitivePattern пары вида "token=значение" и "password: значение" внутри текста
+var sensitivePattern = regexp.MustCompile(
+	`(?i)\b(token|password|passwd|secret|authorization|aes|api[_-]?key)(\s*[=:]\s*)((?:bearer\s+)?(?:"[^"]*"|[^\s,;&]+))`)
+
+// bearerPattern заголовок авторизации вида "Bearer <токен>"
+var bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[^\s,;]+`)
+
+// redactCore обёртка ядра zap, маскирующая секреты в сообщениях и полях
+type redactCore struct {
+	zapcore.Core
+}
+
+// With добавляет к ядру замаскированные поля
+func (c redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactCore{c.Core.With(redactFields(fields))}
+}
+
+// Check регистрирует обёртку, чтобы запись прошла через Write с маскированием
+func (c redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write маскирует секреты и передаёт запись ядру
+func (c redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// Redact заменяет в тексте значения токенов, паролей и ключей на [REDACTED]
+func Redact(text string) string {
+	text = sensitivePattern.ReplaceAllString(text, "${1}${2}"+redacted)
+	return bearerPattern.ReplaceAllString(text, "Bearer "+redacted)
+}
+
+// redactFields копия полей, в которой секреты заменены на [REDACTED]
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, field := range fields {
+		replaced, changed := redactField(field)
+		if !changed {
+			if out != nil {
+				out = append(out, field)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, replaced)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactField маскирует поле с секретным именем или строку с секретом в значении
+func redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if isSensitiveKey(field.Key) {
+		return zap.String(field.Key, redacted), true
+	}
+	switch field.Type {
+	case zapcore.StringType:
+		if masked := Redact(field.String); masked != field.String {
+			return zap.String(field.Key, masked), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			if msg := err.Error(); Redact(msg) != msg {
+				return zap.String(field.Key, Redact(msg)), true
+			}
+		}
+	}
+	return field, false
+}
+
+// isSensitiveKey имя поля указывает на секрет
+func isSensitiveKey(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	if sensitiveNames[normalized] {
+		return true
+	}
+	for _, fragment := range sensitiveKeys {
+		if strings.Contains(normalized, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"token pair", "login ok token=abc.def user=alice", "login ok token=[REDACTED] user=alice"},
+		{"password colon", `password: "hunter2", remember`, "password: [REDACTED], remember"},
+		{"authorization", "Authorization=Bearer xyz", "Authorization=[REDACTED]"},
+		{"bearer", "header bearer eyJhbGciOi", "header Bearer [REDACTED]"},
+		{"plain", "SaveData: starting transaction", "SaveData: starting transaction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redact(tt.in))
+		})
+	}
+}
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, key := range []string{"token", "session_token", "Authorization", "password", "newPassword", "aes", "data_key", "private-key", "otp"} {
+		assert.True(t, isSensitiveKey(key), key)
+	}
+	for _, key := range []string{"user", "request_id", "method", "oid", "keyboard", "status"} {
+		assert.False(t, isSensitiveKey(key), key)
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "gault.log")
+	require.NoError(t, Configure(Options{Output: output}))
+	t.Cleanup(func() { _ = NewLogger() })
+
+	LogInfo("check token=secret-value",
+		zap.String("authorization", "session-token"),
+		zap.String("user", "alice"),
+		zap.String("detail", "password=hunter2"),
+		zap.Error(errors.New("bad aes=0123456789")),
+	)
+
+	entries := readEntries(t, output)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "check token=[REDACTED]", entry["msg"])
+	assert.Equal(t, "[REDACTED]", entry["authorization"])
+	assert.Equal(t, "alice", entry["user"])
+	assert.Equal(t, "password=[REDACTED]", entry["detail"])
+	assert.Equal(t, "bad aes=[REDACTED]", entry["error"])
+}
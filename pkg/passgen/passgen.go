@@ -0,0 +1,166 @@
+// Package passgen генератор паролей и парольных фраз и оценка надёжности пароля
+package passgen
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Наборы символов
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!@#$%^&*()-_=+[]{};:,.<>/?~"
+	// ambiguousChars символы, которые легко перепутать при чтении
+	ambiguousChars = "Il1O0o|`'\""
+)
+
+// Ограничения параметров генерации
+const (
+	MinLength = 4
+	MaxLength = 128
+	MinWords  = 3
+	MaxWords  = 20
+)
+
+//go:embed wordlist.txt
+var wordlistData string
+
+// wordlist слова для парольных фраз
+var wordlist = strings.Fields(wordlistData)
+
+var (
+	// ErrNoCharset не выбран ни один набор символов
+	ErrNoCharset = errors.New("at least one character class is required")
+	// ErrInvalidLength длина пароля или число слов вне допустимого диапазона
+	ErrInvalidLength = errors.New("invalid length")
+)
+
+// Options параметры генерации пароля
+type Options struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+}
+
+// DefaultOptions параметры генерации по умолчанию
+var DefaultOptions = Options{
+	Length:           20,
+	Lower:            true,
+	Upper:            true,
+	Digits:           true,
+	Symbols:          true,
+	ExcludeAmbiguous: true,
+}
+
+// DefaultWords число слов парольной фразы по умолчанию
+const DefaultWords = 6
+
+// переменная для замены в тестах
+var randomInt = func(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+// Generate создаёт случайный пароль, в котором есть хотя бы один символ каждого выбранного набора
+func Generate(opts Options) (string, error) {
+	if opts.Length < MinLength || opts.Length > MaxLength {
+		return "", fmt.Errorf("%w: length must be between %d and %d", ErrInvalidLength, MinLength, MaxLength)
+	}
+
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{opts.Lower, lowerChars},
+		{opts.Upper, upperChars},
+		{opts.Digits, digitChars},
+		{opts.Symbols, symbolChars},
+	} {
+		if !class.enabled {
+			continue
+		}
+		chars := class.chars
+		if opts.ExcludeAmbiguous {
+			chars = removeChars(chars, ambiguousChars)
+		}
+		classes = append(classes, chars)
+	}
+	if len(classes) == 0 {
+		return "", ErrNoCharset
+	}
+
+	password := make([]byte, 0, opts.Length)
+	for _, chars := range classes {
+		c, err := pick(chars)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	all := strings.Join(classes, "")
+	for len(password) < opts.Length {
+		c, err := pick(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Перемешивание, чтобы обязательные символы не стояли в начале
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// Passphrase создаёт парольную фразу из случайных слов встроенного словаря
+func Passphrase(words int, separator string) (string, error) {
+	if words < MinWords || words > MaxWords {
+		return "", fmt.Errorf("%w: words must be between %d and %d", ErrInvalidLength, MinWords, MaxWords)
+	}
+	phrase := make([]string, 0, words)
+	for range words {
+		i, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		phrase = append(phrase, wordlist[i])
+	}
+	return strings.Join(phrase, separator), nil
+}
+
+// pick случайный символ набора
+func pick(chars string) (byte, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// removeChars набор без указанных символов
+func removeChars(chars, remove string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(remove, r) {
+			return -1
+		}
+		return r
+	}, chars)
+}
@@ -0,0 +1,73 @@
+package passgen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate_ContainsEveryClass(t *testing.T) {
+	for range 50 {
+		password, err := Generate(DefaultOptions)
+		require.NoError(t, err)
+		assert.Len(t, password, DefaultOptions.Length)
+		assert.True(t, strings.ContainsAny(password, lowerChars))
+		assert.True(t, strings.ContainsAny(password, upperChars))
+		assert.True(t, strings.ContainsAny(password, digitChars))
+		assert.True(t, strings.ContainsAny(password, symbolChars))
+		assert.False(t, strings.ContainsAny(password, ambiguousChars))
+	}
+}
+
+func TestGenerate_SingleClass(t *testing.T) {
+	password, err := Generate(Options{Length: 12, Digits: true})
+	require.NoError(t, err)
+	assert.Len(t, password, 12)
+	assert.Empty(t, strings.Trim(password, digitChars))
+}
+
+func TestGenerate_InvalidOptions(t *testing.T) {
+	_, err := Generate(Options{Length: 12})
+	assert.ErrorIs(t, err, ErrNoCharset)
+
+	_, err = Generate(Options{Length: 3, Lower: true})
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	_, err = Generate(Options{Length: MaxLength + 1, Lower: true})
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
+
+func TestGenerate_RandomError(t *testing.T) {
+	orig := randomInt
+	defer func() { randomInt = orig }()
+	randomInt = func(int) (int, error) { return 0, errors.New("no entropy") }
+
+	_, err := Generate(DefaultOptions)
+	assert.EqualError(t, err, "no entropy")
+	_, err = Passphrase(DefaultWords, "-")
+	assert.EqualError(t, err, "no entropy")
+}
+
+func TestPassphrase(t *testing.T) {
+	phrase, err := Passphrase(DefaultWords, "-")
+	require.NoError(t, err)
+	words := strings.Split(phrase, "-")
+	assert.Len(t, words, DefaultWords)
+	for _, word := range words {
+		assert.Contains(t, wordlist, word)
+	}
+
+	_, err = Passphrase(MinWords-1, "-")
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
+
+func TestWordlist(t *testing.T) {
+	assert.GreaterOrEqual(t, len(wordlist), 1024)
+	seen := make(map[string]bool, len(wordlist))
+	for _, word := range wordlist {
+		assert.False(t, seen[word], word)
+		seen[word] = true
+	}
+}
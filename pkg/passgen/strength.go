@@ -0,0 +1,123 @@
+package passgen
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Оценки надёжности пароля
+const (
+	ScoreVeryWeak = iota
+	ScoreWeak
+	ScoreFair
+	ScoreStrong
+	ScoreVeryStrong
+)
+
+// Пороги энтропии в битах для оценок ScoreWeak..ScoreVeryStrong
+var scoreThresholds = []float64{28, 36, 60, 128}
+
+// scoreLabels подписи оценок
+var scoreLabels = []string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// commonEntropy энтропия пароля из списка распространённых
+const commonEntropy = 10
+
+// commonPasswords распространённые пароли и основы паролей, которые перебираются первыми
+var commonPasswords = map[string]bool{
+	"password": true, "passw0rd": true, "123456": true, "12345678": true, "123456789": true,
+	"1234567890": true, "qwerty": true, "qwertyuiop": true, "asdfgh": true, "zxcvbn": true,
+	"111111": true, "000000": true, "abc123": true, "iloveyou": true, "admin": true,
+	"welcome": true, "letmein": true, "monkey": true, "dragon": true, "football": true,
+	"baseball": true, "sunshine": true, "princess": true, "master": true, "shadow": true,
+	"superman": true, "trustno1": true, "login": true, "secret": true, "changeme": true,
+	"qazwsx": true, "starwars": true, "whatever": true, "michael": true, "charlie": true,
+	"freedom": true, "hello": true, "default": true, "root": true, "test": true,
+}
+
+// Strength оценка надёжности пароля
+type Strength struct {
+	// Entropy оценка энтропии в битах
+	Entropy float64
+	Score   int
+	Label   string
+}
+
+// Estimate оценка энтропии пароля по размеру алфавита и длине.
+// Повторы и последовательности символов почти не добавляют энтропии,
+// распространённые пароли оцениваются как очень слабые
+func Estimate(password string) Strength {
+	entropy := estimateEntropy(password)
+	score := ScoreVeryWeak
+	for score < len(scoreThresholds) && entropy >= scoreThresholds[score] {
+		score++
+	}
+	return Strength{Entropy: entropy, Score: score, Label: scoreLabels[score]}
+}
+
+// estimateEntropy энтропия пароля в битах
+func estimateEntropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+
+	var lower, upper, digit, symbol, other bool
+	runes := []rune(password)
+	effective := 0.0
+	for i, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+		if i > 0 && isPredictable(runes[i-1], r) {
+			effective += 0.25
+		} else {
+			effective++
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{
+		{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100},
+	} {
+		if class.present {
+			pool += class.size
+		}
+	}
+
+	entropy := effective * math.Log2(float64(pool))
+	if isCommon(password) {
+		entropy = math.Min(entropy, commonEntropy)
+	}
+	return entropy
+}
+
+// isPredictable символ повторяет предыдущий или продолжает последовательность
+func isPredictable(prev, r rune) bool {
+	diff := unicode.ToLower(r) - unicode.ToLower(prev)
+	return diff >= -1 && diff <= 1
+}
+
+// isCommon пароль распространён или получен из распространённого добавлением цифр и символов в конце
+func isCommon(password string) bool {
+	base := strings.ToLower(password)
+	if commonPasswords[base] {
+		return true
+	}
+	base = strings.TrimRightFunc(base, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	return commonPasswords[base]
+}
@@ -0,0 +1,41 @@
+package passgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		password string
+		score    int
+	}{
+		{"", ScoreVeryWeak},
+		{"password", ScoreVeryWeak},
+		{"Password123!", ScoreVeryWeak},
+		{"aaaaaaaaaaaa", ScoreVeryWeak},
+		{"abcdefghijkl", ScoreVeryWeak},
+		{"kitten", ScoreVeryWeak},
+		{"zebras", ScoreWeak},
+		{"kittenpaw", ScoreFair},
+		{"Tr0ub4dor&3", ScoreStrong},
+		{"correct-horse-battery-staple", ScoreVeryStrong},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.score, Estimate(tt.password).Score, tt.password)
+	}
+}
+
+func TestEstimate_Generated(t *testing.T) {
+	password, err := Generate(DefaultOptions)
+	require.NoError(t, err)
+	strength := Estimate(password)
+	assert.GreaterOrEqual(t, strength.Score, ScoreStrong)
+	assert.NotEmpty(t, strength.Label)
+
+	phrase, err := Passphrase(DefaultWords, "-")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, Estimate(phrase).Score, ScoreStrong)
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// KMS сервис управления ключами: шифрует и расшифровывает секреты ключом,
+// который не покидает сервис
+type KMS interface {
+	// Encrypt шифрует plaintext ключом keyID
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+	// Decrypt расшифровывает ciphertext ключом keyID
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// Provider открывает KMS по адресу из конфигурации
+type Provider func(uri string) (KMS, error)
+
+var (
+	// providersMu защищает реестр провайдеров
+	providersMu sync.RWMutex
+	// providers провайдеры KMS по схеме адреса
+	providers = map[string]Provider{
+		"file": openLocalKMS,
+	}
+)
+
+// RegisterProvider добавляет провайдер KMS для адресов вида <scheme>://…,
+// например облачный KMS или HashiCorp Vault
+func RegisterProvider(scheme string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[scheme] = provider
+}
+
+// OpenKMS KMS по адресу, схема выбирает провайдер: file:///etc/gault/kms — локальный каталог ключей
+func OpenKMS(uri string) (KMS, error) {
+	scheme, _, ok := strings.Cut(uri, "://")
+	if !ok {
+		return nil, fmt.Errorf("kms address %q must be <scheme>://…", uri)
+	}
+
+	providersMu.RLock()
+	provider, ok := providers[scheme]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown kms provider %q", scheme)
+	}
+	return provider(uri)
+}
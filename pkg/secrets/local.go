@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// localKeySize размер ключа локального KMS, AES-256
+const localKeySize = 32
+
+// validKeyID идентификатор ключа, он же имя файла в каталоге
+var validKeyID = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// LocalKMS KMS на файлах: каждый ключ хранится в каталоге в файле <keyID> как 64 hex-символа.
+// Для тестов и небольших установок, ключ создаётся командой openssl rand -hex 32
+type LocalKMS struct {
+	dir string
+}
+
+// NewLocalKMS локальный KMS с ключами в каталоге dir
+func NewLocalKMS(dir string) (*LocalKMS, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open kms directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("kms path %q is not a directory", dir)
+	}
+	return &LocalKMS{dir: dir}, nil
+}
+
+// openLocalKMS провайдер для адресов file://<каталог>
+func openLocalKMS(uri string) (KMS, error) {
+	return NewLocalKMS(strings.TrimPrefix(uri, "file://"))
+}
+
+// Encrypt шифрует plaintext ключом keyID, nonce записывается перед шифротекстом
+func (k *LocalKMS) Encrypt(_ context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	gcm, err := k.cipher(keyID)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Decrypt расшифровывает результат Encrypt тем же ключом
+func (k *LocalKMS) Decrypt(_ context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	gcm, err := k.cipher(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// cipher AES-GCM по ключу из файла, файл читается при каждом вызове
+func (k *LocalKMS) cipher(keyID string) (cipher.AEAD, error) {
+	if !validKeyID.MatchString(keyID) {
+		return nil, fmt.Errorf("invalid key id %q", keyID)
+	}
+	data, err := os.ReadFile(filepath.Join(k.dir, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kms key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != localKeySize {
+		return nil, fmt.Errorf("kms key %q must be %d hex-encoded bytes", keyID, localKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
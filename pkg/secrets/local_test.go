@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKMS локальный KMS с ключами keyIDs во временном каталоге
+func newTestKMS(t *testing.T, keyIDs ...string) *LocalKMS {
+	t.Helper()
+	dir := t.TempDir()
+	for i, keyID := range keyIDs {
+		key := strings.Repeat(string(rune('a'+i)), 2*localKeySize)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, keyID), []byte(key+"\n"), 0o600))
+	}
+	kms, err := NewLocalKMS(dir)
+	require.NoError(t, err)
+	return kms
+}
+
+func TestLocalKMS_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t, "db-2025", "db-2026")
+
+	old, err := kms.Encrypt(ctx, "db-2025", []byte("old password"))
+	require.NoError(t, err)
+	current, err := kms.Encrypt(ctx, "db-2026", []byte("new password"))
+	require.NoError(t, err)
+
+	plaintext, err := kms.Decrypt(ctx, "db-2025", old)
+	require.NoError(t, err)
+	assert.Equal(t, "old password", string(plaintext))
+	plaintext, err = kms.Decrypt(ctx, "db-2026", current)
+	require.NoError(t, err)
+	assert.Equal(t, "new password", string(plaintext))
+
+	_, err = kms.Decrypt(ctx, "db-2026", old)
+	assert.ErrorContains(t, err, "failed to decrypt")
+}
+
+func TestLocalKMS_Errors(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t, "good")
+	require.NoError(t, os.WriteFile(filepath.Join(kms.dir, "short"), []byte("abcd"), 0o600))
+
+	_, err := kms.Encrypt(ctx, "../etc/passwd", []byte("x"))
+	assert.ErrorContains(t, err, "invalid key id")
+	_, err = kms.Encrypt(ctx, "missing", []byte("x"))
+	assert.ErrorContains(t, err, "failed to read kms key")
+	_, err = kms.Encrypt(ctx, "short", []byte("x"))
+	assert.ErrorContains(t, err, "must be 32 hex-encoded bytes")
+	_, err = kms.Decrypt(ctx, "good", []byte{1, 2})
+	assert.ErrorContains(t, err, "too short")
+
+	_, err = NewLocalKMS(filepath.Join(kms.dir, "good"))
+	assert.ErrorContains(t, err, "is not a directory")
+	_, err = NewLocalKMS(filepath.Join(kms.dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestOpenKMS(t *testing.T) {
+	dir := newTestKMS(t).dir
+
+	kms, err := OpenKMS("file://" + dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, kms.(*LocalKMS).dir)
+
+	_, err = OpenKMS(dir)
+	assert.ErrorContains(t, err, "must be <scheme>://")
+	_, err = OpenKMS("vault://secret/gault")
+	assert.ErrorContains(t, err, `unknown kms provider "vault"`)
+
+	RegisterProvider("vault", func(string) (KMS, error) { return kms, nil })
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(providers, "vault")
+		providersMu.Unlock()
+	})
+	opened, err := OpenKMS("vault://secret/gault")
+	require.NoError(t, err)
+	assert.Same(t, kms, opened)
+}
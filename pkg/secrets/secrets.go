@@ -0,0 +1,102 @@
+// Package secrets раскрывает ссылки на секреты в значениях конфигурации:
+// file:<путь>, env:<переменная> и kms:<ключ>:<base64 шифротекста>
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Префиксы ссылок на секреты
+const (
+	filePrefix = "file:"
+	envPrefix  = "env:"
+	kmsPrefix  = "kms:"
+)
+
+// ErrNoKMS ссылка kms: без настроенного KMS
+var ErrNoKMS = errors.New("kms is not configured")
+
+// Resolver раскрывает ссылки на секреты, значения без префикса возвращаются как есть
+type Resolver struct {
+	// KMS сервис для ссылок kms:, nil — такие ссылки запрещены
+	KMS KMS
+	// LookupEnv источник переменных окружения, nil — os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+// IsReference значение является ссылкой на секрет, а не самим секретом
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, filePrefix) ||
+		strings.HasPrefix(value, envPrefix) ||
+		strings.HasPrefix(value, kmsPrefix)
+}
+
+// Resolve значение секрета по ссылке. Файлы читаются при каждом вызове, поэтому
+// для смены секрета достаточно заменить файл и перезапустить процесс
+func (r Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, filePrefix):
+		return readFile(strings.TrimPrefix(value, filePrefix))
+	case strings.HasPrefix(value, envPrefix):
+		return r.lookupEnv(strings.TrimPrefix(value, envPrefix))
+	case strings.HasPrefix(value, kmsPrefix):
+		return r.decrypt(ctx, strings.TrimPrefix(value, kmsPrefix))
+	default:
+		return value, nil
+	}
+}
+
+// readFile содержимое файла без завершающего перевода строки
+func readFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("file reference without path")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// lookupEnv значение переменной окружения, отсутствующая переменная — ошибка
+func (r Resolver) lookupEnv(name string) (string, error) {
+	lookup := r.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, ok := lookup(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+	return value, nil
+}
+
+// decrypt расшифровка ссылки вида <ключ>:<base64 шифротекста>
+func (r Resolver) decrypt(ctx context.Context, ref string) (string, error) {
+	if r.KMS == nil {
+		return "", ErrNoKMS
+	}
+	keyID, encoded, ok := strings.Cut(ref, ":")
+	if !ok || keyID == "" || encoded == "" {
+		return "", errors.New("kms reference must be kms:<key id>:<base64 ciphertext>")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid kms ciphertext: %w", err)
+	}
+	plaintext, err := r.KMS.Decrypt(ctx, keyID, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("kms decrypt with key %q: %w", keyID, err)
+	}
+	return string(plaintext), nil
+}
+
+// Reference ссылка kms: для шифротекста, полученного от KMS.Encrypt
+func Reference(keyID string, ciphertext []byte) string {
+	return kmsPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+}
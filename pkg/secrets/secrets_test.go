@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t, "app")
+	ciphertext, err := kms.Encrypt(ctx, "app", []byte("from-kms"))
+	require.NoError(t, err)
+
+	secretFile := filepath.Join(t.TempDir(), "db_dsn")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+
+	resolver := Resolver{
+		KMS: kms,
+		LookupEnv: func(key string) (string, bool) {
+			if key == "DB_DSN" {
+				return "from-env", true
+			}
+			return "", false
+		},
+	}
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain value", "plain value"},
+		{"postgres://user:pass@db/gault", "postgres://user:pass@db/gault"},
+		{"file:" + secretFile, "from-file"},
+		{"env:DB_DSN", "from-env"},
+		{Reference("app", ciphertext), "from-kms"},
+	}
+	for _, tt := range tests {
+		got, err := resolver.Resolve(ctx, tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestResolver_Errors(t *testing.T) {
+	ctx := context.Background()
+	resolver := Resolver{LookupEnv: func(string) (string, bool) { return "", false }}
+
+	_, err := resolver.Resolve(ctx, "file:")
+	assert.ErrorContains(t, err, "without path")
+	_, err = resolver.Resolve(ctx, "file:"+filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to read secret file")
+	_, err = resolver.Resolve(ctx, "env:MISSING")
+	assert.ErrorContains(t, err, `"MISSING" is not set`)
+	_, err = resolver.Resolve(ctx, "kms:app:AAAA")
+	assert.ErrorIs(t, err, ErrNoKMS)
+
+	resolver.KMS = newTestKMS(t, "app")
+	_, err = resolver.Resolve(ctx, "kms:app")
+	assert.ErrorContains(t, err, "kms:<key id>:<base64 ciphertext>")
+	_, err = resolver.Resolve(ctx, "kms:app:not base64!")
+	assert.ErrorContains(t, err, "invalid kms ciphertext")
+	_, err = resolver.Resolve(ctx, "kms:app:AAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	assert.ErrorContains(t, err, `kms decrypt with key "app"`)
+}
+
+func TestIsReference(t *testing.T) {
+	assert.True(t, IsReference("file:/run/secrets/aes"))
+	assert.True(t, IsReference("env:GAULT_AES_KEY"))
+	assert.True(t, IsReference("kms:app:AAAA"))
+	assert.False(t, IsReference("host=db password=secret"))
+	assert.False(t, IsReference(""))
+}
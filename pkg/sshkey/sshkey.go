@@ -0,0 +1,169 @@
+// Package sshkey разбор и проверка SSH-ключей для хранения в хранилище и передачи в ssh-agent
+package sshkey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	// ErrPassphraseRequired ключ зашифрован, а пароль ключа не задан
+	ErrPassphraseRequired = errors.New("private key is encrypted, passphrase is required")
+	// ErrUnsupportedKey тип ключа не поддерживается
+	ErrUnsupportedKey = errors.New("unsupported key type: expected RSA, ECDSA or Ed25519")
+	// ErrCertificateMismatch сертификат выдан для другого ключа
+	ErrCertificateMismatch = errors.New("certificate does not match private key")
+)
+
+// Key SSH-ключ элемента хранилища. Закрытый ключ хранится в формате OpenSSH без пароля,
+// потому что элемент целиком шифруется на клиенте, остальные поля — метаданные для отображения
+type Key struct {
+	PrivateKey  string `json:"private_key"`
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+	Comment     string `json:"comment,omitempty"`
+	// Certificate OpenSSH-сертификат ключа в формате authorized_keys, если есть
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// Parse разбор закрытого ключа OpenSSH или PEM (PKCS#1, PKCS#8, SEC1). Зашифрованный ключ
+// расшифровывается паролем passphrase. Сертификат необязателен и должен соответствовать ключу
+func Parse(privateKey, passphrase []byte, comment string, certificate []byte) (Key, error) {
+	raw, err := ssh.ParseRawPrivateKey(privateKey)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if len(passphrase) == 0 {
+			return Key{}, ErrPassphraseRequired
+		}
+		raw, err = ssh.ParseRawPrivateKeyWithPassphrase(privateKey, passphrase)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	private, err := normalize(raw)
+	if err != nil {
+		return Key{}, err
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(private, comment)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	key := Key{
+		PrivateKey:  string(pem.EncodeToMemory(block)),
+		PublicKey:   authorizedKey(signer.PublicKey(), comment),
+		Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+		Comment:     comment,
+	}
+
+	if len(bytes.TrimSpace(certificate)) > 0 {
+		cert, err := parseCertificate(certificate)
+		if err != nil {
+			return Key{}, err
+		}
+		if !bytes.Equal(cert.Key.Marshal(), signer.PublicKey().Marshal()) {
+			return Key{}, ErrCertificateMismatch
+		}
+		key.Certificate = authorizedKey(cert, comment)
+	}
+	return key, nil
+}
+
+// Decode разбор содержимого элемента хранилища
+func Decode(content string) (Key, error) {
+	var key Key
+	if err := json.Unmarshal([]byte(content), &key); err != nil {
+		return Key{}, fmt.Errorf("failed to decode ssh key: %w", err)
+	}
+	if key.PrivateKey == "" {
+		return Key{}, errors.New("failed to decode ssh key: private key is missing")
+	}
+	return key, nil
+}
+
+// Encode содержимое элемента хранилища
+func (k Key) Encode() (string, error) {
+	data, err := json.Marshal(k)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ssh key: %w", err)
+	}
+	return string(data), nil
+}
+
+// Type алгоритм ключа, например ssh-ed25519
+func (k Key) Type() string {
+	keyType, _, _ := strings.Cut(k.PublicKey, " ")
+	return keyType
+}
+
+// AddedKeys ключи для добавления в ssh-agent: сам ключ и, как делает ssh-add, ключ с сертификатом
+func (k Key) AddedKeys() ([]agent.AddedKey, error) {
+	raw, err := ssh.ParseRawPrivateKey([]byte(k.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	private, err := normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []agent.AddedKey{{PrivateKey: private, Comment: k.Comment}}
+	if k.Certificate != "" {
+		cert, err := parseCertificate([]byte(k.Certificate))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, agent.AddedKey{PrivateKey: private, Certificate: cert, Comment: k.Comment})
+	}
+	return keys, nil
+}
+
+// normalize приведение закрытого ключа к типам, которые принимают ssh и ssh/agent
+func normalize(raw any) (crypto.PrivateKey, error) {
+	switch key := raw.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return key, nil
+	case *ed25519.PrivateKey:
+		return *key, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// parseCertificate разбор OpenSSH-сертификата в формате authorized_keys
+func parseCertificate(data []byte) (*ssh.Certificate, error) {
+	public, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert, ok := public.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.New("failed to parse certificate: not an OpenSSH certificate")
+	}
+	return cert, nil
+}
+
+// authorizedKey открытый ключ или сертификат в формате authorized_keys с комментарием
+func authorizedKey(key ssh.PublicKey, comment string) string {
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	if comment != "" {
+		line += " " + comment
+	}
+	return line
+}
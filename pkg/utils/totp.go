@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// totpPeriod период действия кода TOTP в секундах
+	totpPeriod = 30
+	// totpSkew допустимое отклонение часов клиента в периодах
+	totpSkew = 1
+	// recoveryCodeBytes длина кода восстановления в байтах до кодирования
+	recoveryCodeBytes = 10
+)
+
+// totpOpts параметры TOTP, совместимые с Google Authenticator
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// GenerateTOTPSecret создание секрета TOTP и otpauth:// URI для приложения-аутентификатора
+func GenerateTOTPSecret(issuer, account string) (string, string, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// TOTPCode код TOTP для момента времени t
+func TOTPCode(secret string, t time.Time) (string, error) {
+	code, err := totp.GenerateCodeCustom(secret, t, totpOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate totp code: %w", err)
+	}
+	return code, nil
+}
+
+// ValidateTOTP проверка кода с учётом отклонения часов. Возвращает номер периода совпавшего кода,
+// чтобы вызывающий мог запретить повторное использование кода
+func ValidateTOTP(secret, code string, t time.Time) (int64, bool) {
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := t.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := TOTPCode(secret, at)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// ErrInvalidOTPAuth секрет TOTP не является otpauth:// URI или секретом base32
+var ErrInvalidOTPAuth = errors.New("invalid totp secret: expected otpauth://totp URI or base32 secret")
+
+// OTPAuth параметры TOTP элемента хранилища из otpauth:// URI или секрета base32
+type OTPAuth struct {
+	Secret    string
+	Issuer    string
+	Account   string
+	Period    uint
+	Digits    otp.Digits
+	Algorithm otp.Algorithm
+}
+
+// ParseOTPAuth разбор otpauth://totp URI или секрета base32. Не заданные параметры
+// принимают значения Google Authenticator: 6 цифр, 30 секунд, SHA1
+func ParseOTPAuth(s string) (OTPAuth, error) {
+	s = strings.TrimSpace(s)
+	auth := OTPAuth{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	if strings.HasPrefix(strings.ToLower(s), "otpauth://") {
+		key, err := otp.NewKeyFromURL(s)
+		if err != nil {
+			return OTPAuth{}, fmt.Errorf("%w: %v", ErrInvalidOTPAuth, err)
+		}
+		if key.Type() != "totp" {
+			return OTPAuth{}, fmt.Errorf("%w: unsupported type %q", ErrInvalidOTPAuth, key.Type())
+		}
+		auth.Secret, auth.Issuer, auth.Account = key.Secret(), key.Issuer(), key.AccountName()
+		if period := key.Period(); period > 0 {
+			auth.Period = uint(period)
+		}
+		if key.Digits() != 0 {
+			auth.Digits = key.Digits()
+		}
+		auth.Algorithm = key.Algorithm()
+	} else {
+		auth.Secret = s
+	}
+
+	// Секрет часто записывают группами через пробел и строчными буквами
+	auth.Secret = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(auth.Secret))
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(auth.Secret, "=")); err != nil || auth.Secret == "" {
+		return OTPAuth{}, ErrInvalidOTPAuth
+	}
+	return auth, nil
+}
+
+// Code код TOTP для момента времени t
+func (a OTPAuth) Code(t time.Time) (string, error) {
+	code, err := totp.GenerateCodeCustom(a.Secret, t, totp.ValidateOpts{
+		Period:    a.Period,
+		Digits:    a.Digits,
+		Algorithm: a.Algorithm,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate totp code: %w", err)
+	}
+	return code, nil
+}
+
+// Remaining сколько секунд код, действующий в момент t, остаётся действительным
+func (a OTPAuth) Remaining(t time.Time) int {
+	return int(a.Period) - int(t.Unix()%int64(a.Period))
+}
+
+// GenerateRecoveryCodes создание n одноразовых кодов восстановления вида XXXXXXXX-XXXXXXXX
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		bytes := make([]byte, recoveryCodeBytes)
+		if _, err := randomReader(bytes); err != nil {
+			return nil, err
+		}
+		code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes)
+		codes = append(codes, code[:len(code)/2]+"-"+code[len(code)/2:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode хеш кода восстановления для хранения. Регистр, пробелы и дефисы не учитываются
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
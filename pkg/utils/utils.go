@@ -9,6 +9,7 @@ import (
 	"io"
 
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/nacl/box"
 )
 
 // Объявляем переменную, которую можно подменить в тестах
@@ -87,3 +88,44 @@ func Decrypt(enc, encryptionKey string) (string, error) {
 
 	return string(plainText), nil
 }
+
+// keySize размер ключей X25519 и ключа данных AES-256
+const keySize = 32
+
+// GenerateDataKey создаёт случайный ключ данных для шифрования Encrypt
+func GenerateDataKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
+
+// GenerateKeyPair создаёт ключевую пару X25519 для обмена ключами данных
+func GenerateKeyPair() ([]byte, []byte, error) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey[:], privateKey[:], nil
+}
+
+// SealKey шифрует ключ данных публичным ключом получателя
+func SealKey(dataKey string, publicKey []byte) ([]byte, error) {
+	if len(publicKey) != keySize {
+		return nil, fmt.Errorf("invalid public key size")
+	}
+	return box.SealAnonymous(nil, []byte(dataKey), (*[keySize]byte)(publicKey), rand.Reader)
+}
+
+// OpenKey расшифровывает ключ данных ключевой парой получателя
+func OpenKey(sealed, publicKey, privateKey []byte) (string, error) {
+	if len(publicKey) != keySize || len(privateKey) != keySize {
+		return "", fmt.Errorf("invalid key pair size")
+	}
+	dataKey, ok := box.OpenAnonymous(nil, sealed, (*[keySize]byte)(publicKey), (*[keySize]byte)(privateKey))
+	if !ok {
+		return "", fmt.Errorf("failed to open data key")
+	}
+	return string(dataKey), nil
+}
@@ -3,7 +3,9 @@ package utils
 import (
 	"encoding/base64"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -106,3 +108,109 @@ func TestDecrypt_TamperedCipherText(t *testing.T) {
 	_, err = Decrypt(tampered, validKey)
 	assert.Error(t, err)
 }
+
+func TestSealKey_OpenKey(t *testing.T) {
+	dataKey, err := GenerateDataKey()
+	assert.NoError(t, err)
+	assert.Len(t, dataKey, 32)
+
+	publicKey, privateKey, err := GenerateKeyPair()
+	assert.NoError(t, err)
+
+	sealed, err := SealKey(dataKey, publicKey)
+	assert.NoError(t, err)
+
+	opened, err := OpenKey(sealed, publicKey, privateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, dataKey, opened)
+
+	// Данные, зашифрованные ключом данных, расшифровываются открытым ключом
+	encrypted, err := Encrypt("secret", dataKey)
+	assert.NoError(t, err)
+	decrypted, err := Decrypt(encrypted, opened)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", decrypted)
+}
+
+func TestOpenKey_WrongKeyPair(t *testing.T) {
+	publicKey, _, err := GenerateKeyPair()
+	assert.NoError(t, err)
+	otherPublic, otherPrivate, err := GenerateKeyPair()
+	assert.NoError(t, err)
+
+	sealed, err := SealKey("data-key", publicKey)
+	assert.NoError(t, err)
+
+	_, err = OpenKey(sealed, otherPublic, otherPrivate)
+	assert.Error(t, err)
+	_, err = OpenKey(sealed, []byte("short"), otherPrivate)
+	assert.Error(t, err)
+	_, err = SealKey("data-key", []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestTOTP(t *testing.T) {
+	secret, uri, err := GenerateTOTPSecret("Gault", "alice")
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.Contains(t, uri, "otpauth://totp/Gault:alice")
+	assert.Contains(t, uri, "secret="+secret)
+
+	now := time.Unix(1700000000, 0)
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+	assert.Len(t, code, 6)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	// Код предыдущего периода принимается из-за допустимого отклонения часов
+	step, ok = ValidateTOTP(secret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	_, ok = ValidateTOTP(secret, code, now.Add(5*time.Minute))
+	assert.False(t, ok)
+	_, ok = ValidateTOTP("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestParseOTPAuth(t *testing.T) {
+	// Тестовый вектор RFC 6238 для SHA1
+	auth, err := ParseOTPAuth("otpauth://totp/ACME:alice?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=ACME&digits=8")
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", auth.Issuer)
+	assert.Equal(t, "alice", auth.Account)
+	code, err := auth.Code(time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "94287082", code)
+	assert.Equal(t, 1, auth.Remaining(time.Unix(59, 0)))
+
+	auth, err = ParseOTPAuth(" gezd gnbv gy3t qojq gezd gnbv gy3t qojq ")
+	require.NoError(t, err)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", auth.Secret)
+	code, err = auth.Code(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	expected, err := TOTPCode(auth.Secret, time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, expected, code)
+
+	for _, s := range []string{"", "not base32!", "otpauth://hotp/x?secret=GEZDGNBV&counter=1", "otpauth://totp/x"} {
+		_, err = ParseOTPAuth(s)
+		assert.ErrorIs(t, err, ErrInvalidOTPAuth, s)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	assert.Len(t, codes, 10)
+	assert.Len(t, codes[0], 17)
+	assert.Equal(t, byte('-'), codes[0][8])
+	assert.NotEqual(t, codes[0], codes[1])
+
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToLower(strings.ReplaceAll(codes[0], "-", ""))))
+	assert.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+	assert.NotContains(t, HashRecoveryCode(codes[0]), codes[0])
+}
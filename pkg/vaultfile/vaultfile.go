@@ -0,0 +1,398 @@
+// Package vaultfile формат зашифрованного архива хранилища Gault.
+//
+// Архив версии 1 состоит из открытого заголовка и последовательности зашифрованных кадров:
+//
+//	magic    8 байт  "GAULTVLT"
+//	version  uint16  версия формата, big-endian
+//	hdrLen   uint32  длина заголовка, big-endian
+//	header   JSON    параметры KDF и шифра, см. Header
+//	frames   ...     кадры: uint32 длина, затем nonce(12) || AES-256-GCM(plain)
+//
+// Ключ архива выводится из пароля функцией argon2id с параметрами из заголовка.
+// Первый кадр содержит манифест (JSON, см. Manifest), далее идут части содержимого элементов
+// в порядке манифеста. Открытый текст части начинается с байта флагов: 1 — последняя часть элемента.
+// Дополнительные данные AEAD каждого кадра — SHA-256 от magic, version, hdrLen и header,
+// за которыми следует номер кадра (uint64, big-endian), поэтому подмена заголовка,
+// перестановка и удаление кадров обнаруживаются при расшифровке.
+//
+// Содержимое паролей и карт хранится в архиве в открытом виде и защищено только паролем архива,
+// что позволяет восстановить его с другим ключом хранилища.
+package vaultfile
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Version текущая версия формата
+const Version = 1
+
+// DefaultChunkSize размер части содержимого по умолчанию
+const DefaultChunkSize = 1024 * 1024
+
+const (
+	magic       = "GAULTVLT"
+	kdfName     = "argon2id"
+	cipherName  = "AES-256-GCM"
+	keyLen      = 32
+	saltLen     = 16
+	maxHeader   = 4096
+	maxManifest = 64 * 1024 * 1024
+	maxChunk    = 16 * 1024 * 1024
+	flagLast    = 1
+)
+
+// Параметры argon2id для новых архивов
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+)
+
+// Ограничения параметров KDF при чтении, чтобы чужой файл не исчерпал ресурсы
+const (
+	maxKDFTime    = 16
+	maxKDFMemory  = 1024 * 1024
+	maxKDFThreads = 16
+)
+
+var (
+	// ErrNotVaultFile файл не является архивом хранилища
+	ErrNotVaultFile = errors.New("not a gault vault file")
+	// ErrUnsupportedVersion версия архива не поддерживается
+	ErrUnsupportedVersion = errors.New("unsupported vault file version")
+	// ErrWrongPassword неверный пароль архива или повреждённый заголовок
+	ErrWrongPassword = errors.New("wrong password or corrupted vault file")
+	// ErrCorrupted содержимое архива повреждено или обрезано
+	ErrCorrupted = errors.New("corrupted vault file")
+)
+
+// KDF параметры вывода ключа архива из пароля
+type KDF struct {
+	Name    string `json:"name"`
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// Header открытый заголовок архива
+type Header struct {
+	Version   int       `json:"version"`
+	KDF       KDF       `json:"kdf"`
+	Cipher    string    `json:"cipher"`
+	ChunkSize int       `json:"chunk_size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Entry описание элемента хранилища в манифесте
+type Entry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Folder путь папки вида "/work/bank", пустое значение — корень
+	Folder    string   `json:"folder,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	UpdatedAt int64    `json:"updated_at"`
+}
+
+// Manifest список элементов архива
+type Manifest struct {
+	Items []Entry `json:"items"`
+}
+
+// Writer запись архива: заголовок и манифест пишутся при создании, содержимое — WriteItem
+type Writer struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	headerSum []byte
+	frame     uint64
+	chunkSize int
+	items     int
+	written   int
+}
+
+// NewWriter создание архива с элементами манифеста
+func NewWriter(w io.Writer, password string, manifest Manifest) (*Writer, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	header := Header{
+		Version: Version,
+		KDF: KDF{
+			Name:    kdfName,
+			Salt:    salt,
+			Time:    kdfTime,
+			Memory:  kdfMemory,
+			Threads: kdfThreads,
+		},
+		Cipher:    cipherName,
+		ChunkSize: DefaultChunkSize,
+		CreatedAt: time.Now().UTC(),
+	}
+	headerData, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := encodePrefix(headerData)
+	aead, err := newAEAD(password, header.KDF)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	vw := &Writer{
+		w:         w,
+		aead:      aead,
+		headerSum: headerSum(prefix),
+		chunkSize: header.ChunkSize,
+		items:     len(manifest.Items),
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := vw.writeFrame(manifestData); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return vw, nil
+}
+
+// WriteItem запись содержимого очередного элемента манифеста частями
+func (vw *Writer) WriteItem(content []byte) error {
+	if vw.written >= vw.items {
+		return fmt.Errorf("manifest has only %d items", vw.items)
+	}
+	for {
+		n := min(len(content), vw.chunkSize)
+		var flags byte
+		if n == len(content) {
+			flags = flagLast
+		}
+		if err := vw.writeFrame(append([]byte{flags}, content[:n]...)); err != nil {
+			return fmt.Errorf("failed to write item %d: %w", vw.written, err)
+		}
+		content = content[n:]
+		if flags == flagLast {
+			break
+		}
+	}
+	vw.written++
+	return nil
+}
+
+// Close проверка, что содержимое записано для всех элементов манифеста
+func (vw *Writer) Close() error {
+	if vw.written != vw.items {
+		return fmt.Errorf("written %d of %d items", vw.written, vw.items)
+	}
+	return nil
+}
+
+// writeFrame шифрование и запись кадра
+func (vw *Writer) writeFrame(plain []byte) error {
+	nonce := make([]byte, vw.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := vw.aead.Seal(nonce, nonce, plain, frameAD(vw.headerSum, vw.frame))
+	vw.frame++
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sealed)))
+	if _, err := vw.w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := vw.w.Write(sealed)
+	return err
+}
+
+// Reader чтение архива: манифест доступен сразу после открытия, содержимое — Next
+type Reader struct {
+	r         io.Reader
+	aead      cipher.AEAD
+	headerSum []byte
+	frame     uint64
+	header    Header
+	manifest  Manifest
+	read      int
+}
+
+// NewReader открытие архива и расшифровка манифеста
+func NewReader(r io.Reader, password string) (*Reader, error) {
+	fixed := make([]byte, len(magic)+2+4)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, ErrNotVaultFile
+	}
+	if string(fixed[:len(magic)]) != magic {
+		return nil, ErrNotVaultFile
+	}
+	if binary.BigEndian.Uint16(fixed[len(magic):]) != Version {
+		return nil, ErrUnsupportedVersion
+	}
+	headerLen := binary.BigEndian.Uint32(fixed[len(magic)+2:])
+	if headerLen == 0 || headerLen > maxHeader {
+		return nil, ErrCorrupted
+	}
+	headerData := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, headerData); err != nil {
+		return nil, ErrCorrupted
+	}
+
+	var header Header
+	if err := json.Unmarshal(headerData, &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrCorrupted, err)
+	}
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(password, header.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	vr := &Reader{
+		r:         r,
+		aead:      aead,
+		headerSum: headerSum(append(fixed, headerData...)),
+		header:    header,
+	}
+	manifestData, err := vr.readFrame(maxManifest)
+	if errors.Is(err, ErrCorrupted) {
+		return nil, ErrWrongPassword
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(manifestData, &vr.manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrCorrupted, err)
+	}
+	return vr, nil
+}
+
+// Header открытый заголовок архива
+func (vr *Reader) Header() Header {
+	return vr.header
+}
+
+// Manifest манифест архива
+func (vr *Reader) Manifest() Manifest {
+	return vr.manifest
+}
+
+// Next содержимое очередного элемента манифеста, io.EOF после последнего
+func (vr *Reader) Next() ([]byte, error) {
+	if vr.read >= len(vr.manifest.Items) {
+		if _, err := io.ReadFull(vr.r, make([]byte, 1)); err != io.EOF {
+			return nil, fmt.Errorf("%w: unexpected data after last item", ErrCorrupted)
+		}
+		return nil, io.EOF
+	}
+
+	var content []byte
+	for {
+		plain, err := vr.readFrame(vr.header.ChunkSize + 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(plain) == 0 {
+			return nil, ErrCorrupted
+		}
+		content = append(content, plain[1:]...)
+		if plain[0]&flagLast != 0 {
+			break
+		}
+	}
+	vr.read++
+	return content, nil
+}
+
+// readFrame чтение и расшифровка кадра
+func (vr *Reader) readFrame(limit int) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(vr.r, size[:]); err != nil {
+		return nil, fmt.Errorf("%w: truncated", ErrCorrupted)
+	}
+	n := int(binary.BigEndian.Uint32(size[:]))
+	nonceSize := vr.aead.NonceSize()
+	if n < nonceSize+vr.aead.Overhead() || n > limit+nonceSize+vr.aead.Overhead() {
+		return nil, ErrCorrupted
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(vr.r, sealed); err != nil {
+		return nil, fmt.Errorf("%w: truncated", ErrCorrupted)
+	}
+
+	plain, err := vr.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], frameAD(vr.headerSum, vr.frame))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	vr.frame++
+	return plain, nil
+}
+
+// validate проверка поддерживаемых параметров заголовка
+func (h Header) validate() error {
+	if h.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	if h.Cipher != cipherName || h.KDF.Name != kdfName {
+		return fmt.Errorf("%w: unsupported cipher %q or kdf %q", ErrUnsupportedVersion, h.Cipher, h.KDF.Name)
+	}
+	if len(h.KDF.Salt) < saltLen ||
+		h.KDF.Time == 0 || h.KDF.Time > maxKDFTime ||
+		h.KDF.Memory == 0 || h.KDF.Memory > maxKDFMemory ||
+		h.KDF.Threads == 0 || h.KDF.Threads > maxKDFThreads {
+		return fmt.Errorf("%w: invalid kdf parameters", ErrCorrupted)
+	}
+	if h.ChunkSize <= 0 || h.ChunkSize > maxChunk {
+		return fmt.Errorf("%w: invalid chunk size", ErrCorrupted)
+	}
+	return nil
+}
+
+// newAEAD вывод ключа архива из пароля
+func newAEAD(password string, kdf KDF) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(password), kdf.Salt, kdf.Time, kdf.Memory, kdf.Threads, keyLen)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encodePrefix открытая часть архива перед кадрами
+func encodePrefix(headerData []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(Version))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(headerData)))
+	buf.Write(headerData)
+	return buf.Bytes()
+}
+
+// headerSum хеш открытой части архива для дополнительных данных AEAD
+func headerSum(prefix []byte) []byte {
+	sum := sha256.Sum256(prefix)
+	return sum[:]
+}
+
+// frameAD дополнительные данные AEAD кадра с указанным номером
+func frameAD(headerSum []byte, frame uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(nil), headerSum...), frame)
+}
@@ -0,0 +1,126 @@
+package vaultfile
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeArchive(t *testing.T, password string, chunkSize int, manifest Manifest, contents ...[]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, password, manifest)
+	require.NoError(t, err)
+	if chunkSize > 0 {
+		w.chunkSize = chunkSize
+	}
+	for _, content := range contents {
+		require.NoError(t, w.WriteItem(content))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func testManifest() Manifest {
+	return Manifest{Items: []Entry{
+		{Name: "mail", Type: "password", Folder: "/work", Tags: []string{"email"}, CreatedAt: 1, UpdatedAt: 2},
+		{Name: "empty", Type: "text"},
+		{Name: "photo", Type: "file"},
+	}}
+}
+
+func TestRoundTrip(t *testing.T) {
+	contents := [][]byte{[]byte("login:pass1"), nil, []byte("0123456789")}
+	data := writeArchive(t, "secret", 4, testManifest(), contents...)
+
+	r, err := NewReader(bytes.NewReader(data), "secret")
+	require.NoError(t, err)
+	assert.Equal(t, Version, r.Header().Version)
+	assert.Equal(t, testManifest(), r.Manifest())
+
+	for _, want := range contents {
+		got, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+	_, err = r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestNewReader_WrongPassword(t *testing.T) {
+	data := writeArchive(t, "secret", 0, testManifest(), []byte("a"), nil, []byte("b"))
+
+	_, err := NewReader(bytes.NewReader(data), "other")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestNewReader_NotVaultFile(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte(`{"items":[]}`)), "secret")
+	assert.ErrorIs(t, err, ErrNotVaultFile)
+
+	_, err = NewReader(bytes.NewReader(nil), "secret")
+	assert.ErrorIs(t, err, ErrNotVaultFile)
+}
+
+func TestNewReader_UnsupportedVersion(t *testing.T) {
+	data := writeArchive(t, "secret", 0, Manifest{})
+	data[len(magic)+1] = 2
+
+	_, err := NewReader(bytes.NewReader(data), "secret")
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestNewReader_TamperedHeader(t *testing.T) {
+	data := writeArchive(t, "secret", 0, Manifest{})
+	// Изменение даты создания в открытом заголовке ломает дополнительные данные кадров
+	i := bytes.Index(data, []byte(`"created_at":"`)) + len(`"created_at":"`)
+	data[i] = '1'
+
+	_, err := NewReader(bytes.NewReader(data), "secret")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestNewReader_InvalidKDF(t *testing.T) {
+	data := writeArchive(t, "secret", 0, Manifest{})
+	data = bytes.Replace(data, []byte(`"threads":4`), []byte(`"threads":0`), 1)
+
+	_, err := NewReader(bytes.NewReader(data), "secret")
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestReader_Truncated(t *testing.T) {
+	data := writeArchive(t, "secret", 4, testManifest(), []byte("login:pass1"), nil, []byte("0123456789"))
+
+	r, err := NewReader(bytes.NewReader(data[:len(data)-10]), "secret")
+	require.NoError(t, err)
+	_, err = r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestReader_TrailingData(t *testing.T) {
+	data := writeArchive(t, "secret", 0, Manifest{})
+	data = append(data, 0)
+
+	r, err := NewReader(bytes.NewReader(data), "secret")
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestWriter_ItemCount(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "secret", Manifest{Items: []Entry{{Name: "a", Type: "text"}}})
+	require.NoError(t, err)
+	assert.Error(t, w.Close())
+
+	require.NoError(t, w.WriteItem([]byte("a")))
+	assert.Error(t, w.WriteItem([]byte("b")))
+	assert.NoError(t, w.Close())
+}